@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/fatih/color"
+)
+
+// applicationsSummary contains the results of checking the Argo CD Applications that are managed by a single Argo CD instance
+type applicationsSummary struct {
+	total int
+
+	// The following fields are lists of Applications in 'namespace/name' format
+
+	// outOfSync contains Applications with a sync status of 'OutOfSync'
+	outOfSync []string
+
+	// manualSync contains Applications which do not have automated sync enabled (sync policy is absent, or automated sync is disabled)
+	manualSync []string
+
+	// syncError contains Applications with a 'SyncError' condition
+	syncError []string
+}
+
+// checkApplications summarizes the health of the Applications (from the given list) that are managed by the given Argo CD instance. An Application is managed by the instance if it is in the instance's namespace, or in one of the instance's '.spec.sourceNamespaces'.
+func checkApplications(argoCD v1beta1.ArgoCD, applications []argocdv1alpha1.Application) applicationsSummary {
+
+	var res applicationsSummary
+
+	for _, app := range applications {
+
+		if !applicationIsManagedByArgoCD(app, argoCD) {
+			continue
+		}
+
+		res.total++
+
+		appName := app.Namespace + "/" + app.Name
+
+		if app.Status.Sync.Status == argocdv1alpha1.SyncStatusCodeOutOfSync {
+			res.outOfSync = append(res.outOfSync, appName)
+		}
+
+		if app.Spec.SyncPolicy == nil || !app.Spec.SyncPolicy.IsAutomatedSyncEnabled() {
+			res.manualSync = append(res.manualSync, appName)
+		}
+
+		for _, condition := range app.Status.Conditions {
+			if condition.Type == argocdv1alpha1.ApplicationConditionSyncError {
+				res.syncError = append(res.syncError, appName)
+				break
+			}
+		}
+	}
+
+	sort.Strings(res.outOfSync)
+	sort.Strings(res.manualSync)
+	sort.Strings(res.syncError)
+
+	return res
+}
+
+// applicationIsManagedByArgoCD returns true if the Application is in the namespace of the Argo CD instance, or in one of the instance's '.spec.sourceNamespaces' (which may contain glob patterns)
+func applicationIsManagedByArgoCD(app argocdv1alpha1.Application, argoCD v1beta1.ArgoCD) bool {
+
+	if app.Namespace == argoCD.Namespace {
+		return true
+	}
+
+	for _, sourceNamespace := range argoCD.Spec.SourceNamespaces {
+		if matched, err := filepath.Match(sourceNamespace, app.Namespace); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// outputApplicationsSummary outputs the Application counts for an Argo CD instance. If verbose is true, the names of the Applications in each category are also output.
+func outputApplicationsSummary(summary applicationsSummary, verbose bool) {
+
+	coloredApplications := color.New(color.FgHiWhite, color.Bold).Sprint("Applications")
+
+	outputStatusMessage(fmt.Sprintf("%s: %d total, %d OutOfSync, %d with manual sync (no automated sync policy), %d with SyncError condition", coloredApplications, summary.total, len(summary.outOfSync), len(summary.manualSync), len(summary.syncError)))
+
+	if !verbose {
+		return
+	}
+
+	categories := []struct {
+		name         string
+		applications []string
+	}{
+		{name: "OutOfSync", applications: summary.outOfSync},
+		{name: "Manual sync", applications: summary.manualSync},
+		{name: "SyncError", applications: summary.syncError},
+	}
+
+	for _, category := range categories {
+		if len(category.applications) > 0 {
+			outputStatusMessage("- " + category.name + ": " + strings.Join(category.applications, ", "))
+		}
+	}
+}
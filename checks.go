@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/fatih/color"
+	"github.com/jgwest/argocd-config-check/checks"
+	"github.com/jgwest/argocd-config-check/clients"
+)
+
+// checkRegistration describes a single built-in check: its rule ID, an explanation of the check (output by '--explain'), and the function that implements it.
+// - Exactly one of 'check' and 'clusterCheck' should be set.
+// - All issues reported by the check function are tagged with the rule ID of the check.
+// - Checks with 'check' set are registered with the checks package registry (see checks.Register), and so are run by checks.RunChecks. Checks with 'clusterCheck' set are run by checkIndividualArgoCDCRAgainstCluster.
+// - Checks with 'optInFlag' set are only run when the user specifies that flag.
+type checkRegistration struct {
+	// ruleID is the stable identifier of the check, e.g. 'ACC001'. Rule IDs must never be reused for a different check.
+	ruleID string
+
+	// title is a short (one line) description of the check
+	title string
+
+	// explanation is an extended description of what the check looks for, why it matters, and how to fix it
+	explanation string
+
+	// check is set for checks which only require the ArgoCD CR (and cluster information)
+	check func(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue)
+
+	// clusterCheck is set for checks which must read other resources from the cluster. These checks are skipped when the cluster data is incomplete (e.g. must-gather).
+	clusterCheck func(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue)
+
+	// optInFlag is the command line flag (e.g. '--check-secrets') which enables the check, or empty if the check always runs. Since the user explicitly requested them, opt-in cluster checks are also run when the cluster data is incomplete: they must take this into account when choosing the severity of issues (see k8sClient.IncompleteControlPlaneData).
+	optInFlag string
+
+	// supersedes is the rule ID of a check of the ArgoCD CR whose issues are dropped when this cluster check is run, or empty. This is set where the cluster check confirms (against the deployed resources) what the superseded check can only infer from the ArgoCD CR, so that the same condition is not reported by both.
+	supersedes string
+
+	// applicable returns false (and the reason) if the check does not apply to the ArgoCD CR, for example a check of the Dex configuration when Dex is not configured. This is only used to report the coverage of the checks (see computeCheckCoverage): the check itself must still handle CRs which it does not apply to. nil if the check applies to all ArgoCD CRs.
+	applicable func(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation) (bool, string)
+}
+
+// registeredChecks is the list of all checks, in the order they are run
+var registeredChecks = []checkRegistration{
+	{
+		// This check must be first: its issues stop all further checks of the ArgoCD CR
+		ruleID:      "ACC036",
+		title:       "ArgoCD CR which cannot be checked",
+		explanation: "Looks for ArgoCD CRs for which the results of all other checks would be meaningless or misleading: a CR which is being deleted ('.metadata.deletionTimestamp' is set, so the operator no longer reconciles its configuration), or a CR without a name or namespace. These are reported as Fatal, and no further checks (including checks against the cluster) are run against the CR; an additional Info issue lists the checks which were skipped. If a CR remains in the deleting state, verify that the operator is running, and check the finalizers of the CR.",
+		check:       withoutClusterInfo(checkForInstancesWhichCannotBeChecked),
+	},
+	{
+		ruleID:      "ACC001",
+		title:       "Deprecated ArgoCD CR fields",
+		explanation: "Looks for ArgoCD CR fields which are deprecated, and are now ignored by the operator (for example '.spec.grafana', '.spec.initialRepositories', '.spec.sso.keycloak'). Keycloak is only an Error on operator versions which no longer manage it (or when the operator version is unknown): on earlier versions, its upcoming removal is a Warn. Configuration in these fields has no effect, so functionality the user expects may be silently missing. Remove the deprecated field, and use the replacement mechanism described in the issue message.",
+		check:       checkArgoCDCRForDeprecatedFields,
+	},
+	{
+		ruleID:      "ACC002",
+		title:       "Custom container images for Argo CD components",
+		explanation: "Looks for '.image' fields which replace the container image of an essential Argo CD component (for example '.spec.repo.image'). Only the official OpenShift GitOps images are supported: custom images are an unsupported configuration, and may not be compatible with the operator. Remove the '.image' field, so that the operator uses the supported image. In mirrored/air-gapped environments, use '--allowlist-images' to list the registry prefixes (or exact images) of the organization's mirrors of the official images: matching images are reported as Info, rather than as unsupported.",
+		check:       checkArgoCDCRForUnsupportedCustomImages,
+	},
+	{
+		ruleID:      "ACC003",
+		title:       "Tech preview or experimental features",
+		explanation: "Looks for features which are tech preview in OpenShift GitOps, or experimental upstream (for example ApplicationSets in any namespace, progressive syncs, dynamic controller sharding, and non-default sharding algorithms). These features are not intended for production usage, and are not covered by production support. Disable the feature for production instances, or accept the tech preview scope of support. Progressive syncs which are enabled by one of '--enable-progressive-syncs' (in '.spec.applicationSet.extraCommandArgs') and 'ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_PROGRESSIVE_SYNCS' (in '.spec.applicationSet.env'), but disabled by the other, are also reported as an Error: the argument takes precedence, so the env var is ignored.",
+		check:       withoutClusterInfo(checkForTechPreviewOrExperimentalFeatures),
+	},
+	{
+		ruleID:      "ACC004",
+		title:       "Env vars/arguments/extraConfig which overlap with ArgoCD CR fields",
+		explanation: "Looks for environment variables, container arguments, and '.spec.extraConfig' keys which configure a setting that has a dedicated ArgoCD CR field (for example 'ARGOCD_API_SERVER_REPLICAS' rather than '.spec.server.replicas'). The two may conflict, and in some cases the operator overwrites the value. It also looks for boolean feature flags (for example 'ARGOCD_SERVER_INSECURE' or '--repo-server-strict-tls') which have a dedicated ArgoCD CR boolean: a value which matches the CR field is redundant (Warn), while a value which contradicts it is an Error. Env vars are checked against a table of the env vars read by each Argo CD component: env vars which are not known to be read by the component (for example, a misspelled env var, or one which is not supported by this Argo CD version) are reported as Info. The server subpath settings ('--rootpath'/'--basehref', or the 'ARGOCD_SERVER_ROOTPATH'/'ARGOCD_SERVER_BASEHREF' env vars) are checked for values set in more than one place: conflicting values (including a base href which differs from the root path, and is therefore ignored) are an Error, and redundant values a Warn. Remove the env var/argument/extraConfig key, and use the ArgoCD CR field named in the issue message.",
+		check:       withoutClusterInfo(checkForEnvVarsOrParamsWhichOverlapWithCRFields),
+	},
+	{
+		ruleID:      "ACC005",
+		title:       "Incorrect configurations",
+		explanation: "Looks for combinations of fields which are incorrect: for example 'argocd-cmd-params-cm' keys in '.spec.extraConfig' (which only supports 'argocd-cm' keys), unsupported '.spec.cmdParams' keys, the same key set in both '.spec.extraConfig' and '.spec.cmdParams' (only one of which is in effect), sharding fields which are ignored (including a static number of shards with dynamic scaling), sharding enabled with a zero or negative number of shards, dynamic scaling shard bounds which prevent scaling, HA-only fields while HA is disabled, processor counts too large for the memory limit, and a server root path which is not included in the external URL ('url'). These settings either have no effect, or cause unexpected behaviour. Follow the remediation described in the issue message.",
+		check:       withoutClusterInfo(checkForIncorrectConfigurations),
+	},
+	{
+		ruleID:      "ACC006",
+		title:       "ArgoCD CR status",
+		explanation: "Looks at the '.status' of the ArgoCD CR, reporting when '.status.phase' is not 'Available', when a status condition indicates a problem (e.g. the 'Reconciled' condition is not true), or when the status of an enabled component (e.g. '.status.server', '.status.repo') is not 'Running'. A condition type or component status which the tool does not recognize is a Warn, since it may not indicate a problem; the known failure conditions and statuses are an Error. This indicates that one or more components are not running, or that the operator failed to reconcile the CR. Check the condition message, the operator logs, and the status of the component pods in the ArgoCD namespace.",
+		check:       withoutClusterInfo(checkArgoCDStatusField),
+	},
+	{
+		ruleID:      "ACC007",
+		title:       "Failing best practices",
+		explanation: "Looks for configurations which work, but do not follow best practices: for example an insecure server, a server exposed via a LoadBalancer/NodePort Service rather than a Route/Ingress, Argo CD Agent running with insecure TLS, a sharded controller fronted by a single server replica, or HA enabled without increasing the resources of the redis/HAProxy and controller pods. These reduce the security or scalability of the instance. Follow the recommendation in the issue message.",
+		check:       withoutClusterInfo(checkForFailingBestPractices),
+	},
+	{
+		ruleID:      "ACC008",
+		title:       "Local admin account enabled",
+		explanation: "Looks for instances where the local 'admin' account is enabled. The admin account is a shared credential with full privileges within the instance; on a cluster-scoped instance this effectively includes cluster-wide privileges, and so it is reported as an Error. On a namespace-scoped instance the admin account is a common configuration (e.g. for development instances), and so it is only reported as Info. Configure SSO, then set '.spec.disableAdmin' to true.",
+		check:       checkLocalAdminAccount,
+	},
+	{
+		ruleID:      "ACC009",
+		title:       "Malformed env var values",
+		explanation: "Looks for well-known Argo CD environment variables whose value cannot be parsed as the expected type (for example 'ARGOCD_RECONCILIATION_TIMEOUT=180', which is missing a duration unit). A malformed value may prevent the component from starting, or be silently ignored. Correct the value to the format given in the issue message.",
+		check:       withoutClusterInfo(checkForMalformedEnvVarValues),
+	},
+	{
+		ruleID:      "ACC010",
+		title:       "Disabled core components",
+		explanation: "Looks for core components (application controller, server, repo server, redis) which have been explicitly disabled. Outside of Argo CD Agent configurations, this breaks core functionality: for example, without a repo server no manifests can be generated. Re-enable the component, unless it is intentionally replaced (e.g. by a remote repo server/redis).",
+		check:       withoutClusterInfo(checkForDisabledCoreComponents),
+	},
+	{
+		ruleID:      "ACC011",
+		title:       "Reconciliation timeout outside a safe range",
+		explanation: "Resolves the reconciliation timeout from '.spec.controller.env[ARGOCD_RECONCILIATION_TIMEOUT]', '.spec.controller.appSync', and '.spec.extraConfig[timeout.reconciliation]', reporting when they disagree, or when the value is very low (causing constant re-reconciliation, and load on the API server, Git, and repo server) or very high (delaying drift detection). Set the timeout in only one place, preferably '.spec.controller.appSync', to a value such as the default of 180s.",
+		check:       withoutClusterInfo(checkReconciliationTimeout),
+	},
+	{
+		ruleID:      "ACC012",
+		title:       "Malformed SSH known hosts or TLS certificates",
+		explanation: "Validates the structure of '.spec.initialSSHKnownHosts.keys' (each line should be 'host keytype key') and '.spec.tls.initialCerts' (each value should be PEM-encoded certificates). Malformed entries cause repository connection failures which are difficult to trace back to the ArgoCD CR. Correct the malformed line or certificate reported in the issue message.",
+		check:       withoutClusterInfo(checkForMalformedRepositoryConnectionConfig),
+	},
+	{
+		ruleID:      "ACC013",
+		title:       "Inconsistent Redis TLS configuration",
+		explanation: "Evaluates '.spec.redis.autotls', '.spec.redis.disableTLSVerification', and manually-specified Redis TLS arguments for consistency. Partial configurations result in either components which cannot connect to Redis, or connections which are not verified. Enable TLS for all components with '.spec.redis.autotls: openshift', and leave TLS verification enabled.",
+		check:       withoutClusterInfo(checkRedisTLSConfiguration),
+	},
+	{
+		ruleID:      "ACC014",
+		title:       "Resource compare options which suppress diffing",
+		explanation: "Validates '.spec.extraConfig[resource.compareoptions]', and reports options which broadly suppress diffing (such as 'ignoreAggregatedRoles: true'). Suppressed differences mean that drift between Git and the cluster is not detected. Prefer targeted 'ignoreDifferences' on only the affected resources.",
+		check:       withoutClusterInfo(checkResourceCompareOptions),
+	},
+	{
+		ruleID:      "ACC019",
+		title:       "Cache/temporary paths on a read-only root filesystem",
+		explanation: "Looks for cache/temporary path env vars (such as 'KUBECACHEDIR', 'HOME', 'TMPDIR', 'XDG_CACHE_HOME', 'HELM_CACHE_HOME') of the controller, server, and repo server, whose path is not on a writable volume. The operator runs these components with a read-only root filesystem, so writes to such a path fail, and may cause the component to crash loop. This is a heuristic based on the ArgoCD CR: on a live cluster, it is superseded by ACC020, which checks the deployed containers. Use a path under a writable volume (such as '/tmp'), or add an emptyDir volume and volume mount for the path.",
+		check:       withoutClusterInfo(checkForCachePathsOnReadOnlyRootFilesystem),
+	},
+	{
+		ruleID:      "ACC021",
+		title:       "Conflicting managed-by namespace labels",
+		explanation: "Looks for namespaces which carry more than one of the managed-by label variants ('argocd.argoproj.io/managed-by', 'argocd.argoproj.io/managed-by-cluster-argocd', 'argocd.argoproj.io/applicationset-managed-by-cluster-argocd', 'argocd.argoproj.io/notifications-managed-by-cluster-argocd'), where the labels point at different Argo CD instances. Which instance manages the namespace, and thus the effective RBAC within it, is then ambiguous. Remove the labels which do not point at the intended Argo CD instance.",
+		check:       checkForConflictingManagedByLabels,
+	},
+	{
+		ruleID:      "ACC023",
+		title:       "Ineffective resource health check scripts",
+		explanation: "Looks for '.spec.resourceHealthChecks' entries which have no 'kind', an empty 'check' script, or (best-effort) a Lua script which never returns a health status (no 'return hs' statement). Such health checks silently disable health assessment for the resource kind. Provide a Lua script which returns a table with a 'status' field, or remove the entry.",
+		check:       withoutClusterInfo(checkResourceHealthChecks),
+	},
+	{
+		ruleID:      "ACC024",
+		title:       "Settings below the selected sizing profile",
+		explanation: "Only run when a workload size profile is selected with '--profile'. Reports ArgoCD CR settings (controller sharding, processors, and parallelism, server/repo server replicas, and controller/repo server memory limits) which fall below the expectations of the profile, and so are likely to be a bottleneck at that scale. Unset fields are compared using the Argo CD default values. Profiles: " + describeSizingProfiles(),
+		check:       checkSizingProfile,
+		applicable:  applicableWhenSizingProfileSelected,
+	},
+	{
+		ruleID:      "ACC026",
+		title:       "respectRBAC not enabled or invalid",
+		explanation: "Resolves the effective 'resource.respectRBAC' value (from '.spec.controller.respectRBAC' or '.spec.extraConfig'), reporting an Error if it is not one of 'normal' or 'strict', and a Warn if it is unset on a cluster-scoped instance. Without respectRBAC, the controller attempts to list/watch all resource types regardless of its permissions, which causes errors when its RBAC is restricted. Set '.spec.controller.respectRBAC' to 'normal' or 'strict'.",
+		check:       checkRespectRBAC,
+	},
+	{
+		ruleID:      "ACC027",
+		title:       "Invalid Dex configuration",
+		explanation: "Parses the Dex configuration in '.spec.sso.dex.config', reporting an Error if it is not valid YAML, or if more than one connector has the same 'id'. Either problem prevents Dex from starting (and thus prevents SSO login), and is otherwise only visible in the Dex pod logs. Fix the YAML, and give each connector a unique 'id'.",
+		check:       withoutClusterInfo(checkDexConfig),
+		applicable:  applicableWhenDexConfigured,
+	},
+	{
+		ruleID:      "ACC029",
+		title:       "Missing or partial component resources",
+		explanation: "Looks for instances which do not specify resource requests/limits for the application controller ('.spec.controller.resources'). The memory usage of the controller grows with the number of resources it manages, so without explicit resources it is likely to be OOM killed or starved by other workloads. This is an Error for cluster-scoped instances (which may manage the entire cluster), and Info for namespace-scoped instances (which usually manage fewer resources). Set '.spec.controller.resources', in particular a memory request and limit. Also looks for any enabled component whose resources set only one side of the CPU or memory requirements (Warn): a limit without a request (K8s then defaults the request to the limit, so the full limit is reserved on the node, making the pod harder to schedule), or a memory request without a memory limit (memory usage is unbounded, so the pod may exhaust node memory, and be evicted or OOM killed). A CPU request without a CPU limit is a common, deliberate configuration (it avoids CPU throttling), so it is not reported. Set both the request and the limit of memory, and at least the request of CPU.",
+		check:       checkComponentResources,
+	},
+	{
+		ruleID:      "ACC030",
+		title:       "Contradictory or mismatched '.spec.image'/'.spec.version'",
+		explanation: "Looks for a '.spec.version' which is set together with a '.spec.image' that already includes a tag or digest (the operator appends the version to the image, so the resulting image reference is invalid), and, when the operator version is known, for a '.spec.version' whose Argo CD major/minor version differs from the version shipped with the operator. Remove '.spec.version' (and ideally '.spec.image', see ACC002) so that the images shipped with the operator are used.",
+		check:       checkImageAndVersion,
+	},
+	{
+		ruleID:      "ACC033",
+		title:       "Conflicting Dex configuration sources",
+		explanation: "Looks for Dex configuration which is set both via '.spec.sso.dex' ('config' or 'openShiftOAuth') and via the legacy '.spec.extraConfig[dex.config]' key, which commonly remains on CRs migrated from older operator versions. Only one of these is used by the operator ('.spec.sso.dex.openShiftOAuth' if true, otherwise '.spec.extraConfig[dex.config]'), so the other is silently ignored, and it is unclear which configuration is in effect. Remove the configuration which is not intended, and configure Dex only via '.spec.sso.dex'.",
+		check:       checkForConflictingDexConfigSources,
+		applicable:  applicableWhenDexConfigured,
+	},
+	{
+		ruleID:      "ACC034",
+		title:       "Custom application controller containers",
+		explanation: "Looks for custom init containers ('.spec.controller.initContainers') and sidecar containers ('.spec.controller.sidecarContainers') on the application controller. These are allowed, but may interfere with assumptions the operator makes about the controller StatefulSet (ordinal-based sharding, the controller's volume layout), and are not generally covered by support. A custom container which mounts one of the controller's operator-managed volumes as writable, or a '.spec.controller.volumes' entry which redefines one of them, is reported as unsupported. Remove the custom containers if they are not required, and never modify the controller's operator-managed volumes.",
+		check:       withoutClusterInfo(checkControllerCustomContainers),
+	},
+	{
+		ruleID:      "ACC035",
+		title:       "Resource exclusions which exclude critical kinds",
+		explanation: "Parses the resource exclusions ('.spec.resourceExclusions', or '.spec.extraConfig[resource.exclusions]'), and looks for entries which match kinds that almost every Argo CD instance needs to manage (for example 'apps/Deployment', 'Secret', or Argo CD's own 'argoproj.io' resources), using the same matching rules as Argo CD (an empty 'apiGroups'/'kinds' list matches everything). Excluded resources are not tracked, synced, or pruned by any Application, so Applications silently stop managing them. An exclusion value which cannot be parsed is an Error. Narrow the exclusion to the specific groups/kinds that should be ignored.",
+		check:       withoutClusterInfo(checkResourceExclusions),
+	},
+	{
+		ruleID:      "ACC037",
+		title:       "Ineffective server autoscaling",
+		explanation: "Looks for server autoscaling ('.spec.server.autoscale.enabled') with a custom HorizontalPodAutoscaler spec ('.spec.server.autoscale.hpa') that is invalid or ineffective: a missing 'maxReplicas', a 'minReplicas' greater than (Error) or equal to (Warn) 'maxReplicas', a missing or extreme 'targetCPUUtilizationPercentage', or a 'scaleTargetRef' which is not the server Deployment. Also looks for autoscaling without a server CPU request, since the HPA computes CPU utilization relative to the request, and so cannot scale the server without one. Correct the '.spec.server.autoscale.hpa' fields, or remove 'hpa' to use the operator defaults.",
+		check:       withoutClusterInfo(checkServerAutoscale),
+		applicable:  applicableWhenServerAutoscaleEnabled,
+	},
+	{
+		ruleID:      "ACC039",
+		title:       "Web-based terminal enabled",
+		explanation: "Looks for instances which enable the web-based terminal ('exec.enabled: true' in '.spec.extraConfig'). The terminal allows users with the 'exec' 'create' RBAC permission to open a shell in any pod of the Applications they have access to, via the Argo CD UI, and the commands run within it are not audited by Argo CD. This is a Warn, escalated to an Error when the RBAC configuration grants the permission to every user: when the default role ('.spec.rbac.defaultPolicy') is 'role:admin', or '.spec.rbac.policy' grants 'exec' to the default role (directly, or to a role that the default role inherits via a 'g' line, such as 'role:admin'). Grant 'exec' only to trusted roles, and disable the terminal if it is not required.",
+		check:       withoutClusterInfo(checkWebTerminal),
+	},
+	{
+		ruleID:      "ACC040",
+		title:       "Invalid or inconsistent log formats",
+		explanation: "Validates the log format of each enabled Argo CD component, from each source where it may be set: the CR field (e.g. '.spec.controller.logFormat'), '.spec.cmdParams' (e.g. 'controller.log.format'), and env vars (e.g. 'ARGOCD_APPLICATION_CONTROLLER_LOGFORMAT' or 'ARGOCD_LOG_FORMAT'). A value other than 'text' or 'json' is an Error. The operator always passes the CR field value (or 'text') to the component as a container argument, which takes precedence, so a different value in another source is ignored (Warn). Components which log in different formats complicate log aggregation, so a component whose resolved format differs from the other components is also a Warn. Set the log format via the CR field of each component, using the same value for all components.",
+		check:       withoutClusterInfo(checkLogFormats),
+	},
+	{
+		ruleID:      "ACC041",
+		title:       "Repo server service account token exposed",
+		explanation: "Looks for configuration which makes a service account token available to the repo server: '.spec.repo.mountsatoken: true', a projected 'serviceAccountToken' volume in '.spec.repo.volumes', or a volume mounted at the standard token path ('/var/run/secrets/kubernetes.io/serviceaccount') in '.spec.repo.volumeMounts'. The repo server does not use the K8s API, so the operator does not mount the token by default: a token that is made available widens the impact of a compromise of the repo server (which runs Helm, Kustomize, and plugins against repository content). Remove the token unless a config management plugin requires K8s API access, in which case grant the service account only the minimum RBAC permissions. Only the repo server is checked: the application controller, server, ApplicationSet controller, and other components use the K8s API, so they require a token.",
+		check:       withoutClusterInfo(checkRepoServerServiceAccountToken),
+	},
+	{
+		ruleID:      "ACC043",
+		title:       "Application status badge enabled",
+		explanation: "Looks for instances which enable the Application status badge ('.spec.statusBadgeEnabled', or 'statusbadge.enabled' in '.spec.extraConfig'). The badge endpoint returns the sync and health status of any Application without authentication, which is a minor information disclosure. This is often intentional, so it is a Warn, escalated to an Error when anonymous access ('.spec.usersAnonymousEnabled', or 'users.anonymous.enabled' in '.spec.extraConfig') is also enabled. Disable the status badge if it is not used, and disable anonymous access unless the instance is intended to be public.",
+		check:       withoutClusterInfo(checkStatusBadge),
+	},
+	{
+		ruleID:      "ACC044",
+		title:       "Application controller repo server timeout too low",
+		explanation: "Resolves the timeout of the application controller's calls to the repo server (e.g. to generate the manifests of an Application) from '.spec.controller.extraCommandArgs' ('--repo-server-timeout-seconds', which takes precedence) and '.spec.controller.env[ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS]', reporting when they disagree, or when the resolved value is below the Argo CD default of 60s (or is 0, which disables the timeout). Manifest generation of large repositories (e.g. monorepos) or slow Helm/Kustomize/plugin builds may take longer than a low timeout, causing Applications to fail to sync with 'context deadline exceeded'. The 'controller.repo.server.timeout.seconds' key is an 'argocd-cmd-params-cm' key, so it has no effect in '.spec.extraConfig' (this is reported by ACC005, rather than here). Set the timeout via the env var, to at least 60s (large monorepos commonly require 180s or more).",
+		check:       withoutClusterInfo(checkControllerRepoServerTimeout),
+	},
+	{
+		ruleID:      "ACC045",
+		title:       "Insecure Dex connector configuration",
+		explanation: "Looks for Dex connectors (in '.spec.sso.dex.config', or the legacy '.spec.extraConfig[dex.config]') which disable TLS certificate verification ('insecureSkipVerify: true', or 'insecureCA: true' for the OpenShift connector), reported as a Warn, or which communicate with the identity provider without TLS (an 'http://' 'issuer', 'baseURL', or 'ssoURL', or 'insecureNoSSL: true' for the LDAP connector), reported as an Error. These are commonly development shortcuts left in production configuration: they allow the identities of users who log in via the connector to be intercepted or forged. Use 'https://' URLs, and configure the CA certificate of the identity provider rather than disabling verification.",
+		check:       withoutClusterInfo(checkForInsecureDexConnectors),
+		applicable:  applicableWhenDexConfigured,
+	},
+	{
+		ruleID:      "ACC046",
+		title:       "Conflicting resource tracking method and instance label key",
+		explanation: "Resolves the resource tracking method ('.spec.resourceTrackingMethod', or 'application.resourceTrackingMethod' in '.spec.extraConfig', default 'annotation') and the application instance label key ('.spec.applicationInstanceLabelKey', or 'application.instanceLabelKey' in '.spec.extraConfig', default 'app.kubernetes.io/instance'), and reports combinations which do not behave as commonly expected. With 'annotation' tracking, a custom label key has no effect on which resources belong to an Application, which confuses users who believe they changed tracking behavior (Warn). With 'label' or 'annotation+label' tracking, the default label key is also set by many Helm charts and other tools, causing resources to be incorrectly tracked and pruned (Warn). Either use 'annotation' tracking without a custom label key, or set a custom label key when tracking via labels.",
+		check:       withoutClusterInfo(checkResourceTrackingLabelKey),
+	},
+	{
+		ruleID:      "ACC047",
+		title:       "Source namespaces which do not exist",
+		explanation: "Live cluster only. Looks for namespaces in '.spec.sourceNamespaces' (Applications in any namespace), '.spec.applicationSet.sourceNamespaces', and '.spec.notifications.sourceNamespaces' which do not exist on the cluster, and for glob patterns (e.g. 'team-*') or regular expressions (e.g. '/^team-.*$/') which match no namespace. The feature then silently covers nothing in that namespace, which is commonly caused by a typo in the namespace name, or a namespace which has not yet been created (the operator does not create it). Correct the namespace name or pattern, create the namespace, or remove the entry.",
+		check:       checkForMissingSourceNamespaces,
+	},
+	{
+		ruleID:       "ACC015",
+		title:        "ResourceQuota conflicts",
+		explanation:  "Live cluster only. Compares the total CPU/memory requests and limits of the Argo CD components against the ResourceQuotas in the ArgoCD namespace. If the quota is tighter than the components require, some component pods will not be admitted. Increase the quota, or reduce component resources.",
+		clusterCheck: checkForResourceQuotaConflicts,
+	},
+	{
+		ruleID:       "ACC016",
+		title:        "LimitRange conflicts",
+		explanation:  "Live cluster only. Compares each Argo CD component's CPU/memory requests and limits against the container min/max of the LimitRanges in the ArgoCD namespace. Pods whose resources are outside the LimitRange are rejected. Adjust the component resources in the ArgoCD CR, or the LimitRange.",
+		clusterCheck: checkForLimitRangeConflicts,
+	},
+	{
+		ruleID:       "ACC017",
+		title:        "Local accounts without a password",
+		explanation:  "Live cluster only. Cross-references local accounts with the 'login' capability (defined via 'accounts.<name>' in '.spec.extraConfig') against the 'argocd-secret' Secret. An account without an 'accounts.<name>.password' key cannot log in. Set a password for the account, e.g. via 'argocd account update-password --account <name>'.",
+		clusterCheck: checkForLocalAccountsWithoutPassword,
+	},
+	{
+		ruleID:       "ACC018",
+		title:        "Insufficient node capacity",
+		explanation:  "Live cluster only. Compares the total CPU/memory requests of all enabled Argo CD components against the allocatable capacity of the nodes that the components may be scheduled to (based on '.spec.nodePlacement' node selector and tolerations). If the requests exceed capacity, not all component pods can be scheduled. Reduce component requests, or make additional nodes eligible.",
+		clusterCheck: checkForInsufficientNodeCapacity,
+	},
+	{
+		ruleID:       "ACC020",
+		title:        "Cache/temporary paths not writable in deployed containers",
+		explanation:  "Live cluster only. Looks for cache/temporary path env vars (such as 'KUBECACHEDIR') of the deployed controller, server, and repo server containers, where the container has 'readOnlyRootFilesystem: true' and the path is not on a writable volume. This supersedes the ArgoCD CR heuristic of ACC019, whose findings are not reported when this check is run. Writes to such a path fail, and may cause the component to crash loop. Use a path under a writable volume (such as '/tmp'), or add an emptyDir volume and volume mount for the path.",
+		clusterCheck: checkForCachePathsAgainstDeployedContainers,
+		supersedes:   "ACC019",
+	},
+	{
+		ruleID:       "ACC022",
+		title:        "Orphaned Grafana resources",
+		explanation:  "Live cluster only. Looks for Grafana resources (Deployment, Service, ConfigMaps, Secret, Route) in the ArgoCD namespace which were created by older versions of the operator via '.spec.grafana'. Grafana support has been removed from the operator, so these resources are no longer managed, and will not be cleaned up by the operator. If they are no longer used, delete them manually.",
+		clusterCheck: checkForOrphanedGrafanaResources,
+	},
+	{
+		ruleID:       "ACC025",
+		title:        "ApplicationSet webhook exposed without authentication",
+		explanation:  "Live cluster only. Looks for instances where the ApplicationSet webhook server is exposed outside of the cluster via a Route or Ingress ('.spec.applicationSet.webhookServer'), but no webhook secret (e.g. 'webhook.github.secret') is configured in the 'argocd-secret' Secret. Unauthenticated webhook events can be sent by anyone who can reach the endpoint, which may be abused to trigger excessive reconciliation and requests to Git providers. Configure the webhook secret of your Git provider, or disable the webhook Route/Ingress.",
+		clusterCheck: checkForUnauthenticatedApplicationSetWebhook,
+		applicable:   applicableWhenApplicationSetEnabled,
+	},
+	{
+		ruleID:       "ACC028",
+		title:        "More controller shards than managed clusters",
+		explanation:  "Live cluster only. Compares the number of application controller shards ('.spec.controller.sharding.replicas') against the number of clusters managed by the instance: the cluster Secrets ('argocd.argoproj.io/secret-type: cluster') in the Argo CD namespace, plus the in-cluster cluster (unless disabled). Clusters are assigned to shards, so shards beyond the number of clusters idle while still consuming resources. This is a right-sizing advisory: reduce the number of shards, or enable dynamic scaling.",
+		clusterCheck: checkForIdleControllerShards,
+		applicable:   applicableWhenShardingEnabled,
+	},
+	{
+		ruleID:       "ACC032",
+		title:        "Argo CD components not admitted by OpenShift SecurityContextConstraints",
+		explanation:  "Live OpenShift cluster only. For the application controller, server, and repo server workloads, determines which SecurityContextConstraints (SCCs) the workload's service account may use (via the '.users'/'.groups' of each SCC, or RBAC 'use' permission), and compares the workload's pod spec against them: host namespaces/ports, volume types, privileged containers, added capabilities, privilege escalation, and explicit UIDs. Also verifies that the Argo CD namespace has the 'openshift.io/sa.scc.uid-range' annotation, which is required by SCCs such as 'restricted-v2'. If no SCC admits the pod, its pods are never created ('unable to validate against any security context constraint'). Grant the service account access to a suitable SCC, or adjust the security context/volumes of the component.",
+		clusterCheck: checkForComponentsRejectedBySCCs,
+	},
+	{
+		ruleID:       "ACC038",
+		title:        "Server HorizontalPodAutoscaler unable to scale",
+		explanation:  "Live cluster only. When server autoscaling is enabled ('.spec.server.autoscale.enabled'), reads the HorizontalPodAutoscaler that the operator creates for the server ('<name>-server'), and reports if it does not exist, or if its 'AbleToScale'/'ScalingActive' condition is False (for example, because the metrics server is not installed, or the server pods have no CPU request). Until this is resolved, the server is not autoscaled. Resolve the reason reported by the HPA condition.",
+		clusterCheck: checkForIneffectiveServerHPA,
+		applicable:   applicableWhenServerAutoscaleEnabled,
+	},
+	{
+		ruleID:       "ACC031",
+		title:        "Referenced Secrets/ConfigMaps do not exist",
+		explanation:  "Opt-in via '--check-secrets'. Looks for Secrets and ConfigMaps which are referenced by the ArgoCD CR, but which do not exist in the Argo CD namespace: volumes and non-optional 'valueFrom' environment variables of each component, '.spec.applicationSet.scmRootCAConfigMap', the external certificate of the server Route, the TLS/JWT Secrets of the Argo CD agent, and '$<secret>:<key>' references in the Dex/OIDC configuration. Missing objects cause pods to fail to start (or features to silently fail). On a live cluster these are reported as errors. Must-gathers usually do not contain Secrets, so these are only reported as warnings when analyzing a must-gather or manifest. Create the missing Secret/ConfigMap, or remove the reference.",
+		clusterCheck: checkForMissingReferencedSecretsAndConfigMaps,
+		optInFlag:    "--check-secrets",
+	},
+	{
+		ruleID:       "ACC042",
+		title:        "ArgoCD CR violates the ArgoCD CRD schema",
+		explanation:  "Opt-in via '--validate-schema'. Validates each ArgoCD CR against the OpenAPI schema of the ArgoCD CRD (embedded in the tool, from the operator version whose API the tool uses), and reports each structural violation as an Error, at its JSON path: fields which are not defined by the schema (for example, a misspelled or misplaced field), values of the wrong type, and values which are not allowed (for example, not one of the allowed enum values). The API server drops unknown fields (so they silently have no effect), and rejects the other violations, so this is most useful for manifests which have not yet been applied to a cluster. The other checks do not see unknown fields at all. Correct or remove the field. Note that a field which was added in a newer operator version than the embedded schema is also reported as unknown.",
+		clusterCheck: checkArgoCDCRAgainstSchema,
+		optInFlag:    "--validate-schema",
+	},
+}
+
+func init() {
+	// Built-in checks which only require the ArgoCD CR are run via the checks package registry, alongside any additional checks compiled into the tool (see checks.Register)
+	for _, registration := range registeredChecks {
+		if registration.check != nil {
+			checks.Register(builtInCheck{registration: registration})
+		}
+	}
+}
+
+// builtInCheck adapts a checkRegistration to the checks.Check interface
+type builtInCheck struct {
+	registration checkRegistration
+}
+
+func (b builtInCheck) ID() string {
+	return b.registration.ruleID
+}
+
+func (b builtInCheck) Run(argoCD v1beta1.ArgoCD, clusterInfo checks.ClusterInformation) []checks.Issue {
+
+	issues := []issue{}
+	b.registration.check(argoCD, clusterInfo, &issues)
+
+	res := []checks.Issue{}
+	for _, issue := range issues {
+		res = append(res, checks.Issue{
+			Level:             issue.level,
+			Field:             issue.field,
+			Message:           issue.message,
+			Unsupported:       issue.unsupported,
+			Source:            issue.source,
+			StopFurtherChecks: issue.stopFurtherChecks,
+		})
+	}
+
+	return res
+}
+
+// clusterCheckIsRun returns true if the cluster check is run (see checkIndividualArgoCDCRAgainstCluster): opt-in checks are run if their flag was specified, and other cluster checks are run if the control plane data is complete.
+func clusterCheckIsRun(registration checkRegistration, incompleteControlPlaneData bool, enabledOptInFlags []string) bool {
+	if registration.optInFlag != "" {
+		return slices.Contains(enabledOptInFlags, registration.optInFlag)
+	}
+	return !incompleteControlPlaneData
+}
+
+// withoutClusterInfo adapts a check function which does not require cluster information, to the signature used by checkRegistration
+func withoutClusterInfo(check func(argoCD v1beta1.ArgoCD, issues *[]issue)) func(v1beta1.ArgoCD, clusterInformation, *[]issue) {
+	return func(argoCD v1beta1.ArgoCD, _ clusterInformation, issues *[]issue) {
+		check(argoCD, issues)
+	}
+}
+
+// setRuleIDOfNewIssues sets the rule ID on each issue that was added to the slice since it had length 'previousLength'
+func setRuleIDOfNewIssues(issues []issue, previousLength int, ruleID string) {
+	for i := previousLength; i < len(issues); i++ {
+		issues[i].ruleID = ruleID
+	}
+}
+
+// findCheckRegistration returns the check with the given rule ID (case insensitive), or nil if there is no such check
+func findCheckRegistration(ruleID string) *checkRegistration {
+	for i := range registeredChecks {
+		if strings.EqualFold(registeredChecks[i].ruleID, ruleID) {
+			return &registeredChecks[i]
+		}
+	}
+	return nil
+}
+
+// isKnownRuleID returns true if there is a check with the given rule ID (case insensitive): either a built-in check, or an additional check registered via checks.Register
+func isKnownRuleID(ruleID string) bool {
+
+	if findCheckRegistration(ruleID) != nil {
+		return true
+	}
+
+	return slices.ContainsFunc(checks.RegisteredChecks(), func(check checks.Check) bool {
+		return strings.EqualFold(check.ID(), ruleID)
+	})
+}
+
+// explainRule outputs the explanation of the check with the given rule ID (used by '--explain'). Returns an error if there is no such check.
+func explainRule(ruleID string) error {
+
+	registration := findCheckRegistration(ruleID)
+	if registration == nil {
+		validRuleIDs := []string{}
+		for _, registration := range registeredChecks {
+			validRuleIDs = append(validRuleIDs, registration.ruleID)
+		}
+		return fmt.Errorf("unknown rule ID '%s'. Valid rule IDs are: %s", ruleID, strings.Join(validRuleIDs, ", "))
+	}
+
+	outputStatusMessage(color.New(color.FgHiWhite, color.Bold).Sprint(registration.ruleID + ": " + registration.title))
+	outputStatusMessage("")
+	outputStatusMessage(registration.explanation)
+
+	return nil
+}
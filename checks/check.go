@@ -0,0 +1,206 @@
+// Package checks contains the types used to implement a check of an ArgoCD CR, and the registry of checks that are run by argocd-config-check.
+//
+// Additional checks (for example, to enforce organization-specific policies) may be compiled into argocd-config-check, by implementing the Check interface, and calling Register before RunChecks is called (e.g. from an init function of a file added to the main package). For example:
+//
+//	type requireNamespacePrefix struct{}
+//
+//	func (requireNamespacePrefix) ID() string { return "ORG001" }
+//
+//	func (requireNamespacePrefix) Run(argoCD v1beta1.ArgoCD, clusterInfo checks.ClusterInformation) []checks.Issue {
+//		if strings.HasPrefix(argoCD.Namespace, "gitops-") {
+//			return nil
+//		}
+//		return []checks.Issue{{
+//			Level:   checks.LogLevel_Warn,
+//			Field:   ".metadata.namespace",
+//			Message: "Argo CD instances should be installed in a namespace with the 'gitops-' prefix",
+//		}}
+//	}
+//
+//	func init() {
+//		checks.Register(requireNamespacePrefix{})
+//	}
+//
+// This package is not a library API for running the checks of argocd-config-check from other programs: the built-in checks are implemented in, and registered by, the main package of argocd-config-check (which cannot be imported). A program which imports this package by itself has an empty registry, and RunChecks runs only the checks which that program registered.
+//
+// The registry contains the checks of the ArgoCD CR (and ClusterInformation) only. Built-in checks which must read other resources from the cluster (for example, RBAC or Secrets) are run separately by argocd-config-check after RunChecks, and are not part of the registry: checks of that kind cannot be added via Register.
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	semver "github.com/blang/semver/v4"
+)
+
+type LogLevel string
+
+const (
+	// LogLevel_Fatal should be used if subsequent logic after that point is no longer guaranteed to be accurate (e.g. broken invariant). An example of a fatal case would be if there exist multiple gitops Subscriptions objects (with different versions) on the cluster.
+	LogLevel_Fatal LogLevel = "Fatal"
+
+	// LogLevel_Error should be used in cases where there is a high chance of this being an incorrect configuration
+	LogLevel_Error LogLevel = "Error"
+
+	// LogLevel_Warn should be used in cases where there is a mild/moderate chance of this being an incorrect configuration.
+	LogLevel_Warn LogLevel = "Warn"
+
+	// LogLevel_Info should be used for informational findings, which are not likely to be an incorrect configuration, but which the user may want to review (for example, an env var which is not recognized). Info issues do not affect the score, and are not considered by '--fail-on'.
+	LogLevel_Info LogLevel = "Info"
+)
+
+// IssueSource is where a problematic value came from, for checks which resolve a setting from multiple sources (for example, a CR field which may also be set via an env var)
+type IssueSource string
+
+const (
+	// IssueSource_CRField is a first-class ArgoCD CR field, e.g. '.spec.controller.appSync'
+	IssueSource_CRField IssueSource = "crField"
+
+	// IssueSource_ExtraConfig is a '.spec.extraConfig' ('argocd-cm') key
+	IssueSource_ExtraConfig IssueSource = "extraConfig"
+
+	// IssueSource_CmdParams is a '.spec.cmdParams' ('argocd-cmd-params-cm') key
+	IssueSource_CmdParams IssueSource = "cmdParams"
+
+	// IssueSource_EnvVar is an env var of a component, e.g. '.spec.controller.env'
+	IssueSource_EnvVar IssueSource = "envVar"
+
+	// IssueSource_ExtraCommandArgs is a command line argument of a component, e.g. '.spec.server.extraCommandArgs'
+	IssueSource_ExtraCommandArgs IssueSource = "extraCommandArgs"
+)
+
+// Issue is a problem that was found by a check
+type Issue struct {
+	Level LogLevel
+
+	// Field is the ArgoCD CR field (e.g. '.spec.server.insecure') or other resource that the issue relates to
+	Field string
+
+	Message string
+
+	// RuleID is the ID of the check which reported the issue. This is set by RunChecks, and so does not need to be set by the check.
+	RuleID string
+
+	// Unsupported is true if the issue is an unsupported configuration
+	Unsupported bool
+
+	// Source is where the problematic value came from (see IssueSource), or empty if the check does not resolve the value from multiple sources
+	Source IssueSource
+
+	// StopFurtherChecks is true if the issue makes the results of all subsequent checks of the ArgoCD CR meaningless or misleading (for example, the CR is being deleted). RunChecks does not run any further checks against the CR, and reports which checks were skipped. This should only be set on Fatal issues.
+	StopFurtherChecks bool
+}
+
+// ClusterInformation contains data extracted from operator/cluster configuration that may be useful for subsequent logic
+type ClusterInformation struct {
+	OperatorVersion          *semver.Version
+	OperatorInstallNamespace string
+
+	// OperatorVersionUserSupplied is true if OperatorVersion was supplied by the user (via '--argocd-operator-version'), rather than discovered from the cluster
+	OperatorVersionUserSupplied bool
+
+	// SizingProfile is the name of the workload size profile selected by the user (via '--profile'), or empty if no profile was selected
+	SizingProfile string
+
+	// ImageAllowlist are the registry prefixes (ending in '/') and exact images which the user has approved as mirrors of the official images (via '--allowlist-images'), or empty if none were specified
+	ImageAllowlist []string
+
+	// from Subscription 'ARGOCD_CLUSTER_CONFIG_NAMESPACES' env
+	ClusterScopedNamespaces []string
+
+	// Namespaces are the names of all the namespaces on the cluster, sorted. This is nil if the namespaces were not listed, or if the cluster data is incomplete (e.g. must-gather), since a namespace which is not in incomplete data may still exist.
+	Namespaces []string
+
+	// NamespaceLabels are the labels of each namespace in the cluster data.
+	// key: namespace name
+	// value: labels of the namespace
+	// Unlike Namespaces, this is also populated when the cluster data is incomplete (e.g. must-gather), so it must not be used to determine whether a namespace exists. This is empty if the namespaces could not be listed (e.g. forbidden), and nil if listing the namespaces was not attempted (e.g. the operator installation could not be located).
+	NamespaceLabels map[string]map[string]string
+
+	// key: namespace that is managed
+	// value: namespace of argocd instance that is managing
+	NamespaceWithManagedByLabel map[string]string
+
+	// key: namespace that is managed
+	// value: namespace of argocd instance that is managing
+	NamespaceWithManagedByClusterArgoCDLabel map[string]string
+
+	// key: namespace that is managed
+	// value: namespace of argocd instance that is managing
+	NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel map[string]string
+
+	// key: namespace that is managed
+	// value: namespace of argocd instance that is managing
+	NamespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel map[string]string
+}
+
+// Check is a check of a single ArgoCD CR
+type Check interface {
+	// ID returns the stable identifier of the check (e.g. 'ACC001'), which is reported with each issue. IDs must be unique across all registered checks.
+	ID() string
+
+	// Run returns the issues that were found in the ArgoCD CR, or an empty slice if none were found
+	Run(argoCD v1beta1.ArgoCD, clusterInfo ClusterInformation) []Issue
+}
+
+// registry is the list of registered checks, in the order they were registered (which is the order they are run)
+var registry = []Check{}
+
+// Register adds a check to the list of checks that are run by RunChecks. Register panics if a check with the same ID has already been registered.
+func Register(check Check) {
+
+	for _, existing := range registry {
+		if existing.ID() == check.ID() {
+			panic(fmt.Sprintf("a check with ID '%s' is already registered", check.ID()))
+		}
+	}
+
+	registry = append(registry, check)
+}
+
+// RegisteredChecks returns all registered checks, in the order they are run
+func RegisteredChecks() []Check {
+	return append([]Check{}, registry...)
+}
+
+// RunChecks runs all registered checks against the ArgoCD CR, and returns the issues that were found. Each issue is tagged with the ID of the check which reported it.
+// - If a check reports an issue with StopFurtherChecks set, the remaining checks are not run: an additional Info issue is reported which states why, and which checks were skipped.
+func RunChecks(argoCD v1beta1.ArgoCD, clusterInfo ClusterInformation) []Issue {
+
+	issues := []Issue{}
+
+	for idx, check := range registry {
+
+		var stopIssue *Issue
+
+		for _, issue := range check.Run(argoCD, clusterInfo) {
+			issue.RuleID = check.ID()
+			issues = append(issues, issue)
+
+			if issue.StopFurtherChecks && stopIssue == nil {
+				stopIssue = &issue
+			}
+		}
+
+		if stopIssue == nil {
+			continue
+		}
+
+		skippedIDs := []string{}
+		for _, skipped := range registry[idx+1:] {
+			skippedIDs = append(skippedIDs, skipped.ID())
+		}
+
+		issues = append(issues, Issue{
+			Level:   LogLevel_Info,
+			Field:   stopIssue.Field,
+			RuleID:  check.ID(),
+			Message: fmt.Sprintf("No further checks were run against this ArgoCD CR, since their results would not be meaningful (see the %s issue for '%s'). Skipped checks: %s, and all checks against the cluster.", stopIssue.Level, stopIssue.Field, strings.Join(skippedIDs, ", ")),
+		})
+
+		return issues
+	}
+
+	return issues
+}
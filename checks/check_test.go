@@ -0,0 +1,145 @@
+package checks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requireNamespacePrefix is an example of an additional check (see the package doc): it requires that Argo CD instances are installed in a namespace with the 'gitops-' prefix
+type requireNamespacePrefix struct{}
+
+func (requireNamespacePrefix) ID() string { return "ORG001" }
+
+func (requireNamespacePrefix) Run(argoCD v1beta1.ArgoCD, clusterInfo ClusterInformation) []Issue {
+	if strings.HasPrefix(argoCD.Namespace, "gitops-") {
+		return nil
+	}
+	return []Issue{{
+		Level:   LogLevel_Warn,
+		Field:   ".metadata.namespace",
+		Message: "Argo CD instances should be installed in a namespace with the 'gitops-' prefix",
+	}}
+}
+
+// fakeCheck is a check which reports the given issues, and records whether it was run
+type fakeCheck struct {
+	id     string
+	issues []Issue
+	ran    *bool
+}
+
+func (f fakeCheck) ID() string { return f.id }
+
+func (f fakeCheck) Run(argoCD v1beta1.ArgoCD, clusterInfo ClusterInformation) []Issue {
+	if f.ran != nil {
+		*f.ran = true
+	}
+	return f.issues
+}
+
+// withEmptyRegistry runs the test with no registered checks, and restores the registry afterwards
+func withEmptyRegistry(t *testing.T) {
+	t.Helper()
+
+	previous := registry
+	registry = []Check{}
+	t.Cleanup(func() {
+		registry = previous
+	})
+}
+
+func TestRunChecksReportsIssuesOfAdditionalCheck(t *testing.T) {
+	withEmptyRegistry(t)
+
+	Register(requireNamespacePrefix{})
+
+	argoCD := v1beta1.ArgoCD{ObjectMeta: metav1.ObjectMeta{Name: "argocd", Namespace: "team-a"}}
+
+	expected := []Issue{{
+		Level:   LogLevel_Warn,
+		Field:   ".metadata.namespace",
+		Message: "Argo CD instances should be installed in a namespace with the 'gitops-' prefix",
+		RuleID:  "ORG001",
+	}}
+
+	if actual := RunChecks(argoCD, ClusterInformation{}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	argoCD.Namespace = "gitops-team-a"
+	if actual := RunChecks(argoCD, ClusterInformation{}); len(actual) != 0 {
+		t.Errorf("expected no issues, got %+v", actual)
+	}
+}
+
+func TestRunChecksRunsChecksInRegistrationOrder(t *testing.T) {
+	withEmptyRegistry(t)
+
+	Register(fakeCheck{id: "ORG002", issues: []Issue{{Level: LogLevel_Error, Field: ".spec.b"}}})
+	Register(fakeCheck{id: "ORG001", issues: []Issue{{Level: LogLevel_Warn, Field: ".spec.a"}, {Level: LogLevel_Info, Field: ".spec.c"}}})
+
+	actual := RunChecks(v1beta1.ArgoCD{}, ClusterInformation{})
+
+	expected := []Issue{
+		{Level: LogLevel_Error, Field: ".spec.b", RuleID: "ORG002"},
+		{Level: LogLevel_Warn, Field: ".spec.a", RuleID: "ORG001"},
+		{Level: LogLevel_Info, Field: ".spec.c", RuleID: "ORG001"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	ids := []string{}
+	for _, check := range RegisteredChecks() {
+		ids = append(ids, check.ID())
+	}
+	if !reflect.DeepEqual(ids, []string{"ORG002", "ORG001"}) {
+		t.Errorf("unexpected registered checks: %v", ids)
+	}
+}
+
+func TestRunChecksStopsAfterStopFurtherChecksIssue(t *testing.T) {
+	withEmptyRegistry(t)
+
+	skippedRan := false
+
+	Register(fakeCheck{id: "ORG001", issues: []Issue{{Level: LogLevel_Fatal, Field: ".metadata.deletionTimestamp", StopFurtherChecks: true}}})
+	Register(fakeCheck{id: "ORG002", issues: []Issue{{Level: LogLevel_Warn, Field: ".spec.a"}}, ran: &skippedRan})
+
+	actual := RunChecks(v1beta1.ArgoCD{}, ClusterInformation{})
+
+	if skippedRan {
+		t.Errorf("expected the check after the StopFurtherChecks issue not to be run")
+	}
+
+	if len(actual) != 2 {
+		t.Fatalf("expected the Fatal issue and an Info issue, got %+v", actual)
+	}
+
+	if actual[0].RuleID != "ORG001" || actual[0].Level != LogLevel_Fatal {
+		t.Errorf("unexpected first issue: %+v", actual[0])
+	}
+
+	if actual[1].RuleID != "ORG001" || actual[1].Level != LogLevel_Info || !strings.Contains(actual[1].Message, "Skipped checks: ORG002") {
+		t.Errorf("unexpected second issue: %+v", actual[1])
+	}
+}
+
+func TestRegisterPanicsOnDuplicateID(t *testing.T) {
+	withEmptyRegistry(t)
+
+	Register(fakeCheck{id: "ORG001"})
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Register to panic on a duplicate ID")
+		}
+	}()
+
+	Register(fakeCheck{id: "ORG001"})
+}
@@ -0,0 +1,23 @@
+package clients
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// IsResourceTypeNotKnownError returns true if the error returned by an AbstractK8sClient indicates that the resource type itself is not known, rather than that a resource could not be read. For example, when the CRD of the resource type is not installed.
+// - For the live cluster client, this is the 'no matches for kind' error returned when the API server does not serve the resource type.
+// - For the OMC client, omc reports that the resource type is 'not known' when it is not present in the must-gather.
+func IsResourceTypeNotKnownError(err error) bool {
+
+	if err == nil {
+		return false
+	}
+
+	if meta.IsNoMatchError(err) {
+		return true
+	}
+
+	return strings.Contains(err.Error(), "not known")
+}
@@ -0,0 +1,62 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsResourceTypeNotKnownError(t *testing.T) {
+
+	argoCDResource := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1beta1", Resource: "argocds"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "no matches for kind (CRD not installed on the live cluster)",
+			err:      &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "argoproj.io", Kind: "ArgoCD"}, SearchedVersions: []string{"v1beta1"}},
+			expected: true,
+		},
+		{
+			name:     "wrapped no matches for resource",
+			err:      fmt.Errorf("unable to list ArgoCDs: %w", &meta.NoResourceMatchError{PartialResource: argoCDResource}),
+			expected: true,
+		},
+		{
+			name:     "omc resource type not known",
+			err:      fmt.Errorf("Output from OMC: %s\nUnable to retrieve '%s' from all namespaces: %v", `resource type "argocds" not known`, "argocds", errors.New("exit status 1")),
+			expected: true,
+		},
+		{
+			name:     "nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			// An RBAC failure must not be treated as the CRD being absent, otherwise the resources would silently be reported as missing
+			name:     "forbidden",
+			err:      apierrors.NewForbidden(argoCDResource.GroupResource(), "", errors.New(`User "developer" cannot list resource "argocds" in API group "argoproj.io" at the cluster scope`)),
+			expected: false,
+		},
+		{
+			name:     "not found",
+			err:      apierrors.NewNotFound(argoCDResource.GroupResource(), "argocd"),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := IsResourceTypeNotKnownError(test.err); actual != test.expected {
+				t.Errorf("expected %v, got %v (error: %v)", test.expected, actual, test.err)
+			}
+		})
+	}
+}
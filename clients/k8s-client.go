@@ -14,10 +14,12 @@ import (
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 
 	osappsv1 "github.com/openshift/api/apps/v1"
+	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 
 	argov1alpha1api "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
 	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	securityv1 "github.com/openshift/api/security/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -32,7 +34,12 @@ import (
 )
 
 func SystemK8sClient() (AbstractK8sClient, error) {
-	k8sClientFromSystem, _, err := getSystemK8sClient()
+	return KubeConfigK8sClient("", "")
+}
+
+// KubeConfigK8sClient returns a client for the cluster of the given kubeconfig file and context. If 'kubeConfigPath' is empty, the default kubeconfig loading rules are used (e.g. '$KUBECONFIG' or '~/.kube/config'), and if 'contextName' is empty, the current context of the kubeconfig is used.
+func KubeConfigK8sClient(kubeConfigPath string, contextName string) (AbstractK8sClient, error) {
+	k8sClientFromSystem, _, err := getSystemK8sClient(kubeConfigPath, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +49,24 @@ func SystemK8sClient() (AbstractK8sClient, error) {
 	}, nil
 }
 
+// KubeConfigDataK8sClient returns a client for the cluster of the given kubeconfig content (rather than a kubeconfig file, see KubeConfigK8sClient) and context. If 'contextName' is empty, the current context of the kubeconfig is used.
+func KubeConfigDataK8sClient(kubeConfigData []byte, contextName string) (AbstractK8sClient, error) {
+
+	config, err := getKubeConfigFromData(kubeConfigData, contextName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get k8s config: %v", err)
+	}
+
+	k8sClient, _, err := getK8sClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get k8s client: %v", err)
+	}
+
+	return &traditionalK8sClient{
+		client: k8sClient,
+	}, nil
+}
+
 type traditionalK8sClient struct {
 	client client.Client
 }
@@ -62,8 +87,8 @@ func (t *traditionalK8sClient) IncompleteControlPlaneData() bool {
 	return false
 }
 
-func getSystemK8sClient() (client.Client, *runtime.Scheme, error) {
-	config, err := getSystemKubeConfig()
+func getSystemK8sClient(kubeConfigPath string, contextName string) (client.Client, *runtime.Scheme, error) {
+	config, err := getSystemKubeConfig(kubeConfigPath, contextName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to get k8s config: %v", err)
 	}
@@ -79,90 +104,110 @@ func getSystemK8sClient() (client.Client, *runtime.Scheme, error) {
 // getK8sClient returns a controller-runtime Client for accessing K8s API resources used by the controller.
 func getK8sClient(config *rest.Config) (client.Client, *runtime.Scheme, error) {
 
+	scheme, err := newScheme()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return k8sClient, scheme, nil
+
+}
+
+// newScheme returns a Scheme containing all the K8s API resource types which are read by the tool
+func newScheme() (*runtime.Scheme, error) {
+
 	scheme := runtime.NewScheme()
 
 	if err := corev1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := apps.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if err := rbacv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := admissionv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := monitoringv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := crdv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := argov1beta1api.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := argocdv1alpha1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := olmv1alpha1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	if err := olmv1.AddToScheme(scheme); err != nil {
+		return nil, err
 	}
 
 	if err := routev1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := osappsv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := consolev1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	if err := operatorv1.AddToScheme(scheme); err != nil {
+		return nil, err
 	}
 
 	if err := argov1alpha1api.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := securityv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := networkingv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := autoscalingv2.AddToScheme(scheme); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := batchv1.AddToScheme(scheme); err != nil {
-		return nil, nil, err
-	}
-
-	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
-	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return k8sClient, scheme, nil
-
+	return scheme, nil
 }
 
-// Retrieve the system-level Kubernetes config (e.g. ~/.kube/config or service account config from volume)
-func getSystemKubeConfig() (*rest.Config, error) {
+// Retrieve the system-level Kubernetes config (e.g. ~/.kube/config or service account config from volume), or the config of the given kubeconfig file and/or context, if specified
+func getSystemKubeConfig(kubeConfigPath string, contextName string) (*rest.Config, error) {
 
-	overrides := clientcmd.ConfigOverrides{}
+	overrides := clientcmd.ConfigOverrides{CurrentContext: contextName}
 
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeConfigPath
 	clientConfig := clientcmd.NewInteractiveDeferredLoadingClientConfig(loadingRules, &overrides, os.Stdin)
 
 	restConfig, err := clientConfig.ClientConfig()
@@ -171,3 +216,20 @@ func getSystemKubeConfig() (*rest.Config, error) {
 	}
 	return restConfig, nil
 }
+
+// Retrieve the Kubernetes config from the given kubeconfig content, using the given context if specified. This is equivalent to clientcmd.RESTConfigFromKubeConfig, with the addition of the context override.
+func getKubeConfigFromData(kubeConfigData []byte, contextName string) (*rest.Config, error) {
+
+	kubeConfig, err := clientcmd.Load(kubeConfigData)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse kubeconfig data: %v", err)
+	}
+
+	overrides := clientcmd.ConfigOverrides{CurrentContext: contextName}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*kubeConfig, &overrides).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return restConfig, nil
+}
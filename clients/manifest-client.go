@@ -0,0 +1,447 @@
+package clients
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	argov1alpha1api "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argov1beta1api "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestInputFormat is the format of the manifests read by the manifest client
+type ManifestInputFormat string
+
+const (
+	// ManifestInputFormat_Auto detects the format of each manifest from its content: JSON if it begins with '{' or '[', otherwise YAML.
+	ManifestInputFormat_Auto ManifestInputFormat = "auto"
+
+	// ManifestInputFormat_YAML is a stream of YAML documents separated by '---', for example the output of 'helm template' or 'kustomize build'
+	ManifestInputFormat_YAML ManifestInputFormat = "yaml"
+
+	// ManifestInputFormat_JSON is a stream of JSON documents (or a JSON array of documents), for example the output of 'kubectl get -o json'
+	ManifestInputFormat_JSON ManifestInputFormat = "json"
+)
+
+// ManifestInputFormats is the list of valid manifest input formats
+var ManifestInputFormats = []ManifestInputFormat{ManifestInputFormat_Auto, ManifestInputFormat_YAML, ManifestInputFormat_JSON}
+
+// manifestDefaultNamespace is the namespace of namespaced resources which do not specify one (as is common in 'helm template' output), matching the behaviour of 'kubectl apply'.
+const manifestDefaultNamespace = "default"
+
+// ManifestStats describes the documents that were read by the manifest client
+type ManifestStats struct {
+	// Documents is the number of (non-empty) documents that were parsed, after List-wrapped documents were expanded into their items
+	Documents int
+
+	// ArgoCDs is the number of (v1beta1) ArgoCD CRs that were found
+	ArgoCDs int
+
+	// V1alpha1ArgoCDs is the number of ArgoCD CRs of the deprecated v1alpha1 API version that were found, which are not checked: the tool only reads v1beta1 ArgoCD CRs
+	V1alpha1ArgoCDs int
+
+	// Skipped is the number of documents that were skipped, since their kind is not one that the tool reads
+	Skipped int
+}
+
+// ManifestSource is the location in a file from which a resource was read (by the manifest or must-gather client)
+type ManifestSource struct {
+	// Path is the path of the file
+	Path string
+
+	// Line is the (1-based) line of the file at which the resource's document begins, or 0 if not known (for example, JSON documents, and the items of a List document)
+	Line int
+}
+
+// ManifestSourceLocator is implemented by the clients which read resources from files, and returns the file (and line) from which a resource was read, if known
+type ManifestSourceLocator interface {
+	ManifestSourceOf(obj client.Object) (ManifestSource, bool)
+}
+
+// manifestK8sClient reads K8s resources from local manifest files (for example, the rendered output of a Helm chart or kustomization), rather than from a cluster.
+// - Only resources of a kind known to the tool (see newScheme) are kept: other documents are skipped.
+// - The manifests only contain the resources that the user supplied, so the control plane data is always incomplete.
+type manifestK8sClient struct {
+	scheme  *runtime.Scheme
+	objects []unstructured.Unstructured
+
+	// sources[i] is the file from which objects[i] was read: the source of resources read from stdin is not known, and has an empty path
+	sources []ManifestSource
+}
+
+// ManifestK8sClient returns a client which reads K8s resources from the manifests at 'path': a file, a directory (all '.yaml', '.yml', and '.json' files within it, non-recursively), or '-' for stdin.
+func ManifestK8sClient(path string, format ManifestInputFormat) (AbstractK8sClient, ManifestStats, error) {
+
+	if !slices.Contains(ManifestInputFormats, format) {
+		return nil, ManifestStats{}, fmt.Errorf("unrecognized manifest input format '%s'", format)
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return nil, ManifestStats{}, err
+	}
+
+	res := &manifestK8sClient{scheme: scheme}
+	stats := ManifestStats{}
+
+	files, err := manifestFiles(path)
+	if err != nil {
+		return nil, ManifestStats{}, err
+	}
+
+	for _, file := range files {
+
+		var data []byte
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return nil, ManifestStats{}, fmt.Errorf("unable to read manifest '%s': %w", file, err)
+		}
+
+		documents, err := decodeManifestDocuments(data, format)
+		if err != nil {
+			return nil, ManifestStats{}, fmt.Errorf("unable to parse manifest '%s': %w", file, err)
+		}
+
+		for _, document := range documents {
+
+			stats.Documents++
+
+			gvk := document.object.GroupVersionKind()
+			if !scheme.Recognizes(gvk) {
+				stats.Skipped++
+				continue
+			}
+
+			if gvk == argov1beta1api.GroupVersion.WithKind("ArgoCD") {
+				stats.ArgoCDs++
+			} else if gvk == argov1alpha1api.GroupVersion.WithKind("ArgoCD") {
+				stats.V1alpha1ArgoCDs++
+			}
+
+			source := ManifestSource{}
+			if file != "-" {
+				source = ManifestSource{Path: file, Line: document.line}
+			}
+
+			res.objects = append(res.objects, document.object)
+			res.sources = append(res.sources, source)
+		}
+	}
+
+	return res, stats, nil
+}
+
+// manifestFiles returns the manifest files at 'path' (see ManifestK8sClient)
+func manifestFiles(path string) ([]string, error) {
+
+	if path == "-" {
+		return []string{path}, nil
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fileInfo.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (extension == ".yaml" || extension == ".yml" || extension == ".json") {
+			res = append(res, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no '.yaml', '.yml', or '.json' files were found in directory '%s'", path)
+	}
+
+	return res, nil
+}
+
+// manifestDocument is a resource decoded from a stream of YAML/JSON documents (see decodeManifestDocuments)
+type manifestDocument struct {
+	object unstructured.Unstructured
+
+	// line is the (1-based) line at which the resource's YAML document begins, or 0 if not known (JSON documents, and the items of a List document)
+	line int
+}
+
+// decodeManifestDocuments decodes a stream of YAML/JSON documents into resources.
+// - Empty documents (for example, a document containing only comments, as is common in 'helm template' output) are ignored.
+// - List-wrapped documents (kind 'List', or e.g. 'ArgoCDList') are expanded into their items.
+func decodeManifestDocuments(data []byte, format ManifestInputFormat) ([]manifestDocument, error) {
+
+	if format == ManifestInputFormat_Auto {
+		format = ManifestInputFormat_YAML
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			format = ManifestInputFormat_JSON
+		}
+	}
+
+	jsonDocuments := []json.RawMessage{}
+
+	// jsonDocumentLines[i] is the line at which jsonDocuments[i] begins (YAML only)
+	jsonDocumentLines := []int{}
+
+	if format == ManifestInputFormat_JSON {
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var document json.RawMessage
+			if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			// A JSON array of documents is treated the same as a List
+			var array []json.RawMessage
+			if err := json.Unmarshal(document, &array); err == nil {
+				jsonDocuments = append(jsonDocuments, array...)
+				continue
+			}
+
+			jsonDocuments = append(jsonDocuments, document)
+		}
+
+	} else {
+
+		documentLines := yamlDocumentLines(data)
+
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+		for {
+			document, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			jsonDocument, err := yaml.YAMLToJSON(document)
+			if err != nil {
+				return nil, err
+			}
+
+			line := 0
+			if len(jsonDocuments) < len(documentLines) {
+				line = documentLines[len(jsonDocuments)]
+			}
+
+			jsonDocuments = append(jsonDocuments, jsonDocument)
+			jsonDocumentLines = append(jsonDocumentLines, line)
+		}
+	}
+
+	res := []manifestDocument{}
+
+	for i, jsonDocument := range jsonDocuments {
+
+		if trimmed := bytes.TrimSpace(jsonDocument); len(trimmed) == 0 || string(trimmed) == "null" {
+			continue
+		}
+
+		var document unstructured.Unstructured
+		if err := document.UnmarshalJSON(jsonDocument); err != nil {
+			return nil, err
+		}
+
+		if !document.IsList() {
+			line := 0
+			if i < len(jsonDocumentLines) {
+				line = jsonDocumentLines[i]
+			}
+			res = append(res, manifestDocument{object: document, line: line})
+			continue
+		}
+
+		list, err := document.ToList()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			res = append(res, manifestDocument{object: item})
+		}
+	}
+
+	return res, nil
+}
+
+// yamlDocumentLines returns the (1-based) line at which each document of a YAML stream begins, in the order in which the documents are returned by utilyaml.YAMLReader: a document is a run of lines which are not '---' separators.
+// - The line of a document is that of its first line which is not blank or a comment (where there is one), since 'helm template' output begins each document with a '# Source:' comment.
+func yamlDocumentLines(data []byte) []int {
+
+	res := []int{}
+
+	if len(data) == 0 {
+		return res
+	}
+
+	inDocument, contentFound := false, false
+
+	for i, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+
+		if bytes.HasPrefix(line, []byte("---")) {
+			inDocument = false
+			continue
+		}
+
+		if !inDocument {
+			res = append(res, i+1)
+			inDocument, contentFound = true, false
+		}
+
+		if trimmed := bytes.TrimSpace(line); !contentFound && len(trimmed) > 0 && trimmed[0] != '#' {
+			res[len(res)-1] = i + 1
+			contentFound = true
+		}
+	}
+
+	return res
+}
+
+func (m *manifestK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	return m.list(list, "")
+}
+
+func (m *manifestK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return m.list(list, namespace)
+}
+
+// list populates 'list' with the resources of the list's item type, from 'namespace' (or from all namespaces if empty)
+func (m *manifestK8sClient) list(list client.ObjectList, namespace string) error {
+
+	listGVK, err := apiutil.GVKForObject(list, m.scheme)
+	if err != nil {
+		return err
+	}
+	itemGVK := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+
+	items := []runtime.Object{}
+
+	for _, object := range m.objects {
+
+		if object.GroupVersionKind() != itemGVK || (namespace != "" && namespaceOfManifestObject(object) != namespace) {
+			continue
+		}
+
+		var item runtime.Object
+		if _, isUnstructured := list.(*unstructured.UnstructuredList); isUnstructured {
+			item = &unstructured.Unstructured{}
+		} else if item, err = m.scheme.New(itemGVK); err != nil {
+			return err
+		}
+
+		if err := m.convertFromManifestObject(object, item); err != nil {
+			return err
+		}
+
+		items = append(items, item)
+	}
+
+	return meta.SetList(list, items)
+}
+
+func (m *manifestK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+
+	gvk, err := apiutil.GVKForObject(obj, m.scheme)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range m.objects {
+		if object.GroupVersionKind() == gvk && object.GetName() == key.Name && (key.Namespace == "" || namespaceOfManifestObject(object) == key.Namespace) {
+			return m.convertFromManifestObject(object, obj)
+		}
+	}
+
+	return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}, key.Name)
+}
+
+func (m *manifestK8sClient) ManifestSourceOf(obj client.Object) (ManifestSource, bool) {
+
+	gvk, err := apiutil.GVKForObject(obj, m.scheme)
+	if err != nil {
+		return ManifestSource{}, false
+	}
+
+	for i, object := range m.objects {
+		if object.GroupVersionKind() == gvk && object.GetName() == obj.GetName() && namespaceOfManifestObject(object) == obj.GetNamespace() {
+			return m.sources[i], m.sources[i].Path != ""
+		}
+	}
+
+	return ManifestSource{}, false
+}
+
+func (m *manifestK8sClient) IncompleteControlPlaneData() bool {
+	return true
+}
+
+// convertFromManifestObject converts a resource read from the manifests into its typed equivalent ('into'), defaulting the namespace (see manifestDefaultNamespace).
+func (m *manifestK8sClient) convertFromManifestObject(object unstructured.Unstructured, into runtime.Object) error {
+
+	// The object is returned as it was read (including any fields which are not known to the tool), when requested as unstructured
+	if intoUnstructured, ok := into.(*unstructured.Unstructured); ok {
+		intoUnstructured.Object = object.DeepCopy().Object
+		if intoUnstructured.GetNamespace() == "" && namespaceOfManifestObject(object) != "" {
+			intoUnstructured.SetNamespace(namespaceOfManifestObject(object))
+		}
+		return nil
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, into); err != nil {
+		return fmt.Errorf("unable to convert %s '%s': %w", object.GetKind(), object.GetName(), err)
+	}
+
+	if metaObject, ok := into.(client.Object); ok && metaObject.GetNamespace() == "" && namespaceOfManifestObject(object) != "" {
+		metaObject.SetNamespace(namespaceOfManifestObject(object))
+	}
+
+	return nil
+}
+
+// namespaceOfManifestObject returns the namespace of a resource read from the manifests. Namespaced resources which do not specify a namespace are in manifestDefaultNamespace.
+// - Cluster-scoped resource types read by the tool are detected by kind, since the manifests do not include API discovery data.
+func namespaceOfManifestObject(object unstructured.Unstructured) string {
+
+	if object.GetNamespace() != "" {
+		return object.GetNamespace()
+	}
+
+	switch object.GetKind() {
+	case "Namespace", "Node", "ClusterRole", "ClusterRoleBinding", "CustomResourceDefinition", "ConsoleLink", "ConsolePlugin", "SecurityContextConstraints", "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration":
+		return ""
+	default:
+		return manifestDefaultNamespace
+	}
+}
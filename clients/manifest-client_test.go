@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	argov1beta1api "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestManifestK8sClientManifestSourceOf(t *testing.T) {
+
+	manifest := `# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: team-a
+---
+---
+# Source: chart/templates/argocd.yaml
+
+apiVersion: argoproj.io/v1beta1
+kind: ArgoCD
+metadata:
+  name: argocd
+  namespace: team-a
+--- # comment
+apiVersion: argoproj.io/v1beta1
+kind: ArgoCD
+metadata:
+  name: argocd
+`
+
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k8sClient, _, err := ManifestK8sClient(path, ManifestInputFormat_Auto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	locator, ok := k8sClient.(ManifestSourceLocator)
+	if !ok {
+		t.Fatalf("expected the manifest client to be a ManifestSourceLocator")
+	}
+
+	tests := []struct {
+		namespace     string
+		expectedLine  int
+		expectedFound bool
+	}{
+		{namespace: "team-a", expectedLine: 11, expectedFound: true},
+		// A namespaced resource which does not specify a namespace is in the default namespace
+		{namespace: manifestDefaultNamespace, expectedLine: 17, expectedFound: true},
+		{namespace: "team-b", expectedFound: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.namespace, func(t *testing.T) {
+
+			source, found := locator.ManifestSourceOf(&argov1beta1api.ArgoCD{ObjectMeta: metav1.ObjectMeta{Name: "argocd", Namespace: test.namespace}})
+
+			if found != test.expectedFound {
+				t.Fatalf("expected found to be %v, got %v (%+v)", test.expectedFound, found, source)
+			}
+			if found && (source.Path != path || source.Line != test.expectedLine) {
+				t.Errorf("expected %s:%d, got %s:%d", path, test.expectedLine, source.Path, source.Line)
+			}
+		})
+	}
+}
+
+func TestManifestK8sClientStatsCountsV1alpha1ArgoCDsSeparately(t *testing.T) {
+
+	manifest := `apiVersion: argoproj.io/v1beta1
+kind: ArgoCD
+metadata:
+  name: current
+---
+apiVersion: argoproj.io/v1alpha1
+kind: ArgoCD
+metadata:
+  name: deprecated
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: unknown
+`
+
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stats, err := ManifestK8sClient(path, ManifestInputFormat_YAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ManifestStats{Documents: 3, ArgoCDs: 1, V1alpha1ArgoCDs: 1, Skipped: 1}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
@@ -0,0 +1,395 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// mustGatherNamespacesDir is the directory of a must-gather which contains a directory for each namespace, e.g. 'namespaces/(namespace)/(group)/(resource).yaml'
+	mustGatherNamespacesDir = "namespaces"
+
+	// mustGatherClusterScopedDir is the directory of a must-gather which contains the cluster-scoped resources, e.g. 'cluster-scoped-resources/(group)/(resource)/(name).yaml'
+	mustGatherClusterScopedDir = "cluster-scoped-resources"
+
+	// mustGatherCoreGroupDir is the directory name used by must-gather for the core ("") API group
+	mustGatherCoreGroupDir = "core"
+)
+
+// mustGatherEagerGroups are the API groups which are read when the must-gather client is created, rather than on first use: the ArgoCD CRs, and the OLM Subscription/CSV of the operator. A file of these groups which cannot be parsed is then an error of MustGatherK8sClient, so that the must-gather is read via omc instead, rather than the resource silently being missing from the results.
+var mustGatherEagerGroups = []string{"argoproj.io", "operators.coreos.com"}
+
+// mustGatherK8sClient reads K8s resources directly from the files of a must-gather directory, without requiring the 'omc' tool.
+// - Resources are read from the standard must-gather layout ('oc adm inspect'): 'namespaces/(ns)/(group)/(resource).yaml' (a List), 'namespaces/(ns)/(group)/(resource)/(name).yaml', the 'namespaces/(ns)/(ns).yaml' Namespace, and the same layouts under 'cluster-scoped-resources/(group)'.
+// - The files of an API group are only read (and decoded) the first time a resource of that group is requested.
+// - As with omc, a must-gather may only include a subset of namespaces, so the control plane data is always incomplete.
+// - A file of a resource type known to the tool which cannot be parsed is an error (see objectsOfGroup), rather than being skipped, since the resources in it would otherwise silently be missing from the results.
+type mustGatherK8sClient struct {
+	scheme *runtime.Scheme
+
+	// roots are the directories which contain the 'namespaces'/'cluster-scoped-resources' directories. A must-gather contains one such directory for each must-gather image that was run.
+	roots []string
+
+	// mutex protects the fields below
+	mutex sync.Mutex
+
+	// objectsByGroup are the resources (of kinds known to the tool) that were read from the must-gather, by API group. A group is only present once it has been read.
+	objectsByGroup map[string][]unstructured.Unstructured
+
+	// sourcesByGroup[group][i] is the file from which objectsByGroup[group][i] was read
+	sourcesByGroup map[string][]ManifestSource
+
+	// kindsWithData are the kinds for which at least one resource was returned, and kindsWithNoResources the kinds for which a List returned no resources. See ResourceTypesWithNoResources.
+	kindsWithData        map[string]bool
+	kindsWithNoResources map[string]bool
+}
+
+// MustGatherK8sClient returns a client which reads K8s resources directly from the must-gather directory at 'path'. Returns an error if the directory does not have the standard must-gather layout, or if a file of the ArgoCD CRs or the OLM resources of the operator cannot be parsed (see mustGatherEagerGroups), in which case the must-gather may still be readable via OMCClient.
+func MustGatherK8sClient(path string) (*mustGatherK8sClient, error) {
+
+	roots, err := mustGatherRoots(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &mustGatherK8sClient{
+		scheme:               scheme,
+		roots:                roots,
+		objectsByGroup:       map[string][]unstructured.Unstructured{},
+		sourcesByGroup:       map[string][]ManifestSource{},
+		kindsWithData:        map[string]bool{},
+		kindsWithNoResources: map[string]bool{},
+	}
+
+	for _, group := range mustGatherEagerGroups {
+		if _, err := res.objectsOfGroup(group); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// mustGatherRoots returns the directories at (or immediately within) 'path' which contain a 'namespaces' or 'cluster-scoped-resources' directory. A must-gather usually contains one such directory per must-gather image (e.g. 'quay-io-(image)-sha256-(digest)'), but the path may also be one of those directories.
+func mustGatherRoots(path string) ([]string, error) {
+
+	isRoot := func(dir string) bool {
+		for _, name := range []string{mustGatherNamespacesDir, mustGatherClusterScopedDir} {
+			if fileInfo, err := os.Stat(filepath.Join(dir, name)); err == nil && fileInfo.IsDir() {
+				return true
+			}
+		}
+		return false
+	}
+
+	if isRoot(path) {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && isRoot(filepath.Join(path, entry.Name())) {
+			res = append(res, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("'%s' does not have the standard must-gather layout: no '%s' or '%s' directory was found in it, or in its subdirectories", path, mustGatherNamespacesDir, mustGatherClusterScopedDir)
+	}
+
+	return res, nil
+}
+
+func (m *mustGatherK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	return m.list(list, "")
+}
+
+func (m *mustGatherK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return m.list(list, namespace)
+}
+
+// list populates 'list' with the resources of the list's item type, from 'namespace' (or from all namespaces if empty)
+func (m *mustGatherK8sClient) list(list client.ObjectList, namespace string) error {
+
+	listGVK, err := apiutil.GVKForObject(list, m.scheme)
+	if err != nil {
+		return err
+	}
+
+	objects, err := m.objectsOfGroup(listGVK.Group)
+	if err != nil {
+		return err
+	}
+
+	// The filtering of resources by kind/namespace is the same as for manifests
+	if err := (&manifestK8sClient{scheme: m.scheme, objects: objects}).list(list, namespace); err != nil {
+		return err
+	}
+
+	m.recordKindResult(strings.TrimSuffix(listGVK.Kind, "List"), meta.LenList(list) > 0)
+
+	return nil
+}
+
+func (m *mustGatherK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+
+	gvk, err := apiutil.GVKForObject(obj, m.scheme)
+	if err != nil {
+		return err
+	}
+
+	objects, err := m.objectsOfGroup(gvk.Group)
+	if err != nil {
+		return err
+	}
+
+	return (&manifestK8sClient{scheme: m.scheme, objects: objects}).Get(ctx, key, obj)
+}
+
+func (m *mustGatherK8sClient) ManifestSourceOf(obj client.Object) (ManifestSource, bool) {
+
+	gvk, err := apiutil.GVKForObject(obj, m.scheme)
+	if err != nil {
+		return ManifestSource{}, false
+	}
+
+	objects, err := m.objectsOfGroup(gvk.Group)
+	if err != nil {
+		return ManifestSource{}, false
+	}
+
+	m.mutex.Lock()
+	sources := m.sourcesByGroup[gvk.Group]
+	m.mutex.Unlock()
+
+	return (&manifestK8sClient{scheme: m.scheme, objects: objects, sources: sources}).ManifestSourceOf(obj)
+}
+
+func (m *mustGatherK8sClient) IncompleteControlPlaneData() bool {
+	return true
+}
+
+// recordKindResult records whether a List of a kind returned any resources. See ResourceTypesWithNoResources.
+func (m *mustGatherK8sClient) recordKindResult(kind string, hasData bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if hasData {
+		m.kindsWithData[kind] = true
+	} else {
+		m.kindsWithNoResources[kind] = true
+	}
+}
+
+// ResourceTypesWithNoResources returns the kinds (e.g. 'ResourceQuota') which were listed, but for which the must-gather never contained any resources (in any namespace), in sorted order.
+// - This may be because there are genuinely no resources of the kind, or because the resources (or their namespace) were not captured in the must-gather: the caller should present these as possibly missing data.
+func (m *mustGatherK8sClient) ResourceTypesWithNoResources() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	res := []string{}
+	for kind := range m.kindsWithNoResources {
+		if !m.kindsWithData[kind] {
+			res = append(res, kind)
+		}
+	}
+	slices.Sort(res)
+
+	return res
+}
+
+// objectsOfGroup returns the resources of the given API group which were read from the must-gather, reading them on first use.
+// - Returns an error if a file of a resource type of the group which is known to the tool (e.g. 'argoproj.io/argocds.yaml') cannot be parsed. Other files which cannot be parsed are skipped, since must-gathers also contain YAML files which are not K8s resources (for example, collected configuration files).
+func (m *mustGatherK8sClient) objectsOfGroup(group string) ([]unstructured.Unstructured, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if objects, exists := m.objectsByGroup[group]; exists {
+		return objects, nil
+	}
+
+	files, err := m.filesOfGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	knownResources := m.knownResourcesOfGroup(group)
+
+	res := []unstructured.Unstructured{}
+	sources := []ManifestSource{}
+
+	// The same resource may be present both in a List file and in a file of its own, so only the first is kept
+	seen := map[string]bool{}
+
+	for _, file := range files {
+
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read must-gather file '%s': %w", file.path, err)
+		}
+
+		documents, err := decodeManifestDocuments(data, ManifestInputFormat_YAML)
+		if err != nil {
+			if knownResources[file.resource] {
+				return nil, fmt.Errorf("unable to parse must-gather file '%s': %w", file.path, err)
+			}
+			continue
+		}
+
+		for _, document := range documents {
+
+			gvk := document.object.GroupVersionKind()
+			if gvk.Group != group || !m.scheme.Recognizes(gvk) {
+				continue
+			}
+
+			id := gvk.Kind + "/" + document.object.GetNamespace() + "/" + document.object.GetName()
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			res = append(res, document.object)
+			sources = append(sources, ManifestSource{Path: file.path, Line: document.line})
+		}
+	}
+
+	m.objectsByGroup[group] = res
+	m.sourcesByGroup[group] = sources
+
+	return res, nil
+}
+
+// knownResourcesOfGroup returns the (lower case, plural) resource names of the kinds of the given API group which are known to the tool, e.g. 'argocds' for 'argoproj.io'
+func (m *mustGatherK8sClient) knownResourcesOfGroup(group string) map[string]bool {
+
+	res := map[string]bool{}
+
+	for gvk := range m.scheme.AllKnownTypes() {
+		if gvk.Group != group || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		res[plural.Resource] = true
+	}
+
+	return res
+}
+
+// mustGatherFile is a YAML file of the must-gather which may contain resources of an API group (see filesOfGroup)
+type mustGatherFile struct {
+	path string
+
+	// resource is the (lower case, plural) resource name that the location of the file corresponds to, e.g. 'argocds' for both 'argoproj.io/argocds.yaml' and 'argoproj.io/argocds/(name).yaml', or 'namespaces' for the Namespace file of a namespace
+	resource string
+}
+
+// filesOfGroup returns the YAML files of the must-gather which may contain resources of the given API group
+func (m *mustGatherK8sClient) filesOfGroup(group string) ([]mustGatherFile, error) {
+
+	groupDir := group
+	if groupDir == "" {
+		groupDir = mustGatherCoreGroupDir
+	}
+
+	res := []mustGatherFile{}
+
+	for _, root := range m.roots {
+
+		groupDirs := []string{filepath.Join(root, mustGatherClusterScopedDir, groupDir)}
+
+		namespaceEntries, err := os.ReadDir(filepath.Join(root, mustGatherNamespacesDir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		for _, namespaceEntry := range namespaceEntries {
+			if !namespaceEntry.IsDir() {
+				continue
+			}
+			namespaceDir := filepath.Join(root, mustGatherNamespacesDir, namespaceEntry.Name())
+
+			groupDirs = append(groupDirs, filepath.Join(namespaceDir, groupDir))
+
+			// The Namespace resource itself is stored alongside the resources of the namespace, e.g. 'namespaces/openshift-gitops/openshift-gitops.yaml'
+			if group == "" {
+				for _, path := range mustGatherYAMLFiles(namespaceDir, false) {
+					res = append(res, mustGatherFile{path: path, resource: "namespaces"})
+				}
+			}
+		}
+
+		for _, dir := range groupDirs {
+			for _, path := range mustGatherYAMLFiles(dir, true) {
+				res = append(res, mustGatherFile{path: path, resource: mustGatherResourceOfFile(dir, path)})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// mustGatherResourceOfFile returns the resource name that a file within a group directory corresponds to: the file name for a List file (e.g. '(group)/argocds.yaml'), otherwise the directory of the file (e.g. '(group)/argocds/(name).yaml')
+func mustGatherResourceOfFile(groupDir string, path string) string {
+
+	relativePath, err := filepath.Rel(groupDir, path)
+	if err != nil {
+		return ""
+	}
+
+	if dir, _, found := strings.Cut(relativePath, string(filepath.Separator)); found {
+		return dir
+	}
+
+	return strings.TrimSuffix(relativePath, filepath.Ext(relativePath))
+}
+
+// mustGatherYAMLFiles returns the '.yaml'/'.yml' files in 'dir' (and its subdirectories, if 'recursive'), or none if 'dir' does not exist
+func mustGatherYAMLFiles(dir string, recursive bool) []string {
+
+	res := []string{}
+
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Missing/unreadable directories are expected (most groups are not present in every namespace)
+			return filepath.SkipDir
+		}
+
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		extension := strings.ToLower(filepath.Ext(path))
+		if extension == ".yaml" || extension == ".yml" {
+			res = append(res, path)
+		}
+		return nil
+	})
+
+	return res
+}
@@ -0,0 +1,131 @@
+package clients
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	argov1beta1api "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const mustGatherTestArgoCD = `apiVersion: argoproj.io/v1beta1
+kind: ArgoCD
+metadata:
+  name: argocd
+  namespace: team-a
+`
+
+// writeMustGatherFiles creates a must-gather directory containing the given files (relative path to content), and returns its path
+func writeMustGatherFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for relativePath, content := range files {
+		path := filepath.Join(dir, relativePath)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMustGatherK8sClientReadsResources(t *testing.T) {
+
+	dir := writeMustGatherFiles(t, map[string]string{
+		"namespaces/team-a/argoproj.io/argocds/argocd.yaml": mustGatherTestArgoCD,
+		// YAML files which are not in a directory of a known resource type are skipped, even if they cannot be parsed
+		"namespaces/team-a/argoproj.io/notes/config.yaml": "not: [valid",
+	})
+
+	k8sClient, err := MustGatherK8sClient(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var argoCDs argov1beta1api.ArgoCDList
+	if err := k8sClient.ListFromAllNamespaces(context.Background(), &argoCDs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(argoCDs.Items) != 1 || argoCDs.Items[0].Namespace != "team-a" || argoCDs.Items[0].Name != "argocd" {
+		t.Errorf("expected the ArgoCD CR team-a/argocd, got %+v", argoCDs.Items)
+	}
+}
+
+func TestMustGatherK8sClientMalformedResourceFile(t *testing.T) {
+
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "ArgoCD List file", file: "namespaces/team-a/argoproj.io/argocds.yaml"},
+		{name: "ArgoCD file", file: "namespaces/team-a/argoproj.io/argocds/argocd.yaml"},
+		{name: "Subscription file", file: "namespaces/openshift-gitops-operator/operators.coreos.com/subscriptions/gitops.yaml"},
+		{name: "CSV file", file: "namespaces/openshift-gitops-operator/operators.coreos.com/clusterserviceversions.yaml"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			dir := writeMustGatherFiles(t, map[string]string{
+				test.file: "kind: ArgoCD\nmetadata: [unterminated",
+			})
+
+			// The file is of a group which is read eagerly, so the error is returned by MustGatherK8sClient (allowing the caller to fall back to omc)
+			_, err := MustGatherK8sClient(dir)
+			if err == nil {
+				t.Fatalf("expected an error for the malformed file")
+			}
+			if !strings.Contains(err.Error(), filepath.Base(test.file)) {
+				t.Errorf("expected the error to name the malformed file, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMustGatherK8sClientMalformedResourceFileOfLazyGroup(t *testing.T) {
+
+	dir := writeMustGatherFiles(t, map[string]string{
+		"namespaces/team-a/argoproj.io/argocds/argocd.yaml": mustGatherTestArgoCD,
+		"namespaces/team-a/core/configmaps.yaml":            "kind: ConfigMapList\nitems: [unterminated",
+	})
+
+	k8sClient, err := MustGatherK8sClient(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The core group is only read on first use, at which point the malformed file is an error, rather than the ConfigMaps silently being missing
+	var configMaps corev1.ConfigMapList
+	if err := k8sClient.ListFromAllNamespaces(context.Background(), &configMaps); err == nil {
+		t.Errorf("expected an error for the malformed file, got %d ConfigMap(s)", len(configMaps.Items))
+	}
+}
+
+func TestMustGatherResourceOfFile(t *testing.T) {
+
+	groupDir := filepath.Join("ns", "argoproj.io")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: filepath.Join(groupDir, "argocds.yaml"), expected: "argocds"},
+		{path: filepath.Join(groupDir, "argocds", "argocd.yaml"), expected: "argocds"},
+		{path: filepath.Join(groupDir, "argocds", "nested", "argocd.yml"), expected: "argocds"},
+	}
+
+	for _, test := range tests {
+		if actual := mustGatherResourceOfFile(groupDir, test.path); actual != test.expected {
+			t.Errorf("%s: expected '%s', got '%s'", test.path, test.expected, actual)
+		}
+	}
+}
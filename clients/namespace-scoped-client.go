@@ -0,0 +1,58 @@
+package clients
+
+import (
+	"context"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceScopedK8sClient wraps another AbstractK8sClient, and scopes all 'list from all namespaces' calls to a single namespace.
+// - This is useful for users who do not have cluster-wide read access (for example, users who may only read resources in the namespace of their own Argo CD instance), since a cluster-wide List would otherwise fail entirely.
+// - Cluster-scoped resources (e.g. Namespaces) cannot be scoped to a namespace, and so are passed through to the wrapped client unchanged (and may fail if the user lacks permission).
+type namespaceScopedK8sClient struct {
+	inner     AbstractK8sClient
+	namespace string
+}
+
+// NamespaceScopedK8sClient returns a client that lists resources only from 'namespace', rather than from all namespaces.
+func NamespaceScopedK8sClient(inner AbstractK8sClient, namespace string) AbstractK8sClient {
+	return &namespaceScopedK8sClient{
+		inner:     inner,
+		namespace: namespace,
+	}
+}
+
+func (n *namespaceScopedK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+
+	if isClusterScopedList(list) {
+		return n.inner.ListFromAllNamespaces(ctx, list)
+	}
+
+	return n.inner.ListFromSingleNamespace(ctx, list, n.namespace)
+}
+
+func (n *namespaceScopedK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return n.inner.ListFromSingleNamespace(ctx, list, namespace)
+}
+
+func (n *namespaceScopedK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return n.inner.Get(ctx, key, obj)
+}
+
+// IncompleteControlPlaneData always returns true: resources from outside the namespace are not visible to this client, so the data does not represent the full set of resources on the cluster.
+func (n *namespaceScopedK8sClient) IncompleteControlPlaneData() bool {
+	return true
+}
+
+// isClusterScopedList returns true if the list is of a cluster-scoped resource type. This must include each cluster-scoped resource type that is listed via ListFromAllNamespaces.
+func isClusterScopedList(list client.ObjectList) bool {
+	switch list.(type) {
+	case *corev1.NamespaceList, *corev1.NodeList, *rbacv1.ClusterRoleList, *rbacv1.ClusterRoleBindingList, *securityv1.SecurityContextConstraintsList:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeListK8sClient is a fake AbstractK8sClient which records the namespace of each List call ("" for ListFromAllNamespaces)
+type fakeListK8sClient struct {
+	listNamespaces []string
+}
+
+func (f *fakeListK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	f.listNamespaces = append(f.listNamespaces, "")
+	return nil
+}
+
+func (f *fakeListK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	f.listNamespaces = append(f.listNamespaces, namespace)
+	return nil
+}
+
+func (f *fakeListK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return nil
+}
+
+func (f *fakeListK8sClient) IncompleteControlPlaneData() bool {
+	return false
+}
+
+func TestNamespaceScopedK8sClientListFromAllNamespaces(t *testing.T) {
+
+	tests := []struct {
+		name string
+		list client.ObjectList
+
+		// expectedNamespace is the namespace that the list is expected to be scoped to, or "" if it is expected to be listed from all namespaces
+		expectedNamespace string
+	}{
+		{name: "Namespaces", list: &corev1.NamespaceList{}},
+		{name: "Nodes", list: &corev1.NodeList{}},
+		{name: "ClusterRoles", list: &rbacv1.ClusterRoleList{}},
+		{name: "ClusterRoleBindings", list: &rbacv1.ClusterRoleBindingList{}},
+		{name: "SecurityContextConstraints", list: &securityv1.SecurityContextConstraintsList{}},
+		{name: "Secrets", list: &corev1.SecretList{}, expectedNamespace: "argocd"},
+		{name: "RoleBindings", list: &rbacv1.RoleBindingList{}, expectedNamespace: "argocd"},
+		{name: "Applications", list: &argocdv1alpha1.ApplicationList{}, expectedNamespace: "argocd"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			inner := &fakeListK8sClient{}
+
+			if err := NamespaceScopedK8sClient(inner, "argocd").ListFromAllNamespaces(context.Background(), test.list); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(inner.listNamespaces) != 1 || inner.listNamespaces[0] != test.expectedNamespace {
+				t.Errorf("expected a single list from namespace '%s' (\"\" is all namespaces), got: %q", test.expectedNamespace, inner.listNamespaces)
+			}
+		})
+	}
+}
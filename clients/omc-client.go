@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
@@ -13,19 +18,110 @@ import (
 // omcClient is a wrapper for the OMC CLI tool (https://github.com/gmeghnag/omc). It is used to read must-gathers from K8s (OpenShift) .
 type omcClient struct {
 	omcPath string
+
+	// runOMC runs omc commands: see omcCommandRunner
+	runOMC omcCommandRunner
+
+	// resourceTypesMutex protects the fields below
+	resourceTypesMutex sync.Mutex
+
+	// resourceTypesWithData are the OMC resource types (e.g. 'argocds') for which omc returned at least one resource
+	resourceTypesWithData map[string]bool
+
+	// resourceTypesWithNoResources are the OMC resource types for which omc reported that no resources were found
+	resourceTypesWithNoResources map[string]bool
+}
+
+// omcCommandRunner runs omc with the given arguments, and returns its combined output (stdout and stderr). This allows the omc binary to be replaced, for example by a fake which returns canned output.
+type omcCommandRunner func(args ...string) ([]byte, error)
+
+// execOMCCommand is the default omcCommandRunner, which runs the 'omc' binary from the PATH
+func execOMCCommand(args ...string) ([]byte, error) {
+	return exec.Command("omc", args...).CombinedOutput()
 }
 
+// omcUseAttempts is the number of times 'omc use' is run (and verified) before giving up
+const omcUseAttempts = 3
+
+// omcProjectMustGatherRegex matches the must-gather path in the output of 'omc project', e.g. 'Using project "default" on must-gather "/tmp/must-gather/quay-io-(...)".'
+var omcProjectMustGatherRegex = regexp.MustCompile(`on must-gather "([^"]*)"`)
+
 func OMCClient(path string) (*omcClient, error) {
+	return omcClientWithRunner(path, execOMCCommand)
+}
+
+// omcClientWithRunner returns an omcClient which uses 'runOMC' to run omc, after running 'omc use' with the must-gather at 'path', and verifying that omc is now using it.
+// - omc keeps the must-gather in use in its own config file (shared between invocations), so if 'omc use' fails without reporting an error, or another process switches the must-gather, every 'omc get' silently reads a different (e.g. previously used) must-gather. The verification prevents results being reported for the wrong must-gather.
+// - 'omc use' and the verification are retried (see omcUseAttempts), since a concurrent omc invocation may briefly leave the config file in an inconsistent state.
+func omcClientWithRunner(path string, runOMC omcCommandRunner) (*omcClient, error) {
+
+	var lastErr error
+
+	for range omcUseAttempts {
+
+		if outBytes, err := runOMC("use", path); err != nil {
+			lastErr = fmt.Errorf("failed to run 'omc use %s': %w (output: %s)", path, err, strings.TrimSpace(string(outBytes)))
+			continue
+		}
+
+		if lastErr = verifyOMCMustGatherInUse(path, runOMC); lastErr != nil {
+			continue
+		}
+
+		return &omcClient{
+			omcPath:                      path,
+			runOMC:                       runOMC,
+			resourceTypesWithData:        map[string]bool{},
+			resourceTypesWithNoResources: map[string]bool{},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("after %d attempts: %w", omcUseAttempts, lastErr)
+}
+
+// verifyOMCMustGatherInUse returns an error if the must-gather that omc reports as in use (via 'omc project') is not the must-gather at 'path' (or a directory within it: omc uses the directory which contains the resources, e.g. '(path)/quay-io-(...)').
+func verifyOMCMustGatherInUse(path string, runOMC omcCommandRunner) error {
+
+	outBytes, err := runOMC("project")
+	output := strings.TrimSpace(string(outBytes))
+	if err != nil {
+		return fmt.Errorf("unable to verify the must-gather in use by omc, 'omc project' failed: %w (output: %s)", err, output)
+	}
+
+	match := omcProjectMustGatherRegex.FindStringSubmatch(output)
+	if match == nil {
+		return fmt.Errorf("unable to verify the must-gather in use by omc, unrecognized output from 'omc project': %s", output)
+	}
+
+	requestedPath, err := canonicalOMCPath(path)
+	if err != nil {
+		return err
+	}
+	inUsePath, err := canonicalOMCPath(match[1])
+	if err != nil {
+		return err
+	}
+
+	if inUsePath != requestedPath && !strings.HasPrefix(inUsePath, requestedPath+string(filepath.Separator)) {
+		return fmt.Errorf("omc is using the must-gather at '%s', rather than the requested must-gather at '%s': this may be left over from a previous 'omc use'. Results would not be from the requested must-gather", match[1], path)
+	}
+
+	return nil
+}
 
-	cmd := exec.Command("omc", "use", path)
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to run 'omc use %s': %w", path, err)
+// canonicalOMCPath returns the absolute path of 'path', with symbolic links resolved where possible, so that paths reported by omc can be compared with the path that was requested
+func canonicalOMCPath(path string) (string, error) {
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the absolute path of '%s': %w", path, err)
 	}
 
-	return &omcClient{
-		omcPath: path,
-	}, nil
+	if resolvedPath, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolvedPath
+	}
 
+	return filepath.Clean(absPath), nil
 }
 
 func (o *omcClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
@@ -35,12 +131,12 @@ func (o *omcClient) ListFromAllNamespaces(ctx context.Context, list client.Objec
 		return fmt.Errorf("unable to convert objectListToOMCType: %v", err)
 	}
 
-	cmd := exec.Command("omc", "get", typeFromList, "-A", "-o", "yaml")
-	outBytes, err := cmd.CombinedOutput()
+	outBytes, err := o.runOMC("get", typeFromList, "-A", "-o", "yaml")
 	k8sResourceListYAML := (string)(outBytes)
 
 	// omc returns yaml EXCEPT when (e.g.) this error occurs. Note that when this error occurs, error code from omc is 0.
-	if strings.HasPrefix(k8sResourceListYAML, "No resources ") && strings.HasSuffix(strings.TrimSpace(k8sResourceListYAML), "found.") {
+	if isOMCNoResourcesFoundOutput(k8sResourceListYAML) {
+		o.recordResourceTypeResult(typeFromList, false)
 		return nil
 	}
 
@@ -52,19 +148,60 @@ func (o *omcClient) ListFromAllNamespaces(ctx context.Context, list client.Objec
 		return fmt.Errorf("Output from OMC: %s\nFailed to unmarshal YAML to %T: %w", k8sResourceListYAML, list, err)
 	}
 
+	o.recordResourceTypeResult(typeFromList, meta.LenList(list) > 0)
+
 	return nil
 }
 
+// isOMCNoResourcesFoundOutput returns true if the output of 'omc get' is the 'No resources found.' (or 'No resources found in (namespace) namespace.') sentinel, rather than YAML. omc outputs this sentinel both when there are genuinely no resources of the type, and when the resources were not captured in the must-gather: the two cannot be distinguished.
+func isOMCNoResourcesFoundOutput(output string) bool {
+	output = strings.TrimSpace(output)
+	return strings.HasPrefix(output, "No resources found") && !strings.Contains(output, "\n")
+}
+
+// recordResourceTypeResult records whether omc returned resources ('hasData'), or the 'no resources found' sentinel, for a resource type. See ResourceTypesWithNoResources.
+func (o *omcClient) recordResourceTypeResult(resourceType string, hasData bool) {
+	o.resourceTypesMutex.Lock()
+	defer o.resourceTypesMutex.Unlock()
+
+	if hasData {
+		o.resourceTypesWithData[resourceType] = true
+	} else {
+		o.resourceTypesWithNoResources[resourceType] = true
+	}
+}
+
+// ResourceTypesWithNoResources returns the OMC resource types (e.g. 'resourcequotas') which were read, but for which omc never returned any resources (in any namespace), in sorted order.
+// - This may be because there are genuinely no resources of the type, or because the resources (or their namespace) were not captured in the must-gather: the caller should present these as possibly missing data.
+func (o *omcClient) ResourceTypesWithNoResources() []string {
+	o.resourceTypesMutex.Lock()
+	defer o.resourceTypesMutex.Unlock()
+
+	res := []string{}
+	for resourceType := range o.resourceTypesWithNoResources {
+		if !o.resourceTypesWithData[resourceType] {
+			res = append(res, resourceType)
+		}
+	}
+	slices.Sort(res)
+
+	return res
+}
+
 func (o *omcClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
 	typeFromList, err := convertObjectListToOMCType(list)
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command("omc", "get", typeFromList, "-n", namespace, "-o", "yaml")
-	outBytes, err := cmd.CombinedOutput()
+	outBytes, err := o.runOMC("get", typeFromList, "-n", namespace, "-o", "yaml")
 	k8sResourceListYAML := (string)(outBytes)
 
+	if isOMCNoResourcesFoundOutput(k8sResourceListYAML) {
+		o.recordResourceTypeResult(typeFromList, false)
+		return nil
+	}
+
 	if err != nil {
 		return fmt.Errorf("Output from OMC: %s\nUnable to retrieve '%s' from all namespaces: %v", k8sResourceListYAML, typeFromList, err)
 	}
@@ -73,6 +210,8 @@ func (o *omcClient) ListFromSingleNamespace(ctx context.Context, list client.Obj
 		return fmt.Errorf("Output from OMC: %s\nFailed to unmarshal YAML to %T: %w", k8sResourceListYAML, list, err)
 	}
 
+	o.recordResourceTypeResult(typeFromList, meta.LenList(list) > 0)
+
 	return nil
 }
 
@@ -82,8 +221,7 @@ func (o *omcClient) Get(ctx context.Context, key client.ObjectKey, obj client.Ob
 		return err
 	}
 
-	cmd := exec.Command("omc", "get", typeFromObj, key.Name, "-n", key.Namespace, "-o", "yaml")
-	outBytes, err := cmd.CombinedOutput()
+	outBytes, err := o.runOMC("get", typeFromObj, key.Name, "-n", key.Namespace, "-o", "yaml")
 	k8sResourceYAML := (string)(outBytes)
 
 	if err != nil {
@@ -110,8 +248,18 @@ func convertObjectListToOMCType(list client.ObjectList) (string, error) {
 		return "subscriptions", nil
 	case "*v1alpha1.ClusterServiceVersion":
 		return "clusterserviceversions", nil
+	case "*v1.OperatorGroupList":
+		return "operatorgroups", nil
 	case "*v1.NamespaceList":
 		return "namespaces", nil
+	case "*v1alpha1.ApplicationList":
+		return "applications", nil
+	case "*v1.ResourceQuotaList":
+		return "resourcequotas", nil
+	case "*v1.LimitRangeList":
+		return "limitranges", nil
+	case "*v1.EventList":
+		return "events", nil
 
 	default:
 		return "", fmt.Errorf("unrecognized type: %s", listType)
@@ -129,6 +277,10 @@ func convertObjectToOMCType(obj client.Object) (string, error) {
 		return "clusterserviceversions", nil
 	case "*v1.Namespace":
 		return "namespaces", nil
+	case "*v1.Secret":
+		return "secrets", nil
+	case "*v1.ConfigMap":
+		return "configmaps", nil
 	default:
 		return "", fmt.Errorf("unrecognized type: %s", objType)
 	}
@@ -0,0 +1,130 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeOMC is a fake omc binary (see omcCommandRunner), which reports the given must-gather paths from successive 'omc project' invocations
+type fakeOMC struct {
+	// projectPaths are the must-gather paths reported by each 'omc project' invocation. The last path is repeated for any further invocations.
+	projectPaths []string
+
+	// useErr is returned by every 'omc use' invocation, if non-nil
+	useErr error
+
+	useCalls     int
+	projectCalls int
+}
+
+func (f *fakeOMC) run(args ...string) ([]byte, error) {
+
+	switch args[0] {
+	case "use":
+		f.useCalls++
+		if f.useErr != nil {
+			return []byte("error: unable to use must-gather"), f.useErr
+		}
+		return []byte{}, nil
+
+	case "project":
+		path := f.projectPaths[min(f.projectCalls, len(f.projectPaths)-1)]
+		f.projectCalls++
+		return fmt.Appendf(nil, "Using project \"default\" on must-gather \"%s\".\n", path), nil
+	}
+
+	return nil, fmt.Errorf("unexpected omc command: %v", args)
+}
+
+func TestOMCClientWithRunner(t *testing.T) {
+
+	mustGatherPath := filepath.Join(t.TempDir(), "must-gather")
+	stalePath := filepath.Join(t.TempDir(), "previous-must-gather")
+
+	tests := []struct {
+		name        string
+		omc         *fakeOMC
+		expectedErr string
+
+		expectedUseCalls int
+	}{
+		{
+			name:             "omc uses the requested must-gather",
+			omc:              &fakeOMC{projectPaths: []string{mustGatherPath}},
+			expectedUseCalls: 1,
+		},
+		{
+			name:             "omc uses a directory within the requested must-gather",
+			omc:              &fakeOMC{projectPaths: []string{filepath.Join(mustGatherPath, "quay-io-openshift-gitops-must-gather-sha256-0123")}},
+			expectedUseCalls: 1,
+		},
+		{
+			name:             "omc uses the requested must-gather on the second attempt",
+			omc:              &fakeOMC{projectPaths: []string{stalePath, mustGatherPath}},
+			expectedUseCalls: 2,
+		},
+		{
+			name:             "omc remains on a stale must-gather",
+			omc:              &fakeOMC{projectPaths: []string{stalePath}},
+			expectedErr:      "rather than the requested must-gather",
+			expectedUseCalls: omcUseAttempts,
+		},
+		{
+			name:             "omc uses a must-gather whose path only has the requested path as a prefix",
+			omc:              &fakeOMC{projectPaths: []string{mustGatherPath + "-old"}},
+			expectedErr:      "rather than the requested must-gather",
+			expectedUseCalls: omcUseAttempts,
+		},
+		{
+			name:             "'omc use' fails",
+			omc:              &fakeOMC{projectPaths: []string{mustGatherPath}, useErr: errors.New("exit status 1")},
+			expectedErr:      "failed to run 'omc use",
+			expectedUseCalls: omcUseAttempts,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			omcClient, err := omcClientWithRunner(mustGatherPath, test.omc.run)
+
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Errorf("expected an error containing '%s', got: %v", test.expectedErr, err)
+				}
+			} else if err != nil || omcClient == nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if test.omc.useCalls != test.expectedUseCalls {
+				t.Errorf("expected 'omc use' to be run %d time(s), got %d", test.expectedUseCalls, test.omc.useCalls)
+			}
+		})
+	}
+}
+
+func TestIsOMCNoResourcesFoundOutput(t *testing.T) {
+
+	tests := []struct {
+		output   string
+		expected bool
+	}{
+		{output: "No resources found.", expected: true},
+		{output: "No resources found in openshift-gitops namespace.\n", expected: true},
+		{output: "  No resources found.  \n", expected: true},
+		{output: "apiVersion: v1\nitems: []\nkind: List\n", expected: false},
+		{output: "", expected: false},
+		// YAML output which happens to contain the sentinel text is not the sentinel
+		{output: "No resources found.\napiVersion: v1\nkind: List\n", expected: false},
+		{output: "error: resource type \"argocds\" not known", expected: false},
+	}
+
+	for _, test := range tests {
+		if actual := isOMCNoResourcesFoundOutput(test.output); actual != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.output, test.expected, actual)
+		}
+	}
+}
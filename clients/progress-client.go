@@ -0,0 +1,95 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// progressK8sClient wraps another AbstractK8sClient, and writes a lightweight progress indicator (the resource type currently being fetched, and elapsed time) while each call is in progress.
+// - This is purely cosmetic: all calls are passed through to the wrapped client unchanged.
+// - This is mostly useful for the OMC client, where each 'omc get' against a large must-gather can take many seconds.
+type progressK8sClient struct {
+	inner AbstractK8sClient
+	out   io.Writer
+}
+
+// ProgressK8sClient returns a client that passes all calls through to 'inner', while writing progress output to 'out' (usually stderr).
+func ProgressK8sClient(inner AbstractK8sClient, out io.Writer) AbstractK8sClient {
+	return &progressK8sClient{
+		inner: inner,
+		out:   out,
+	}
+}
+
+func (p *progressK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	stop := p.start("Fetching " + resourceTypeName(list) + " from all namespaces")
+	defer stop()
+
+	return p.inner.ListFromAllNamespaces(ctx, list)
+}
+
+func (p *progressK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	stop := p.start("Fetching " + resourceTypeName(list) + " from namespace '" + namespace + "'")
+	defer stop()
+
+	return p.inner.ListFromSingleNamespace(ctx, list, namespace)
+}
+
+func (p *progressK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	stop := p.start("Fetching " + resourceTypeName(obj) + " '" + key.String() + "'")
+	defer stop()
+
+	return p.inner.Get(ctx, key, obj)
+}
+
+func (p *progressK8sClient) IncompleteControlPlaneData() bool {
+	return p.inner.IncompleteControlPlaneData()
+}
+
+// start begins writing the progress indicator for 'description', and returns a function that stops the indicator and clears it from the line.
+// - Nothing is written for calls that complete before the first tick, to avoid flicker on fast calls.
+func (p *progressK8sClient) start(description string) func() {
+
+	spinnerFrames := []string{"|", "/", "-", "\\"}
+
+	startTime := time.Now()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		written := false
+
+		for frame := 0; ; frame++ {
+			select {
+			case <-done:
+				if written {
+					fmt.Fprint(p.out, "\r\033[K") // Return to start of line, and clear it
+				}
+				return
+			case <-ticker.C:
+				fmt.Fprintf(p.out, "\r\033[K%s %s (%.0fs elapsed)", spinnerFrames[frame%len(spinnerFrames)], description, time.Since(startTime).Seconds())
+				written = true
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// resourceTypeName returns a short human-readable name for the type of a K8s object/list, e.g. 'v1beta1.ArgoCDList'
+func resourceTypeName(obj any) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", obj), "*")
+}
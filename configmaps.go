@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/fatih/color"
+	"github.com/jgwest/argocd-config-check/clients"
+)
+
+// This file contains the mappings between ArgoCD CR fields and the 'argocd-cm'/'argocd-cmd-params-cm' ConfigMap keys that they correspond to. These mappings are used both by the checks (for example, to detect when a user specified a value in '.spec.extraConfig' that has a corresponding CR field) and by '--config-map-dump' to compute the effective ConfigMap contents.
+
+// configMapFieldMapping maps a ConfigMap key to the ArgoCD CR field that the operator uses to populate it.
+type configMapFieldMapping struct {
+	key     string // ConfigMap key, e.g. 'admin.enabled'
+	crField string // ArgoCD CR field, e.g. '.spec.disableAdmin'
+
+	// crValue returns the value the operator would set for the key based on the CR field, or false if the CR field is not set (in which case the operator/Argo CD default applies).
+	crValue func(argoCD v1beta1.ArgoCD) (string, bool)
+}
+
+// argoCDCMFieldMappings is the list of 'argocd-cm' keys that have a corresponding first-class ArgoCD CR field.
+var argoCDCMFieldMappings = []configMapFieldMapping{
+	{key: "admin.enabled", crField: ".spec.disableAdmin", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(!a.Spec.DisableAdmin), true
+	}},
+	{key: "application.instanceLabelKey", crField: ".spec.applicationInstanceLabelKey", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return a.ApplicationInstanceLabelKey(), true
+	}},
+	{key: "application.resourceTrackingMethod", crField: ".spec.resourceTrackingMethod", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.ResourceTrackingMethod)
+	}},
+	{key: "dex.config", crField: ".spec.sso.dex", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.SSO == nil || a.Spec.SSO.Dex == nil {
+			return "", false
+		}
+		if a.Spec.SSO.Dex.OpenShiftOAuth {
+			return "(generated by operator from '.spec.sso.dex.openShiftOAuth')", true
+		}
+		return nonEmptyString(a.Spec.SSO.Dex.Config)
+	}},
+	{key: "ga.anonymizeusers", crField: ".spec.gaAnonymizeUsers", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.GAAnonymizeUsers), a.Spec.GAAnonymizeUsers
+	}},
+	{key: "ga.trackingid", crField: ".spec.gaTrackingID", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.GATrackingID)
+	}},
+	{key: "help.chatText", crField: ".spec.helpChatText", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.HelpChatText)
+	}},
+	{key: "help.chatUrl", crField: ".spec.helpChatURL", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.HelpChatURL)
+	}},
+	{key: "installationID", crField: ".spec.installationID", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.InstallationID)
+	}},
+	{key: "kustomize.buildOptions", crField: ".spec.kustomizeBuildOptions", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.KustomizeBuildOptions)
+	}},
+	{key: "oidc.config", crField: ".spec.oidcConfig", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.OIDCConfig)
+	}},
+	{key: "resource.respectRBAC", crField: ".spec.controller.respectRBAC", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Controller.RespectRBAC)
+	}},
+	{key: "resource.exclusions", crField: ".spec.resourceExclusions", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.ResourceExclusions)
+	}},
+	{key: "resource.inclusions", crField: ".spec.resourceInclusions", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.ResourceInclusions)
+	}},
+	{key: "statusbadge.enabled", crField: ".spec.statusBadgeEnabled", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.StatusBadgeEnabled), true
+	}},
+	{key: "timeout.reconciliation", crField: ".spec.controller.appSync", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Controller.AppSync == nil {
+			return "", false
+		}
+		return a.Spec.Controller.AppSync.Duration.String(), true
+	}},
+	{key: "ui.bannercontent", crField: ".spec.banner.content", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Banner == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.Banner.Content)
+	}},
+	{key: "ui.bannerpermanent", crField: ".spec.banner.permanent", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Banner == nil || !a.Spec.Banner.Permanent {
+			return "", false
+		}
+		return "true", true
+	}},
+	{key: "ui.bannerposition", crField: ".spec.banner.position", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Banner == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.Banner.Position)
+	}},
+	{key: "ui.bannerurl", crField: ".spec.banner.url", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Banner == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.Banner.URL)
+	}},
+	{key: "users.anonymous.enabled", crField: ".spec.usersAnonymousEnabled", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.UsersAnonymousEnabled), true
+	}},
+}
+
+// argoCDCmdParamsCMFieldMappings is the list of 'argocd-cmd-params-cm' keys that have a corresponding first-class ArgoCD CR field.
+// - Note: the operator applies most of these as container arguments rather than as ConfigMap entries, but the effective setting is the same (and container arguments take precedence over 'argocd-cmd-params-cm' values).
+var argoCDCmdParamsCMFieldMappings = []configMapFieldMapping{
+	{key: "controller.status.processors", crField: ".spec.controller.processors.status", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.Processors.Status)
+	}},
+	{key: "controller.operation.processors", crField: ".spec.controller.processors.operation", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.Processors.Operation)
+	}},
+	{key: "controller.log.level", crField: ".spec.controller.logLevel", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Controller.LogLevel)
+	}},
+	{key: "controller.log.format", crField: ".spec.controller.logFormat", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Controller.LogFormat)
+	}},
+	{key: "controller.kubectl.parallelism.limit", crField: ".spec.controller.parallelismLimit", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.ParallelismLimit)
+	}},
+	{key: "server.insecure", crField: ".spec.server.insecure", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.Server.Insecure), a.Spec.Server.Insecure
+	}},
+	{key: "server.log.level", crField: ".spec.server.logLevel", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Server.LogLevel)
+	}},
+	{key: "server.log.format", crField: ".spec.server.logFormat", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Server.LogFormat)
+	}},
+	{key: "reposerver.log.level", crField: ".spec.repo.logLevel", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Repo.LogLevel)
+	}},
+	{key: "reposerver.log.format", crField: ".spec.repo.logFormat", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Repo.LogFormat)
+	}},
+	{key: "applicationsetcontroller.log.level", crField: ".spec.applicationSet.logLevel", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.ApplicationSet == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.ApplicationSet.LogLevel)
+	}},
+	{key: "applicationsetcontroller.log.format", crField: ".spec.applicationSet.logFormat", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.ApplicationSet == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.ApplicationSet.LogFormat)
+	}},
+	{key: "applicationsetcontroller.namespaces", crField: ".spec.applicationSet.sourceNamespaces", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.ApplicationSet == nil {
+			return "", false
+		}
+		return nonEmptyString(strings.Join(a.Spec.ApplicationSet.SourceNamespaces, ","))
+	}},
+	{key: "notificationscontroller.log.level", crField: ".spec.notifications.logLevel", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Notifications.LogLevel)
+	}},
+	{key: "notificationscontroller.log.format", crField: ".spec.notifications.logFormat", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Notifications.LogFormat)
+	}},
+}
+
+// argoCDCmdParamsCMKeys is a list of keys which I have verified are from 'argocd-cmd-params-cm'. A mistake users can make is specifying these in '.spec.extraConfig': extraConfig is only for adding values to 'argocd-cm', which is different, and has a different set of supported values.
+var argoCDCmdParamsCMKeys = []string{
+	"controller.operation.processors",
+	"controller.status.processors",
+	"controller.log.format",
+	"controller.log.level",
+	"controller.sharding.algorithm",
+	"controller.kubectl.parallelism.limit",
+	"controller.diff.server.side",
+	"controller.repo.server.timeout.seconds",
+
+	"server.insecure",
+	"server.log.format",
+	"server.log.level",
+	"server.repo.server.timeout.seconds",
+	"server.repo.server.strict.tls",
+
+	"reposerver.log.format",
+	"reposerver.log.level",
+	"reposerver.parallelism.limit",
+	"reposerver.disable.tls",
+	"reposerver.repo.cache.expiration",
+	"reposerver.default.cache.expiration",
+	"reposerver.git.request.timeout",
+
+	"dexserver.log.format",
+	"dexserver.log.level",
+	"dexserver.disable.tls",
+
+	"applicationsetcontroller.log.format",
+	"applicationsetcontroller.log.level",
+	"applicationsetcontroller.dryrun",
+	"applicationsetcontroller.namespaces",
+	"applicationsetcontroller.allowed.scm.providers",
+	"applicationsetcontroller.enable.scm.providers",
+	"applicationsetcontroller.requeue.after",
+	"applicationsetcontroller.status.max.resources.count",
+
+	"notificationscontroller.log.level",
+	"notificationscontroller.log.format",
+}
+
+// supportedCmdParamsKeys is the subset of 'argocd-cmd-params-cm' keys that are supported in '.spec.cmdParams'.
+var supportedCmdParamsKeys = []string{
+	"controller.resource.health.persist", "server.profile.enabled", "controller.profile.enabled",
+}
+
+// isArgoCDCmdParamsCMKey returns true if the key is known to be an 'argocd-cmd-params-cm' key (rather than an 'argocd-cm' key)
+func isArgoCDCmdParamsCMKey(key string) bool {
+	return slices.Contains(argoCDCmdParamsCMKeys, key) || slices.Contains(supportedCmdParamsKeys, key)
+}
+
+// checkForSettingsInBothConfigMaps identifies keys which are set in both '.spec.extraConfig' (which populates 'argocd-cm') and '.spec.cmdParams' (which populates 'argocd-cmd-params-cm'). A key belongs to only one of the two ConfigMaps, so one of the values has no effect: this usually means the user was unsure which map the setting belongs in, and set it in both (possibly with different values, in which case the value they expect may not be the one in effect).
+// - A known 'argocd-cmd-params-cm' key (see isArgoCDCmdParamsCMKey) is only read from '.spec.cmdParams', so the extraConfig value is reported. Any other key is assumed to be an 'argocd-cm' key, so the cmdParams value is reported.
+// - The keys being in the wrong map is also reported by the extraConfig/cmdParams checks of checkForIncorrectConfigurations: this check reports which of the two values is in effect, and whether they conflict.
+// - The keys of argoCDCmdParamsCMKeys are not reported, since checkForIncorrectConfigurations already reports these as an Error whenever they are in '.spec.extraConfig'.
+func checkForSettingsInBothConfigMaps(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	keys := []string{}
+	for key := range argoCD.Spec.ExtraConfig {
+		if _, exists := argoCD.Spec.CmdParams[key]; exists && !slices.Contains(argoCDCmdParamsCMKeys, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+
+		extraConfigValue := argoCD.Spec.ExtraConfig[key]
+		cmdParamsValue := argoCD.Spec.CmdParams[key]
+
+		extraConfigField := ".spec.extraConfig[" + key + "]"
+		cmdParamsField := ".spec.cmdParams[" + key + "]"
+
+		valuesDescription := fmt.Sprintf("'%s' is set in both '%s' (value '%s') and '%s' (value '%s')", key, extraConfigField, extraConfigValue, cmdParamsField, cmdParamsValue)
+		if extraConfigValue != cmdParamsValue {
+			valuesDescription += ", with conflicting values"
+		}
+
+		if isArgoCDCmdParamsCMKey(key) {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   extraConfigField,
+				source:  IssueSource_ExtraConfig,
+				message: fmt.Sprintf("%s. This is an 'argocd-cmd-params-cm' key, which is only read from '.spec.cmdParams', so the extraConfig value has no effect, and the value in effect is '%s'. Remove the key from '.spec.extraConfig'.", valuesDescription, cmdParamsValue),
+			})
+		} else {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   cmdParamsField,
+				source:  IssueSource_CmdParams,
+				message: fmt.Sprintf("%s. This is not a known 'argocd-cmd-params-cm' key, so it is likely an 'argocd-cm' key, which is only read from '.spec.extraConfig': the cmdParams value has no effect, and the value in effect is '%s'. Remove the key from '.spec.cmdParams'.", valuesDescription, extraConfigValue),
+			})
+		}
+	}
+}
+
+// configMapEntry is a single key/value of a computed ConfigMap, along with where the value came from.
+type configMapEntry struct {
+	key    string
+	value  string
+	source string // e.g. '.spec.extraConfig' or '.spec.disableAdmin'
+}
+
+// computeEffectiveArgoCDCM returns a best-effort computation of the 'argocd-cm' ConfigMap that the operator would generate from the ArgoCD CR, sorted by key.
+// - Values from first-class CR fields are applied first, then values from '.spec.extraConfig' are applied on top (extraConfig takes precedence in the operator).
+func computeEffectiveArgoCDCM(argoCD v1beta1.ArgoCD) []configMapEntry {
+
+	entries := map[string]configMapEntry{}
+
+	for _, mapping := range argoCDCMFieldMappings {
+		if value, set := mapping.crValue(argoCD); set {
+			entries[mapping.key] = configMapEntry{key: mapping.key, value: value, source: mapping.crField}
+		}
+	}
+
+	for key, value := range argoCD.Spec.ExtraConfig {
+		entries[key] = configMapEntry{key: key, value: value, source: ".spec.extraConfig"}
+	}
+
+	return sortedConfigMapEntries(entries)
+}
+
+// effectiveArgoCDCMEntry returns the entry of the computed 'argocd-cm' ConfigMap with the given key (see computeEffectiveArgoCDCM), or nil if the key is not set.
+func effectiveArgoCDCMEntry(argoCD v1beta1.ArgoCD, key string) *configMapEntry {
+
+	for _, entry := range computeEffectiveArgoCDCM(argoCD) {
+		if entry.key == key {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+// computeEffectiveArgoCDCmdParamsCM returns a best-effort computation of the effective 'argocd-cmd-params-cm' settings for the ArgoCD CR, sorted by key.
+// - Values from '.spec.cmdParams' are applied first, then values from first-class CR fields are applied on top (the operator applies CR fields as container arguments, which take precedence).
+func computeEffectiveArgoCDCmdParamsCM(argoCD v1beta1.ArgoCD) []configMapEntry {
+
+	entries := map[string]configMapEntry{}
+
+	for key, value := range argoCD.Spec.CmdParams {
+		entries[key] = configMapEntry{key: key, value: value, source: ".spec.cmdParams"}
+	}
+
+	for _, mapping := range argoCDCmdParamsCMFieldMappings {
+		if value, set := mapping.crValue(argoCD); set {
+			entries[mapping.key] = configMapEntry{key: mapping.key, value: value, source: mapping.crField}
+		}
+	}
+
+	return sortedConfigMapEntries(entries)
+}
+
+func sortedConfigMapEntries(entries map[string]configMapEntry) []configMapEntry {
+	res := make([]configMapEntry, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, entry)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].key < res[j].key
+	})
+
+	return res
+}
+
+// dumpEffectiveConfigMaps outputs the computed 'argocd-cm'/'argocd-cmd-params-cm' values for each ArgoCD CR (used by '--config-map-dump').
+func dumpEffectiveConfigMaps(ctx context.Context, k8sClient clients.AbstractK8sClient) {
+
+	argoCDList := listArgoCDs(ctx, k8sClient)
+
+	outputStatusMessage("NOTE: ConfigMap values are computed on a best-effort basis, only for the fields understood by this tool. Operator-generated defaults are not included.")
+	outputStatusMessage("")
+
+	for _, argoCD := range argoCDList.Items {
+
+		outputStatusMessage("------------------------------------------------------------------------------")
+		coloredNamespace := color.New(color.FgHiCyan).Sprint("Namespace")
+		coloredArgoCD := color.New(color.FgHiCyan).Sprint("ArgoCD")
+		outputStatusMessage(coloredNamespace + " '" + argoCD.Namespace + "' -> " + coloredArgoCD + " '" + argoCD.Name + "':")
+		outputStatusMessage("")
+
+		outputConfigMapEntries("argocd-cm", computeEffectiveArgoCDCM(argoCD))
+		outputConfigMapEntries("argocd-cmd-params-cm", computeEffectiveArgoCDCmdParamsCM(argoCD))
+	}
+}
+
+func outputConfigMapEntries(configMapName string, entries []configMapEntry) {
+
+	outputStatusMessage(color.New(color.FgHiWhite, color.Bold).Sprint(configMapName) + ":")
+
+	if len(entries) == 0 {
+		outputStatusMessage("  (no values)")
+	}
+
+	for _, entry := range entries {
+		source := color.New(color.FgHiBlack).Sprint("(from " + entry.source + ")")
+
+		if strings.Contains(strings.TrimSpace(entry.value), "\n") {
+			// Output multi-line values (e.g. dex.config) as an indented block
+			outputStatusMessage(fmt.Sprintf("  %s: %s", entry.key, source))
+			for line := range strings.SplitSeq(strings.TrimRight(entry.value, "\n"), "\n") {
+				outputStatusMessage("    " + line)
+			}
+		} else {
+			outputStatusMessage(fmt.Sprintf("  %s: %s %s", entry.key, entry.value, source))
+		}
+	}
+
+	outputStatusMessage("")
+}
+
+func nonEmptyString(value string) (string, bool) {
+	return value, value != ""
+}
+
+func nonZeroInt32(value int32) (string, bool) {
+	return strconv.Itoa(int(value)), value != 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+func TestCheckForSettingsInBothConfigMaps(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		key           string
+		expectedField string
+	}{
+		// Reported as an Error by checkForIncorrectConfigurations whenever it is in extraConfig, so it is not also reported here
+		{name: "argocd-cmd-params-cm key which is not supported in cmdParams", key: "controller.repo.server.timeout.seconds"},
+		{name: "argocd-cmd-params-cm key which is supported in cmdParams", key: "controller.resource.health.persist", expectedField: ".spec.extraConfig[controller.resource.health.persist]"},
+		{name: "argocd-cm key", key: "admin.enabled", expectedField: ".spec.cmdParams[admin.enabled]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			argoCD := v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{
+				ExtraConfig: map[string]string{test.key: "true"},
+				CmdParams:   map[string]string{test.key: "false"},
+			}}
+
+			issues := []issue{}
+			checkForSettingsInBothConfigMaps(argoCD, &issues)
+
+			if test.expectedField == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got: %+v", issues)
+				}
+				return
+			}
+
+			if len(issues) != 1 || issues[0].field != test.expectedField {
+				t.Errorf("expected a single issue of field '%s', got: %+v", test.expectedField, issues)
+			}
+		})
+	}
+}
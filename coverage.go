@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/jgwest/argocd-config-check/checks"
+)
+
+// checkCoverageStatus is whether a check was run against an ArgoCD CR (see checkCoverage)
+type checkCoverageStatus string
+
+const (
+	// checkCoverage_Ran is a check which was run against the ArgoCD CR (whether or not it reported any findings)
+	checkCoverage_Ran checkCoverageStatus = "ran"
+
+	// checkCoverage_NotApplicable is a check which was run, but which does not apply to the ArgoCD CR (see checkRegistration.applicable), so the absence of findings says nothing about the CR
+	checkCoverage_NotApplicable checkCoverageStatus = "not applicable"
+
+	// checkCoverage_Skipped is a check which was not run against the ArgoCD CR, for example a live cluster only check when analyzing a must-gather
+	checkCoverage_Skipped checkCoverageStatus = "skipped"
+)
+
+// checkCoverage is whether a single check was run against an ArgoCD CR, and the number of findings it reported
+type checkCoverage struct {
+	ruleID string
+	title  string // empty for additional checks registered via checks.Register
+
+	status checkCoverageStatus
+
+	// reason is why the check was skipped or is not applicable, or empty if the check ran
+	reason string
+
+	findingCount int
+}
+
+// computeCheckCoverage returns the coverage of each check against an ArgoCD CR (in the order the checks are run), given the issues that were reported for the CR: whether the check ran, was not applicable, or was skipped (and why). This is reported by '--show-coverage', so that a result with no issues can be trusted.
+// - Checks of the ArgoCD CR (see checks.RunChecks) are run unless an earlier check stopped further checks of the CR (see issue.stopFurtherChecks).
+// - Checks against the cluster (see checkIndividualArgoCDCRAgainstCluster) are additionally skipped when the control plane data is incomplete (e.g. must-gather or '--manifest'), or when they are opt-in checks whose flag was not specified.
+// - A check of the ArgoCD CR is skipped if it is superseded by a cluster check that was run (see checkRegistration.supersedes).
+// - A check which reported findings always 'ran', even if it would otherwise be not applicable.
+func computeCheckCoverage(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues []issue, incompleteControlPlaneData bool, enabledOptInFlags []string) []checkCoverage {
+
+	findingCounts := map[string]int{}
+	stoppedByRuleID := ""
+	for _, currIssue := range issues {
+		findingCounts[currIssue.ruleID]++
+		if currIssue.stopFurtherChecks && stoppedByRuleID == "" {
+			stoppedByRuleID = currIssue.ruleID
+		}
+	}
+	stoppedReason := fmt.Sprintf("a finding of %s stopped further checks of the ArgoCD CR", stoppedByRuleID)
+
+	// key: rule ID of a check of the ArgoCD CR, value: rule ID of the cluster check which superseded it (see checkRegistration.supersedes)
+	supersededBy := map[string]string{}
+	if stoppedByRuleID == "" {
+		for _, registration := range registeredChecks {
+			if registration.clusterCheck != nil && registration.supersedes != "" && clusterCheckIsRun(registration, incompleteControlPlaneData, enabledOptInFlags) {
+				supersededBy[registration.supersedes] = registration.ruleID
+			}
+		}
+	}
+
+	// coverageOf returns the coverage of a check which was not skipped
+	coverageOf := func(ruleID string) checkCoverage {
+		res := checkCoverage{ruleID: ruleID, status: checkCoverage_Ran, findingCount: findingCounts[ruleID]}
+
+		registration := findCheckRegistration(ruleID)
+		if registration == nil {
+			return res
+		}
+		res.title = registration.title
+
+		if registration.applicable != nil && res.findingCount == 0 {
+			if applicable, reason := registration.applicable(argoCD, clusterInfo); !applicable {
+				res.status = checkCoverage_NotApplicable
+				res.reason = reason
+			}
+		}
+
+		return res
+	}
+
+	res := []checkCoverage{}
+
+	stopped := false
+	for _, check := range checks.RegisteredChecks() {
+		if stopped {
+			coverage := checkCoverage{ruleID: check.ID(), status: checkCoverage_Skipped, reason: stoppedReason}
+			if registration := findCheckRegistration(check.ID()); registration != nil {
+				coverage.title = registration.title
+			}
+			res = append(res, coverage)
+			continue
+		}
+
+		if supersedingRuleID, exists := supersededBy[check.ID()]; exists {
+			coverage := checkCoverage{ruleID: check.ID(), status: checkCoverage_Skipped, reason: "superseded by " + supersedingRuleID + ", which checks the resources on the cluster"}
+			if registration := findCheckRegistration(check.ID()); registration != nil {
+				coverage.title = registration.title
+			}
+			res = append(res, coverage)
+			continue
+		}
+
+		res = append(res, coverageOf(check.ID()))
+		stopped = stoppedByRuleID != "" && check.ID() == stoppedByRuleID
+	}
+
+	for _, registration := range registeredChecks {
+		if registration.clusterCheck == nil {
+			continue
+		}
+
+		reason := ""
+		switch {
+		case stoppedByRuleID != "":
+			reason = stoppedReason
+		case clusterCheckIsRun(registration, incompleteControlPlaneData, enabledOptInFlags):
+		case registration.optInFlag != "":
+			reason = "opt-in check, which is enabled by '" + registration.optInFlag + "'"
+		default:
+			reason = "live cluster only, and the cluster data is incomplete (e.g. must-gather or '--manifest')"
+		}
+
+		if reason != "" {
+			res = append(res, checkCoverage{ruleID: registration.ruleID, title: registration.title, status: checkCoverage_Skipped, reason: reason})
+			continue
+		}
+
+		res = append(res, coverageOf(registration.ruleID))
+	}
+
+	return res
+}
+
+// outputCheckCoverage outputs the coverage of each check against an ArgoCD CR (see computeCheckCoverage). This is informational, and does not affect the reported issues.
+func outputCheckCoverage(coverage []checkCoverage) {
+
+	counts := map[checkCoverageStatus]int{}
+	for _, currCoverage := range coverage {
+		counts[currCoverage.status]++
+	}
+
+	outputStatusMessage(fmt.Sprintf("Check coverage (%d ran, %d not applicable, %d skipped):", counts[checkCoverage_Ran], counts[checkCoverage_NotApplicable], counts[checkCoverage_Skipped]))
+
+	for _, currCoverage := range coverage {
+
+		name := currCoverage.ruleID
+		if currCoverage.title != "" {
+			name += " (" + currCoverage.title + ")"
+		}
+
+		description := string(currCoverage.status)
+		switch {
+		case currCoverage.reason != "":
+			description += ": " + currCoverage.reason
+		case currCoverage.findingCount == 0:
+			description += ", no findings"
+		default:
+			description += fmt.Sprintf(", %d finding(s)", currCoverage.findingCount)
+		}
+
+		outputStatusMessage("- " + name + ": " + description)
+	}
+}
+
+// applicableWhenDexConfigured is the applicability (see checkRegistration.applicable) of the checks of the Dex configuration
+func applicableWhenDexConfigured(argoCD v1beta1.ArgoCD, _ clusterInformation) (bool, string) {
+	if (argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil) || strings.TrimSpace(argoCD.Spec.ExtraConfig["dex.config"]) != "" {
+		return true, ""
+	}
+	return false, "Dex is not configured ('.spec.sso.dex', or '.spec.extraConfig[dex.config]')"
+}
+
+// applicableWhenSizingProfileSelected is the applicability (see checkRegistration.applicable) of the checks against a workload size profile
+func applicableWhenSizingProfileSelected(_ v1beta1.ArgoCD, clusterInfo clusterInformation) (bool, string) {
+	if clusterInfo.SizingProfile != "" {
+		return true, ""
+	}
+	return false, "no sizing profile was selected via '--profile'"
+}
+
+// applicableWhenServerAutoscaleEnabled is the applicability (see checkRegistration.applicable) of the checks of server autoscaling
+func applicableWhenServerAutoscaleEnabled(argoCD v1beta1.ArgoCD, _ clusterInformation) (bool, string) {
+	if argoCD.Spec.Server.IsEnabled() && argoCD.Spec.Server.Autoscale.Enabled {
+		return true, ""
+	}
+	return false, "server autoscaling is not enabled ('.spec.server.autoscale.enabled')"
+}
+
+// applicableWhenShardingEnabled is the applicability (see checkRegistration.applicable) of the checks of application controller sharding
+func applicableWhenShardingEnabled(argoCD v1beta1.ArgoCD, _ clusterInformation) (bool, string) {
+	if argoCD.Spec.Controller.IsEnabled() && argoCD.Spec.Controller.Sharding.Enabled {
+		return true, ""
+	}
+	return false, "application controller sharding is not enabled ('.spec.controller.sharding.enabled')"
+}
+
+// applicableWhenApplicationSetEnabled is the applicability (see checkRegistration.applicable) of the checks of the ApplicationSet controller
+func applicableWhenApplicationSetEnabled(argoCD v1beta1.ArgoCD, _ clusterInformation) (bool, string) {
+	if argoCD.Spec.ApplicationSet != nil && argoCD.Spec.ApplicationSet.IsEnabled() {
+		return true, ""
+	}
+	return false, "the ApplicationSet controller is not enabled ('.spec.applicationSet')"
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"slices"
+	"strconv"
+)
+
+// csvHeader is the header row of the CSV output. Each subsequent row is a single finding.
+var csvHeader = []string{"cluster", "namespace", "name", "severity", "ruleID", "field", "unsupported", "message"}
+
+// outputResultsAsCSV writes each finding of the check results (of one or more clusters) as a row of a single CSV document, for triage in a spreadsheet.
+// - Fields are quoted/escaped as per RFC 4180 (e.g. messages containing commas, quotes, or newlines).
+// - Operator installation findings are included, with an empty namespace and name.
+// - Rows are sorted by cluster, then namespace (and name, for multiple instances in a namespace), then field, so that the output is the same between runs (the original order of the findings is kept otherwise).
+func outputResultsAsCSV(allResults []checkResults) {
+
+	rows := [][]string{}
+
+	for _, results := range allResults {
+
+		for _, entry := range results.installEntries {
+			rows = append(rows, []string{results.cluster, "", "", string(entry.level), "", "", "false", entry.message})
+		}
+
+		for _, instance := range results.instances {
+			for _, issue := range instance.issues {
+				rows = append(rows, []string{results.cluster, instance.namespace, instance.name, string(issue.level), issue.ruleID, issue.field, strconv.FormatBool(issue.unsupported), issue.message})
+			}
+		}
+	}
+
+	// Column indices of csvHeader which rows are sorted by
+	const clusterColumn, namespaceColumn, nameColumn, fieldColumn = 0, 1, 2, 5
+
+	slices.SortStableFunc(rows, func(a, b []string) int {
+		return cmp.Or(
+			cmp.Compare(a[clusterColumn], b[clusterColumn]),
+			cmp.Compare(a[namespaceColumn], b[namespaceColumn]),
+			cmp.Compare(a[nameColumn], b[nameColumn]),
+			cmp.Compare(a[fieldColumn], b[fieldColumn]),
+		)
+	})
+
+	writer := csv.NewWriter(reportOutput)
+
+	if err := writer.Write(csvHeader); err != nil {
+		failWithError("unable to write CSV output", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		failWithError("unable to write CSV output", err)
+	}
+}
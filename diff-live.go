@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/fatih/color"
+	"github.com/jgwest/argocd-config-check/clients"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxDiffValueLength is the maximum number of characters of a (JSON) value to output in a CR field difference. Longer values are truncated.
+const maxDiffValueLength = 120
+
+// findingKey identifies a finding when comparing the findings of the live and proposed ArgoCD CRs. The message is not included, since it often includes the value of the field (e.g. a timeout): a finding whose value changed, but which is still reported, is not a new finding.
+type findingKey struct {
+	ruleID string
+	level  LogLevel
+	field  string
+}
+
+// findingKeyOf returns the findingKey of an issue
+func findingKeyOf(issue issue) findingKey {
+	return findingKey{ruleID: issue.ruleID, level: issue.level, field: issue.field}
+}
+
+// runLiveDiffChecks checks each proposed ArgoCD CR (from the '--manifest' client) against the ArgoCD CR of the same namespace/name which is currently applied to the live cluster, and reports the differences between the CRs, the findings which the proposed CR would introduce, and the findings it would resolve. This answers 'does this change make the configuration worse?', for example as a pre-merge gate of a GitOps pull request.
+// - Both CRs are checked with the operator installation data of the live cluster. Only the checks of the ArgoCD CR itself are compared: checks against other cluster resources would read the live state, rather than the state after the change.
+// - The '.status' of the live CR is copied to the proposed CR, since the status is observed from the cluster, rather than being part of the change. A proposed CR which does not exist on the cluster is reported as a new instance, so all of its findings are new.
+// - The returned results contain only the new findings of each instance (and the score of the proposed CR), so that '--fail-on', '--fail-on-unsupported', and machine-readable output apply to what the change introduces.
+// - A live ArgoCD CR which cannot be retrieved (other than because it does not exist, e.g. forbidden) is reported as a Fatal finding of the instance, and the other instances are still compared.
+func runLiveDiffChecks(ctx context.Context, manifestClient clients.AbstractK8sClient, liveClient clients.AbstractK8sClient, opts runOptions) checkResults {
+
+	clusterInfo, entries := acquireInstallConfigurationDataWithRunOptions(ctx, liveClient, opts)
+
+	results := checkResults{
+		clusterInfo:    clusterInfo,
+		installEntries: entries,
+		instances:      []instanceResult{},
+	}
+
+	outputEntryList(entries)
+
+	if entryListContainsFatal(entries) {
+		return results
+	}
+
+	outputStatusMessage("Comparing the proposed ArgoCD CR(s) against the ArgoCD CR(s) applied to the cluster. Only checks of the ArgoCD CR itself are compared.")
+	outputStatusMessage("")
+
+	for _, proposed := range listArgoCDs(ctx, manifestClient).Items {
+
+		outputStatusMessage("------------------------------------------------------------------------------")
+		coloredNamespace := color.New(color.FgHiCyan).Sprint("Namespace")
+		coloredArgoCD := color.New(color.FgHiCyan).Sprint("ArgoCD")
+		outputStatusMessage(coloredNamespace + " '" + proposed.Namespace + "' -> " + coloredArgoCD + " '" + proposed.Name + "' (proposed change):")
+		outputStatusMessage("")
+
+		var live v1beta1.ArgoCD
+		liveExists := true
+		if err := liveClient.Get(ctx, client.ObjectKeyFromObject(&proposed), &live); err != nil {
+
+			// As with other per-instance retrieval failures, the instance is reported as Fatal, and the other instances are still compared
+			if !apierrors.IsNotFound(err) {
+				retrievalIssues := []issue{{
+					level:   LogLevel_Fatal,
+					field:   "(ArgoCD '" + proposed.Name + "' in namespace '" + proposed.Namespace + "' on the cluster)",
+					message: "Unable to retrieve the ArgoCD CR from the cluster, so the proposed ArgoCD CR could not be compared against it: " + err.Error() + forbiddenErrorHint(err),
+				}}
+				outputIssues(retrievalIssues, proposed.Namespace+"/"+proposed.Name, opts.outputFormat)
+
+				results.instances = append(results.instances, instanceResult{
+					namespace:      proposed.Namespace,
+					name:           proposed.Name,
+					issues:         retrievalIssues,
+					score:          scoreIssues(retrievalIssues),
+					manifestSource: opts.manifestSourceOf(&proposed),
+				})
+				continue
+			}
+
+			liveExists = false
+		}
+
+		liveIssues := []issue{}
+		if liveExists {
+			proposed.Status = live.Status
+			liveIssues = dedupeIssues(suppressIssuesByAnnotation(live, checkIndividualArgoCDCR(live, clusterInfo)))
+		}
+
+		proposedIssues := dedupeIssues(suppressIssuesByAnnotation(proposed, checkIndividualArgoCDCR(proposed, clusterInfo)))
+		proposedScore := scoreIssues(proposedIssues)
+
+		addedIssues, removedIssues := diffFindings(liveIssues, proposedIssues)
+
+		if opts.onlyUnsupported {
+			addedIssues = filterUnsupportedIssues(addedIssues)
+			removedIssues = filterUnsupportedIssues(removedIssues)
+		}
+
+		sortIssuesByField(addedIssues)
+		sortIssuesByField(removedIssues)
+
+		if !liveExists {
+			outputStatusMessage("This ArgoCD CR does not exist on the cluster: it is a new instance, so all of its findings are new.")
+		} else if differences := diffArgoCDCRs(live, proposed); len(differences) == 0 {
+			outputStatusMessage("The proposed ArgoCD CR does not change the '.spec' or annotations of the ArgoCD CR on the cluster.")
+		} else {
+			outputStatusMessage(fmt.Sprintf("Changes to the ArgoCD CR (%d):", len(differences)))
+			for _, difference := range differences {
+				outputStatusMessage("  " + difference)
+			}
+		}
+		outputStatusMessage("")
+
+		outputStatusMessage(fmt.Sprintf("Findings introduced by the change (%d):", len(addedIssues)))
+		outputIssues(addedIssues, proposed.Namespace+"/"+proposed.Name, opts.outputFormat)
+
+		outputStatusMessage(fmt.Sprintf("Findings resolved by the change (%d):", len(removedIssues)))
+		outputIssues(removedIssues, proposed.Namespace+"/"+proposed.Name, opts.outputFormat)
+
+		if liveExists {
+			outputStatusMessage(fmt.Sprintf("Score: %s (on the cluster) -> %s (proposed)", scoreIssues(liveIssues).string(), proposedScore.string()))
+		} else {
+			outputStatusMessage("Score: " + proposedScore.string() + " (proposed)")
+		}
+
+		results.instances = append(results.instances, instanceResult{
+			namespace:      proposed.Namespace,
+			name:           proposed.Name,
+			issues:         addedIssues,
+			score:          proposedScore,
+			manifestSource: opts.manifestSourceOf(&proposed),
+		})
+	}
+
+	return results
+}
+
+// diffFindings returns the findings of 'proposed' which are not findings of 'live' (added), and the findings of 'live' which are not findings of 'proposed' (removed), compared by findingKey
+func diffFindings(live []issue, proposed []issue) (added []issue, removed []issue) {
+
+	keysOf := func(issues []issue) map[findingKey]bool {
+		res := map[findingKey]bool{}
+		for _, issue := range issues {
+			res[findingKeyOf(issue)] = true
+		}
+		return res
+	}
+
+	liveKeys := keysOf(live)
+	proposedKeys := keysOf(proposed)
+
+	added = []issue{}
+	for _, issue := range proposed {
+		if !liveKeys[findingKeyOf(issue)] {
+			added = append(added, issue)
+		}
+	}
+
+	removed = []issue{}
+	for _, issue := range live {
+		if !proposedKeys[findingKeyOf(issue)] {
+			removed = append(removed, issue)
+		}
+	}
+
+	return added, removed
+}
+
+// diffArgoCDCRs returns the differences between the '.spec' and '.metadata.annotations' of the live and proposed ArgoCD CRs, one per changed field, in the form '+ (field): (value)' (added), '- (field): (value)' (removed), or '~ (field): (live value) -> (proposed value)' (changed), sorted by field. Lists are compared as a whole.
+func diffArgoCDCRs(live v1beta1.ArgoCD, proposed v1beta1.ArgoCD) []string {
+
+	toUnstructured := func(obj any) map[string]any {
+		res, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			failWithError("unable to convert the ArgoCD CR for comparison", err)
+		}
+		return res
+	}
+
+	res := []string{}
+
+	diffUnstructuredValues(".spec", toUnstructured(&live.Spec), true, toUnstructured(&proposed.Spec), true, &res)
+
+	liveAnnotations, proposedAnnotations := map[string]any{}, map[string]any{}
+	for key, value := range live.Annotations {
+		liveAnnotations[key] = value
+	}
+	for key, value := range proposed.Annotations {
+		proposedAnnotations[key] = value
+	}
+	// This annotation (set by 'kubectl apply') contains the previously applied CR, so it always differs from a manifest
+	delete(liveAnnotations, "kubectl.kubernetes.io/last-applied-configuration")
+	delete(proposedAnnotations, "kubectl.kubernetes.io/last-applied-configuration")
+
+	diffUnstructuredValues(".metadata.annotations", liveAnnotations, true, proposedAnnotations, true, &res)
+
+	return res
+}
+
+// diffUnstructuredValues appends the differences between the live and proposed (unstructured) values at 'path' to 'res', recursing into maps (see diffArgoCDCRs)
+func diffUnstructuredValues(path string, live any, liveExists bool, proposed any, proposedExists bool, res *[]string) {
+
+	liveMap, liveIsMap := live.(map[string]any)
+	proposedMap, proposedIsMap := proposed.(map[string]any)
+
+	if liveIsMap && proposedIsMap {
+		keys := []string{}
+		for key := range liveMap {
+			keys = append(keys, key)
+		}
+		for key := range proposedMap {
+			if _, exists := liveMap[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			childPath := path + "." + key
+			if strings.ContainsAny(key, "./") {
+				childPath = path + "[" + key + "]"
+			}
+			liveValue, liveValueExists := liveMap[key]
+			proposedValue, proposedValueExists := proposedMap[key]
+			diffUnstructuredValues(childPath, liveValue, liveValueExists, proposedValue, proposedValueExists, res)
+		}
+		return
+	}
+
+	switch {
+	case liveExists && proposedExists && reflect.DeepEqual(live, proposed):
+		return
+	case !liveExists:
+		*res = append(*res, fmt.Sprintf("+ %s: %s", path, diffValueString(proposed)))
+	case !proposedExists:
+		*res = append(*res, fmt.Sprintf("- %s: %s", path, diffValueString(live)))
+	default:
+		*res = append(*res, fmt.Sprintf("~ %s: %s -> %s", path, diffValueString(live), diffValueString(proposed)))
+	}
+}
+
+// diffValueString returns the (compact JSON) representation of an unstructured value in a CR field difference, truncated to maxDiffValueLength
+func diffValueString(value any) string {
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	// Truncated by rune (rather than by byte), so that a multi-byte character is not split
+	res := []rune(string(jsonBytes))
+	if len(res) > maxDiffValueLength {
+		return string(res[:maxDiffValueLength]) + "..."
+	}
+	return string(res)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/jgwest/argocd-config-check/clients"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDiffValueString(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "short value", value: map[string]any{"enabled": true}, expected: `{"enabled":true}`},
+		{name: "ASCII value at the maximum length", value: strings.Repeat("a", maxDiffValueLength-2), expected: `"` + strings.Repeat("a", maxDiffValueLength-2) + `"`},
+		{name: "long ASCII value", value: strings.Repeat("a", 200), expected: `"` + strings.Repeat("a", maxDiffValueLength-1) + "..."},
+		// Each 'é' is 2 bytes, so truncating by byte would split a character
+		{name: "long multi-byte value", value: strings.Repeat("é", 200), expected: `"` + strings.Repeat("é", maxDiffValueLength-1) + "..."},
+		{name: "multi-byte value which is short in runes, but long in bytes", value: strings.Repeat("é", 100), expected: `"` + strings.Repeat("é", 100) + `"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			actual := diffValueString(test.value)
+
+			if !utf8.ValidString(actual) {
+				t.Fatalf("expected valid UTF-8, got %q", actual)
+			}
+
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+// fakeLiveDiffK8sClient is the live cluster client of a '--diff-against-live' run: it does not contain the operator installation, and Get returns the configured error for an ArgoCD CR (or NotFound, if none is configured)
+type fakeLiveDiffK8sClient struct {
+	// getErrByNamespace is the error returned by Get of an ArgoCD CR in the given namespace
+	getErrByNamespace map[string]error
+}
+
+func (f *fakeLiveDiffK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	return fmt.Errorf("no %T on the fake cluster", list)
+}
+
+func (f *fakeLiveDiffK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return fmt.Errorf("no %T on the fake cluster", list)
+}
+
+func (f *fakeLiveDiffK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*v1beta1.ArgoCD); !ok {
+		return fmt.Errorf("unexpected get: %T %v", obj, key)
+	}
+	if err := f.getErrByNamespace[key.Namespace]; err != nil {
+		return err
+	}
+	return apierrors.NewNotFound(schema.GroupResource{Group: "argoproj.io", Resource: "argocds"}, key.Name)
+}
+
+func (f *fakeLiveDiffK8sClient) IncompleteControlPlaneData() bool {
+	return false
+}
+
+func TestRunLiveDiffChecksContinuesAfterRetrievalFailure(t *testing.T) {
+
+	_ = captureReportOutput(t)
+	previousStatusOutput := statusOutput
+	statusOutput = io.Discard
+	t.Cleanup(func() {
+		statusOutput = previousStatusOutput
+	})
+
+	manifestPath := filepath.Join(t.TempDir(), "argocds.yaml")
+	manifest := ""
+	for _, namespace := range []string{"team-a", "team-b"} {
+		manifest += fmt.Sprintf("---\napiVersion: argoproj.io/v1beta1\nkind: ArgoCD\nmetadata:\n  name: argocd\n  namespace: %s\n", namespace)
+	}
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestClient, _, err := clients.ManifestK8sClient(manifestPath, clients.ManifestInputFormat_Auto)
+	if err != nil {
+		t.Fatalf("unable to read the manifest: %v", err)
+	}
+
+	liveClient := &fakeLiveDiffK8sClient{getErrByNamespace: map[string]error{
+		"team-a": apierrors.NewForbidden(schema.GroupResource{Group: "argoproj.io", Resource: "argocds"}, "argocd", errors.New(`User "developer" cannot get resource "argocds" in namespace "team-a"`)),
+	}}
+
+	results := runLiveDiffChecks(context.Background(), manifestClient, liveClient, runOptions{outputFormat: outputFormat_Text})
+
+	if len(results.instances) != 2 {
+		t.Fatalf("expected results for both instances, got %+v", results.instances)
+	}
+
+	forbidden, notFound := results.instances[0], results.instances[1]
+	if forbidden.namespace != "team-a" || notFound.namespace != "team-b" {
+		t.Fatalf("unexpected instances: %+v", results.instances)
+	}
+
+	if len(forbidden.issues) != 1 || forbidden.issues[0].level != LogLevel_Fatal || !strings.Contains(forbidden.issues[0].message, "forbidden") {
+		t.Errorf("expected a single Fatal issue for the instance which could not be retrieved, got %+v", forbidden.issues)
+	}
+
+	// The instance which does not exist on the cluster is still compared, as a new instance
+	for _, currIssue := range notFound.issues {
+		if currIssue.level == LogLevel_Fatal {
+			t.Errorf("unexpected Fatal issue for the new instance: %+v", currIssue)
+		}
+	}
+}
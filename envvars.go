@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// This file contains the env var knowledge base: the env vars that are read by each Argo CD component, and whether each may be set via the component's '.env' field in the ArgoCD CR. It drives the env var portion of checkForEnvVarsOrParamsWhichOverlapWithCRFields.
+//
+// Source: the env vars read by the Argo CD v3.2 components, as found in the Argo CD source ('cmd/<component>' flag defaults, and the env var constants of 'common/common.go' and 'util/env'), which are also documented in the 'argocd-cmd-params-cm.yaml' reference of the Argo CD documentation. Whether a CR field is preferred (or required) is based on the command line arguments and env vars that the argocd-operator (v0.17) itself sets on each component.
+// - To add an env var, add an entry to the table of its component (or to commonEnvVars if it is read by all components): no other changes are required.
+
+// envVarSupport describes whether an Argo CD env var may be set on a component via the ArgoCD CR
+type envVarSupport string
+
+const (
+	// envVarSupport_Supported env vars may be set via the component's '.env': no issue is reported
+	envVarSupport_Supported envVarSupport = "supported"
+
+	// envVarSupport_PreferCRField env vars may be set via the component's '.env', but there is a dedicated ArgoCD CR field for the setting, which is preferable (Warn)
+	envVarSupport_PreferCRField envVarSupport = "preferCRField"
+
+	// envVarSupport_Unsupported env vars should not be set via the component's '.env': the operator sets the setting itself (from the CR field), so the env var is ignored or conflicts with it (Error)
+	envVarSupport_Unsupported envVarSupport = "unsupported"
+)
+
+// envVarDefinition is an env var that is read by an Argo CD component
+type envVarDefinition struct {
+	name    string
+	support envVarSupport
+
+	// crField is the ArgoCD CR field that should be used instead of the env var. Empty for supported env vars.
+	crField string
+}
+
+// supported, preferCRField, and unsupported are shorthand constructors for the entries of the env var tables
+func supported(names ...string) []envVarDefinition {
+	res := []envVarDefinition{}
+	for _, name := range names {
+		res = append(res, envVarDefinition{name: name, support: envVarSupport_Supported})
+	}
+	return res
+}
+
+func preferCRField(name string, crField string) envVarDefinition {
+	return envVarDefinition{name: name, support: envVarSupport_PreferCRField, crField: crField}
+}
+
+func unsupported(name string, crField string) envVarDefinition {
+	return envVarDefinition{name: name, support: envVarSupport_Unsupported, crField: crField}
+}
+
+// componentEnvVars are the env vars of a single Argo CD component
+type componentEnvVars struct {
+	name  string // e.g. 'application controller'
+	field string // e.g. '.spec.controller'
+
+	// env returns whether the component is enabled, and the env vars specified for it in the ArgoCD CR
+	env func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar)
+
+	envVars []envVarDefinition
+}
+
+// commonEnvVars are env vars which are read by all Argo CD components (for example, by shared logging, gRPC, TLS, Git, and Kubernetes client code), plus env vars which are commonly set for tools/libraries run within the components.
+var commonEnvVars = slices.Concat(
+	supported("ARGOCD_LOG_FORMAT", "ARGOCD_LOG_LEVEL", "ARGOCD_LOG_FORMAT_ENABLE_FULL_TIMESTAMP", "ARGOCD_LOG_FORMAT_TIMESTAMP", "FORCE_LOG_COLORS",
+		"ARGOCD_GRPC_KEEP_ALIVE_MIN", "ARGOCD_GRPC_MAX_SIZE_MB", "ARGOCD_ENABLE_GRPC_TIME_HISTOGRAM",
+		"ARGOCD_TLS_CIPHERS", "ARGOCD_TLS_MAX_VERSION", "ARGOCD_TLS_MIN_VERSION", "ARGOCD_TLS_DATA_PATH", "ARGOCD_SSH_DATA_PATH", "ARGOCD_GPG_DATA_PATH", "ARGOCD_GNUPGHOME", "ARGOCD_GPG_ENABLED",
+		"ARGOCD_GIT_ATTEMPTS_COUNT", "ARGOCD_GIT_RETRY_DURATION", "ARGOCD_GIT_RETRY_FACTOR", "ARGOCD_GIT_RETRY_MAX_DURATION", "ARGOCD_GIT_REQUEST_TIMEOUT", "ARGOCD_GIT_MODULES_ENABLED", "ARGOCD_GIT_LS_REMOTE_PARALLELISM_LIMIT", "ARGOCD_GITHUB_APP_CREDS_EXPIRATION_DURATION",
+		"ARGOCD_K8S_RETRY_COUNT", "ARGOCD_K8S_RETRY_DURATION_MILLISECONDS", "ARGOCD_WATCH_API_BUFFER_SIZE", "ARGOCD_ENABLE_K8S_EVENT", "ARGOCD_TRACING_ENABLED", "ARGOCD_ENABLE_PROFILER_FILE_PATH",
+		"ARGOCD_DEFAULT_CACHE_EXPIRATION", "ARGOCD_REPO_CACHE_EXPIRATION", "ARGOCD_APP_STATE_CACHE_EXPIRATION", "ARGOCD_REVISION_CACHE_LOCK_TIMEOUT", "ARGOCD_HELM_INDEX_CACHE_DURATION",
+		"ARGOCD_HYDRATOR_ENABLED", "ARGOCD_RBAC_DEBUG", "ARGOCD_SSO_DEBUG", "ARGOCD_MAX_COOKIE_NUMBER",
+		"REDIS_PASSWORD", "REDIS_USERNAME", "REDIS_COMPRESSION", "REDIS_SENTINEL_PASSWORD", "REDIS_SENTINEL_USERNAME",
+		"WORKQUEUE_BACKOFF_FACTOR", "WORKQUEUE_BASE_DELAY_NS", "WORKQUEUE_BUCKET_QPS", "WORKQUEUE_BUCKET_SIZE", "WORKQUEUE_FAILURE_COOLDOWN_NS", "WORKQUEUE_MAX_DELAY_NS",
+		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy", "TZ", "SSL_CERT_DIR", "SSL_CERT_FILE", "PATH", "KUBECONFIG", "KUBECTL_EXTERNAL_DIFF",
+		"GOMAXPROCS", "GOMEMLIMIT", "GOGC", "GODEBUG"),
+	supported(cachePathEnvVars...),
+)
+
+// genericEnvVarPrefixes are prefixes of env vars which are commonly set for tools/libraries run within the components (for example, config management tools run by the repo server, or cloud provider credentials), and which are therefore never reported as unrecognized.
+var genericEnvVarPrefixes = []string{"HELM_", "KUSTOMIZE_", "GIT_", "SSH_", "GNUPG", "AWS_", "AZURE_", "GOOGLE_", "OTEL_", "GITHUB_", "GITLAB_", "GITEA_", "JAEGER_", "ARGOCD_ENV_"}
+
+// componentEnvVarTables are the env vars of each Argo CD component (in addition to commonEnvVars)
+var componentEnvVarTables = []componentEnvVars{
+	{
+		name: "application controller", field: ".spec.controller",
+		env: func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar) {
+			return argoCD.Spec.Controller.IsEnabled(), argoCD.Spec.Controller.Env
+		},
+		envVars: slices.Concat(
+			[]envVarDefinition{
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS", ".spec.controller.processors.status"),
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS", ".spec.controller.processors.operation"),
+				unsupported("ARGOCD_CONTROLLER_REPLICAS", ".spec.controller.sharding.replicas"),
+				unsupported("ARGOCD_RECONCILIATION_TIMEOUT", ".spec.controller.appSync"),
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_LOGLEVEL", ".spec.controller.logLevel"),
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_LOGFORMAT", ".spec.controller.logFormat"),
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_KUBECTL_PARALLELISM_LIMIT", ".spec.controller.parallelismLimit"),
+				unsupported("ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER", ".spec.repo.remote"),
+				unsupported("ARGOCD_APPLICATION_NAMESPACES", ".spec.sourceNamespaces"),
+			},
+			supported("ARGOCD_HARD_RECONCILIATION_TIMEOUT", "ARGOCD_RECONCILIATION_JITTER", "ARGOCD_REPO_ERROR_GRACE_PERIOD_SECONDS", "ARGOCD_IGNORE_NORMALIZER_JQ_TIMEOUT",
+				"ARGOCD_APPLICATION_CONTROLLER_COMMIT_SERVER", "ARGOCD_APPLICATION_CONTROLLER_METRICS_CACHE_EXPIRATION", "ARGOCD_APPLICATION_CONTROLLER_PERSIST_RESOURCE_HEALTH",
+				"ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_PLAINTEXT", "ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS", "ARGOCD_APPLICATION_CONTROLLER_SYNC_TIMEOUT",
+				"ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_TIMEOUT_SECONDS", "ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_CAP_SECONDS", "ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_COOLDOWN_SECONDS", "ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_FACTOR", "ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_BACKOFF_TIMEOUT_SECONDS",
+				"ARGOCD_APPLICATION_CONTROLLER_OTLP_ADDRESS", "ARGOCD_APPLICATION_CONTROLLER_OTLP_ATTRS", "ARGOCD_APPLICATION_CONTROLLER_OTLP_HEADERS", "ARGOCD_APPLICATION_CONTROLLER_OTLP_INSECURE",
+				"ARGOCD_APPLICATION_CONTROLLER_SERVER_SIDE_DIFF", "ARGOCD_APPLICATION_CONTROLLER_NAME", "ARGOCD_CONTROLLER_SHARD", "ARGOCD_CONTROLLER_SHARDING_ALGORITHM", "ARGOCD_CONTROLLER_HEARTBEAT_TIME",
+				"ARGOCD_APPLICATION_TREE_SHARD_SIZE", "ARGOCD_SYNC_WITH_REPLACE_ALLOWED", "ARGOCD_EXEC_TIMEOUT", "ARGOCD_EXEC_FATAL_TIMEOUT"),
+		),
+	},
+	{
+		name: "server", field: ".spec.server",
+		env: func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar) {
+			return argoCD.Spec.Server.IsEnabled(), argoCD.Spec.Server.Env
+		},
+		envVars: slices.Concat(
+			[]envVarDefinition{
+				unsupported("ARGOCD_API_SERVER_REPLICAS", ".spec.server.replicas"),
+				unsupported("ARGOCD_SERVER_LOG_LEVEL", ".spec.server.logLevel"),
+				unsupported("ARGOCD_SERVER_LOGFORMAT", ".spec.server.logFormat"),
+				unsupported("ARGOCD_SERVER_REPO_SERVER", ".spec.repo.remote"),
+				unsupported("ARGOCD_APPLICATION_NAMESPACES", ".spec.sourceNamespaces"),
+			},
+			supported("ARGOCD_SERVER_ROOTPATH", "ARGOCD_SERVER_BASEHREF", "ARGOCD_SERVER_CONTENT_SECURITY_POLICY", "ARGOCD_SERVER_X_FRAME_OPTIONS", "ARGOCD_SERVER_STATIC_ASSETS",
+				"ARGOCD_SERVER_DEX_SERVER", "ARGOCD_SERVER_DEX_SERVER_PLAINTEXT", "ARGOCD_SERVER_DEX_SERVER_STRICT_TLS", "ARGOCD_SERVER_DISABLE_AUTH", "ARGOCD_SERVER_ENABLE_GZIP", "ARGOCD_SERVER_ENABLE_PROXY_EXTENSION",
+				"ARGOCD_SERVER_LISTEN_ADDRESS", "ARGOCD_SERVER_METRICS_LISTEN_ADDRESS", "ARGOCD_SERVER_REPO_SERVER_PLAINTEXT", "ARGOCD_SERVER_REPO_SERVER_TIMEOUT_SECONDS", "ARGOCD_SERVER_WEBHOOK_PARALLELISM_LIMIT",
+				"ARGOCD_SERVER_OTLP_ADDRESS", "ARGOCD_SERVER_OTLP_ATTRS", "ARGOCD_SERVER_OTLP_HEADERS", "ARGOCD_SERVER_OTLP_INSECURE",
+				"ARGOCD_SERVER_LOGIN_ATTEMPTS_EXPIRATION", "ARGOCD_SERVER_OIDC_CACHE_EXPIRATION", "ARGOCD_SERVER_CONNECTION_STATUS_CACHE_EXPIRATION", "ARGOCD_SERVER_NAME",
+				"ARGOCD_API_CONTENT_TYPES", "ARGOCD_SYNC_WITH_REPLACE_ALLOWED", "ARGOCD_MAX_CONCURRENT_LOGIN_REQUESTS_COUNT",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_ALLOWED_SCM_PROVIDERS", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_GITHUB_API_METRICS", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_NEW_GIT_FILE_GLOBBING", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_SCM_PROVIDERS", "ARGOCD_APPLICATIONSET_CONTROLLER_SCM_ROOT_CA_PATH"),
+		),
+	},
+	{
+		name: "repo server", field: ".spec.repo",
+		env: func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar) {
+			return argoCD.Spec.Repo.IsEnabled() && !argoCD.Spec.Repo.IsRemote(), argoCD.Spec.Repo.Env
+		},
+		envVars: slices.Concat(
+			[]envVarDefinition{
+				preferCRField("ARGOCD_EXEC_TIMEOUT", ".spec.repo.execTimeout"),
+				unsupported("ARGOCD_REPO_SERVER_LOGLEVEL", ".spec.repo.logLevel"),
+				unsupported("ARGOCD_REPO_SERVER_LOGFORMAT", ".spec.repo.logFormat"),
+			},
+			supported("ARGOCD_EXEC_FATAL_TIMEOUT", "ARGOCD_REPO_SERVER_PARALLELISM_LIMIT", "ARGOCD_REPO_SERVER_LISTEN_ADDRESS", "ARGOCD_REPO_SERVER_METRICS_LISTEN_ADDRESS", "ARGOCD_REPO_SERVER_DISABLE_TLS", "ARGOCD_REPO_SERVER_NAME",
+				"ARGOCD_REPO_SERVER_ALLOW_OUT_OF_BOUNDS_SYMLINKS", "ARGOCD_REPO_SERVER_INCLUDE_HIDDEN_DIRECTORIES", "ARGOCD_REPO_SERVER_ENABLE_BUILTIN_GIT_CONFIG", "ARGOCD_REPO_SERVER_MAX_COMBINED_DIRECTORY_MANIFESTS_SIZE",
+				"ARGOCD_REPO_SERVER_HELM_MANIFEST_MAX_EXTRACTED_SIZE", "ARGOCD_REPO_SERVER_HELM_MANIFEST_MAX_INDEX_SIZE", "ARGOCD_REPO_SERVER_DISABLE_HELM_MANIFEST_MAX_EXTRACTED_SIZE",
+				"ARGOCD_REPO_SERVER_OCI_MANIFEST_MAX_EXTRACTED_SIZE", "ARGOCD_REPO_SERVER_DISABLE_OCI_MANIFEST_MAX_EXTRACTED_SIZE", "ARGOCD_REPO_SERVER_OCI_LAYER_MEDIA_TYPES",
+				"ARGOCD_REPO_SERVER_STREAMED_MANIFEST_MAX_EXTRACTED_SIZE", "ARGOCD_REPO_SERVER_STREAMED_MANIFEST_MAX_TAR_SIZE", "ARGOCD_REPO_SERVER_PLUGIN_TAR_EXCLUSIONS", "ARGOCD_REPO_SERVER_PLUGIN_USE_MANIFEST_GENERATE_PATHS",
+				"ARGOCD_REPO_SERVER_OTLP_ADDRESS", "ARGOCD_REPO_SERVER_OTLP_ATTRS", "ARGOCD_REPO_SERVER_OTLP_INSECURE", "ARGOCD_REPO_OTLP_HEADERS",
+				"ARGOCD_PAUSE_GEN_AFTER_FAILED_ATTEMPTS", "ARGOCD_PAUSE_GEN_MINUTES", "ARGOCD_PAUSE_GEN_REQUESTS", "ARGOCD_PLUGINSOCKFILEPATH", "ARGOCD_CMP_CHUNK_SIZE", "ARGOCD_CMP_WORKDIR"),
+		),
+	},
+	{
+		name: "applicationset controller", field: ".spec.applicationSet",
+		env: func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar) {
+			if argoCD.Spec.ApplicationSet == nil {
+				return false, nil
+			}
+			return argoCD.Spec.ApplicationSet.IsEnabled(), argoCD.Spec.ApplicationSet.Env
+		},
+		envVars: slices.Concat(
+			[]envVarDefinition{
+				unsupported("ARGOCD_APPLICATIONSET_CONTROLLER_NAMESPACES", ".spec.applicationSet.sourceNamespaces"),
+				unsupported("ARGOCD_APPLICATIONSET_CONTROLLER_LOGLEVEL", ".spec.applicationSet.logLevel"),
+				unsupported("ARGOCD_APPLICATIONSET_CONTROLLER_LOGFORMAT", ".spec.applicationSet.logformat"),
+				preferCRField("ARGOCD_APPLICATIONSET_CONTROLLER_ALLOWED_SCM_PROVIDERS", ".spec.applicationSet.scmProviders"),
+				preferCRField("ARGOCD_APPLICATIONSET_CONTROLLER_SCM_ROOT_CA_PATH", ".spec.applicationSet.scmRootCAConfigMap"),
+			},
+			supported("ARGOCD_APPLICATIONSET_CONTROLLER_CONCURRENT_RECONCILIATIONS", "ARGOCD_APPLICATIONSET_CONTROLLER_DEBUG", "ARGOCD_APPLICATIONSET_CONTROLLER_DRY_RUN",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_GITHUB_API_METRICS", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_LEADER_ELECTION", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_NEW_GIT_FILE_GLOBBING",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_POLICY_OVERRIDE", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_PROGRESSIVE_SYNCS", "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_SCM_PROVIDERS",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_GLOBAL_PRESERVED_ANNOTATIONS", "ARGOCD_APPLICATIONSET_CONTROLLER_GLOBAL_PRESERVED_LABELS", "ARGOCD_APPLICATIONSET_CONTROLLER_MAX_RESOURCES_STATUS_COUNT",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_POLICY", "ARGOCD_APPLICATIONSET_CONTROLLER_REPO_SERVER", "ARGOCD_APPLICATIONSET_CONTROLLER_REPO_SERVER_PLAINTEXT", "ARGOCD_APPLICATIONSET_CONTROLLER_REPO_SERVER_STRICT_TLS",
+				"ARGOCD_APPLICATIONSET_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS", "ARGOCD_APPLICATIONSET_CONTROLLER_REQUEUE_AFTER", "ARGOCD_APPLICATIONSET_CONTROLLER_TOKENREF_STRICT_MODE", "ARGOCD_APPLICATIONSET_CONTROLLER_WEBHOOK_PARALLELISM_LIMIT"),
+		),
+	},
+	{
+		name: "notifications controller", field: ".spec.notifications",
+		env: func(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar) {
+			return argoCD.Spec.Notifications.Enabled, argoCD.Spec.Notifications.Env
+		},
+		envVars: slices.Concat(
+			[]envVarDefinition{
+				unsupported("ARGOCD_NOTIFICATIONS_CONTROLLER_LOGLEVEL", ".spec.notifications.logLevel"),
+				unsupported("ARGOCD_NOTIFICATIONS_CONTROLLER_LOGFORMAT", ".spec.notifications.logformat"),
+				unsupported("ARGOCD_APPLICATION_NAMESPACES", ".spec.notifications.sourceNamespaces"),
+			},
+			supported("ARGOCD_NOTIFICATION_CONTROLLER_REPO_SERVER_PLAINTEXT", "ARGOCD_NOTIFICATION_CONTROLLER_SELF_SERVICE_NOTIFICATION_ENABLED"),
+		),
+	},
+}
+
+// checkEnvVarsAgainstKnowledgeBase reports the env vars of each enabled component which are known to have a (preferred or required) ArgoCD CR field, and the env vars which are not known to be read by the component at all (see componentEnvVarTables).
+// - Unrecognized env vars are reported as Info: they may be misspelled, may have been removed from Argo CD, or may be intended for a tool run within the component.
+// - Env vars which are feature flags with a dedicated CR boolean are not reported here, see checkForFeatureFlagsWhichOverlapWithCRBooleans.
+func checkEnvVarsAgainstKnowledgeBase(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, component := range componentEnvVarTables {
+
+		enabled, env := component.env(argoCD)
+		if !enabled {
+			continue
+		}
+
+		for _, envVar := range env {
+
+			field := component.field + ".env[" + envVar.Name + "]"
+
+			definition := findEnvVarDefinition(component, envVar.Name)
+
+			if definition == nil {
+
+				if isFeatureFlagEnvVar(component.field, envVar.Name) || slices.ContainsFunc(genericEnvVarPrefixes, func(prefix string) bool { return strings.HasPrefix(envVar.Name, prefix) }) {
+					continue
+				}
+
+				*issues = append(*issues, issue{
+					level:   LogLevel_Info,
+					field:   field,
+					source:  IssueSource_EnvVar,
+					message: fmt.Sprintf("'%s' is not an env var that is known to be read by the %s. It may be misspelled, may not be supported by this Argo CD version, or may be intended for a tool run within the %s. If it is not required, remove it.", envVar.Name, component.name, component.name),
+				})
+				continue
+			}
+
+			switch definition.support {
+			case envVarSupport_PreferCRField:
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   field,
+					source:  IssueSource_EnvVar,
+					message: fmt.Sprintf("Specifying %s is supported, but it is preferable to use '%s' ArgoCD CR field for this.", envVar.Name, definition.crField),
+				})
+
+			case envVarSupport_Unsupported:
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   field,
+					source:  IssueSource_EnvVar,
+					message: fmt.Sprintf("Specifying %s is not supported. Use '%s' ArgoCD CR field for this.", envVar.Name, definition.crField),
+				})
+			}
+		}
+	}
+}
+
+// findEnvVarDefinition returns the definition of the env var of the given component (or a common env var), or nil if the env var is not known
+func findEnvVarDefinition(component componentEnvVars, name string) *envVarDefinition {
+
+	for _, envVars := range [][]envVarDefinition{component.envVars, commonEnvVars} {
+		for idx := range envVars {
+			if envVars[idx].name == name {
+				return &envVars[idx]
+			}
+		}
+	}
+
+	return nil
+}
+
+// isFeatureFlagEnvVar returns true if the env var is a feature flag of the component which has a dedicated ArgoCD CR boolean (see featureFlagMappings)
+func isFeatureFlagEnvVar(componentField string, name string) bool {
+	return slices.ContainsFunc(featureFlagMappings, func(mapping featureFlagMapping) bool {
+		return mapping.componentField == componentField && mapping.envVar == name
+	})
+}
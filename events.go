@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/fatih/color"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxReportedEvents is the maximum number of Warning events that are reported for a single Argo CD instance. The most recent events are reported.
+const maxReportedEvents = 20
+
+// warningEvent is a Warning-type Event in the namespace of an Argo CD instance (for example, 'FailedScheduling', 'BackOff', or 'Unhealthy')
+type warningEvent struct {
+	lastSeen time.Time
+	reason   string
+
+	// object is the object the event is about, in 'Kind/name' format
+	object  string
+	message string
+	count   int32
+}
+
+// eventsSummary contains the recent Warning events in the namespace of a single Argo CD instance
+type eventsSummary struct {
+	// events are the most recent Warning events (at most maxReportedEvents), most recent first
+	events []warningEvent
+
+	// total is the number of Warning events within the window, which may be more than were reported
+	total int
+
+	// windowEnd is the time that the window ends (the time of the check for a live cluster, otherwise the time of the most recent event), and window is its duration
+	windowEnd time.Time
+	window    time.Duration
+}
+
+// eventTime returns the time an Event was last observed, falling back to the other timestamps of the Event for events which do not set a last timestamp
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// checkEvents returns the Warning events (from the given list) in the namespace of the Argo CD instance which were last observed within 'window' of 'windowEnd'.
+// - If 'windowEnd' is zero, the window ends at the most recent event in the list. This is used for must-gather/manifest data, which was captured at some time in the past.
+func checkEvents(argoCD v1beta1.ArgoCD, events []corev1.Event, window time.Duration, windowEnd time.Time) eventsSummary {
+
+	namespaceWarnings := []corev1.Event{}
+	for _, event := range events {
+		if event.Namespace == argoCD.Namespace && event.Type == corev1.EventTypeWarning {
+			namespaceWarnings = append(namespaceWarnings, event)
+		}
+	}
+
+	if windowEnd.IsZero() {
+		for _, event := range events {
+			if eventTime(event).After(windowEnd) {
+				windowEnd = eventTime(event)
+			}
+		}
+	}
+
+	res := eventsSummary{windowEnd: windowEnd, window: window}
+
+	for _, event := range namespaceWarnings {
+
+		if eventTime(event).Before(windowEnd.Add(-window)) {
+			continue
+		}
+
+		res.events = append(res.events, warningEvent{
+			lastSeen: eventTime(event),
+			reason:   event.Reason,
+			object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			message:  event.Message,
+			count:    max(event.Count, 1),
+		})
+	}
+
+	sort.SliceStable(res.events, func(i, j int) bool {
+		return res.events[i].lastSeen.After(res.events[j].lastSeen)
+	})
+
+	res.total = len(res.events)
+	if len(res.events) > maxReportedEvents {
+		res.events = res.events[:maxReportedEvents]
+	}
+
+	return res
+}
+
+// outputEventsSummary outputs the recent Warning events of an Argo CD instance namespace. These are informational: they provide runtime context for the issues, and do not affect the score or exit code.
+func outputEventsSummary(summary eventsSummary) {
+
+	coloredEvents := color.New(color.FgHiWhite, color.Bold).Sprint("Warning events")
+
+	if summary.windowEnd.IsZero() {
+		// There were no events at all in the must-gather/manifest data, so there is no window
+		outputStatusMessage(fmt.Sprintf("%s: none found", coloredEvents))
+		return
+	}
+
+	if summary.total == 0 {
+		outputStatusMessage(fmt.Sprintf("%s: none in the %s before %s", coloredEvents, summary.window, summary.windowEnd.Format(time.RFC3339)))
+		return
+	}
+
+	outputStatusMessage(fmt.Sprintf("%s: %d in the %s before %s", coloredEvents, summary.total, summary.window, summary.windowEnd.Format(time.RFC3339)))
+
+	for _, event := range summary.events {
+		outputStatusMessage(fmt.Sprintf("- %s %s (%s, x%d): %s", event.lastSeen.Format(time.RFC3339), event.reason, event.object, event.count, event.message))
+	}
+
+	if summary.total > len(summary.events) {
+		outputStatusMessage(fmt.Sprintf("- (%d older event(s) not shown)", summary.total-len(summary.events)))
+	}
+}
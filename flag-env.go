@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// flagEnvVarPrefix is the prefix of the env vars which may be used to set the command line flags, e.g. 'ACC_FAIL_ON' for '--fail-on'. This is more convenient than command line arguments when the tool is run as a container (e.g. a CronJob).
+const flagEnvVarPrefix = "ACC_"
+
+// repeatableFlags are the flags which may be specified multiple times on the command line. Their env var may contain multiple values, separated by the OS path list separator (':' on Linux/macOS), as with KUBECONFIG.
+var repeatableFlags = map[string]bool{
+	"kubeconfig": true,
+}
+
+// flagEnvVarName returns the name of the env var which sets the given flag, e.g. 'ACC_FAIL_ON' for 'fail-on'
+func flagEnvVarName(flagName string) string {
+	return flagEnvVarPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyFlagEnvVars sets each flag which was not specified on the command line from its env var (see flagEnvVarName), if the env var is set. It must be called after the flags are parsed.
+// - Precedence is: command line flag, then env var, then the flag default. An env var which is set to an empty value is ignored.
+// - The env var is parsed exactly as the command line value would be (e.g. 'ACC_QUIET=true'), and an invalid value is an error which names the env var.
+func applyFlagEnvVars(flags *flag.FlagSet) error {
+
+	setOnCommandLine := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	var err error
+
+	flags.VisitAll(func(f *flag.Flag) {
+		if err != nil || setOnCommandLine[f.Name] {
+			return
+		}
+
+		envVarName := flagEnvVarName(f.Name)
+		value := os.Getenv(envVarName)
+		if value == "" {
+			return
+		}
+
+		values := []string{value}
+		if repeatableFlags[f.Name] {
+			values = filepath.SplitList(value)
+		}
+
+		for _, currValue := range values {
+			if currValue == "" {
+				continue
+			}
+			if setErr := flags.Set(f.Name, currValue); setErr != nil {
+				err = fmt.Errorf("invalid value '%s' of env var '%s' (for '--%s'): %w", currValue, envVarName, f.Name, setErr)
+				return
+			}
+		}
+	})
+
+	return err
+}
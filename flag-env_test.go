@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyFlagEnvVars(t *testing.T) {
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+
+		expectedFailOn      string
+		expectedQuiet       bool
+		expectedMaxParallel int
+		expectedKubeConfigs []string
+
+		// expectedErr is a substring of the expected error, or empty if no error is expected
+		expectedErr string
+	}{
+		{
+			name:                "defaults, without flags or env vars",
+			expectedMaxParallel: 4,
+			expectedKubeConfigs: []string{},
+		},
+		{
+			name:                "flag takes precedence over env var",
+			args:                []string{"--fail-on", "error", "--quiet=false", "--max-parallel", "2"},
+			env:                 map[string]string{"ACC_FAIL_ON": "warn", "ACC_QUIET": "true", "ACC_MAX_PARALLEL": "8"},
+			expectedFailOn:      "error",
+			expectedMaxParallel: 2,
+			expectedKubeConfigs: []string{},
+		},
+		{
+			name:                "env var takes precedence over default",
+			env:                 map[string]string{"ACC_FAIL_ON": "warn", "ACC_QUIET": "true", "ACC_MAX_PARALLEL": "8"},
+			expectedFailOn:      "warn",
+			expectedQuiet:       true,
+			expectedMaxParallel: 8,
+			expectedKubeConfigs: []string{},
+		},
+		{
+			name:                "empty env var is ignored",
+			env:                 map[string]string{"ACC_FAIL_ON": "", "ACC_MAX_PARALLEL": ""},
+			expectedMaxParallel: 4,
+			expectedKubeConfigs: []string{},
+		},
+		{
+			name:        "invalid int env var",
+			env:         map[string]string{"ACC_MAX_PARALLEL": "many"},
+			expectedErr: "ACC_MAX_PARALLEL",
+		},
+		{
+			name:        "invalid bool env var",
+			env:         map[string]string{"ACC_QUIET": "maybe"},
+			expectedErr: "ACC_QUIET",
+		},
+		{
+			name:                "repeatable env var is split on the path list separator",
+			env:                 map[string]string{"ACC_KUBECONFIG": strings.Join([]string{"/tmp/a", "", "/tmp/b"}, string(os.PathListSeparator))},
+			expectedMaxParallel: 4,
+			expectedKubeConfigs: []string{"/tmp/a", "/tmp/b"},
+		},
+		{
+			name:                "repeatable flag takes precedence over env var",
+			args:                []string{"--kubeconfig", "/tmp/c"},
+			env:                 map[string]string{"ACC_KUBECONFIG": "/tmp/a"},
+			expectedMaxParallel: 4,
+			expectedKubeConfigs: []string{"/tmp/c"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			// Env vars which are not set by the test are cleared, so that the environment of the test run does not affect the result
+			for _, name := range []string{"ACC_FAIL_ON", "ACC_QUIET", "ACC_MAX_PARALLEL", "ACC_KUBECONFIG"} {
+				t.Setenv(name, test.env[name])
+			}
+
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			failOn := flags.String("fail-on", "", "")
+			quiet := flags.Bool("quiet", false, "")
+			maxParallel := flags.Int("max-parallel", 4, "")
+			kubeConfigPaths := []string{}
+			flags.Func("kubeconfig", "", func(value string) error {
+				kubeConfigPaths = append(kubeConfigPaths, value)
+				return nil
+			})
+
+			if err := flags.Parse(test.args); err != nil {
+				t.Fatalf("unable to parse arguments: %v", err)
+			}
+
+			err := applyFlagEnvVars(flags)
+
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected an error containing '%s', got: %v", test.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if *failOn != test.expectedFailOn {
+				t.Errorf("fail-on: expected '%s', got '%s'", test.expectedFailOn, *failOn)
+			}
+			if *quiet != test.expectedQuiet {
+				t.Errorf("quiet: expected %v, got %v", test.expectedQuiet, *quiet)
+			}
+			if *maxParallel != test.expectedMaxParallel {
+				t.Errorf("max-parallel: expected %d, got %d", test.expectedMaxParallel, *maxParallel)
+			}
+			if !reflect.DeepEqual(kubeConfigPaths, test.expectedKubeConfigs) {
+				t.Errorf("kubeconfig: expected %v, got %v", test.expectedKubeConfigs, kubeConfigPaths)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitHubAnnotationCommand returns the GitHub Actions workflow command (annotation type) that is used for issues of the given log level
+func gitHubAnnotationCommand(level LogLevel) string {
+	switch level {
+	case LogLevel_Fatal, LogLevel_Error:
+		return "error"
+	case LogLevel_Warn:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// escapeGitHubCommandData escapes the message of a GitHub Actions workflow command, so that it is output as a single command
+func escapeGitHubCommandData(data string) string {
+	data = strings.ReplaceAll(data, "%", "%25")
+	data = strings.ReplaceAll(data, "\r", "%0D")
+	data = strings.ReplaceAll(data, "\n", "%0A")
+	return data
+}
+
+// escapeGitHubCommandProperty escapes a property value (e.g. 'title') of a GitHub Actions workflow command
+func escapeGitHubCommandProperty(property string) string {
+	property = escapeGitHubCommandData(property)
+	property = strings.ReplaceAll(property, ":", "%3A")
+	property = strings.ReplaceAll(property, ",", "%2C")
+	return property
+}
+
+// gitHubAnnotationFileProperties returns the 'file' (and 'line') properties of the annotations of an instance's issues, which attach the annotations to the manifest/must-gather file that the ArgoCD CR was read from, or empty if the file is not known. The path is output as it was specified by the user, so it should be relative to the root of the repository in order for GitHub to attach the annotation to the file.
+func gitHubAnnotationFileProperties(instance instanceResult) string {
+	if instance.manifestSource == nil {
+		return ""
+	}
+	res := "file=" + escapeGitHubCommandProperty(filepath.ToSlash(instance.manifestSource.Path)) + ","
+	if instance.manifestSource.Line > 0 {
+		res += "line=" + strconv.Itoa(instance.manifestSource.Line) + ","
+	}
+	return res
+}
+
+// outputResultsAsGitHubAnnotations writes each issue of the check results as a GitHub Actions workflow command (for example '::warning title=ACC004 (ns/name)::...'), so that the issues are shown as annotations on a workflow run/pull request.
+// - Fatal and Error issues are output as 'error' annotations, Warn issues as 'warning' annotations, and all others as 'notice' annotations.
+// - When the ArgoCD CR was read from a file ('--manifest', or a must-gather), the annotations are attached to the file (and the line at which the CR's document begins, where known), e.g. '::warning file=manifests/argocd.yaml,line=12,title=...::...'.
+// - Annotations do not themselves fail the workflow step: use '--fail-on' to exit with a non-zero status code.
+func outputResultsAsGitHubAnnotations(results checkResults) {
+
+	for _, entry := range results.installEntries {
+		fmt.Fprintf(reportOutput, "::%s title=%s::%s\n", gitHubAnnotationCommand(entry.level), escapeGitHubCommandProperty(results.operatorInstallationName()), escapeGitHubCommandData(entry.message))
+	}
+
+	for _, instance := range results.instances {
+
+		instanceName := results.instanceName(instance)
+
+		for _, issue := range instance.issues {
+
+			title := instanceName
+			if issue.ruleID != "" {
+				title = issue.ruleID + " (" + instanceName + ")"
+			}
+
+			message := issue.message
+			if issue.unsupported {
+				message = "[Unsupported] " + message
+			}
+
+			fmt.Fprintf(reportOutput, "::%s %stitle=%s::ArgoCD '%s' %s: %s\n", gitHubAnnotationCommand(issue.level), gitHubAnnotationFileProperties(instance), escapeGitHubCommandProperty(title), instanceName, escapeGitHubCommandData(issue.field), escapeGitHubCommandData(message))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jgwest/argocd-config-check/clients"
+)
+
+func TestOutputResultsAsGitHubAnnotationsFileProperties(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		manifestSource *clients.ManifestSource
+		expected       string
+	}{
+		{
+			name:     "not read from a file",
+			expected: "::warning title=ACC004 (team-a/argocd)::ArgoCD 'team-a/argocd' .spec.server: message\n",
+		},
+		{
+			name:           "file and line",
+			manifestSource: &clients.ManifestSource{Path: "manifests/argocd.yaml", Line: 12},
+			expected:       "::warning file=manifests/argocd.yaml,line=12,title=ACC004 (team-a/argocd)::ArgoCD 'team-a/argocd' .spec.server: message\n",
+		},
+		{
+			name:           "file with no line",
+			manifestSource: &clients.ManifestSource{Path: "must-gather/argoproj.io/argocds.yaml"},
+			expected:       "::warning file=must-gather/argoproj.io/argocds.yaml,title=ACC004 (team-a/argocd)::ArgoCD 'team-a/argocd' .spec.server: message\n",
+		},
+		{
+			name:           "path containing a comma",
+			manifestSource: &clients.ManifestSource{Path: "manifests/a,b.yaml", Line: 1},
+			expected:       "::warning file=manifests/a%2Cb.yaml,line=1,title=ACC004 (team-a/argocd)::ArgoCD 'team-a/argocd' .spec.server: message\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			output := captureReportOutput(t)
+
+			outputResultsAsGitHubAnnotations(checkResults{instances: []instanceResult{{
+				namespace:      "team-a",
+				name:           "argocd",
+				issues:         []issue{{level: LogLevel_Warn, ruleID: "ACC004", field: ".spec.server", message: "message"}},
+				manifestSource: test.manifestSource,
+			}}})
+
+			if actual := output.String(); !strings.Contains(actual, test.expected) {
+				t.Errorf("expected annotation %q, got:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
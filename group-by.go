@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// groupBy is how the reported issues are grouped in the (text/table) output
+type groupBy string
+
+const (
+	// groupBy_Instance reports the issues of each ArgoCD instance as each instance is checked. This is the default.
+	groupBy_Instance groupBy = "instance"
+
+	// groupBy_Rule reports the issues of all ArgoCD instances (of all clusters) once all instances have been checked, grouped by rule ID, with the instances affected by each rule. See outputResultsGroupedByRule.
+	groupBy_Rule groupBy = "rule"
+)
+
+// groupByValues is the list of valid '--group-by' values, in the order they are presented to the user
+var groupByValues = []groupBy{groupBy_Instance, groupBy_Rule}
+
+// parseGroupBy converts the user-specified '--group-by' value into a groupBy, or returns an error if it is not a valid value.
+func parseGroupBy(value string) (groupBy, error) {
+
+	validValues := []string{}
+	for _, currValue := range groupByValues {
+		if string(currValue) == value {
+			return currValue, nil
+		}
+		validValues = append(validValues, string(currValue))
+	}
+
+	return "", fmt.Errorf("unrecognized value '%s': valid values are: %s", value, strings.Join(validValues, ", "))
+}
+
+// ruleFindings are the findings of a single rule, across all ArgoCD instances
+type ruleFindings struct {
+	ruleID string
+
+	// level is the most severe level of the findings
+	level LogLevel
+
+	// unsupported is true if any of the findings is an unsupported configuration
+	unsupported bool
+
+	findingCount int
+
+	// instances are the affected ArgoCD instances, in the order they were checked
+	instances []ruleFindingsInstance
+}
+
+// ruleFindingsInstance is an ArgoCD instance which is affected by a rule, and the fields of its findings
+type ruleFindingsInstance struct {
+	name   string // see checkResults.instanceName
+	fields []string
+}
+
+// groupFindingsByRule aggregates the findings of all ArgoCD instances by rule ID. Rules are sorted by the number of affected instances (most first), then by severity (most severe first), then by rule ID, so that the most widespread misconfigurations are first.
+// - Issues without a rule ID are not included: these are the notes about suppressed findings (see suppressIssuesByAnnotation), which describe findings that are included.
+func groupFindingsByRule(allResults []checkResults) []ruleFindings {
+
+	findingsByRuleID := map[string]*ruleFindings{}
+
+	for _, results := range allResults {
+		for _, instance := range results.instances {
+			for _, currIssue := range instance.issues {
+
+				if currIssue.ruleID == "" {
+					continue
+				}
+
+				findings, exists := findingsByRuleID[currIssue.ruleID]
+				if !exists {
+					findings = &ruleFindings{ruleID: currIssue.ruleID, level: currIssue.level}
+					findingsByRuleID[currIssue.ruleID] = findings
+				}
+
+				if logLevelSeverity(currIssue.level) > logLevelSeverity(findings.level) {
+					findings.level = currIssue.level
+				}
+				findings.unsupported = findings.unsupported || currIssue.unsupported
+				findings.findingCount++
+
+				instanceName := results.instanceName(instance)
+				if len(findings.instances) == 0 || findings.instances[len(findings.instances)-1].name != instanceName {
+					findings.instances = append(findings.instances, ruleFindingsInstance{name: instanceName})
+				}
+				lastInstance := &findings.instances[len(findings.instances)-1]
+				if !slices.Contains(lastInstance.fields, currIssue.field) {
+					lastInstance.fields = append(lastInstance.fields, currIssue.field)
+				}
+			}
+		}
+	}
+
+	res := []ruleFindings{}
+	for _, findings := range findingsByRuleID {
+		res = append(res, *findings)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if len(res[i].instances) != len(res[j].instances) {
+			return len(res[i].instances) > len(res[j].instances)
+		}
+		if logLevelSeverity(res[i].level) != logLevelSeverity(res[j].level) {
+			return logLevelSeverity(res[i].level) > logLevelSeverity(res[j].level)
+		}
+		return res[i].ruleID < res[j].ruleID
+	})
+
+	return res
+}
+
+// outputResultsGroupedByRule reports the findings of all ArgoCD instances grouped by rule (see groupFindingsByRule): each rule once, with its title, the number of affected instances and findings, and the fields of the findings of each affected instance. This gives a 'top misconfigurations across the fleet' view, for prioritizing organization-wide fixes.
+func outputResultsGroupedByRule(allResults []checkResults) {
+
+	instanceCount := 0
+	for _, results := range allResults {
+		instanceCount += len(results.instances)
+	}
+
+	groupedFindings := groupFindingsByRule(allResults)
+
+	fmt.Fprintln(reportOutput, "==============================================================================")
+	fmt.Fprintf(reportOutput, "Findings by rule (%d rule(s), across %d ArgoCD instance(s)):\n", len(groupedFindings), instanceCount)
+	fmt.Fprintln(reportOutput)
+
+	if len(groupedFindings) == 0 {
+		fmt.Fprintln(reportOutput, "No issues found.")
+		fmt.Fprintln(reportOutput)
+		return
+	}
+
+	for _, findings := range groupedFindings {
+
+		title := findings.ruleID
+		if registration := findCheckRegistration(findings.ruleID); registration != nil {
+			title += ": " + registration.title
+		}
+
+		coloredLevel := colorizeLogLevel(findings.level, string(findings.level))
+		if findings.unsupported {
+			coloredLevel += " [Unsupported]"
+		}
+
+		fmt.Fprintf(reportOutput, "%s (%s)\n", color.New(color.Bold).Sprint(title), coloredLevel)
+		fmt.Fprintf(reportOutput, "%d of %d instance(s) affected, %d finding(s):\n", len(findings.instances), instanceCount, findings.findingCount)
+		for _, instance := range findings.instances {
+			fmt.Fprintf(reportOutput, "- %s: %s\n", instance.name, strings.Join(instance.fields, ", "))
+		}
+		fmt.Fprintln(reportOutput)
+	}
+}
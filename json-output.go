@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonSchemaVersion is the version of the structure of the JSON output document when checking a single cluster (see jsonResults).
+//
+// Compatibility contract for consumers of the JSON output:
+// - New fields may be added to the document WITHOUT incrementing the schema version. Consumers should ignore fields that they do not recognize.
+// - The schema version is incremented only on a breaking change: when an existing field is removed or renamed, or when the type or meaning of an existing field changes.
+// - Consumers may pass '--format-version' with the schema version they support, in which case the tool will fail (rather than produce output in an unexpected shape) if that version is not the version of the document produced by the tool (see validateFormatVersion).
+const jsonSchemaVersion = 1
+
+// jsonMultiClusterSchemaVersion is the version of the structure of the JSON output document when checking multiple clusters (see jsonMultiClusterResults), which follows the same compatibility contract as jsonSchemaVersion.
+// - Both documents are output by '--output json', so their versions never overlap: a '--format-version' then identifies a single shape of document, and a consumer which only supports the single cluster document is not given a multi-cluster document.
+const jsonMultiClusterSchemaVersion = 2
+
+// jsonDocumentKind identifies the shape of a JSON output document. It is always the second field of each document (after the schema version), so that a consumer can tell the documents apart: each kind of document has its own schema version (e.g. jsonSchemaVersion, jsonTopologySchemaVersion), so the schema version alone does not identify the shape of the document.
+type jsonDocumentKind string
+
+const (
+	// jsonDocumentKind_Results is the document of the check results (see jsonResults), output by '--output json'
+	jsonDocumentKind_Results jsonDocumentKind = "results"
+
+	// jsonDocumentKind_MultiClusterResults is the document of the check results of multiple clusters (see jsonMultiClusterResults), output by '--output json' when checking multiple clusters
+	jsonDocumentKind_MultiClusterResults jsonDocumentKind = "multiClusterResults"
+
+	// jsonDocumentKind_Topology is the document of the cluster topology (see jsonTopology), output by '--topology json'
+	jsonDocumentKind_Topology jsonDocumentKind = "topology"
+)
+
+// validateFormatVersion returns an error if the user-specified '--format-version' is not the schema version of the JSON document produced by the tool: jsonMultiClusterSchemaVersion when checking multiple clusters, otherwise jsonSchemaVersion. A '--format-version' of 0 (the default) is not checked.
+func validateFormatVersion(formatVersion int, multiCluster bool) error {
+
+	if formatVersion == 0 {
+		return nil
+	}
+
+	if multiCluster && formatVersion != jsonMultiClusterSchemaVersion {
+		return fmt.Errorf("unsupported version %d: when checking multiple clusters, this version of the tool only produces schema version %d", formatVersion, jsonMultiClusterSchemaVersion)
+	}
+
+	if !multiCluster && formatVersion != jsonSchemaVersion {
+		return fmt.Errorf("unsupported version %d: when checking a single cluster, this version of the tool only produces schema version %d", formatVersion, jsonSchemaVersion)
+	}
+
+	return nil
+}
+
+// jsonResults is the top-level JSON output document
+type jsonResults struct {
+	// SchemaVersion is always the first field of the document. See jsonSchemaVersion.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Kind is always jsonDocumentKind_Results
+	Kind jsonDocumentKind `json:"kind"`
+
+	Operator jsonOperator `json:"operator"`
+
+	// InstallationFindings are the results of checking the operator installation (Subscription/CSV)
+	InstallationFindings []jsonInstallationFinding `json:"installationFindings"`
+
+	Instances []jsonInstance `json:"instances"`
+}
+
+type jsonOperator struct {
+	// Version is empty if the operator version could not be determined
+	Version string `json:"version"`
+
+	// VersionUserSupplied is true if the version was supplied by the user (via '--argocd-operator-version'), rather than discovered from the cluster
+	VersionUserSupplied bool `json:"versionUserSupplied"`
+
+	// InstallNamespace is empty if the operator install namespace could not be determined
+	InstallNamespace string `json:"installNamespace"`
+
+	ClusterScopedNamespaces []string `json:"clusterScopedNamespaces"`
+}
+
+type jsonInstallationFinding struct {
+	Severity LogLevel `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+type jsonInstance struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Issues    []jsonIssue `json:"issues"`
+
+	// Score (0-100) and Grade (A-F) are computed from the issues of the instance. See scoreIssues.
+	Score int    `json:"score"`
+	Grade string `json:"grade"`
+
+	// Applications is omitted if the Applications summary was not requested (see '--include-applications')
+	Applications *jsonApplicationsSummary `json:"applications,omitempty"`
+
+	// WarningEvents is omitted if the Warning events were not requested (see '--include-events')
+	WarningEvents *jsonEventsSummary `json:"warningEvents,omitempty"`
+
+	// NonDefaultFields is omitted if the non-default fields were not requested (see '--show-nondefault')
+	NonDefaultFields *[]jsonNonDefaultField `json:"nonDefaultFields,omitempty"`
+}
+
+type jsonIssue struct {
+	Severity    LogLevel `json:"severity"`
+	RuleID      string   `json:"ruleID"`
+	Field       string   `json:"field"`
+	Message     string   `json:"message"`
+	Unsupported bool     `json:"unsupported"`
+
+	// Source is where the problematic value came from (e.g. 'envVar', 'extraConfig', 'cmdParams', 'extraCommandArgs', 'crField'), or empty if not determined. See checks.IssueSource.
+	Source IssueSource `json:"source"`
+}
+
+type jsonApplicationsSummary struct {
+	Total      int      `json:"total"`
+	OutOfSync  []string `json:"outOfSync"`
+	ManualSync []string `json:"manualSync"`
+	SyncError  []string `json:"syncError"`
+}
+
+type jsonEventsSummary struct {
+	// Total is the number of Warning events within the window, which may be more than are included in Events
+	Total  int         `json:"total"`
+	Events []jsonEvent `json:"events"`
+}
+
+type jsonEvent struct {
+	LastSeen string `json:"lastSeen"`
+	Reason   string `json:"reason"`
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count"`
+}
+
+type jsonNonDefaultField struct {
+	Component    string `json:"component"`
+	Field        string `json:"field"`
+	Value        string `json:"value"`
+	DefaultValue string `json:"defaultValue"`
+}
+
+// toJSONResults converts the check results into the JSON output document
+func toJSONResults(results checkResults) jsonResults {
+
+	res := jsonResults{
+		SchemaVersion: jsonSchemaVersion,
+		Kind:          jsonDocumentKind_Results,
+		Operator: jsonOperator{
+			InstallNamespace:        results.clusterInfo.OperatorInstallNamespace,
+			ClusterScopedNamespaces: nonNilStrings(results.clusterInfo.ClusterScopedNamespaces),
+		},
+		InstallationFindings: []jsonInstallationFinding{},
+		Instances:            []jsonInstance{},
+	}
+
+	if results.clusterInfo.OperatorVersion != nil {
+		res.Operator.Version = results.clusterInfo.OperatorVersion.String()
+		res.Operator.VersionUserSupplied = results.clusterInfo.OperatorVersionUserSupplied
+	}
+
+	for _, entry := range results.installEntries {
+		res.InstallationFindings = append(res.InstallationFindings, jsonInstallationFinding{Severity: entry.level, Message: entry.message})
+	}
+
+	for _, instance := range results.instances {
+
+		jsonInst := jsonInstance{
+			Namespace: instance.namespace,
+			Name:      instance.name,
+			Issues:    []jsonIssue{},
+			Score:     instance.score.score,
+			Grade:     instance.score.grade,
+		}
+
+		for _, issue := range instance.issues {
+			jsonInst.Issues = append(jsonInst.Issues, jsonIssue{
+				Severity:    issue.level,
+				RuleID:      issue.ruleID,
+				Field:       issue.field,
+				Message:     issue.message,
+				Unsupported: issue.unsupported,
+				Source:      issue.source,
+			})
+		}
+
+		if instance.applications != nil {
+			jsonInst.Applications = &jsonApplicationsSummary{
+				Total:      instance.applications.total,
+				OutOfSync:  nonNilStrings(instance.applications.outOfSync),
+				ManualSync: nonNilStrings(instance.applications.manualSync),
+				SyncError:  nonNilStrings(instance.applications.syncError),
+			}
+		}
+
+		if instance.events != nil {
+			jsonInst.WarningEvents = &jsonEventsSummary{Total: instance.events.total, Events: []jsonEvent{}}
+			for _, event := range instance.events.events {
+				jsonInst.WarningEvents.Events = append(jsonInst.WarningEvents.Events, jsonEvent{
+					LastSeen: event.lastSeen.Format(time.RFC3339),
+					Reason:   event.reason,
+					Object:   event.object,
+					Message:  event.message,
+					Count:    event.count,
+				})
+			}
+		}
+
+		if instance.nonDefaultFields != nil {
+			fields := []jsonNonDefaultField{}
+			for _, field := range instance.nonDefaultFields {
+				fields = append(fields, jsonNonDefaultField{Component: field.component, Field: field.crField, Value: field.value, DefaultValue: field.defaultValue})
+			}
+			jsonInst.NonDefaultFields = &fields
+		}
+
+		res.Instances = append(res.Instances, jsonInst)
+	}
+
+	return res
+}
+
+// outputResultsAsJSON writes the check results as a single JSON document
+func outputResultsAsJSON(results checkResults) {
+
+	data, err := json.MarshalIndent(toJSONResults(results), "", "  ")
+	if err != nil {
+		failWithError("unable to convert results to JSON", err)
+	}
+
+	fmt.Fprintln(reportOutput, string(data))
+}
+
+// nonNilStrings returns an empty slice if 'values' is nil, so that it is output as an empty JSON array rather than 'null'
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
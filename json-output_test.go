@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONResultsSchemaVersion(t *testing.T) {
+
+	results := checkResults{
+		instances: []instanceResult{{
+			namespace: "argocd",
+			name:      "argocd",
+			issues:    []issue{{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"}},
+		}},
+	}
+
+	data, err := json.Marshal(toJSONResults(results))
+	if err != nil {
+		t.Fatalf("unable to marshal JSON results: %v", err)
+	}
+
+	schemaVersion, kind := jsonDocumentHeader(t, data)
+	if schemaVersion != jsonSchemaVersion || kind != jsonDocumentKind_Results {
+		t.Errorf("expected schema version %d of kind '%s', got %d of kind '%s'", jsonSchemaVersion, jsonDocumentKind_Results, schemaVersion, kind)
+	}
+}
+
+// jsonDocumentHeader returns the schema version and kind of a JSON output document. These must be the first and second keys of the document, so that consumers can check them before reading the rest of the document.
+func jsonDocumentHeader(t *testing.T, data []byte) (int, jsonDocumentKind) {
+	t.Helper()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		t.Fatalf("expected a JSON object, got %v (error: %v)", token, err)
+	}
+
+	if token, err := decoder.Token(); err != nil || token != "schemaVersion" {
+		t.Fatalf("expected 'schemaVersion' to be the first key, got %v (error: %v)", token, err)
+	}
+	var schemaVersion int
+	if err := decoder.Decode(&schemaVersion); err != nil {
+		t.Fatalf("unable to decode 'schemaVersion': %v", err)
+	}
+
+	if token, err := decoder.Token(); err != nil || token != "kind" {
+		t.Fatalf("expected 'kind' to be the second key, got %v (error: %v)", token, err)
+	}
+	var kind jsonDocumentKind
+	if err := decoder.Decode(&kind); err != nil {
+		t.Fatalf("unable to decode 'kind': %v", err)
+	}
+
+	return schemaVersion, kind
+}
+
+func TestJSONTopologySchemaVersion(t *testing.T) {
+
+	data, err := json.Marshal(toJSONTopology(clusterInformation{}, nil))
+	if err != nil {
+		t.Fatalf("unable to marshal JSON topology: %v", err)
+	}
+
+	schemaVersion, kind := jsonDocumentHeader(t, data)
+	if schemaVersion != jsonTopologySchemaVersion || kind != jsonDocumentKind_Topology {
+		t.Errorf("expected schema version %d of kind '%s', got %d of kind '%s'", jsonTopologySchemaVersion, jsonDocumentKind_Topology, schemaVersion, kind)
+	}
+}
+
+func TestValidateFormatVersion(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		formatVersion int
+		multiCluster  bool
+		expectedErr   string
+	}{
+		{name: "not specified", formatVersion: 0},
+		{name: "not specified, multiple clusters", formatVersion: 0, multiCluster: true},
+		{name: "single cluster", formatVersion: jsonSchemaVersion},
+		{name: "multiple clusters", formatVersion: jsonMultiClusterSchemaVersion, multiCluster: true},
+		{name: "single cluster version, multiple clusters", formatVersion: jsonSchemaVersion, multiCluster: true, expectedErr: "when checking multiple clusters"},
+		{name: "multiple cluster version, single cluster", formatVersion: jsonMultiClusterSchemaVersion, expectedErr: "when checking a single cluster"},
+		{name: "negative", formatVersion: -1, expectedErr: "unsupported version -1"},
+		{name: "unknown", formatVersion: jsonMultiClusterSchemaVersion + 1, multiCluster: true, expectedErr: "unsupported version"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateFormatVersion(test.formatVersion, test.multiCluster)
+			if test.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if test.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), test.expectedErr)) {
+				t.Errorf("expected error containing %q, got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestJSONMultiClusterSchemaVersion(t *testing.T) {
+
+	output := captureReportOutput(t)
+
+	outputMultiClusterResultsAsJSON([]clusterResult{{target: clusterTarget{name: "cluster-a"}}})
+
+	schemaVersion, kind := jsonDocumentHeader(t, output.Bytes())
+	if schemaVersion != jsonMultiClusterSchemaVersion || kind != jsonDocumentKind_MultiClusterResults {
+		t.Errorf("expected schema version %d of kind '%s', got %d of kind '%s'", jsonMultiClusterSchemaVersion, jsonDocumentKind_MultiClusterResults, schemaVersion, kind)
+	}
+
+	// A consumer of one document can never be given the other document, at the version it expects
+	if jsonMultiClusterSchemaVersion == jsonSchemaVersion {
+		t.Errorf("expected the schema versions of the single and multiple cluster documents to differ")
+	}
+}
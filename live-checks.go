@@ -0,0 +1,1288 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	argocdcommon "github.com/argoproj/argo-cd/v3/common"
+	"github.com/jgwest/argocd-config-check/clients"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkIndividualArgoCDCRAgainstCluster runs checks which require reading K8s resources other than the ArgoCD CR itself (for example, ResourceQuotas in the Argo CD namespace), and returns 'crIssues' (the issues of the checks of the ArgoCD CR) followed by the issues of these checks.
+// - These checks are skipped when the control plane data is incomplete (e.g. must-gather), since in that case we cannot distinguish between a resource that does not exist, and a resource that was not exported.
+// - Opt-in checks are only run when their flag is in 'enabledOptInFlags'. These are run even when the control plane data is incomplete (see checkRegistration.optInFlag).
+// - The issues of a check of the ArgoCD CR which is superseded by a cluster check that is run are removed from 'crIssues' (see checkRegistration.supersedes).
+func checkIndividualArgoCDCRAgainstCluster(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, crIssues []issue, clusterInfo clusterInformation, enabledOptInFlags []string) []issue {
+
+	issues := []issue{}
+	supersededRuleIDs := []string{}
+
+	for _, registration := range registeredChecks {
+		if registration.clusterCheck == nil || !clusterCheckIsRun(registration, k8sClient.IncompleteControlPlaneData(), enabledOptInFlags) {
+			continue
+		}
+
+		previousLength := len(issues)
+		registration.clusterCheck(ctx, k8sClient, argoCD, &issues)
+		setRuleIDOfNewIssues(issues, previousLength, registration.ruleID)
+
+		if registration.supersedes != "" {
+			supersededRuleIDs = append(supersededRuleIDs, registration.supersedes)
+		}
+	}
+
+	res := slices.DeleteFunc(slices.Clone(crIssues), func(crIssue issue) bool {
+		return slices.Contains(supersededRuleIDs, crIssue.ruleID)
+	})
+
+	return append(res, issues...)
+}
+
+// checkForResourceQuotaConflicts identifies ResourceQuotas in the Argo CD namespace which are tighter than the total resources declared by the Argo CD components in the ArgoCD CR. When this is the case, some component pods will not be admitted.
+func checkForResourceQuotaConflicts(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	var resourceQuotaList corev1.ResourceQuotaList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &resourceQuotaList, argoCD.Namespace); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(ResourceQuotas in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to list ResourceQuotas, so they could not be compared against ArgoCD component resources: " + err.Error(),
+		})
+		return
+	}
+
+	components := argoCDComponentResources(argoCD)
+
+	// Each quota resource name, and the side of the components' resource requirements it constrains
+	quotaResources := []struct {
+		quotaResourceName corev1.ResourceName
+		resourceName      corev1.ResourceName
+		limits            bool // true if the quota constrains limits, false if it constrains requests
+	}{
+		{quotaResourceName: corev1.ResourceRequestsCPU, resourceName: corev1.ResourceCPU},
+		{quotaResourceName: corev1.ResourceCPU, resourceName: corev1.ResourceCPU},
+		{quotaResourceName: corev1.ResourceRequestsMemory, resourceName: corev1.ResourceMemory},
+		{quotaResourceName: corev1.ResourceMemory, resourceName: corev1.ResourceMemory},
+		{quotaResourceName: corev1.ResourceLimitsCPU, resourceName: corev1.ResourceCPU, limits: true},
+		{quotaResourceName: corev1.ResourceLimitsMemory, resourceName: corev1.ResourceMemory, limits: true},
+	}
+
+	for _, quota := range resourceQuotaList.Items {
+
+		for _, quotaResource := range quotaResources {
+
+			hardLimit, exists := quota.Spec.Hard[quotaResource.quotaResourceName]
+			if !exists {
+				continue
+			}
+
+			total := resource.Quantity{}
+			contributors := []string{}
+
+			for _, component := range components {
+
+				quantity, exists := componentResourceQuantity(component, quotaResource.resourceName, quotaResource.limits)
+				if !exists {
+					continue
+				}
+
+				for range component.replicas {
+					total.Add(quantity)
+				}
+				contributors = append(contributors, fmt.Sprintf("%s: %s x %d replica(s)", component.name, quantity.String(), component.replicas))
+			}
+
+			if total.Cmp(hardLimit) > 0 {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   "(ResourceQuota '" + quota.Name + "' in namespace '" + argoCD.Namespace + "')",
+					message: fmt.Sprintf("ResourceQuota '%s' allows a maximum '%s' of %s, but the Argo CD components declare a total of %s (%s). Some Argo CD component pods will not be admitted to the namespace. Note that the quota is shared with any other workloads in the namespace.", quota.Name, quotaResource.quotaResourceName, hardLimit.String(), total.String(), strings.Join(contributors, ", ")),
+				})
+			}
+		}
+	}
+}
+
+// checkForLimitRangeConflicts identifies LimitRanges in the Argo CD namespace whose container min/max would reject the resources declared for an Argo CD component in the ArgoCD CR.
+func checkForLimitRangeConflicts(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	var limitRangeList corev1.LimitRangeList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &limitRangeList, argoCD.Namespace); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(LimitRanges in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to list LimitRanges, so they could not be compared against ArgoCD component resources: " + err.Error(),
+		})
+		return
+	}
+
+	components := argoCDComponentResources(argoCD)
+
+	for _, limitRange := range limitRangeList.Items {
+
+		for _, limitRangeItem := range limitRange.Spec.Limits {
+
+			if limitRangeItem.Type != corev1.LimitTypeContainer {
+				continue
+			}
+
+			for _, component := range components {
+
+				for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+
+					problems := []string{}
+
+					request, requestExists := componentResourceQuantity(component, resourceName, false)
+					limit, limitExists := componentResourceQuantity(component, resourceName, true)
+
+					if min, exists := limitRangeItem.Min[resourceName]; exists && requestExists && request.Cmp(min) < 0 {
+						problems = append(problems, fmt.Sprintf("request of %s is less than the LimitRange minimum of %s", request.String(), min.String()))
+					}
+
+					if max, exists := limitRangeItem.Max[resourceName]; exists {
+						if requestExists && request.Cmp(max) > 0 {
+							problems = append(problems, fmt.Sprintf("request of %s is greater than the LimitRange maximum of %s", request.String(), max.String()))
+						}
+						if limitExists && limit.Cmp(max) > 0 {
+							problems = append(problems, fmt.Sprintf("limit of %s is greater than the LimitRange maximum of %s", limit.String(), max.String()))
+						}
+					}
+
+					if len(problems) > 0 {
+						*issues = append(*issues, issue{
+							level:   LogLevel_Warn,
+							field:   component.field,
+							message: fmt.Sprintf("LimitRange '%s' in namespace '%s' will reject the %s component's pods: the '%s' %s.", limitRange.Name, argoCD.Namespace, component.name, resourceName, strings.Join(problems, ", and ")),
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+// componentResourceQuantity returns the quantity of the named resource declared in the component's requests (or limits, if 'limits' is true), and whether it was declared at all.
+func componentResourceQuantity(component componentResources, resourceName corev1.ResourceName, limits bool) (resource.Quantity, bool) {
+
+	if component.resources == nil {
+		return resource.Quantity{}, false
+	}
+
+	resourceList := component.resources.Requests
+	if limits {
+		resourceList = component.resources.Limits
+	}
+
+	quantity, exists := resourceList[resourceName]
+	return quantity, exists
+}
+
+// checkForLocalAccountsWithoutPassword identifies local accounts defined via '.spec.extraConfig' ('accounts.<name>: login' in 'argocd-cm') which do not have a corresponding password in the 'argocd-secret' Secret ('accounts.<name>.password'). Such accounts cannot be used to log in.
+// - Accounts with only the 'apiKey' capability do not require a password, and so are not reported.
+func checkForLocalAccountsWithoutPassword(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	loginAccounts := []string{}
+
+	for key, value := range argoCD.Spec.ExtraConfig {
+
+		accountName, isAccountKey := strings.CutPrefix(key, "accounts.")
+		if !isAccountKey || strings.HasSuffix(accountName, ".enabled") {
+			continue
+		}
+
+		if argoCD.Spec.ExtraConfig["accounts."+accountName+".enabled"] == "false" {
+			continue
+		}
+
+		for capability := range strings.SplitSeq(value, ",") {
+			if strings.TrimSpace(capability) == "login" {
+				loginAccounts = append(loginAccounts, accountName)
+				break
+			}
+		}
+	}
+
+	if len(loginAccounts) == 0 {
+		return
+	}
+
+	sort.Strings(loginAccounts)
+
+	argoCDSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "argocd-secret",
+			Namespace: argoCD.Namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&argoCDSecret), &argoCDSecret); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(Secret 'argocd-secret' in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to retrieve 'argocd-secret', so local accounts defined in '.spec.extraConfig' could not be verified to have a password: " + err.Error(),
+		})
+		return
+	}
+
+	for _, accountName := range loginAccounts {
+
+		passwordKey := "accounts." + accountName + ".password"
+
+		if len(argoCDSecret.Data[passwordKey]) == 0 {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.extraConfig[accounts." + accountName + "]",
+				message: fmt.Sprintf("Local account '%s' has the 'login' capability, but no password has been set for it: the '%s' key does not exist in Secret 'argocd-secret' in namespace '%s'. The account cannot be used to log in until a password is set (e.g. via 'argocd account update-password --account %s').", accountName, passwordKey, argoCD.Namespace, accountName),
+			})
+		}
+	}
+}
+
+// checkForInsufficientNodeCapacity identifies Argo CD instances whose components (as a whole) request more CPU/memory than is allocatable on the nodes that the components may be scheduled to (based on '.spec.nodePlacement').
+// - Allocatable capacity is an upper bound: it does not account for the resources already requested by other workloads on the nodes. So, if this check reports an issue, the instance certainly cannot be fully scheduled, but the converse is not true.
+func checkForInsufficientNodeCapacity(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	field := "(Nodes of cluster)"
+	if argoCD.Spec.NodePlacement != nil {
+		field = ".spec.nodePlacement"
+	}
+
+	var nodeList corev1.NodeList
+	if err := k8sClient.ListFromAllNamespaces(ctx, &nodeList); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: "Unable to list Nodes, so the total resources requested by ArgoCD components could not be compared against node capacity: " + err.Error(),
+		})
+		return
+	}
+
+	eligibleNodes := []corev1.Node{}
+	for _, node := range nodeList.Items {
+		if nodeIsEligibleForPlacement(node, argoCD.Spec.NodePlacement) {
+			eligibleNodes = append(eligibleNodes, node)
+		}
+	}
+
+	if len(eligibleNodes) == 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: fmt.Sprintf("None of the %d nodes of the cluster are eligible to run ArgoCD component pods (nodes must be schedulable, match the '.spec.nodePlacement' node selector, and have no taints that are not tolerated by '.spec.nodePlacement' tolerations). Argo CD component pods will not be scheduled.", len(nodeList.Items)),
+		})
+		return
+	}
+
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+
+		allocatable := resource.Quantity{}
+		for _, node := range eligibleNodes {
+			if quantity, exists := node.Status.Allocatable[resourceName]; exists {
+				allocatable.Add(quantity)
+			}
+		}
+
+		// Sum the requests of each component, in order, noting the component which causes the total to exceed the allocatable capacity
+		totalRequested := resource.Quantity{}
+		exceedingComponent := ""
+
+		for _, component := range argoCDComponentResources(argoCD) {
+
+			quantity, exists := componentResourceQuantity(component, resourceName, false)
+			if !exists {
+				continue
+			}
+
+			for range component.replicas {
+				totalRequested.Add(quantity)
+			}
+
+			if exceedingComponent == "" && totalRequested.Cmp(allocatable) > 0 {
+				exceedingComponent = component.name
+			}
+		}
+
+		if exceedingComponent != "" {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field,
+				message: fmt.Sprintf("The Argo CD components request a total '%s' of %s, but the total allocatable '%s' of the %d node(s) eligible to run the components is only %s. The '%s' component's requests push the total over the allocatable capacity. Not all Argo CD component pods can be scheduled: reduce component resource requests, or make additional nodes eligible (see '.spec.nodePlacement').", resourceName, totalRequested.String(), resourceName, len(eligibleNodes), allocatable.String(), exceedingComponent),
+			})
+		}
+	}
+}
+
+// nodeIsEligibleForPlacement returns true if pods with the given node placement could be scheduled to the node: the node must be schedulable, have all the labels of the node selector, and have no NoSchedule/NoExecute taints that are not tolerated.
+func nodeIsEligibleForPlacement(node corev1.Node, nodePlacement *v1beta1.ArgoCDNodePlacementSpec) bool {
+
+	if node.Spec.Unschedulable {
+		return false
+	}
+
+	var nodeSelector map[string]string
+	var tolerations []corev1.Toleration
+	if nodePlacement != nil {
+		nodeSelector = nodePlacement.NodeSelector
+		tolerations = nodePlacement.Tolerations
+	}
+
+	for key, value := range nodeSelector {
+		if nodeValue, exists := node.Labels[key]; !exists || nodeValue != value {
+			return false
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkForCachePathsAgainstDeployedContainers identifies cache/temporary path env vars (for example 'KUBECACHEDIR') of the deployed controller, server, and repo server containers, which point to a path that is not writable: the container has 'readOnlyRootFilesystem: true', and the path is not on a writable volume.
+// - Unlike checkForCachePathsOnReadOnlyRootFilesystem, this uses the security context and volumes of the Deployment/StatefulSet that was actually created by the operator, and so the conflict is confirmed rather than likely.
+func checkForCachePathsAgainstDeployedContainers(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, component := range argoCDComponentFilesystems(argoCD) {
+
+		workloadDescription := fmt.Sprintf("%s '%s' in namespace '%s'", component.workloadKind, component.workload.GetName(), argoCD.Namespace)
+
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(component.workload), component.workload); err != nil {
+			if apierrors.IsNotFound(err) {
+				// The workload may not yet have been created by the operator: this is reported by the '.status' check
+				continue
+			}
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   "(" + workloadDescription + ")",
+				message: "Unable to retrieve the " + component.name + " workload, so its cache/temporary paths could not be compared against its security context: " + err.Error(),
+			})
+			continue
+		}
+
+		var podSpec corev1.PodSpec
+		switch workload := component.workload.(type) {
+		case *appsv1.Deployment:
+			podSpec = workload.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			podSpec = workload.Spec.Template.Spec
+		}
+
+		containerIndex := slices.IndexFunc(podSpec.Containers, func(container corev1.Container) bool { return container.Name == component.containerName })
+		if containerIndex == -1 {
+			continue
+		}
+		container := podSpec.Containers[containerIndex]
+
+		if container.SecurityContext == nil || container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
+			continue
+		}
+
+		writableMountPaths := writableMountPathsOfContainer(podSpec.Volumes, container.VolumeMounts)
+
+		for _, envVar := range cachePathsOfEnv(container.Env) {
+
+			if slices.ContainsFunc(writableMountPaths, func(mountPath string) bool { return isPathUnderMountPath(envVar.Value, mountPath) }) {
+				continue
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   component.field + ".env[" + envVar.Name + "]",
+				message: fmt.Sprintf("Env var '%s' points the %s at path '%s', but the '%s' container of %s has 'readOnlyRootFilesystem: true', and the path is not on a writable volume (writable volume mounts: %s). Writes to this path will fail, which may cause the component to crash loop. Use a path under a writable volume, or add a writable volume (e.g. emptyDir) via '%s.volumes' and '%s.volumeMounts'.", envVar.Name, component.name, envVar.Value, component.containerName, workloadDescription, strings.Join(writableMountPaths, ", "), component.field, component.field),
+			})
+		}
+	}
+}
+
+// checkForOrphanedGrafanaResources identifies Grafana resources (Deployment, Service, ConfigMaps, Secret, Route) in the Argo CD namespace that were created by older versions of the operator, while '.spec.grafana' was supported. Grafana support has been removed from the operator, so these resources are no longer managed, and will not be cleaned up by the operator.
+// - A resource is considered a Grafana resource if its name is '(argocd name)-grafana' (or begins with '(argocd name)-grafana-'), or if it has the 'app.kubernetes.io/name: (argocd name)-grafana' label that the operator applied to Grafana resources.
+func checkForOrphanedGrafanaResources(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	grafanaName := argoCD.Name + "-grafana"
+
+	isGrafanaResource := func(obj metav1.Object) bool {
+		return obj.GetName() == grafanaName || strings.HasPrefix(obj.GetName(), grafanaName+"-") || obj.GetLabels()["app.kubernetes.io/name"] == grafanaName
+	}
+
+	resourceTypes := []struct {
+		kind string
+		list client.ObjectList
+	}{
+		{kind: "Deployment", list: &appsv1.DeploymentList{}},
+		{kind: "Service", list: &corev1.ServiceList{}},
+		{kind: "ConfigMap", list: &corev1.ConfigMapList{}},
+		{kind: "Secret", list: &corev1.SecretList{}},
+		{kind: "Route", list: &routev1.RouteList{}},
+	}
+
+	orphanedResources := []string{}
+
+	for _, resourceType := range resourceTypes {
+
+		if err := k8sClient.ListFromSingleNamespace(ctx, resourceType.list, argoCD.Namespace); err != nil {
+			if resourceType.kind == "Route" && clients.IsResourceTypeNotKnownError(err) {
+				// Routes are only available on OpenShift
+				continue
+			}
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   "(" + resourceType.kind + "s in namespace '" + argoCD.Namespace + "')",
+				message: "Unable to list " + resourceType.kind + "s, so they could not be checked for orphaned Grafana resources: " + err.Error(),
+			})
+			continue
+		}
+
+		items, err := meta.ExtractList(resourceType.list)
+		if err != nil {
+			continue
+		}
+
+		for _, item := range items {
+			obj, ok := item.(metav1.Object)
+			if ok && isGrafanaResource(obj) {
+				orphanedResources = append(orphanedResources, resourceType.kind+" '"+obj.GetName()+"'")
+			}
+		}
+	}
+
+	if len(orphanedResources) == 0 {
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   "(Grafana resources in namespace '" + argoCD.Namespace + "')",
+		message: fmt.Sprintf("Orphaned Grafana resources were found in namespace '%s': %s. Grafana support ('.spec.grafana') has been removed from the operator, so these resources are no longer managed by the operator, and will NOT be removed by it. If they are no longer used, they should be deleted manually.", argoCD.Namespace, strings.Join(orphanedResources, ", ")),
+	})
+}
+
+// webhookSecretKeys are the keys of the 'argocd-secret' Secret which contain the shared secret used to authenticate webhook events, for each Git provider
+var webhookSecretKeys = []string{"webhook.github.secret", "webhook.gitlab.secret", "webhook.bitbucket.uuid", "webhook.bitbucketserver.secret", "webhook.gogs.secret", "webhook.azuredevops.username", "webhook.azuredevops.password"}
+
+// checkForUnauthenticatedApplicationSetWebhook identifies instances where the ApplicationSet webhook server is exposed outside of the cluster (via '.spec.applicationSet.webhookServer' Route/Ingress), but no webhook secret is configured in 'argocd-secret'. Without a webhook secret, webhook events are not authenticated, and so anyone who can reach the endpoint can trigger them.
+func checkForUnauthenticatedApplicationSetWebhook(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if argoCD.Spec.ApplicationSet == nil || isExplicitlyDisabled(argoCD.Spec.ApplicationSet.Enabled) {
+		return
+	}
+
+	webhookServer := argoCD.Spec.ApplicationSet.WebhookServer
+
+	exposures := []string{}
+	if webhookServer.Route.Enabled {
+		exposures = append(exposures, "Route")
+	}
+	if webhookServer.Ingress.Enabled {
+		exposures = append(exposures, "Ingress")
+	}
+
+	if len(exposures) == 0 {
+		return
+	}
+
+	argoCDSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "argocd-secret",
+			Namespace: argoCD.Namespace,
+		},
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&argoCDSecret), &argoCDSecret); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(Secret 'argocd-secret' in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to retrieve 'argocd-secret', so the exposed ApplicationSet webhook server could not be verified to have a webhook secret: " + err.Error(),
+		})
+		return
+	}
+
+	for _, key := range webhookSecretKeys {
+		if len(argoCDSecret.Data[key]) > 0 {
+			return
+		}
+	}
+
+	// Correlate with the webhook Route created by the operator, to report where the webhook is exposed
+	exposedAt := ""
+	if webhookServer.Route.Enabled {
+		route := routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      argoCD.Name + "-" + common.ApplicationSetControllerWebhookSuffix,
+				Namespace: argoCD.Namespace,
+			},
+		}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&route), &route); err == nil && route.Spec.Host != "" {
+			exposedAt = fmt.Sprintf(" (Route '%s' exposes the webhook at host '%s')", route.Name, route.Spec.Host)
+		}
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   ".spec.applicationSet.webhookServer",
+		message: fmt.Sprintf("The ApplicationSet webhook server is exposed outside of the cluster via %s%s, but no webhook secret is configured in Secret 'argocd-secret' (none of: %s). Without a webhook secret, webhook events are not authenticated: anyone who can reach the endpoint can trigger ApplicationSet reconciliation (and thus requests to Git providers), which may be abused for denial of service or server-side request forgery. Configure the webhook secret of your Git provider in 'argocd-secret' (and in the webhook configuration of the Git provider), or disable the Route/Ingress if the webhook is not used.", strings.Join(exposures, " and "), exposedAt, strings.Join(webhookSecretKeys, ", ")),
+	})
+}
+
+// checkForIdleControllerShards identifies instances with more (static) application controller shards than there are clusters for the instance to manage. Clusters are assigned to shards, so each shard beyond the number of clusters has no clusters to manage, and idles.
+// - Managed clusters are the cluster Secrets ('argocd.argoproj.io/secret-type: cluster') in the Argo CD namespace, plus the in-cluster cluster (unless it is disabled, or already defined by a cluster Secret).
+// - Dynamic scaling is not reported: in that case the number of shards is computed from the number of clusters.
+func checkForIdleControllerShards(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	sharding := argoCD.Spec.Controller.Sharding
+
+	if !argoCD.Spec.Controller.IsEnabled() || !sharding.Enabled || sharding.Replicas <= 1 {
+		return
+	}
+
+	if sharding.DynamicScalingEnabled != nil && *sharding.DynamicScalingEnabled {
+		return
+	}
+
+	var secretList corev1.SecretList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &secretList, argoCD.Namespace); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(cluster Secrets in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to list Secrets, so the number of controller shards could not be compared against the number of managed clusters: " + err.Error(),
+		})
+		return
+	}
+
+	clusterCount := 0
+	inClusterDefinedBySecret := false
+	for _, secret := range secretList.Items {
+		if secret.Labels[common.ArgoCDSecretTypeLabel] != argocdcommon.LabelValueSecretTypeCluster {
+			continue
+		}
+		clusterCount++
+		if strings.TrimSuffix(string(secret.Data["server"]), "/") == common.ArgoCDDefaultServer {
+			inClusterDefinedBySecret = true
+		}
+	}
+
+	inClusterEnabled := true
+	if entry := effectiveArgoCDCMEntry(argoCD, "cluster.inClusterEnabled"); entry != nil && entry.value == "false" {
+		inClusterEnabled = false
+	}
+
+	clusterDescription := fmt.Sprintf("%d cluster Secret(s)", clusterCount)
+	if inClusterEnabled && !inClusterDefinedBySecret {
+		clusterCount++
+		clusterDescription += ", plus the in-cluster cluster"
+	}
+
+	if int(sharding.Replicas) <= clusterCount {
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   ".spec.controller.sharding.replicas",
+		message: fmt.Sprintf("The application controller is configured with %d shards, but this Argo CD instance manages only %d cluster(s) (%s in namespace '%s'). Clusters are assigned to shards, so %d shard(s) have no clusters to manage, and only consume resources. Consider reducing '.spec.controller.sharding.replicas' to %d, or enabling dynamic scaling ('.spec.controller.sharding.dynamicScalingEnabled').", sharding.Replicas, clusterCount, clusterDescription, argoCD.Namespace, int(sharding.Replicas)-clusterCount, max(clusterCount, 1)),
+	})
+}
+
+// objectReference is a reference from a field of the ArgoCD CR to a Secret or ConfigMap in the Argo CD namespace
+type objectReference struct {
+	// kind is either 'Secret' or 'ConfigMap'
+	kind string
+
+	name string
+
+	// field is the ArgoCD CR field which references the object
+	field string
+}
+
+// dexSecretReferenceRegex matches '$<secret name>:<key>' references in the Dex/OIDC configuration, which are resolved by Argo CD from the named Secret. ('$<key>' references, without a Secret name, are resolved from 'argocd-secret'.)
+var dexSecretReferenceRegex = regexp.MustCompile(`\$([a-z0-9]([-a-z0-9.]*[a-z0-9])?):[-._a-zA-Z0-9]+`)
+
+// referencedSecretsAndConfigMaps returns the Secrets and ConfigMaps which are referenced by the ArgoCD CR.
+// - Optional references (e.g. volumes and 'valueFrom' environment variables with 'optional: true') are not included, since the component starts without them.
+// - Secrets/ConfigMaps which are generated by the operator when not specified (e.g. the Argo CD agent TLS Secrets) are only included when they are explicitly named in the CR.
+func referencedSecretsAndConfigMaps(argoCD v1beta1.ArgoCD) []objectReference {
+
+	res := []objectReference{}
+
+	for _, componentEnv := range argoCDComponentEnvs(argoCD) {
+		for _, envVar := range componentEnv.env {
+			if envVar.ValueFrom == nil {
+				continue
+			}
+			field := componentEnv.field + "[" + envVar.Name + "]"
+			if ref := envVar.ValueFrom.SecretKeyRef; ref != nil && (ref.Optional == nil || !*ref.Optional) {
+				res = append(res, objectReference{kind: "Secret", name: ref.Name, field: field})
+			}
+			if ref := envVar.ValueFrom.ConfigMapKeyRef; ref != nil && (ref.Optional == nil || !*ref.Optional) {
+				res = append(res, objectReference{kind: "ConfigMap", name: ref.Name, field: field})
+			}
+		}
+	}
+
+	componentVolumes := []struct {
+		field   string
+		volumes []corev1.Volume
+	}{
+		{field: ".spec.controller.volumes", volumes: argoCD.Spec.Controller.Volumes},
+		{field: ".spec.repo.volumes", volumes: argoCD.Spec.Repo.Volumes},
+		{field: ".spec.server.volumes", volumes: argoCD.Spec.Server.Volumes},
+	}
+	if argoCD.Spec.ApplicationSet != nil {
+		componentVolumes = append(componentVolumes, struct {
+			field   string
+			volumes []corev1.Volume
+		}{field: ".spec.applicationSet.volumes", volumes: argoCD.Spec.ApplicationSet.Volumes})
+	}
+	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil {
+		componentVolumes = append(componentVolumes, struct {
+			field   string
+			volumes []corev1.Volume
+		}{field: ".spec.sso.dex.volumes", volumes: argoCD.Spec.SSO.Dex.Volumes})
+	}
+
+	for _, component := range componentVolumes {
+		for _, volume := range component.volumes {
+			field := component.field + "[" + volume.Name + "]"
+			if source := volume.Secret; source != nil && (source.Optional == nil || !*source.Optional) {
+				res = append(res, objectReference{kind: "Secret", name: source.SecretName, field: field})
+			}
+			if source := volume.ConfigMap; source != nil && (source.Optional == nil || !*source.Optional) {
+				res = append(res, objectReference{kind: "ConfigMap", name: source.Name, field: field})
+			}
+		}
+	}
+
+	if argoCD.Spec.ApplicationSet != nil && argoCD.Spec.ApplicationSet.SCMRootCAConfigMap != "" {
+		res = append(res, objectReference{kind: "ConfigMap", name: argoCD.Spec.ApplicationSet.SCMRootCAConfigMap, field: ".spec.applicationSet.scmRootCAConfigMap"})
+	}
+
+	if route := argoCD.Spec.Server.Route; route.Enabled && route.TLS != nil && route.TLS.ExternalCertificate != nil && route.TLS.ExternalCertificate.Name != "" {
+		res = append(res, objectReference{kind: "Secret", name: route.TLS.ExternalCertificate.Name, field: ".spec.server.route.tls.externalCertificate.name"})
+	}
+
+	if agentSpec := argoCD.Spec.ArgoCDAgent; agentSpec != nil {
+
+		if principal := agentSpec.Principal; principal != nil && principal.IsEnabled() {
+			if tls := principal.TLS; tls != nil && (tls.InsecureGenerate == nil || !*tls.InsecureGenerate) {
+				res = appendSecretReferenceIfNamed(res, tls.SecretName, ".spec.argoCDAgent.principal.tls.secretName")
+				res = appendSecretReferenceIfNamed(res, tls.RootCASecretName, ".spec.argoCDAgent.principal.tls.rootCASecretName")
+			}
+			if resourceProxy := principal.ResourceProxy; resourceProxy != nil {
+				res = appendSecretReferenceIfNamed(res, resourceProxy.SecretName, ".spec.argoCDAgent.principal.resourceProxy.secretName")
+				res = appendSecretReferenceIfNamed(res, resourceProxy.CASecretName, ".spec.argoCDAgent.principal.resourceProxy.caSecretName")
+			}
+			if jwt := principal.JWT; jwt != nil && (jwt.InsecureGenerate == nil || !*jwt.InsecureGenerate) {
+				res = appendSecretReferenceIfNamed(res, jwt.SecretName, ".spec.argoCDAgent.principal.jwt.secretName")
+			}
+		}
+
+		if agent := agentSpec.Agent; agent != nil && agent.IsEnabled() && agent.TLS != nil {
+			res = appendSecretReferenceIfNamed(res, agent.TLS.SecretName, ".spec.argoCDAgent.agent.tls.secretName")
+			res = appendSecretReferenceIfNamed(res, agent.TLS.RootCASecretName, ".spec.argoCDAgent.agent.tls.rootCASecretName")
+		}
+	}
+
+	ssoConfigs := []struct {
+		field  string
+		config string
+	}{
+		{field: ".spec.oidcConfig", config: argoCD.Spec.OIDCConfig},
+	}
+	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil {
+		ssoConfigs = append(ssoConfigs, struct {
+			field  string
+			config string
+		}{field: ".spec.sso.dex.config", config: argoCD.Spec.SSO.Dex.Config})
+	}
+
+	for _, ssoConfig := range ssoConfigs {
+		for _, match := range dexSecretReferenceRegex.FindAllStringSubmatch(ssoConfig.config, -1) {
+			res = append(res, objectReference{kind: "Secret", name: match[1], field: ssoConfig.field + ": " + match[0]})
+		}
+	}
+
+	return res
+}
+
+// appendSecretReferenceIfNamed appends a reference to Secret 'name', if the name is non-empty
+func appendSecretReferenceIfNamed(references []objectReference, name string, field string) []objectReference {
+	if name == "" {
+		return references
+	}
+	return append(references, objectReference{kind: "Secret", name: name, field: field})
+}
+
+// checkForMissingReferencedSecretsAndConfigMaps identifies Secrets and ConfigMaps which are referenced by the ArgoCD CR (see referencedSecretsAndConfigMaps), but which do not exist in the Argo CD namespace.
+// - This check is opt-in ('--check-secrets'), since it requires read access to Secrets.
+// - When the cluster data is incomplete (e.g. must-gather), a missing object may simply not have been exported (Secrets are usually excluded from must-gathers), so these are reported as warnings rather than errors.
+func checkForMissingReferencedSecretsAndConfigMaps(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	incompleteData := k8sClient.IncompleteControlPlaneData()
+
+	// The result of retrieving each object (by kind and name), since a single object may be referenced by multiple fields
+	retrievalErrors := map[string]error{}
+
+	for _, reference := range referencedSecretsAndConfigMaps(argoCD) {
+
+		key := reference.kind + "/" + reference.name
+
+		err, retrieved := retrievalErrors[key]
+		if !retrieved {
+			var obj client.Object = &corev1.Secret{}
+			if reference.kind == "ConfigMap" {
+				obj = &corev1.ConfigMap{}
+			}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: argoCD.Namespace, Name: reference.name}, obj)
+			retrievalErrors[key] = err
+		}
+
+		if err == nil {
+			continue
+		}
+
+		switch {
+		case incompleteData:
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   reference.field,
+				message: fmt.Sprintf("%s '%s' is referenced by '%s', but could not be found in namespace '%s'. The data being analyzed (e.g. must-gather) may not include this %s (Secrets, in particular, are usually not exported), so verify that it exists on the cluster. If it does not exist, the component which references it will fail to start (or the feature which uses it will not work).", reference.kind, reference.name, reference.field, argoCD.Namespace, reference.kind),
+			})
+
+		case apierrors.IsNotFound(err):
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   reference.field,
+				message: fmt.Sprintf("%s '%s' is referenced by '%s', but does not exist in namespace '%s'. The component which references it will fail to start (or the feature which uses it will not work). Create the %s, or remove the reference.", reference.kind, reference.name, reference.field, argoCD.Namespace, reference.kind),
+			})
+
+		default:
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   reference.field,
+				message: fmt.Sprintf("Unable to retrieve %s '%s' (referenced by '%s') from namespace '%s', so it could not be verified to exist: %v", reference.kind, reference.name, reference.field, argoCD.Namespace, err),
+			})
+		}
+	}
+}
+
+// checkForComponentsRejectedBySCCs identifies (on OpenShift) Argo CD component workloads whose pods would not be admitted by any of the SecurityContextConstraints that the workload's service account is allowed to use. In that case, the pods of the component are never created.
+// - An SCC may be used by a service account if the SCC lists the service account (or one of its groups) in '.users'/'.groups', or if RBAC grants the 'use' verb on the SCC (see sccAccess).
+// - The pod spec is compared against only the most common SCC constraints (see sccRejectionReasons), so this does not replace the SCC admission plugin: it catches the common causes of rejection early.
+// - This check is skipped when SCCs are not a known resource type (i.e. the cluster is not OpenShift).
+func checkForComponentsRejectedBySCCs(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	var sccList securityv1.SecurityContextConstraintsList
+	if err := k8sClient.ListFromAllNamespaces(ctx, &sccList); err != nil {
+		if clients.IsResourceTypeNotKnownError(err) {
+			return
+		}
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(SecurityContextConstraints)",
+			message: "Unable to list SecurityContextConstraints, so the Argo CD components could not be verified to be admitted by them: " + err.Error(),
+		})
+		return
+	}
+
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: argoCD.Namespace}}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(Namespace '" + argoCD.Namespace + "')",
+			message: "Unable to retrieve the Argo CD namespace, so the Argo CD components could not be verified to be admitted by SecurityContextConstraints: " + err.Error(),
+		})
+		return
+	}
+
+	uidRange := namespace.Annotations[securityv1.UIDRangeAnnotation]
+	if uidRange == "" {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(annotations of Namespace '" + argoCD.Namespace + "')",
+			message: fmt.Sprintf("Namespace '%s' does not have the '%s' annotation, which is normally added by OpenShift when the namespace is created. SecurityContextConstraints which allocate a UID from the namespace range (for example, 'restricted-v2') cannot admit pods in this namespace, so Argo CD component pods may fail to be created with 'unable to validate against any security context constraint'. Check whether the annotation was removed, or whether the namespace was created in an unusual way.", argoCD.Namespace, securityv1.UIDRangeAnnotation),
+		})
+	}
+
+	access, err := readSCCAccess(ctx, k8sClient, argoCD.Namespace)
+	if err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(RBAC in namespace '" + argoCD.Namespace + "')",
+			message: "Unable to read the (Cluster)Roles/(Cluster)RoleBindings which grant access to SecurityContextConstraints, so the Argo CD components could not be verified to be admitted by them: " + err.Error(),
+		})
+		return
+	}
+
+	for _, component := range argoCDComponentFilesystems(argoCD) {
+
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(component.workload), component.workload); err != nil {
+			// A workload which cannot be retrieved is reported by the '.status' check (if missing), and by checkForCachePathsAgainstDeployedContainers (otherwise)
+			continue
+		}
+
+		var podSpec corev1.PodSpec
+		switch workload := component.workload.(type) {
+		case *appsv1.Deployment:
+			podSpec = workload.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			podSpec = workload.Spec.Template.Spec
+		}
+
+		serviceAccount := podSpec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+
+		field := fmt.Sprintf("(ServiceAccount '%s' of %s '%s' in namespace '%s')", serviceAccount, component.workloadKind, component.workload.GetName(), argoCD.Namespace)
+
+		usableSCCs := access.sccsUsableByServiceAccount(sccList.Items, argoCD.Namespace, serviceAccount)
+		if len(usableSCCs) == 0 {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field,
+				message: fmt.Sprintf("ServiceAccount '%s', which is used by the %s, is not allowed to use any SecurityContextConstraints (neither via the '.users'/'.groups' of an SCC, nor via RBAC 'use' permission), so the pods of the %s cannot be admitted. Grant the service account access to an SCC (by default, all authenticated users may use 'restricted-v2').", serviceAccount, component.name, component.name),
+			})
+			continue
+		}
+
+		rejections := []string{}
+		admitted := false
+		for _, scc := range usableSCCs {
+			reasons := sccRejectionReasons(scc, podSpec, uidRange)
+			if len(reasons) == 0 {
+				admitted = true
+				break
+			}
+			rejections = append(rejections, fmt.Sprintf("'%s' (%s)", scc.Name, strings.Join(reasons, "; ")))
+		}
+
+		if admitted {
+			continue
+		}
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: fmt.Sprintf("The pods of the %s would not be admitted by any of the SecurityContextConstraints that ServiceAccount '%s' may use, so they will fail to be created with 'unable to validate against any security context constraint'. SCCs which reject the pod: %s. Adjust the security context/volumes of the %s ('%s'), or grant the service account access to an SCC which allows them.", component.name, serviceAccount, strings.Join(rejections, ", "), component.name, component.field),
+		})
+	}
+}
+
+// sccAccess is the RBAC data which determines which SecurityContextConstraints may be used by the service accounts of an Argo CD namespace: the 'use' verb on 'securitycontextconstraints' in the 'security.openshift.io' API group.
+type sccAccess struct {
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+
+	// roleBindings are the RoleBindings of the Argo CD namespace
+	roleBindings []rbacv1.RoleBinding
+
+	// clusterRoleRules and roleRules are the rules of each ClusterRole, and of each Role of the Argo CD namespace, by name
+	clusterRoleRules map[string][]rbacv1.PolicyRule
+	roleRules        map[string][]rbacv1.PolicyRule
+}
+
+// readSCCAccess reads the RBAC resources which are used to determine which SecurityContextConstraints may be used by the service accounts of 'namespace'
+func readSCCAccess(ctx context.Context, k8sClient clients.AbstractK8sClient, namespace string) (sccAccess, error) {
+
+	res := sccAccess{clusterRoleRules: map[string][]rbacv1.PolicyRule{}, roleRules: map[string][]rbacv1.PolicyRule{}}
+
+	var clusterRoleBindingList rbacv1.ClusterRoleBindingList
+	if err := k8sClient.ListFromAllNamespaces(ctx, &clusterRoleBindingList); err != nil {
+		return sccAccess{}, err
+	}
+	res.clusterRoleBindings = clusterRoleBindingList.Items
+
+	var roleBindingList rbacv1.RoleBindingList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &roleBindingList, namespace); err != nil {
+		return sccAccess{}, err
+	}
+	res.roleBindings = roleBindingList.Items
+
+	var clusterRoleList rbacv1.ClusterRoleList
+	if err := k8sClient.ListFromAllNamespaces(ctx, &clusterRoleList); err != nil {
+		return sccAccess{}, err
+	}
+	for _, clusterRole := range clusterRoleList.Items {
+		res.clusterRoleRules[clusterRole.Name] = clusterRole.Rules
+	}
+
+	var roleList rbacv1.RoleList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &roleList, namespace); err != nil {
+		return sccAccess{}, err
+	}
+	for _, role := range roleList.Items {
+		res.roleRules[role.Name] = role.Rules
+	}
+
+	return res, nil
+}
+
+// sccsUsableByServiceAccount returns the SCCs (sorted by name) which may be used by the given service account, either via the '.users'/'.groups' of the SCC, or via RBAC.
+// - The groups of a service account are 'system:serviceaccounts', 'system:serviceaccounts:(namespace)', and 'system:authenticated'.
+func (a sccAccess) sccsUsableByServiceAccount(sccs []securityv1.SecurityContextConstraints, namespace string, serviceAccount string) []securityv1.SecurityContextConstraints {
+
+	user := "system:serviceaccount:" + namespace + ":" + serviceAccount
+	groups := []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+
+	subjectsMatch := func(subjects []rbacv1.Subject, bindingNamespace string) bool {
+		return slices.ContainsFunc(subjects, func(subject rbacv1.Subject) bool {
+			switch subject.Kind {
+			case rbacv1.ServiceAccountKind:
+				subjectNamespace := subject.Namespace
+				if subjectNamespace == "" {
+					subjectNamespace = bindingNamespace
+				}
+				return subject.Name == serviceAccount && subjectNamespace == namespace
+			case rbacv1.UserKind:
+				return subject.Name == user
+			case rbacv1.GroupKind:
+				return slices.Contains(groups, subject.Name)
+			default:
+				return false
+			}
+		})
+	}
+
+	// The SCCs which RBAC grants 'use' of, by name. allGrantedByRBAC is true if 'use' is granted for all SCCs (a rule without resource names).
+	grantedByRBAC := map[string]bool{}
+	allGrantedByRBAC := false
+
+	applyRules := func(rules []rbacv1.PolicyRule) {
+		for _, rule := range rules {
+			if !policyRuleAllowsSCCUse(rule) {
+				continue
+			}
+			if len(rule.ResourceNames) == 0 {
+				allGrantedByRBAC = true
+			}
+			for _, name := range rule.ResourceNames {
+				grantedByRBAC[name] = true
+			}
+		}
+	}
+
+	for _, clusterRoleBinding := range a.clusterRoleBindings {
+		if clusterRoleBinding.RoleRef.Kind == "ClusterRole" && subjectsMatch(clusterRoleBinding.Subjects, "") {
+			applyRules(a.clusterRoleRules[clusterRoleBinding.RoleRef.Name])
+		}
+	}
+
+	for _, roleBinding := range a.roleBindings {
+		if !subjectsMatch(roleBinding.Subjects, roleBinding.Namespace) {
+			continue
+		}
+		if roleBinding.RoleRef.Kind == "ClusterRole" {
+			applyRules(a.clusterRoleRules[roleBinding.RoleRef.Name])
+		} else {
+			applyRules(a.roleRules[roleBinding.RoleRef.Name])
+		}
+	}
+
+	res := []securityv1.SecurityContextConstraints{}
+	for _, scc := range sccs {
+		if allGrantedByRBAC || grantedByRBAC[scc.Name] || slices.Contains(scc.Users, user) || slices.ContainsFunc(scc.Groups, func(group string) bool { return slices.Contains(groups, group) }) {
+			res = append(res, scc)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res
+}
+
+// policyRuleAllowsSCCUse returns true if the RBAC rule grants the 'use' verb on SecurityContextConstraints
+func policyRuleAllowsSCCUse(rule rbacv1.PolicyRule) bool {
+	return (slices.Contains(rule.APIGroups, securityv1.GroupName) || slices.Contains(rule.APIGroups, rbacv1.APIGroupAll)) &&
+		(slices.Contains(rule.Resources, "securitycontextconstraints") || slices.Contains(rule.Resources, rbacv1.ResourceAll)) &&
+		(slices.Contains(rule.Verbs, "use") || slices.Contains(rule.Verbs, rbacv1.VerbAll))
+}
+
+// sccRejectionReasons returns the reasons that the SCC would reject a pod with the given spec, or an empty slice if the pod would be admitted.
+// - Only the most common constraints are compared: host namespaces/ports, volume types, privileged containers, added capabilities, privilege escalation, and explicit UIDs.
+// - 'uidRange' is the value of the 'openshift.io/sa.scc.uid-range' annotation of the pod's namespace (e.g. '1000700000/10000'), which is used by 'MustRunAsRange' SCCs that do not specify a range.
+func sccRejectionReasons(scc securityv1.SecurityContextConstraints, podSpec corev1.PodSpec, uidRange string) []string {
+
+	res := []string{}
+
+	if podSpec.HostNetwork && !scc.AllowHostNetwork {
+		res = append(res, "host network is not allowed")
+	}
+	if podSpec.HostPID && !scc.AllowHostPID {
+		res = append(res, "host PID is not allowed")
+	}
+	if podSpec.HostIPC && !scc.AllowHostIPC {
+		res = append(res, "host IPC is not allowed")
+	}
+
+	if !slices.Contains(scc.Volumes, securityv1.FSTypeAll) {
+		for _, volume := range podSpec.Volumes {
+			if volumeType := volumeFSType(volume); volumeType != "" && !slices.Contains(scc.Volumes, volumeType) {
+				res = append(res, fmt.Sprintf("volume '%s' of type '%s' is not allowed", volume.Name, volumeType))
+			}
+		}
+	}
+
+	var podRunAsUser *int64
+	if podSpec.SecurityContext != nil {
+		podRunAsUser = podSpec.SecurityContext.RunAsUser
+	}
+
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+
+		for _, port := range container.Ports {
+			if port.HostPort != 0 && !scc.AllowHostPorts {
+				res = append(res, fmt.Sprintf("container '%s': host port %d is not allowed", container.Name, port.HostPort))
+			}
+		}
+
+		runAsUser := podRunAsUser
+
+		if securityContext := container.SecurityContext; securityContext != nil {
+
+			if securityContext.Privileged != nil && *securityContext.Privileged && !scc.AllowPrivilegedContainer {
+				res = append(res, fmt.Sprintf("container '%s': privileged containers are not allowed", container.Name))
+			}
+
+			if securityContext.AllowPrivilegeEscalation != nil && *securityContext.AllowPrivilegeEscalation && scc.AllowPrivilegeEscalation != nil && !*scc.AllowPrivilegeEscalation {
+				res = append(res, fmt.Sprintf("container '%s': privilege escalation is not allowed", container.Name))
+			}
+
+			if securityContext.Capabilities != nil && !slices.Contains(scc.AllowedCapabilities, "*") {
+				for _, capability := range securityContext.Capabilities.Add {
+					if !slices.Contains(scc.AllowedCapabilities, capability) && !slices.Contains(scc.DefaultAddCapabilities, capability) {
+						res = append(res, fmt.Sprintf("container '%s': capability '%s' is not allowed", container.Name, capability))
+					}
+				}
+			}
+
+			if securityContext.RunAsUser != nil {
+				runAsUser = securityContext.RunAsUser
+			}
+		}
+
+		if runAsUser != nil {
+			if reason := sccRunAsUserRejectionReason(scc.RunAsUser, *runAsUser, uidRange); reason != "" {
+				res = append(res, fmt.Sprintf("container '%s': %s", container.Name, reason))
+			}
+		}
+	}
+
+	return res
+}
+
+// sccRunAsUserRejectionReason returns the reason that the SCC 'runAsUser' strategy would reject a container which explicitly runs as 'uid', or an empty string if the UID is allowed. See sccRejectionReasons for 'uidRange'.
+func sccRunAsUserRejectionReason(strategy securityv1.RunAsUserStrategyOptions, uid int64, uidRange string) string {
+
+	switch strategy.Type {
+	case securityv1.RunAsUserStrategyMustRunAs:
+		if strategy.UID != nil && *strategy.UID != uid {
+			return fmt.Sprintf("UID %d is not the required UID %d", uid, *strategy.UID)
+		}
+
+	case securityv1.RunAsUserStrategyMustRunAsRange:
+		minUID, maxUID := strategy.UIDRangeMin, strategy.UIDRangeMax
+		if minUID == nil || maxUID == nil {
+			rangeMin, rangeMax, ok := parseSCCUIDRange(uidRange)
+			if !ok {
+				return fmt.Sprintf("UID %d cannot be validated, since the namespace has no valid UID range", uid)
+			}
+			minUID, maxUID = &rangeMin, &rangeMax
+		}
+		if uid < *minUID || uid > *maxUID {
+			return fmt.Sprintf("UID %d is not within the allowed range %d-%d", uid, *minUID, *maxUID)
+		}
+
+	case securityv1.RunAsUserStrategyMustRunAsNonRoot:
+		if uid == 0 {
+			return "running as root (UID 0) is not allowed"
+		}
+	}
+
+	return ""
+}
+
+// parseSCCUIDRange parses the value of the 'openshift.io/sa.scc.uid-range' namespace annotation, which is of the form '(first UID)/(size)', into the first and last UIDs of the range.
+func parseSCCUIDRange(value string) (int64, int64, bool) {
+
+	first, size, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	firstUID, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	rangeSize, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || rangeSize < 1 {
+		return 0, 0, false
+	}
+
+	return firstUID, firstUID + rangeSize - 1, true
+}
+
+// volumeFSType returns the SCC volume type (see securityv1.FSType) of the common volume sources, or an empty string for other volume sources.
+func volumeFSType(volume corev1.Volume) securityv1.FSType {
+	switch {
+	case volume.ConfigMap != nil:
+		return securityv1.FSTypeConfigMap
+	case volume.Secret != nil:
+		return securityv1.FSTypeSecret
+	case volume.EmptyDir != nil:
+		return securityv1.FSTypeEmptyDir
+	case volume.Projected != nil:
+		return securityv1.FSProjected
+	case volume.DownwardAPI != nil:
+		return securityv1.FSTypeDownwardAPI
+	case volume.PersistentVolumeClaim != nil:
+		return securityv1.FSTypePersistentVolumeClaim
+	case volume.Ephemeral != nil:
+		return securityv1.FSTypeEphemeral
+	case volume.CSI != nil:
+		return securityv1.FSTypeCSI
+	case volume.HostPath != nil:
+		return securityv1.FSTypeHostPath
+	case volume.NFS != nil:
+		return securityv1.FSTypeNFS
+	default:
+		return ""
+	}
+}
+
+// checkForIneffectiveServerHPA compares server autoscaling ('.spec.server.autoscale') against the HorizontalPodAutoscaler that the operator created for the server, and identifies a HPA which is missing, or which reports that it is unable to scale (for example, because the server's CPU metrics are not available).
+func checkForIneffectiveServerHPA(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if !argoCD.Spec.Server.IsEnabled() || !argoCD.Spec.Server.Autoscale.Enabled {
+		return
+	}
+
+	hpaName := argoCD.Name + "-server"
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: argoCD.Namespace, Name: hpaName}, &hpa); err != nil {
+
+		if apierrors.IsNotFound(err) {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.server.autoscale.enabled",
+				message: fmt.Sprintf("Server autoscaling is enabled, but the HorizontalPodAutoscaler '%s' does not exist in namespace '%s', so the server is not autoscaled. Check the operator logs for reconciliation errors.", hpaName, argoCD.Namespace),
+			})
+			return
+		}
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(HorizontalPodAutoscaler '" + hpaName + "')",
+			message: "Unable to read the server HorizontalPodAutoscaler, so it could not be verified: " + err.Error(),
+		})
+		return
+	}
+
+	for _, condition := range hpa.Status.Conditions {
+
+		if condition.Status != corev1.ConditionFalse || (condition.Type != autoscalingv2.ScalingActive && condition.Type != autoscalingv2.AbleToScale) {
+			continue
+		}
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.server.autoscale.hpa",
+			message: fmt.Sprintf("The server HorizontalPodAutoscaler '%s' reports that it is unable to scale (condition '%s' is False, reason '%s': %s). Until this is resolved, the server is not autoscaled.", hpaName, condition.Type, condition.Reason, condition.Message),
+		})
+	}
+}
+
+// gitOpsConsolePluginName is the name of the ConsolePlugin that the OpenShift GitOps operator creates, which adds the GitOps pages (e.g. 'Environments') to the OpenShift console
+const gitOpsConsolePluginName = "gitops-plugin"
+
+// checkGitOpsConsolePlugin verifies that the OpenShift GitOps console plugin is wired up, when it is enabled in the console operator configuration (the 'consoles.operator.openshift.io' 'cluster' resource): that the ConsolePlugin it refers to exists. If it does not, the GitOps pages are silently absent from the console, which users usually only notice when looking for them.
+// - An Info entry reports the plugin name and its enabled state. A plugin which is not enabled is not checked further, since the plugin may be deliberately disabled.
+// - Nothing is reported on clusters without the OpenShift console APIs (e.g. non-OpenShift clusters), where there is no console to integrate with.
+// - The console resources are cluster-scoped, which users with namespace-scoped permissions cannot read, so a Forbidden error is reported as Info, rather than as a Warn.
+func checkGitOpsConsolePlugin(ctx context.Context, k8sClient clients.AbstractK8sClient) []entry {
+
+	var consoleOperatorConfig operatorv1.Console
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, &consoleOperatorConfig); err != nil {
+
+		// The console is not installed (e.g. a non-OpenShift cluster, or the console capability is disabled), so there is nothing to enable the plugin in
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		level := LogLevel_Warn
+		if apierrors.IsForbidden(err) {
+			level = LogLevel_Info
+		}
+
+		return []entry{{
+			level:   level,
+			message: fmt.Sprintf("Unable to read the console operator configuration ('consoles.operator.openshift.io' 'cluster'), so the OpenShift GitOps console plugin ('%s') was not checked. Error: %s", gitOpsConsolePluginName, err.Error()),
+		}}
+	}
+
+	if !slices.Contains(consoleOperatorConfig.Spec.Plugins, gitOpsConsolePluginName) {
+		return []entry{{
+			level:   LogLevel_Info,
+			message: fmt.Sprintf("The OpenShift GitOps console plugin ('%s') is not enabled in the console operator configuration ('.spec.plugins' of 'consoles.operator.openshift.io' 'cluster'), so the GitOps pages (e.g. 'Environments') are not available in the OpenShift console. To use them, enable the plugin, e.g. via 'Console plugins' in the console operator details page.", gitOpsConsolePluginName),
+		}}
+	}
+
+	var consolePlugin consolev1.ConsolePlugin
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: gitOpsConsolePluginName}, &consolePlugin); err != nil {
+
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return []entry{{
+				level:   LogLevel_Warn,
+				message: fmt.Sprintf("The OpenShift GitOps console plugin ('%s') is enabled in the console operator configuration, but the ConsolePlugin '%s' does not exist, so the GitOps pages (e.g. 'Environments') are not available in the OpenShift console. The operator creates this ConsolePlugin: check the operator logs for errors in reconciling it.", gitOpsConsolePluginName, gitOpsConsolePluginName),
+			}}
+		}
+
+		level := LogLevel_Warn
+		if apierrors.IsForbidden(err) {
+			level = LogLevel_Info
+		}
+
+		return []entry{{
+			level:   level,
+			message: fmt.Sprintf("The OpenShift GitOps console plugin ('%s') is enabled in the console operator configuration, but ConsolePlugin '%s' could not be read, so whether it exists was not checked. Error: %s", gitOpsConsolePluginName, gitOpsConsolePluginName, err.Error()),
+		}}
+	}
+
+	return []entry{{
+		level:   LogLevel_Info,
+		message: fmt.Sprintf("The OpenShift GitOps console plugin ('%s') is enabled in the console operator configuration.", gitOpsConsolePluginName),
+	}}
+}
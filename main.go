@@ -1,100 +1,659 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/v3/util/glob"
+	"github.com/argoproj/argo-cd/v3/util/settings"
 	semver "github.com/blang/semver/v4"
 	"github.com/fatih/color"
+	"github.com/jgwest/argocd-config-check/checks"
 	"github.com/jgwest/argocd-config-check/clients"
+	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 func main() {
 
+	flags := flag.NewFlagSet("argocd-config-check", flag.ContinueOnError)
+	quiet := flags.Bool("quiet", false, "Suppress progress output while cluster/must-gather data is being retrieved")
+	includeApplications := flags.Bool("include-applications", false, "Also summarize the sync status of the Argo CD Applications managed by each ArgoCD instance")
+	includeEvents := flags.Bool("include-events", false, fmt.Sprintf("Also list the recent Warning events (for example 'FailedScheduling', 'BackOff', 'Unhealthy') in the namespace of each ArgoCD instance, at most %d per instance", maxReportedEvents))
+	eventsWindow := flags.Duration("events-window", time.Hour, "With --include-events, how far back to list Warning events. For a must-gather or manifest, this is measured back from the most recent event.")
+	showNonDefault := flags.Bool("show-nondefault", false, "Also list the commonly tuned ArgoCD CR fields (resources, replicas, processors, sharding, etc.) which are set to values other than the operator defaults, grouped by component. This is informational, and does not affect the reported issues.")
+	showCoverage := flags.Bool("show-coverage", false, "Also list, for each ArgoCD instance, each check and whether it ran, was not applicable (e.g. Dex checks when Dex is not configured), or was skipped (e.g. live cluster only checks, when analyzing a must-gather), so that a result with no issues can be trusted. This is informational, and does not affect the reported issues.")
+	verbose := flags.Bool("verbose", false, "Output additional detail (for example, the names of Applications in each category when used with --include-applications)")
+	configMapDump := flags.Bool("config-map-dump", false, "Output the effective 'argocd-cm'/'argocd-cmd-params-cm' values computed from each ArgoCD CR, instead of running checks")
+	failOn := flags.String("fail-on", "", fmt.Sprintf("Exit with status code %d if any issue has the given severity, or a more severe one. One of: warn, error, fatal", exitCode_IssuesFound))
+	failOnUnsupported := flags.Bool("fail-on-unsupported", false, fmt.Sprintf("Exit with status code %d if any issue is an unsupported configuration, regardless of severity", exitCode_UnsupportedConfiguration))
+	onlyUnsupported := flags.Bool("only-unsupported", false, "Only report issues that are unsupported configurations")
+	namespace := flags.String("namespace", "", "Only read resources from the given namespace, rather than from all namespaces. Useful for users without cluster-wide read access.")
+	outputFormatFlag := flags.String("output", string(outputFormat_Text), "Output format for reported issues. One of: text, text-compact, table, json, github, teamcity, csv. 'text-compact' outputs one line per issue ('<severity> <namespace>/<name> <field>: <message>'), for grep and long logs. 'table' outputs a compact table sorted by severity. 'json' outputs a single JSON document to stdout (status messages are written to stderr): when checking multiple clusters, the document is of kind 'multiClusterResults', with the results of each cluster in 'clusters' (see '--format-version'). 'github' outputs GitHub Actions workflow commands, which annotate the workflow run. 'teamcity' outputs TeamCity service messages: Fatal issues are reported as build problems, and all other issues as inspections. 'csv' outputs one row per issue (with a header row), for triage in a spreadsheet.")
+	groupByFlag := flags.String("group-by", string(groupBy_Instance), "How reported issues are grouped (with '--output text', 'text-compact', or 'table'). One of: instance, rule. 'rule' reports each rule once, across all ArgoCD instances, with the number and list of affected instances, sorted by the number of affected instances.")
+	formatVersion := flags.Int("format-version", 0, fmt.Sprintf("The schema version of the '--output json' document that is expected by the consumer. The tool fails if the document it produces has a different version: %d when checking a single cluster, %d when checking multiple clusters. 0 (the default) is not checked.", jsonSchemaVersion, jsonMultiClusterSchemaVersion))
+	noColor := flags.Bool("no-color", false, "Disable colored output")
+	outputFile := flags.String("output-file", "", "Write the output to the given file, rather than to stdout. The file is only replaced once the run has completed successfully.")
+	explain := flags.String("explain", "", "Output a detailed description of the check with the given rule ID (e.g. 'ACC001'): what it looks for, why it matters, and how to fix it")
+	operatorVersionFlag := flags.String("argocd-operator-version", "", "The version of the OpenShift GitOps operator (e.g. '1.12.0') to use for version-aware checks, rather than discovering it from the Subscription/CSV. Useful when analyzing data that does not include the operator installation.")
+	profileFlag := flags.String("profile", "", "Report tuning recommendations where the ArgoCD CR settings are below the expectations of the given workload size profile. One of: small, medium, large. Run with '--explain ACC024' for the expectations of each profile.")
+	topologyFormat := flags.String("topology", "", "Output the relationships between namespaces and Argo CD instances (which namespaces are managed by which instance, via each managed-by label, and which instances are cluster-scoped) in the given format, instead of running checks. One of: json")
+	maxParallel := flags.Int("max-parallel", runtime.NumCPU(), "The maximum number of ArgoCD instances whose CR is checked concurrently. This applies only to checks of the ArgoCD CR itself: resources are still read from the cluster/must-gather one instance at a time.")
+	manifestPath := flags.String("manifest", "", "Check the ArgoCD CRs in the given manifest file (or directory of manifest files, or '-' for stdin), rather than on a cluster or in a must-gather. For example, the output of 'helm template' or 'kustomize build'.")
+	inputFormat := flags.String("input-format", string(clients.ManifestInputFormat_Auto), "The format of the '--manifest' files. One of: auto, yaml, json. Multi-document YAML, JSON arrays, and List-wrapped documents are supported.")
+	minScore := flags.Int("min-score", 0, fmt.Sprintf("Exit with status code %d if the score (0-100) of any ArgoCD instance is less than the given value. Scoring: %s", exitCode_ScoreBelowMinimum, scoreFormulaDescription))
+	checkSecrets := flags.Bool("check-secrets", false, "Also verify that the Secrets and ConfigMaps referenced by each ArgoCD CR exist (requires read access to Secrets). Missing objects are reported as errors on a live cluster, and as warnings for a must-gather or manifest, which may not include them.")
+	validateSchema := flags.Bool("validate-schema", false, "Also validate each ArgoCD CR against the embedded ArgoCD CRD schema, and report each structural violation (unknown fields, values of the wrong type, values which are not allowed) as an error. Most useful with '--manifest', for CRs which have not yet been applied to a cluster.")
+	versionFlag := flags.Bool("version", false, "Output the version and build information of the tool (and the versions of the embedded Argo CD/operator APIs), and exit")
+	selfTest := flags.Bool("self-test", false, "Run all checks against built-in fixture ArgoCD CRs and verify the expected issues are reported. Does not require cluster or must-gather access.")
+	allowlistImages := flags.String("allowlist-images", "", "A comma-separated list of registry prefixes (ending in '/', e.g. 'mirror.example.com/openshift-gitops/') or exact images, which are organization-approved mirrors of the official images. Custom images which match the list are reported as Info, rather than as unsupported. This is intended for mirrored/air-gapped environments: only allowlist mirrors of the official images.")
+	waitForAvailable := flags.Bool("wait-for-available", false, fmt.Sprintf("Wait until each ArgoCD instance on the live cluster is available ('.status.phase' is 'Available', the CR is reconciled, and each enabled component is running), instead of running checks. Exits with status code %d if this does not happen within '--timeout'. Useful as a gate in install scripts.", exitCode_WaitTimedOut))
+	waitTimeout := flags.Duration("timeout", 5*time.Minute, "With --wait-for-available, the maximum duration to wait for the ArgoCD instance(s) to become available")
+	diffAgainstLive := flags.Bool("diff-against-live", false, "With '--manifest', compare each proposed ArgoCD CR against the ArgoCD CR of the same namespace/name on the live cluster (of the current kubeconfig context, or of a single '--kubeconfig'/'--contexts' cluster), and report the changes to the CR, and the findings that the change would introduce or resolve. '--fail-on' and '--fail-on-unsupported' apply only to the introduced findings, for use as a pre-merge gate.")
+	contextsFlag := flags.String("contexts", "", fmt.Sprintf("Check the clusters of the given comma-separated list of kubeconfig contexts, rather than only the cluster of the current context. With multiple clusters, results are grouped by cluster, and a cluster which cannot be checked is skipped (exit status code %d).", exitCode_ClusterCheckFailed))
+	kubeConfigPaths := []string{}
+	flags.Func("kubeconfig", "Read the cluster configuration from the given kubeconfig file, rather than from the default location. May be specified multiple times to check the cluster of each file, in which case results are grouped by cluster.", func(value string) error {
+		if value == "" {
+			return fmt.Errorf("must not be empty")
+		}
+		kubeConfigPaths = append(kubeConfigPaths, value)
+		return nil
+	})
+	kubeConfigDataFlag := flags.String("kubeconfig-data", "", fmt.Sprintf("Read the cluster configuration from the given base64-encoded kubeconfig content, rather than from a kubeconfig file, e.g. in CI systems which provide the kubeconfig as a secret. The kubeconfig is not written to disk. Since command line arguments may be visible to other processes, prefer setting this via its env var (e.g. '%s=$(base64 -w0 kubeconfig)'). May not be specified with '--kubeconfig'.", flagEnvVarName("kubeconfig-data")))
+
+	flags.Usage = func() {
+		outputUsage(flags)
+	}
+
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		// '--help' (or '-h') is not an error: the usage has already been output by the flag set
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		failWithError("unable to parse arguments", err)
+	}
+
+	if err := applyFlagEnvVars(flags); err != nil {
+		failWithError("unable to parse options from env vars", err)
+	}
+
+	if *noColor {
+		color.NoColor = true
+	}
+
+	if *versionFlag {
+		outputVersion()
+		return
+	}
+
+	selectedOutputFormat, err := parseOutputFormat(*outputFormatFlag)
+	if err != nil {
+		failWithError("invalid '--output' value", err)
+	}
+
+	selectedGroupBy, err := parseGroupBy(*groupByFlag)
+	if err != nil {
+		failWithError("invalid '--group-by' value", err)
+	}
+	if selectedGroupBy == groupBy_Rule && selectedOutputFormat.isMachineReadable() {
+		failWithError(fmt.Sprintf("'--group-by %s' may only be used with '--output text', 'text-compact', or 'table'", groupBy_Rule), nil)
+	}
+
+	var failOnLevel LogLevel
+	if *failOn != "" {
+		failOnLevel, err = parseFailOnLevel(*failOn)
+		if err != nil {
+			failWithError("invalid '--fail-on' value", err)
+		}
+	}
+
+	var operatorVersionOverride *semver.Version
+	if *operatorVersionFlag != "" {
+		version, err := semver.Parse(strings.TrimPrefix(*operatorVersionFlag, "v"))
+		if err != nil {
+			failWithError("invalid '--argocd-operator-version' value '"+*operatorVersionFlag+"': expected a semantic version, e.g. '1.12.0'", err)
+		}
+		operatorVersionOverride = &version
+	}
+
+	if *profileFlag != "" {
+		if _, err := findSizingProfile(*profileFlag); err != nil {
+			failWithError("invalid '--profile' value", err)
+		}
+	}
+
+	imageAllowlist, err := parseImageAllowlistFlag(*allowlistImages)
+	if err != nil {
+		failWithError("invalid '--allowlist-images' value", err)
+	}
+
+	if *minScore < 0 || *minScore > scoreMaximum {
+		failWithError(fmt.Sprintf("invalid '--min-score' value %d: must be between 0 and %d", *minScore, scoreMaximum), nil)
+	}
+
+	if *eventsWindow <= 0 {
+		failWithError(fmt.Sprintf("invalid '--events-window' value %s: must be greater than zero", *eventsWindow), nil)
+	}
+
+	if *waitTimeout <= 0 {
+		failWithError(fmt.Sprintf("invalid '--timeout' value %s: must be greater than zero", *waitTimeout), nil)
+	}
+
+	if *maxParallel < 1 {
+		failWithError(fmt.Sprintf("invalid '--max-parallel' value %d: must be at least 1", *maxParallel), nil)
+	}
+
+	contextNames, err := parseContextsFlag(*contextsFlag)
+	if err != nil {
+		failWithError("invalid '--contexts' value", err)
+	}
+
+	var kubeConfigData []byte
+	if *kubeConfigDataFlag != "" {
+		if len(kubeConfigPaths) > 0 {
+			failWithError("'--kubeconfig' and '--kubeconfig-data' may not both be specified", nil)
+		}
+		kubeConfigData, err = parseKubeConfigDataFlag(*kubeConfigDataFlag)
+		if err != nil {
+			failWithError("invalid '--kubeconfig-data' value", err)
+		}
+	}
+
+	if *topologyFormat != "" && *topologyFormat != topologyFormat_JSON {
+		failWithError(fmt.Sprintf("unsupported '--topology' value '%s': valid formats are: %s", *topologyFormat, topologyFormat_JSON), nil)
+	}
+
+	// For machine-readable formats, the report output should contain only the formatted output
+	if selectedOutputFormat.isMachineReadable() || *topologyFormat != "" {
+		statusOutput = os.Stderr
+	}
+
+	if *explain != "" {
+		if err := explainRule(*explain); err != nil {
+			failWithError("unable to explain rule", err)
+		}
+		return
+	}
+
+	if *selfTest {
+		if !runSelfTest() {
+			os.Exit(1)
+		}
+		return
+	}
+
 	var abstractK8sClient clients.AbstractK8sClient
 
-	if len(os.Args) == 1 {
-		var err error
-		abstractK8sClient, err = clients.SystemK8sClient()
+	// multiClusterTargets are the clusters to check when more than one cluster was specified via '--kubeconfig'/'--contexts' (see runMultiClusterChecks), otherwise empty
+	var multiClusterTargets []clusterTarget
+
+	// mustGatherClient is the must-gather (or OMC) client when reading from a must-gather, which reports the resource types which were found to have no resources (see outputMustGatherEmptyResourceTypes)
+	var mustGatherClient interface{ ResourceTypesWithNoResources() []string }
+
+	// liveDiffClient is the client of the live cluster that the '--manifest' ArgoCD CRs are compared against, with '--diff-against-live' (see runLiveDiffChecks), otherwise nil
+	var liveDiffClient clients.AbstractK8sClient
+
+	if *diffAgainstLive {
+		if *manifestPath == "" {
+			failWithError("'--diff-against-live' requires '--manifest', with the proposed ArgoCD CR(s)", nil)
+		}
+		if *configMapDump || *topologyFormat != "" {
+			failWithError("'--config-map-dump' and '--topology' may not be used with '--diff-against-live'", nil)
+		}
+
+		targets := clusterTargets(kubeConfigPaths, kubeConfigData, contextNames)
+		if len(targets) != 1 {
+			failWithError("'--diff-against-live' may only be used with a single cluster", nil)
+		}
+
+		liveDiffClient, err = targets[0].k8sClient()
 		if err != nil {
 			failWithError("unable to retrieve system K8s client configuration", err)
 		}
-		outputStatusMessage("Using default K8s client configuration from '.kube/config'")
+		outputStatusMessage("Comparing against the live cluster '" + targets[0].name + "'")
+
+	} else if (len(kubeConfigPaths) > 0 || kubeConfigData != nil || len(contextNames) > 0) && (*manifestPath != "" || flags.NArg() != 0) {
+		failWithError("'--kubeconfig', '--kubeconfig-data' and '--contexts' may not be specified with a must-gather path or '--manifest'", nil)
+	}
+
+	if *manifestPath != "" {
+		if flags.NArg() != 0 {
+			failWithError("a must-gather path may not be specified with '--manifest'", nil)
+		}
+
+		var stats clients.ManifestStats
+		abstractK8sClient, stats, err = clients.ManifestK8sClient(*manifestPath, clients.ManifestInputFormat(*inputFormat))
+		if err != nil {
+			failWithError("unable to read manifests from '"+*manifestPath+"'", err)
+		}
+		outputStatusMessage(fmt.Sprintf("Using manifests from '%s': parsed %d document(s), found %d ArgoCD CR(s) (%d document(s) of other kinds were skipped)", *manifestPath, stats.Documents, stats.ArgoCDs, stats.Skipped))
+		if stats.V1alpha1ArgoCDs > 0 {
+			outputStatusMessage(entry{level: LogLevel_Warn, message: fmt.Sprintf("%d ArgoCD CR(s) of the deprecated 'argoproj.io/v1alpha1' API version were skipped: only 'argoproj.io/v1beta1' ArgoCD CRs are checked", stats.V1alpha1ArgoCDs)}.string())
+		}
+
+	} else if targets := clusterTargets(kubeConfigPaths, kubeConfigData, contextNames); flags.NArg() == 0 && len(targets) > 1 {
+		if *configMapDump || *topologyFormat != "" {
+			failWithError("'--config-map-dump' and '--topology' may only be used with a single cluster", nil)
+		}
+		multiClusterTargets = targets
+		outputStatusMessage(fmt.Sprintf("Checking %d clusters: %s", len(targets), strings.Join(clusterTargetNames(targets), ", ")))
 
-	} else if len(os.Args) == 2 {
+	} else if flags.NArg() == 0 {
 		var err error
-		pathToOMCDirectory := os.Args[1]
-		abstractK8sClient, err = clients.OMCClient(pathToOMCDirectory)
+		abstractK8sClient, err = targets[0].k8sClient()
 		if err != nil {
-			failWithError("unable to retrieve OMC client data from '"+pathToOMCDirectory+"'", err)
+			failWithError("unable to retrieve system K8s client configuration", err)
+		}
+		if len(kubeConfigPaths) > 0 || kubeConfigData != nil || len(contextNames) > 0 {
+			outputStatusMessage("Using K8s client configuration of cluster '" + targets[0].name + "'")
+		} else {
+			outputStatusMessage("Using default K8s client configuration from '.kube/config'")
 		}
-		outputStatusMessage("Using must-gather from '" + pathToOMCDirectory + "'")
 
-	} else {
-		outputStatusMessage("Unexpected number of arguments. Valid parameters are:")
-		outputStatusMessage("")
-		outputStatusMessage("A) Validate Argo CD configuration using live K8s cluster via system K8s configuration (e.g. `~/.kube/config`)")
-		outputStatusMessage("- argocd-config-check")
-		outputStatusMessage("")
-		outputStatusMessage("B) Validate Argo CD configuration using must-gather output (requires 'omc' tool)")
-		outputStatusMessage("- argocd-config-check (path to must-gather directory for use by omc)")
-		outputStatusMessage("")
+	} else if flags.NArg() == 1 {
+		pathToMustGather := flags.Arg(0)
+
+		// The must-gather is read directly where possible, and omc is only required for must-gathers which do not have the standard layout
+		if nativeClient, nativeErr := clients.MustGatherK8sClient(pathToMustGather); nativeErr == nil {
+			abstractK8sClient = nativeClient
+			mustGatherClient = nativeClient
+			outputStatusMessage("Using must-gather from '" + pathToMustGather + "'")
+
+		} else {
+			outputStatusMessage("Unable to read the must-gather directly, so 'omc' will be used: " + nativeErr.Error())
+			omcClient, err := clients.OMCClient(pathToMustGather)
+			if err != nil {
+				failWithError("unable to retrieve OMC client data from '"+pathToMustGather+"'", err)
+			}
+			abstractK8sClient = omcClient
+			mustGatherClient = omcClient
+			outputStatusMessage("Using must-gather from '" + pathToMustGather + "' (via omc)")
+		}
 
+	} else {
+		flags.Usage()
 		failWithError("Unexpected number of arguments.", nil)
 	}
+
+	// Validated once the clusters are known, since the version of the document depends on whether multiple clusters are checked
+	if err := validateFormatVersion(*formatVersion, len(multiClusterTargets) > 0); err != nil {
+		failWithError("invalid '--format-version' value", err)
+	}
+
+	// Captured before the client is wrapped (by the namespace-scoped and progress clients), which would hide it
+	manifestSources, _ := abstractK8sClient.(clients.ManifestSourceLocator)
+
+	if *namespace != "" {
+		if abstractK8sClient != nil {
+			abstractK8sClient = clients.NamespaceScopedK8sClient(abstractK8sClient, *namespace)
+		}
+		if liveDiffClient != nil {
+			liveDiffClient = clients.NamespaceScopedK8sClient(liveDiffClient, *namespace)
+		}
+		outputStatusMessage("Only reading resources from namespace '" + *namespace + "': cluster-wide information (e.g. operator install, other Argo CD instances) may be incomplete")
+	}
 	outputStatusMessage("")
 
+	// Progress output is purely cosmetic, so only write it when a user is watching the terminal
+	showProgress := !*quiet && isTerminal(os.Stderr)
+	if showProgress && abstractK8sClient != nil {
+		abstractK8sClient = clients.ProgressK8sClient(abstractK8sClient, os.Stderr)
+	}
+
+	// When writing to a file, output is buffered in memory, and only written to the file once the run has completed successfully: if the run fails, the previous file is preserved.
+	var outputFileBuffer *bytes.Buffer
+	if *outputFile != "" {
+		outputFileBuffer = &bytes.Buffer{}
+		reportOutput = outputFileBuffer
+		if !selectedOutputFormat.isMachineReadable() && *topologyFormat == "" {
+			statusOutput = outputFileBuffer
+		}
+		color.NoColor = true // Color escape codes are not wanted in a file
+	}
+
+	if *waitForAvailable {
+		// The status of a must-gather or manifest is a snapshot, which will never change
+		if abstractK8sClient == nil || abstractK8sClient.IncompleteControlPlaneData() || liveDiffClient != nil {
+			failWithError("'--wait-for-available' may only be used with a single live cluster (not with a must-gather, '--manifest', or multiple clusters)", nil)
+		}
+		if *configMapDump || *topologyFormat != "" {
+			failWithError("'--config-map-dump' and '--topology' may not be used with '--wait-for-available'", nil)
+		}
+	}
+
+	// The findings of '--diff-against-live' are reported per instance, alongside the changes to each CR
+	if selectedGroupBy == groupBy_Rule && (liveDiffClient != nil || *waitForAvailable) {
+		failWithError(fmt.Sprintf("'--group-by %s' may not be used with '--diff-against-live' or '--wait-for-available'", groupBy_Rule), nil)
+	}
+
 	ctx := context.Background()
 
-	runChecks(ctx, abstractK8sClient)
+	// Run before preflightIncompleteControlPlaneData, which otherwise fails on the first ArgoCD CR which cannot be decoded
+	if *validateSchema && abstractK8sClient != nil {
+		preflightArgoCDSchemaValidation(ctx, abstractK8sClient)
+	}
+
+	if abstractK8sClient != nil && abstractK8sClient.IncompleteControlPlaneData() {
+		preflightIncompleteControlPlaneData(ctx, abstractK8sClient)
+	}
+
+	exitCode := 0
+
+	opts := runOptions{
+		includeApplications: *includeApplications,
+		includeEvents:       *includeEvents,
+		eventsWindow:        *eventsWindow,
+		showNonDefault:      *showNonDefault,
+		showCoverage:        *showCoverage,
+		verbose:             *verbose,
+		onlyUnsupported:     *onlyUnsupported,
+		outputFormat:        selectedOutputFormat,
+		groupBy:             selectedGroupBy,
+
+		operatorVersionOverride: operatorVersionOverride,
+		sizingProfile:           *profileFlag,
+		imageAllowlist:          imageAllowlist,
+		maxParallel:             *maxParallel,
+		checkSecrets:            *checkSecrets,
+		validateSchema:          *validateSchema,
+		manifestSources:         manifestSources,
+	}
+
+	if len(multiClusterTargets) > 0 {
+		clusterResults := runMultiClusterChecks(ctx, multiClusterTargets, opts, clusterConnectionOptions{namespace: *namespace, showProgress: showProgress})
+
+		allResults := []checkResults{}
+		clusterCheckFailed := false
+		for _, clusterRes := range clusterResults {
+			allResults = append(allResults, clusterRes.results)
+			clusterCheckFailed = clusterCheckFailed || clusterRes.err != nil
+		}
+
+		switch selectedOutputFormat {
+		case outputFormat_JSON:
+			outputMultiClusterResultsAsJSON(clusterResults)
+		case outputFormat_CSV:
+			outputResultsAsCSV(allResults)
+		}
+
+		if opts.groupBy == groupBy_Rule {
+			outputResultsGroupedByRule(allResults)
+		}
+
+		exitCode = exitCodeForResults(allResults, *failOnUnsupported, failOnLevel, *minScore)
+		if exitCode == 0 && clusterCheckFailed {
+			exitCode = exitCode_ClusterCheckFailed
+		}
+
+	} else if liveDiffClient != nil {
+		results := runLiveDiffChecks(ctx, abstractK8sClient, liveDiffClient, opts)
+
+		switch selectedOutputFormat {
+		case outputFormat_JSON:
+			outputResultsAsJSON(results)
+		case outputFormat_GitHub:
+			outputResultsAsGitHubAnnotations(results)
+		case outputFormat_TeamCity:
+			outputResultsAsTeamCityServiceMessages(results)
+		case outputFormat_CSV:
+			outputResultsAsCSV([]checkResults{results})
+		}
+
+		exitCode = exitCodeForResults([]checkResults{results}, *failOnUnsupported, failOnLevel, *minScore)
+
+	} else if *waitForAvailable {
+		exitCode = waitForAvailableArgoCDs(ctx, abstractK8sClient, *waitTimeout)
 
+	} else if *configMapDump {
+		dumpEffectiveConfigMaps(ctx, abstractK8sClient)
+
+	} else if *topologyFormat != "" {
+		outputTopology(ctx, abstractK8sClient)
+
+	} else {
+		results := runChecks(ctx, abstractK8sClient, opts)
+
+		switch selectedOutputFormat {
+		case outputFormat_JSON:
+			outputResultsAsJSON(results)
+		case outputFormat_GitHub:
+			outputResultsAsGitHubAnnotations(results)
+		case outputFormat_TeamCity:
+			outputResultsAsTeamCityServiceMessages(results)
+		case outputFormat_CSV:
+			outputResultsAsCSV([]checkResults{results})
+		}
+
+		if opts.groupBy == groupBy_Rule {
+			outputResultsGroupedByRule([]checkResults{results})
+		}
+
+		outputScoreSummary(results)
+
+		if mustGatherClient != nil {
+			outputMustGatherEmptyResourceTypes(mustGatherClient.ResourceTypesWithNoResources())
+		}
+
+		exitCode = exitCodeForResults([]checkResults{results}, *failOnUnsupported, failOnLevel, *minScore)
+	}
+
+	if outputFileBuffer != nil {
+		if err := writeFileAtomically(*outputFile, outputFileBuffer.Bytes()); err != nil {
+			failWithError("unable to write output to '"+*outputFile+"'", err)
+		}
+		fmt.Fprintln(os.Stderr, "Output written to '"+*outputFile+"'")
+	}
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+
+}
+
+// outputUsage outputs how the tool is run (the sources of the data to check, and the options) to the output of the flag set. This is the usage of the flag set, so it is also output for '--help', and for an unrecognized option.
+// - The options are output by flag.FlagSet.PrintDefaults, from the usage strings of the flags, so that the help output cannot drift from the options that are actually accepted.
+func outputUsage(flags *flag.FlagSet) {
+
+	output := flags.Output()
+
+	fmt.Fprintln(output, "Usage:")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "A) Validate Argo CD configuration using live K8s cluster via system K8s configuration (e.g. `~/.kube/config`)")
+	fmt.Fprintln(output, "- argocd-config-check")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "B) Validate Argo CD configuration using must-gather output (the 'omc' tool is only required if the must-gather does not have the standard layout)")
+	fmt.Fprintln(output, "- argocd-config-check (path to must-gather directory)")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "C) Validate ArgoCD CRs in local manifest files (e.g. 'helm template' or 'kustomize build' output)")
+	fmt.Fprintln(output, "- argocd-config-check --manifest (path to file, directory, or '-' for stdin)")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Options (must precede the must-gather path):")
+	flags.PrintDefaults()
+	fmt.Fprintln(output)
+	fmt.Fprintf(output, "Each option may also be set via an env var: '%s' followed by the option name in upper case, with '-' replaced by '_' (e.g. '%s=error', '%s=true'). '%s' may contain multiple paths, separated by '%c'. An option specified on the command line takes precedence over its env var, which takes precedence over the default.\n", flagEnvVarPrefix, flagEnvVarName("fail-on"), flagEnvVarName("quiet"), flagEnvVarName("kubeconfig"), os.PathListSeparator)
+	fmt.Fprintln(output)
+}
+
+// outputMustGatherEmptyResourceTypes outputs a summary of the resource types for which the must-gather contained no resources. omc cannot distinguish between there genuinely being no resources of a type, and the resources (or their namespace) not being captured in the must-gather, so checks which depend on these resource types may be based on missing data.
+func outputMustGatherEmptyResourceTypes(resourceTypes []string) {
+
+	if len(resourceTypes) == 0 {
+		return
+	}
+
+	outputStatusMessage("--------------------")
+	outputStatusMessage("Note: the must-gather contained no resources of the following type(s): " + strings.Join(resourceTypes, ", "))
+	outputStatusMessage("- This may be because there are no such resources on the cluster, or because they (or their namespace) were not captured in the must-gather. If resources of these types were expected, the results of checks which use them may be incomplete.")
+	outputStatusMessage("")
+}
+
+// exitCodeForResults returns the exit status code for the given check results (of one or more clusters), based on '--fail-on-unsupported', '--fail-on', and '--min-score', or 0 if none of them apply.
+func exitCodeForResults(allResults []checkResults, failOnUnsupported bool, failOnLevel LogLevel, minScore int) int {
+
+	allIssues := []issue{}
+	belowMinimum := []string{}
+	for _, results := range allResults {
+		allIssues = append(allIssues, results.allIssues()...)
+		belowMinimum = append(belowMinimum, instancesBelowMinimumScore(results, minScore)...)
+	}
+
+	if failOnUnsupported && issueListContainsUnsupported(allIssues) {
+		return exitCode_UnsupportedConfiguration
+	} else if failOnLevel != "" && issueListContainsLevel(allIssues, failOnLevel) {
+		return exitCode_IssuesFound
+	} else if len(belowMinimum) > 0 {
+		outputStatusMessage(fmt.Sprintf("The score of the following ArgoCD instance(s) is below the minimum score of %d: %s", minScore, strings.Join(belowMinimum, ", ")))
+		return exitCode_ScoreBelowMinimum
+	}
+
+	return 0
+}
+
+// exitCode_UnsupportedConfiguration is the exit status code used (with '--fail-on-unsupported') when at least one reported issue is an unsupported configuration. This is distinct from the status code that is used when the tool itself fails (see failWithError).
+const exitCode_UnsupportedConfiguration = 3
+
+// exitCode_ScoreBelowMinimum is the exit status code used (with '--min-score') when the score of at least one ArgoCD instance is below the minimum score. If '--fail-on-unsupported' or '--fail-on' also applies, their status code is used instead.
+const exitCode_ScoreBelowMinimum = 4
+
+// exitCode_IssuesFound is the exit status code used (with '--fail-on') when at least one reported issue has at least the given severity. If '--fail-on-unsupported' also applies, exitCode_UnsupportedConfiguration is used instead.
+const exitCode_IssuesFound = 2
+
+// failOnLevels are the valid '--fail-on' values, and the (minimum) severity of each
+var failOnLevels = map[string]LogLevel{
+	"warn":  LogLevel_Warn,
+	"error": LogLevel_Error,
+	"fatal": LogLevel_Fatal,
+}
+
+// parseFailOnLevel converts the user-specified '--fail-on' value into a LogLevel, or returns an error if it is not valid.
+func parseFailOnLevel(value string) (LogLevel, error) {
+	level, exists := failOnLevels[strings.ToLower(value)]
+	if !exists {
+		return "", fmt.Errorf("unrecognized severity '%s': valid values are: warn, error, fatal", value)
+	}
+	return level, nil
 }
 
-// clusterInformation contains data extracted from operator/cluster configuration that may be useful for subsequent logic
-type clusterInformation struct {
-	operatorVersion   *semver.Version
-	operatorInstallNS string
+// runOptions contains user-specified options (from command line flags) which affect how checks are run and reported
+type runOptions struct {
+	// includeApplications enables an additional pass which summarizes the Applications managed by each Argo CD instance
+	includeApplications bool
+
+	// includeEvents enables an additional pass which lists the recent Warning events in the namespace of each Argo CD instance, and eventsWindow is how far back events are listed
+	includeEvents bool
+	eventsWindow  time.Duration
+
+	// showNonDefault enables an additional pass which lists the fields of each ArgoCD CR that are set to non-default values (see findNonDefaultFields)
+	showNonDefault bool
+
+	// showCoverage enables an additional pass which lists whether each check ran against each Argo CD instance (see computeCheckCoverage)
+	showCoverage bool
+
+	// verbose enables additional detail in output
+	verbose bool
+
+	// outputFormat is the format used to report issues
+	outputFormat outputFormat
+
+	// groupBy is how the reported issues are grouped: with groupBy_Rule, the issues are not reported per instance, but by outputResultsGroupedByRule once all instances have been checked
+	groupBy groupBy
 
-	// from Subscription 'ARGOCD_CLUSTER_CONFIG_NAMESPACES' env
-	clusterScopedNamespaces []string
+	// onlyUnsupported filters the reported issues to only those which are unsupported configurations
+	onlyUnsupported bool
 
-	// key: namespace that is managed
-	// value: namespace of argocd instance that is managing
-	namespaceWithManagedByLabel map[string]string
+	// operatorVersionOverride is the user-supplied operator version (from '--argocd-operator-version'), which is used instead of the version discovered from the cluster. nil if not specified.
+	operatorVersionOverride *semver.Version
 
-	// key: namespace that is managed
-	// value: namespace of argocd instance that is managing
-	namespaceWithManagedByClusterArgoCDLabel map[string]string
+	// sizingProfile is the name of the workload size profile selected by the user (from '--profile'), or empty if not specified
+	sizingProfile string
 
-	// key: namespace that is managed
-	// value: namespace of argocd instance that is managing
-	namespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel map[string]string
+	// imageAllowlist are the registry prefixes/images which are approved mirrors of the official images (from '--allowlist-images'), or empty if not specified
+	imageAllowlist []string
 
-	// key: namespace that is managed
-	// value: namespace of argocd instance that is managing
-	namespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel map[string]string
+	// maxParallel is the maximum number of ArgoCD CRs that are checked concurrently (see checkArgoCDCRsConcurrently)
+	maxParallel int
+
+	// checkSecrets enables the opt-in check which verifies that the Secrets/ConfigMaps referenced by each ArgoCD CR exist (see checkForMissingReferencedSecretsAndConfigMaps)
+	checkSecrets bool
+
+	// validateSchema enables the opt-in check which validates each ArgoCD CR against the embedded ArgoCD CRD schema (see checkArgoCDCRAgainstSchema)
+	validateSchema bool
+
+	// manifestSources locates the file from which each ArgoCD CR was read, when reading from '--manifest' or a must-gather, otherwise nil
+	manifestSources clients.ManifestSourceLocator
+}
+
+// manifestSourceOf returns the file (and line) from which the ArgoCD CR was read, or nil if it was not read from a file (see runOptions.manifestSources)
+func (opts runOptions) manifestSourceOf(argoCD *v1beta1.ArgoCD) *clients.ManifestSource {
+	if opts.manifestSources == nil {
+		return nil
+	}
+	source, found := opts.manifestSources.ManifestSourceOf(argoCD)
+	if !found {
+		return nil
+	}
+	return &source
+}
+
+// enabledOptInFlags returns the flags of the opt-in checks (see checkRegistration.optInFlag) which were enabled by the user
+func (opts runOptions) enabledOptInFlags() []string {
+	res := []string{}
+	if opts.checkSecrets {
+		res = append(res, "--check-secrets")
+	}
+	if opts.validateSchema {
+		res = append(res, "--validate-schema")
+	}
+	return res
 }
 
-type LogLevel string
+// clusterInformation contains data extracted from operator/cluster configuration that may be useful for subsequent logic. See checks.ClusterInformation.
+type clusterInformation = checks.ClusterInformation
+
+type LogLevel = checks.LogLevel
 
 const (
-	// LogLevel_Fatal should be used if subsequent logic after that point is no longer guaranteed to be accurate (e.g. broken invariant). An example of a fatal case would be if there exist multiple gitops Subscriptions objects (with different versions) on the cluster.
-	LogLevel_Fatal LogLevel = "Fatal"
+	LogLevel_Fatal = checks.LogLevel_Fatal
+	LogLevel_Error = checks.LogLevel_Error
+	LogLevel_Warn  = checks.LogLevel_Warn
+	LogLevel_Info  = checks.LogLevel_Info
+)
 
-	// LogLevel_Error should be used in cases where there is a high chance of this being an incorrect configuration
-	LogLevel_Error LogLevel = "Error"
+type IssueSource = checks.IssueSource
 
-	// LogLevel_Warn should be used in cases where there is a mild/moderate chance of this being an incorrect configuration.
-	LogLevel_Warn LogLevel = "Warn"
+const (
+	IssueSource_CRField          = checks.IssueSource_CRField
+	IssueSource_ExtraConfig      = checks.IssueSource_ExtraConfig
+	IssueSource_CmdParams        = checks.IssueSource_CmdParams
+	IssueSource_EnvVar           = checks.IssueSource_EnvVar
+	IssueSource_ExtraCommandArgs = checks.IssueSource_ExtraCommandArgs
 )
 
 type entry struct {
@@ -103,17 +662,7 @@ type entry struct {
 }
 
 func (e entry) string() string {
-	var coloredLevel string
-	switch e.level {
-	case LogLevel_Fatal:
-		coloredLevel = color.New(color.FgRed, color.Bold).Sprint(e.level)
-	case LogLevel_Error:
-		coloredLevel = color.RedString(string(e.level))
-	case LogLevel_Warn:
-		coloredLevel = color.YellowString(string(e.level))
-	default:
-		coloredLevel = string(e.level)
-	}
+	coloredLevel := colorizeLogLevel(e.level, string(e.level))
 	return fmt.Sprintf("[%s] %s", coloredLevel, e.message)
 }
 
@@ -156,7 +705,7 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 		} else {
 			resEntries = append(resEntries, entry{
 				level:   LogLevel_Error,
-				message: "Unable to locate operator install Subscription in any namespace. Error: " + err.Error(),
+				message: "Unable to locate operator install Subscription in any namespace. Error: " + err.Error() + forbiddenErrorHint(err),
 			})
 		}
 
@@ -164,6 +713,10 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 	}
 
 	var gitopsSubscription *olmv1alpha1.Subscription
+
+	// argoCDOperatorSubscriptions are all Subscriptions to an operator which reconciles ArgoCD CRs: OpenShift GitOps, or the community Argo CD operator
+	argoCDOperatorSubscriptions := []olmv1alpha1.Subscription{}
+
 	for idx := range subscriptionList.Items {
 
 		sub := subscriptionList.Items[idx]
@@ -172,19 +725,24 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 			continue
 		}
 
-		if sub.Spec.Package != "openshift-gitops-operator" {
+		if !slices.Contains(argoCDOperatorPackages, sub.Spec.Package) {
 			continue
 		}
 
-		if gitopsSubscription != nil { // If we already found a gitops subscription in a previous iteration of the loop. This REALLY shouldn't happen.
-			resEntries = append(resEntries, entry{
-				level:   LogLevel_Fatal,
-				message: fmt.Sprintf("unexpected number of gitops subscriptions found: one in '%s' and one in '%s'", gitopsSubscription.Namespace+"/"+gitopsSubscription.Name, sub.Namespace+"/"+sub.Name),
-			})
-			return resClusterInformation, resEntries
+		argoCDOperatorSubscriptions = append(argoCDOperatorSubscriptions, sub)
+
+		if sub.Spec.Package == "openshift-gitops-operator" {
+			gitopsSubscription = &sub
 		}
+	}
 
-		gitopsSubscription = &sub
+	// Multiple operators reconciling the same ArgoCD CRs will conflict with each other. This REALLY shouldn't happen, but may occur during messy upgrades/reinstalls.
+	if len(argoCDOperatorSubscriptions) > 1 {
+		resEntries = append(resEntries, entry{
+			level:   LogLevel_Fatal,
+			message: describeConflictingSubscriptions(ctx, k8sClient, argoCDOperatorSubscriptions),
+		})
+		return resClusterInformation, resEntries
 	}
 
 	if gitopsSubscription == nil {
@@ -203,7 +761,7 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 		return resClusterInformation, resEntries
 	}
 
-	resClusterInformation.operatorInstallNS = gitopsSubscription.Namespace
+	resClusterInformation.OperatorInstallNamespace = gitopsSubscription.Namespace
 
 	if gitopsSubscription.Namespace != "openshift-gitops-operator" {
 		resEntries = append(resEntries, entry{
@@ -234,9 +792,25 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 	installedCSV := gitopsSubscription.Status.InstalledCSV
 
 	if installedCSV != currentCSV {
+
+		message := fmt.Sprintf("the '.status.currentCSV' field of operator ('%s') != '.status.installedCSV' of operator ('%s')", currentCSV, installedCSV)
+
+		// The remediation differs based on whether the admin is expected to approve the InstallPlan, or OLM is expected to proceed on its own
+		if gitopsSubscription.GetInstallPlanApproval() == olmv1alpha1.ApprovalManual {
+
+			installPlanName := ""
+			if gitopsSubscription.Status.InstallPlanRef != nil {
+				installPlanName = " '" + gitopsSubscription.Status.InstallPlanRef.Name + "'"
+			}
+
+			message += ". The Subscription has 'installPlanApproval: Manual', so this likely indicates that an update is pending manual approval: an administrator must approve the InstallPlan" + installPlanName + " in namespace '" + gitopsSubscription.Namespace + "' for the update to proceed."
+		} else {
+			message += ". The Subscription has 'installPlanApproval: Automatic', indicating the automatic update may be in progress or stalled. Check the status of the Subscription, InstallPlan, and CSV in namespace '" + gitopsSubscription.Namespace + "'."
+		}
+
 		resEntries = append(resEntries, entry{
 			level:   LogLevel_Error, // Error and return
-			message: "the '.status.currentCSV' field of operator != '.status.installedCSV' of operator, indicating installation may be in progress or stalled.",
+			message: message,
 		})
 		return resClusterInformation, resEntries
 	}
@@ -253,7 +827,7 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 				for ns := range rawNamespaceList {
 					trimmed := strings.TrimSpace(ns)
 					if trimmed != "" {
-						resClusterInformation.clusterScopedNamespaces = append(resClusterInformation.clusterScopedNamespaces, trimmed)
+						resClusterInformation.ClusterScopedNamespaces = append(resClusterInformation.ClusterScopedNamespaces, trimmed)
 					}
 				}
 				clusterConfigNamespacesEnvVarCount++
@@ -271,7 +845,7 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 	} else {
 		// TODO: Handle the DISABLE DEFAULT env var
 
-		resClusterInformation.clusterScopedNamespaces = []string{"openshift-gitops"} // Just assume the default
+		resClusterInformation.ClusterScopedNamespaces = []string{"openshift-gitops"} // Just assume the default
 	}
 
 	csv := olmv1alpha1.ClusterServiceVersion{
@@ -296,6 +870,8 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 		return resClusterInformation, resEntries
 	}
 
+	resEntries = append(resEntries, csvConditionEntries(csv)...)
+
 	if csv.Status.Phase != "Succeeded" || csv.Status.Reason != "InstallSucceeded" {
 		resEntries = append(resEntries, entry{
 			level:   LogLevel_Error,
@@ -304,73 +880,238 @@ func acquireInstallConfigurationData(ctx context.Context, k8sClient clients.Abst
 		return resClusterInformation, resEntries
 	}
 
-	resClusterInformation.operatorVersion = &csv.Spec.Version.Version
+	resClusterInformation.OperatorVersion = &csv.Spec.Version.Version
+
+	// The console plugin is cluster-scoped, and cannot be distinguished from a resource that was not exported when the data is incomplete
+	if !k8sClient.IncompleteControlPlaneData() {
+		resEntries = append(resEntries, checkGitOpsConsolePlugin(ctx, k8sClient)...)
+	}
+
+	resEntries = append(resEntries, acquireNamespaceInformation(ctx, k8sClient, &resClusterInformation)...)
+
+	return resClusterInformation, resEntries
+}
+
+// acquireNamespaceInformation lists the namespaces of the cluster, and populates the namespace fields of 'clusterInfo': the labels of each namespace, and the relationships between namespaces and Argo CD instances (via the managed-by labels).
+// - If the namespaces cannot be listed (e.g. forbidden), an entry describing the failure is returned: the namespace label fields are then empty (rather than nil), and Namespaces is nil.
+func acquireNamespaceInformation(ctx context.Context, k8sClient clients.AbstractK8sClient, clusterInfo *clusterInformation) []entry {
+
+	clusterInfo.NamespaceLabels = map[string]map[string]string{}
+	clusterInfo.NamespaceWithManagedByLabel = map[string]string{}
+	clusterInfo.NamespaceWithManagedByClusterArgoCDLabel = map[string]string{}
+	clusterInfo.NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel = map[string]string{}
+	clusterInfo.NamespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel = map[string]string{}
 
-	// Identify relationships between namespaces and argo cd instances
 	var namespaceList corev1.NamespaceList
 	if err := k8sClient.ListFromAllNamespaces(ctx, &namespaceList); err != nil {
-		resEntries = append(resEntries, entry{
+
+		if k8sClient.IncompleteControlPlaneData() {
+			// Namespaces are cluster-scoped, so they may not be readable (e.g. when running with only namespace-level access)
+			return []entry{{
+				level:   LogLevel_Warn,
+				message: "Unable to list Namespaces, so the relationships between namespaces and Argo CD instances will not be checked. This may be expected if the cluster data is incomplete. Error: " + err.Error(),
+			}}
+		}
+
+		return []entry{{
 			level:   LogLevel_Fatal,
-			message: "unable to list Namespaces: " + err.Error(),
-		})
-		return resClusterInformation, resEntries
+			message: "unable to list Namespaces: " + err.Error() + forbiddenErrorHint(err),
+		}}
 	}
 
-	resClusterInformation.namespaceWithManagedByLabel = map[string]string{}
+	namespaceNames := []string{}
 
 	for _, namespace := range namespaceList.Items {
 
+		namespaceNames = append(namespaceNames, namespace.Name)
+		clusterInfo.NamespaceLabels[namespace.Name] = maps.Clone(namespace.Labels)
+
 		if val, exists := namespace.Labels[common.ArgoCDManagedByLabel]; exists {
-			resClusterInformation.namespaceWithManagedByLabel[namespace.Name] = val
+			clusterInfo.NamespaceWithManagedByLabel[namespace.Name] = val
 		}
 
 		if val, exists := namespace.Labels[common.ArgoCDManagedByClusterArgoCDLabel]; exists {
-			resClusterInformation.namespaceWithManagedByClusterArgoCDLabel[namespace.Name] = val
+			clusterInfo.NamespaceWithManagedByClusterArgoCDLabel[namespace.Name] = val
 		}
 
 		if val, exists := namespace.Labels[common.ArgoCDApplicationSetManagedByClusterArgoCDLabel]; exists {
-			resClusterInformation.namespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel[namespace.Name] = val
+			clusterInfo.NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel[namespace.Name] = val
 		}
 
 		if val, exists := namespace.Labels[common.ArgoCDNotificationsManagedByClusterArgoCDLabel]; exists {
-			resClusterInformation.namespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel[namespace.Name] = val
+			clusterInfo.NamespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel[namespace.Name] = val
 		}
 	}
 
-	return resClusterInformation, resEntries
+	if !k8sClient.IncompleteControlPlaneData() {
+		sort.Strings(namespaceNames)
+		clusterInfo.Namespaces = namespaceNames
+	}
+
+	return []entry{}
 }
 
-func runChecks(ctx context.Context, k8sClient clients.AbstractK8sClient) {
+// argoCDOperatorPackages are the OLM package names of operators which reconcile ArgoCD CRs: the Red Hat build (OpenShift GitOps), and the community build
+var argoCDOperatorPackages = []string{"openshift-gitops-operator", "argocd-operator"}
 
-	clusterInfo, entries := acquireInstallConfigurationData(ctx, k8sClient)
+// describeConflictingSubscriptions returns a description of multiple Subscriptions to Argo CD operators (which will conflict with each other), including the catalog source and install mode of each, and the likely cause of the conflict.
+func describeConflictingSubscriptions(ctx context.Context, k8sClient clients.AbstractK8sClient, subscriptions []olmv1alpha1.Subscription) string {
 
-	outputEntryList(entries)
+	descriptions := []string{}
+	sources := []string{}
+	installModes := []string{}
 
-	if entryListContainsFatal(entries) {
-		return
-	}
+	for _, sub := range subscriptions {
 
-	entries = []entry{} // reset list after output
+		source := sub.Spec.CatalogSourceNamespace + "/" + sub.Spec.CatalogSource
+		installMode := subscriptionInstallMode(ctx, k8sClient, sub.Namespace)
 
-	operatorVersion := "N/A"
-	operatorInstallNS := "N/A"
+		descriptions = append(descriptions, fmt.Sprintf("'%s' in '%s' (package: %s, source: %s, channel: %s, install mode: %s)", sub.Name, sub.Namespace, sub.Spec.Package, source, sub.Spec.Channel, installMode))
 
-	clusterScopedNamespaces := []string{}
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+		if !slices.Contains(installModes, installMode) {
+			installModes = append(installModes, installMode)
+		}
+	}
 
-	if clusterInfo.operatorVersion != nil {
-		operatorVersion = clusterInfo.operatorVersion.String()
+	message := fmt.Sprintf("multiple Argo CD operator subscriptions were found: %s.", strings.Join(descriptions, ", "))
+
+	if slices.Contains(installModes, olmInstallMode_AllNamespaces) && len(installModes) > 1 {
+		message += " The operator is installed both cluster-wide (AllNamespaces) and namespace-scoped."
 	}
 
-	if clusterInfo.operatorInstallNS != "" {
-		operatorInstallNS = clusterInfo.operatorInstallNS
+	if len(sources) > 1 {
+		message += " The subscriptions are from different catalog sources: for example, both a community build and the Red Hat build of the operator are installed."
 	}
 
-	if len(clusterInfo.clusterScopedNamespaces) > 0 {
-		clusterScopedNamespaces = clusterInfo.clusterScopedNamespaces
+	message += " Each of these operators will attempt to reconcile the same ArgoCD CRs, and will conflict with each other. Uninstall all but one of the operators (including the Subscription and ClusterServiceVersion)."
+
+	return message
+}
+
+// olmInstallMode_AllNamespaces is the install mode of an operator whose OperatorGroup targets all namespaces
+const olmInstallMode_AllNamespaces = "AllNamespaces"
+
+// subscriptionInstallMode returns the install mode of operators in the given namespace, based on the OperatorGroup of the namespace: 'AllNamespaces', or the namespace(s) targeted by the OperatorGroup. Returns 'unknown' if the OperatorGroup could not be determined.
+func subscriptionInstallMode(ctx context.Context, k8sClient clients.AbstractK8sClient, namespace string) string {
+
+	var operatorGroupList olmv1.OperatorGroupList
+	if err := k8sClient.ListFromSingleNamespace(ctx, &operatorGroupList, namespace); err != nil || len(operatorGroupList.Items) != 1 {
+		return "unknown"
 	}
 
-	outputStatusMessage("--------------------")
-	outputStatusMessage("Installed operator version is: '" + operatorVersion + "'")
+	operatorGroupSpec := operatorGroupList.Items[0].Spec
+
+	if len(operatorGroupSpec.TargetNamespaces) > 0 {
+		return "namespace-scoped, targeting: " + strings.Join(operatorGroupSpec.TargetNamespaces, ", ")
+	}
+
+	if operatorGroupSpec.Selector != nil {
+		return "namespace-scoped, targeting namespaces matching label selector: " + metav1.FormatLabelSelector(operatorGroupSpec.Selector)
+	}
+
+	return olmInstallMode_AllNamespaces
+}
+
+// csvProblemReasons are the ClusterServiceVersion condition reasons which indicate that the operator install is failing or degraded
+var csvProblemReasons = []olmv1alpha1.ConditionReason{
+	olmv1alpha1.CSVReasonRequirementsNotMet,
+	olmv1alpha1.CSVReasonOwnerConflict,
+	olmv1alpha1.CSVReasonComponentFailed,
+	olmv1alpha1.CSVReasonComponentFailedNoRetry,
+	olmv1alpha1.CSVReasonInvalidStrategy,
+	olmv1alpha1.CSVReasonInstallCheckFailed,
+	olmv1alpha1.CSVReasonComponentUnhealthy,
+	olmv1alpha1.CSVReasonNeedsReinstall,
+	olmv1alpha1.CSVReasonAPIServiceResourceIssue,
+	olmv1alpha1.CSVReasonAPIServiceInstallFailed,
+	olmv1alpha1.CSVReasonInvalidInstallModes,
+	olmv1alpha1.CSVReasonUnsupportedOperatorGroup,
+	olmv1alpha1.CSVReasonNoOperatorGroup,
+	olmv1alpha1.CSVReasonTooManyOperatorGroups,
+	olmv1alpha1.CSVReasonInterOperatorGroupOwnerConflict,
+	olmv1alpha1.CSVReasonInvalidWebhookDescription,
+}
+
+// csvConditionEntries returns an entry for each condition of the ClusterServiceVersion which indicates a failing or degraded operator, including the condition message.
+// - CSV conditions are a history of the phases that the CSV has transitioned through (oldest first). Only conditions since the CSV most recently reached the 'Succeeded' phase are considered, as earlier problems have since been resolved.
+// - This may reveal problems before '.status.phase' has changed (or explain why it has changed), and so is reported in addition to the phase/reason check.
+func csvConditionEntries(csv olmv1alpha1.ClusterServiceVersion) []entry {
+
+	res := []entry{}
+
+	conditions := csv.Status.Conditions
+
+	lastSucceededIndex := -1
+	for i, condition := range conditions {
+		if condition.Phase == olmv1alpha1.CSVPhaseSucceeded {
+			lastSucceededIndex = i
+		}
+	}
+
+	for _, condition := range conditions[lastSucceededIndex+1:] {
+
+		if condition.Phase != olmv1alpha1.CSVPhaseFailed && !slices.Contains(csvProblemReasons, condition.Reason) {
+			continue
+		}
+
+		transitionTime := ""
+		if condition.LastTransitionTime != nil {
+			transitionTime = " at " + condition.LastTransitionTime.UTC().Format(time.RFC3339)
+		}
+
+		res = append(res, entry{
+			level:   LogLevel_Error,
+			message: fmt.Sprintf("ClusterServiceVersion '%s' reported condition with phase '%s' and reason '%s'%s, indicating the operator install is failing or degraded: %s", csv.Name, condition.Phase, condition.Reason, transitionTime, condition.Message),
+		})
+	}
+
+	return res
+}
+
+// runChecks runs all checks against the ArgoCD CRs visible to the client, and outputs the results. Returns the issues that were reported across all ArgoCD instances.
+func runChecks(ctx context.Context, k8sClient clients.AbstractK8sClient, opts runOptions) checkResults {
+
+	clusterInfo, entries := acquireInstallConfigurationDataWithRunOptions(ctx, k8sClient, opts)
+
+	results := checkResults{
+		clusterInfo:    clusterInfo,
+		installEntries: entries,
+		instances:      []instanceResult{},
+	}
+
+	outputEntryList(entries)
+
+	if entryListContainsFatal(entries) {
+		return results
+	}
+
+	entries = []entry{} // reset list after output
+
+	operatorVersion := "N/A"
+	operatorInstallNS := "N/A"
+
+	clusterScopedNamespaces := []string{}
+
+	if clusterInfo.OperatorVersion != nil {
+		operatorVersion = clusterInfo.OperatorVersion.String()
+	}
+
+	if clusterInfo.OperatorInstallNamespace != "" {
+		operatorInstallNS = clusterInfo.OperatorInstallNamespace
+	}
+
+	if len(clusterInfo.ClusterScopedNamespaces) > 0 {
+		clusterScopedNamespaces = clusterInfo.ClusterScopedNamespaces
+	}
+
+	outputStatusMessage("--------------------")
+	outputStatusMessage("Installed operator version is: '" + operatorVersion + "'")
+	if clusterInfo.OperatorVersionUserSupplied {
+		outputStatusMessage("- Note: this version was supplied via '--argocd-operator-version', rather than discovered from the cluster")
+	}
 	outputStatusMessage("- Currently supported operator versions can be found at: https://access.redhat.com/support/policy/updates/openshift_operators")
 	outputStatusMessage("")
 	outputStatusMessage("Operator installed in namespace: '" + operatorInstallNS + "'")
@@ -381,44 +1122,95 @@ func runChecks(ctx context.Context, k8sClient clients.AbstractK8sClient) {
 	// TODO: list which namespaces are managed by which instances
 	// TODO: list which namespaces are managed by which cluster instances (etc)
 
-	var argoCDList v1beta1.ArgoCDList
-	if err := k8sClient.ListFromAllNamespaces(ctx, &argoCDList); err != nil {
+	argoCDList := listArgoCDs(ctx, k8sClient)
 
-		if k8sClient.IncompleteControlPlaneData() {
-			if strings.Contains(err.Error(), "not known") {
-				outputStatusMessage("NOTE: Based on the error, the must-gather may not be a gitops must-gather (for example, it may instead be an openshift must-gather).")
-			}
+	var applicationList argocdv1alpha1.ApplicationList
+	if opts.includeApplications {
+		if err := k8sClient.ListFromAllNamespaces(ctx, &applicationList); err != nil {
+			outputStatusMessage(entry{level: LogLevel_Warn, message: "Unable to list Applications, so Application summaries will not be included. Error: " + err.Error()}.string())
+			outputStatusMessage("")
+			opts.includeApplications = false
 		}
-
-		failWithError("unable to list ArgoCDs", err)
 	}
 
-	if len(argoCDList.Items) == 0 {
-		if k8sClient.IncompleteControlPlaneData() {
-			failWithError("unable to locate any ArgoCD CRs: the must-gather may not be a gitops must-gather (for example, it may instead be an openshift must-gather)", nil)
-		} else {
-			failWithError("unable to locate any ArgoCD CRs", nil)
-		}
+	// For a live cluster, events are listed relative to now. Otherwise, the data was captured in the past, so events are listed relative to the most recent event (see checkEvents).
+	eventsWindowEnd := time.Time{}
+	if !k8sClient.IncompleteControlPlaneData() {
+		eventsWindowEnd = time.Now()
 	}
 
+	crIssues := checkArgoCDCRsConcurrently(argoCDList.Items, clusterInfo, opts.maxParallel)
+
 	// For each Argo CD instance...
-	for _, argoCD := range argoCDList.Items {
-		issues := checkIndividualArgoCDCR(argoCD, clusterInfo)
+	for idx, argoCD := range argoCDList.Items {
+		issues := crIssues[idx]
+		if !issueListStopsFurtherChecks(issues) {
+			issues = checkIndividualArgoCDCRAgainstCluster(ctx, k8sClient, argoCD, issues, clusterInfo, opts.enabledOptInFlags())
+		}
+
+		issues = suppressIssuesByAnnotation(argoCD, issues)
+
+		var coverage []checkCoverage
+		if opts.showCoverage {
+			coverage = computeCheckCoverage(argoCD, clusterInfo, issues, k8sClient.IncompleteControlPlaneData(), opts.enabledOptInFlags())
+		}
+
+		// The score is computed from all issues, before filtering, so that it does not depend on which issues are reported
+		score := scoreIssues(dedupeIssues(issues))
+
+		if opts.onlyUnsupported {
+			issues = filterUnsupportedIssues(issues)
+		}
 
 		outputStatusMessage("------------------------------------------------------------------------------")
 		coloredNamespace := color.New(color.FgHiCyan).Sprint("Namespace")
 		coloredArgoCD := color.New(color.FgHiCyan).Sprint("ArgoCD")
 		outputStatusMessage(coloredNamespace + " '" + argoCD.Namespace + "' -> " + coloredArgoCD + " '" + argoCD.Name + "':")
 
+		result := instanceResult{namespace: argoCD.Namespace, name: argoCD.Name, score: score, manifestSource: opts.manifestSourceOf(&argoCD)}
+
+		if opts.includeApplications {
+			applications := checkApplications(argoCD, applicationList.Items)
+			result.applications = &applications
+
+			outputApplicationsSummary(applications, opts.verbose)
+			outputStatusMessage("")
+		}
+
+		if opts.includeEvents {
+			var eventList corev1.EventList
+			if err := k8sClient.ListFromSingleNamespace(ctx, &eventList, argoCD.Namespace); err != nil {
+				outputStatusMessage(entry{level: LogLevel_Warn, message: "Unable to list Events in namespace '" + argoCD.Namespace + "', so Warning events will not be included. Error: " + err.Error()}.string())
+			} else {
+				events := checkEvents(argoCD, eventList.Items, opts.eventsWindow, eventsWindowEnd)
+				result.events = &events
+
+				outputEventsSummary(events)
+			}
+			outputStatusMessage("")
+		}
+
+		if opts.showNonDefault {
+			result.nonDefaultFields = findNonDefaultFields(argoCD)
+
+			outputNonDefaultFields(result.nonDefaultFields)
+			outputStatusMessage("")
+		}
+
+		if opts.showCoverage {
+			outputCheckCoverage(coverage)
+			outputStatusMessage("")
+		}
+
 		// {
 		// 	labelMaps := []struct {
 		// 		label      string
 		// 		namespaces map[string]string
 		// 	}{
-		// 		{label: common.ArgoCDManagedByLabel, namespaces: clusterInfo.namespaceWithManagedByLabel},
-		// 		{label: common.ArgoCDManagedByClusterArgoCDLabel, namespaces: clusterInfo.namespaceWithManagedByClusterArgoCDLabel},
-		// 		{label: common.ArgoCDApplicationSetManagedByClusterArgoCDLabel, namespaces: clusterInfo.namespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel},
-		// 		{label: common.ArgoCDNotificationsManagedByClusterArgoCDLabel, namespaces: clusterInfo.namespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel},
+		// 		{label: common.ArgoCDManagedByLabel, namespaces: clusterInfo.NamespaceWithManagedByLabel},
+		// 		{label: common.ArgoCDManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithManagedByClusterArgoCDLabel},
+		// 		{label: common.ArgoCDApplicationSetManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel},
+		// 		{label: common.ArgoCDNotificationsManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel},
 		// 	}
 
 		// 	for _, lm := range labelMaps {
@@ -436,21 +1228,226 @@ func runChecks(ctx context.Context, k8sClient clients.AbstractK8sClient) {
 		// 	}
 		// }
 
+		sortIssuesByField(issues)
+		issues = dedupeIssues(issues)
+
+		result.issues = issues
+		results.instances = append(results.instances, result)
+
 		if len(issues) == 0 {
-			outputStatusMessage("No issues found.")
+			if opts.onlyUnsupported {
+				outputStatusMessage("No unsupported configurations found.")
+			} else {
+				outputStatusMessage("No issues found.")
+			}
+			outputStatusMessage("Score: " + score.string())
 			continue
 		}
 
-		sortIssuesByField(issues)
-
 		outputStatusMessage("")
 
-		for _, issue := range issues {
-			reportIssue(issue)
-			fmt.Println()
+		if opts.groupBy == groupBy_Rule {
+			outputStatusMessage(fmt.Sprintf("%d issue(s) found (reported by rule, once all instances have been checked).", len(issues)))
+		} else {
+			outputIssues(issues, argoCD.Namespace+"/"+argoCD.Name, opts.outputFormat)
+		}
+
+		outputStatusMessage("Score: " + score.string())
+
+	}
+
+	return results
+}
+
+// acquireInstallConfigurationDataWithRunOptions acquires the operator installation data (see acquireInstallConfigurationData), and applies the user-specified options which affect it (e.g. '--argocd-operator-version').
+func acquireInstallConfigurationDataWithRunOptions(ctx context.Context, k8sClient clients.AbstractK8sClient, opts runOptions) (clusterInformation, []entry) {
+
+	clusterInfo, entries := acquireInstallConfigurationData(ctx, k8sClient)
+
+	clusterInfo.SizingProfile = opts.sizingProfile
+	clusterInfo.ImageAllowlist = opts.imageAllowlist
+
+	if opts.operatorVersionOverride != nil {
+		if clusterInfo.OperatorVersion != nil && !clusterInfo.OperatorVersion.Equals(*opts.operatorVersionOverride) {
+			entries = append(entries, entry{
+				level:   LogLevel_Warn,
+				message: fmt.Sprintf("The operator version supplied via '--argocd-operator-version' (%s) differs from the version discovered from the cluster (%s). The supplied version will be used.", opts.operatorVersionOverride.String(), clusterInfo.OperatorVersion.String()),
+			})
+		}
+		clusterInfo.OperatorVersion = opts.operatorVersionOverride
+		clusterInfo.OperatorVersionUserSupplied = true
+	}
+
+	return clusterInfo, entries
+}
+
+// checkArgoCDCRsConcurrently runs checkIndividualArgoCDCR against each of the ArgoCD CRs, using up to 'maxParallel' concurrent workers. The issues of each ArgoCD CR are returned at the same index as the CR, so that results are output in a deterministic order.
+// - Checks of the ArgoCD CR are pure (they do not read from the cluster, nor modify shared state), and so may safely run concurrently.
+// - Checks which read from the cluster (checkIndividualArgoCDCRAgainstCluster) are NOT run concurrently: the clients are not designed for concurrent use (for example, the omc client shells out for each call, and the progress client writes a single progress line).
+func checkArgoCDCRsConcurrently(argoCDs []v1beta1.ArgoCD, clusterInfo clusterInformation, maxParallel int) [][]issue {
+
+	res := make([][]issue, len(argoCDs))
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(maxParallel, 1), len(argoCDs)) {
+		wg.Go(func() {
+			for idx := range indexes {
+				res[idx] = checkIndividualArgoCDCR(argoCDs[idx], clusterInfo)
+			}
+		})
+	}
+
+	for idx := range argoCDs {
+		indexes <- idx
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return res
+}
+
+// checkResults contains the results of running all checks. This is used by output formats that report all results at once (for example, JSON), and to determine the exit status code.
+type checkResults struct {
+	// cluster is the name of the cluster that the results are from, when checking multiple clusters (see runMultiClusterChecks), otherwise empty
+	cluster string
+
+	clusterInfo clusterInformation
+
+	// installEntries are the results of checking the operator installation (Subscription/CSV)
+	installEntries []entry
+
+	instances []instanceResult
+}
+
+// instanceResult contains the results of checking a single ArgoCD instance
+type instanceResult struct {
+	namespace string
+	name      string
+	issues    []issue
+
+	// applications is nil if the Applications summary was not requested
+	applications *applicationsSummary
+
+	// events is nil if the Warning events were not requested
+	events *eventsSummary
+
+	// nonDefaultFields is nil if the non-default fields were not requested
+	nonDefaultFields []nonDefaultField
+
+	// score is computed from all of the issues of the instance (see scoreIssues)
+	score instanceScore
+
+	// manifestSource is the file (and line) from which the ArgoCD CR was read, or nil if it was not read from a file (e.g. from a live cluster, or from stdin)
+	manifestSource *clients.ManifestSource
+}
+
+// instanceName returns the name used to identify an ArgoCD instance in output: 'namespace/name', prefixed with the cluster when checking multiple clusters
+func (r checkResults) instanceName(instance instanceResult) string {
+	if r.cluster == "" {
+		return instance.namespace + "/" + instance.name
+	}
+	return r.cluster + ":" + instance.namespace + "/" + instance.name
+}
+
+// operatorInstallationName returns the name used to identify the operator installation in output, which includes the cluster when checking multiple clusters
+func (r checkResults) operatorInstallationName() string {
+	if r.cluster == "" {
+		return "Operator installation"
+	}
+	return "Operator installation (cluster '" + r.cluster + "')"
+}
+
+// allIssues returns the issues of all ArgoCD instances
+func (r checkResults) allIssues() []issue {
+	res := []issue{}
+	for _, instance := range r.instances {
+		res = append(res, instance.issues...)
+	}
+	return res
+}
+
+// listArgoCDs returns all ArgoCD CRs visible to the client, or exits with an error if none could be retrieved.
+func listArgoCDs(ctx context.Context, k8sClient clients.AbstractK8sClient) v1beta1.ArgoCDList {
+
+	argoCDList, err := tryListArgoCDs(ctx, k8sClient)
+	if err != nil {
+		failWithError(err.Error(), nil)
+	}
+
+	return argoCDList
+}
+
+// tryListArgoCDs returns all ArgoCD CRs visible to the client, or an error if none could be retrieved.
+func tryListArgoCDs(ctx context.Context, k8sClient clients.AbstractK8sClient) (v1beta1.ArgoCDList, error) {
+
+	var argoCDList v1beta1.ArgoCDList
+	if err := k8sClient.ListFromAllNamespaces(ctx, &argoCDList); err != nil {
+		if clients.IsResourceTypeNotKnownError(err) {
+			return argoCDList, fmt.Errorf("the ArgoCD CRD ('argocds.argoproj.io') was not found: is the OpenShift GitOps operator installed? (If the operator was recently uninstalled, or only partially removed, the ArgoCD CRD may have been deleted.) %w", err)
+		}
+		return argoCDList, fmt.Errorf("unable to list ArgoCDs%s: %w", forbiddenErrorHint(err), err)
+	}
+
+	if len(argoCDList.Items) == 0 {
+		return argoCDList, fmt.Errorf("unable to locate any ArgoCD CRs")
+	}
+
+	return argoCDList, nil
+}
+
+// preflightIncompleteControlPlaneData verifies that incomplete cluster data (e.g. a must-gather) contains the core OpenShift GitOps resources, before any checks are run. If not, a single explanation is output, and the tool exits.
+// - Support engineers are sometimes handed the wrong archive (for example, a standard OpenShift must-gather rather than one collected with the GitOps must-gather image). Without this, the problem would instead surface as a number of partial/confusing errors throughout the run.
+func preflightIncompleteControlPlaneData(ctx context.Context, k8sClient clients.AbstractK8sClient) {
+
+	var argoCDList v1beta1.ArgoCDList
+	argoCDErr := k8sClient.ListFromAllNamespaces(ctx, &argoCDList)
+
+	if argoCDErr == nil && len(argoCDList.Items) > 0 {
+		return
+	}
+
+	var subscriptionList olmv1alpha1.SubscriptionList
+	subscriptionErr := k8sClient.ListFromAllNamespaces(ctx, &subscriptionList)
+
+	gitopsSubscriptionFound := false
+	if subscriptionErr == nil {
+		for _, sub := range subscriptionList.Items {
+			if sub.Spec != nil && sub.Spec.Package == "openshift-gitops-operator" {
+				gitopsSubscriptionFound = true
+				break
+			}
 		}
+	}
+
+	message := "no ArgoCD CRs were found in the cluster data."
+
+	if gitopsSubscriptionFound {
+		message += " The OpenShift GitOps operator Subscription was found, so the data may not include the namespace(s) containing Argo CD instances (for example, if the must-gather was collected for only a subset of namespaces)."
+	} else {
+		message += " The OpenShift GitOps operator Subscription was also not found. The data likely does not contain OpenShift GitOps resources: for example, it may be a standard OpenShift must-gather, rather than a must-gather collected with the OpenShift GitOps must-gather image."
+	}
+
+	// When the resource type is not present at all, the client reports the type as not known (see clients.IsResourceTypeNotKnownError)
+	if clients.IsResourceTypeNotKnownError(argoCDErr) {
+		message += " (The ArgoCD resource type is not known to the must-gather, which indicates that no GitOps data was collected.)"
+	}
+
+	if argoCDErr != nil {
+		failWithError(message+" Error from listing ArgoCDs:", argoCDErr)
+	}
+
+	failWithError(message, nil)
+}
 
+// forbiddenErrorHint returns a suggestion to use namespace-scoped mode if the error indicates the user does not have permission to list resources cluster-wide, or an empty string otherwise.
+func forbiddenErrorHint(err error) string {
+	if !apierrors.IsForbidden(err) {
+		return ""
 	}
+	return " (the user does not have permission to read this resource from all namespaces: to only read resources from the namespace of an Argo CD instance, re-run with '--namespace (namespace of Argo CD instance)')"
 }
 
 // sortIssuesByField sorts a slice of issues alphabetically by their 'field' field.
@@ -460,29 +1457,119 @@ func sortIssuesByField(issues []issue) {
 	})
 }
 
-func outputStatusMessage(str string) {
-	fmt.Println(str)
+// dedupeIssues collapses issues which were reported by the same rule on the same field into a single issue, keeping the highest severity of the duplicates. This can occur when a setting is expressed in multiple overlapping ways, and a check reports the same underlying problem for each of them.
+// - Issues of different rules are never collapsed, even if their field and message are identical, since each rule is a distinct finding (with its own '--explain' description).
+// - If the messages of the duplicates differ, the distinct messages are combined (separated by a space), so that no detail is lost.
+// - Issues without a rule ID (which are not reported by a registered check) are only collapsed if their field and message are identical.
+// - The order of the (first occurrence of each) issue is preserved.
+func dedupeIssues(issues []issue) []issue {
+
+	type issueKey struct {
+		ruleID  string
+		field   string
+		message string
+	}
+
+	res := []issue{}
+	indexByKey := map[issueKey]int{} // index of issue in 'res'
+
+	for _, currIssue := range issues {
+
+		key := issueKey{ruleID: currIssue.ruleID, field: currIssue.field}
+		if currIssue.ruleID == "" {
+			key.message = currIssue.message
+		}
+
+		existingIndex, exists := indexByKey[key]
+		if !exists {
+			indexByKey[key] = len(res)
+			res = append(res, currIssue)
+			continue
+		}
+
+		existing := &res[existingIndex]
+		if logLevelSeverity(currIssue.level) > logLevelSeverity(existing.level) {
+			existing.level = currIssue.level
+		}
+		existing.unsupported = existing.unsupported || currIssue.unsupported
+		if !strings.Contains(existing.message, currIssue.message) {
+			existing.message += " " + currIssue.message
+		}
+	}
+
+	return res
 }
 
-func reportIssue(i issue) {
-	var coloredLevel string
-	switch i.level {
+// filterUnsupportedIssues returns only the issues which are unsupported configurations
+func filterUnsupportedIssues(issues []issue) []issue {
+	res := []issue{}
+	for _, currIssue := range issues {
+		if currIssue.unsupported {
+			res = append(res, currIssue)
+		}
+	}
+	return res
+}
+
+// issueListContainsUnsupported returns true if at least one issue is an unsupported configuration
+func issueListContainsUnsupported(issues []issue) bool {
+	for _, currIssue := range issues {
+		if currIssue.unsupported {
+			return true
+		}
+	}
+	return false
+}
+
+// issueListContainsLevel returns true if any issue has the given log level, or a more severe one
+func issueListContainsLevel(issues []issue, level LogLevel) bool {
+	for _, currIssue := range issues {
+		if logLevelSeverity(currIssue.level) >= logLevelSeverity(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// logLevelSeverity returns a numeric value for a log level, where larger values are more severe
+func logLevelSeverity(level LogLevel) int {
+	switch level {
 	case LogLevel_Fatal:
-		coloredLevel = color.New(color.FgRed, color.Bold).Sprint(i.level)
+		return 3
 	case LogLevel_Error:
-		coloredLevel = color.RedString(string(i.level))
+		return 2
 	case LogLevel_Warn:
-		coloredLevel = color.YellowString(string(i.level))
+		return 1
 	default:
-		coloredLevel = string(i.level)
+		return 0
 	}
-	fmt.Println("Severity: " + coloredLevel)
+}
+
+// reportOutput is where the formatted results (issues) are written. This is stdout, unless '--output-file' is specified.
+var reportOutput io.Writer = os.Stdout
+
+// statusOutput is where status messages are written. This is the same as reportOutput for human-readable output formats, and stderr for machine-readable output formats (so that they do not interfere with the formatted output).
+var statusOutput io.Writer = os.Stdout
+
+func outputStatusMessage(str string) {
+	fmt.Fprintln(statusOutput, str)
+}
+
+func reportIssue(i issue) {
+	coloredLevel := colorizeLogLevel(i.level, string(i.level))
+	fmt.Fprintln(reportOutput, "Severity: "+coloredLevel)
 	coloredField := color.New(color.FgHiWhite, color.Bold).Sprint(i.field)
-	fmt.Println("Field: " + coloredField)
-	fmt.Println("-", i.message)
+	fmt.Fprintln(reportOutput, "Field: "+coloredField)
+	if i.ruleID != "" {
+		fmt.Fprintln(reportOutput, "Rule: "+i.ruleID+" (for details, run with '--explain "+i.ruleID+"')")
+	}
+	if i.source != "" {
+		fmt.Fprintln(reportOutput, "Source: "+string(i.source))
+	}
+	fmt.Fprintln(reportOutput, "-", i.message)
 	if i.unsupported {
 		coloredBang := color.New(color.FgBlack, color.BgRed).Sprint("!")
-		fmt.Println(coloredBang + " Unsupported, non-production configuration. This may be due to use of tech preview/experimental feature, or unsupported configuration. See message for details.")
+		fmt.Fprintln(reportOutput, coloredBang+" Unsupported, non-production configuration. This may be due to use of tech preview/experimental feature, or unsupported configuration. See message for details.")
 	}
 }
 
@@ -491,128 +1578,190 @@ type issue struct {
 	field   string
 	message string
 
+	// ruleID is the ID of the check that reported the issue (see registeredChecks, and checks.Register). This is set automatically, and should not be set by check functions.
+	ruleID string
+
 	// unsupported should be set to true if the configuration (or particular feature) detected is not supported by the OpenShift GitOps team. For example, using tech preview features, or using custom non-Red-Hat-built container images for essential Argo CD components.
 	unsupported bool
+
+	// source is where the problematic value came from (e.g. an env var, or extraConfig). This should be set by checks which resolve a value from multiple sources, and is otherwise empty.
+	source IssueSource
+
+	// stopFurtherChecks should be set to true (on a Fatal issue) if the issue makes the results of all subsequent checks of the ArgoCD CR meaningless or misleading. See checks.Issue.StopFurtherChecks, and checkForInstancesWhichCannotBeChecked.
+	stopFurtherChecks bool
 }
 
+// checkIndividualArgoCDCR runs all registered checks (both built-in, and any additional checks compiled into the tool via checks.Register) against the ArgoCD CR.
 func checkIndividualArgoCDCR(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation) []issue {
 
 	issues := []issue{}
 
-	// TODO: Return on fatals?
-
-	checkArgoCDCRForDeprecatedFields(argoCD, &issues)
-	checkArgoCDCRForUnsupportedCustomImages(argoCD, &issues)
-	checkForTechPreviewOrExperimentalFeatures(argoCD, &issues)
-	checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD, &issues)
-	checkForIncorrectConfigurations(argoCD, &issues)
-	checkArgoCDStatusField(argoCD, &issues)
-	checkForFailingBestPractices(argoCD, &issues)
+	// Checks are not run after an issue which stops further checks (see checks.RunChecks)
+	for _, checkIssue := range checks.RunChecks(argoCD, clusterInfo) {
+		issues = append(issues, issue{
+			level:             checkIssue.Level,
+			field:             checkIssue.Field,
+			message:           checkIssue.Message,
+			ruleID:            checkIssue.RuleID,
+			unsupported:       checkIssue.Unsupported,
+			source:            checkIssue.Source,
+			stopFurtherChecks: checkIssue.StopFurtherChecks,
+		})
+	}
 
 	return issues
 
 }
 
-// checkArgoCDCRForUnsupportedCustomImages identifies the use of custom container images for components where that is not supported. Only official OpenShift GitOps images (built by konflux and server by Red Hat image registry) are supported.
-func checkArgoCDCRForUnsupportedCustomImages(argoCD v1beta1.ArgoCD, issues *[]issue) {
+// issueListStopsFurtherChecks returns true if any of the issues stops further checks of the ArgoCD CR (see issue.stopFurtherChecks), in which case the checks against the cluster are not run for the CR
+func issueListStopsFurtherChecks(issues []issue) bool {
+	return slices.ContainsFunc(issues, func(issue issue) bool { return issue.stopFurtherChecks })
+}
 
-	if argoCD.Spec.ApplicationSet != nil && len(argoCD.Spec.ApplicationSet.Image) > 0 {
+// checkForInstancesWhichCannotBeChecked identifies ArgoCD CRs for which the results of all other checks would be meaningless or misleading. Each of these is reported as Fatal, and stops all further checks of the CR (see checks.Issue.StopFurtherChecks). The conditions are:
+// - The CR is being deleted ('.metadata.deletionTimestamp' is set): the operator no longer reconciles its configuration, and is removing the instance.
+// - The CR has no name or namespace (for example, a hand-written manifest): the operator cannot reconcile it, and checks which relate the CR to other resources would be inaccurate.
+// This check is registered first, so that it runs before all other checks.
+func checkForInstancesWhichCannotBeChecked(argoCD v1beta1.ArgoCD, issues *[]issue) {
 
+	if argoCD.DeletionTimestamp != nil {
 		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.applicationSet.image",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
+			level:             LogLevel_Fatal,
+			field:             ".metadata.deletionTimestamp",
+			message:           fmt.Sprintf("The ArgoCD CR is being deleted (deletion requested at %s). The operator no longer reconciles its configuration, so the configuration was not checked. If the CR remains in this state, verify that the operator is running, and check the finalizers of the CR (%s).", argoCD.DeletionTimestamp.UTC().Format(time.RFC3339), strings.Join(argoCD.Finalizers, ", ")),
+			stopFurtherChecks: true,
 		})
-
+		return
 	}
 
-	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil && len(argoCD.Spec.SSO.Dex.Image) > 0 {
-
+	if argoCD.Name == "" || argoCD.Namespace == "" {
 		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.sso.dex.image",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
+			level:             LogLevel_Fatal,
+			field:             ".metadata",
+			message:           "The ArgoCD CR does not have both a name and a namespace, so it is not a valid ArgoCD CR, and the configuration was not checked. Specify '.metadata.name' and '.metadata.namespace'.",
+			stopFurtherChecks: true,
 		})
-
 	}
+}
 
-	if len(argoCD.Spec.HA.RedisProxyImage) > 0 {
+// checkArgoCDCRForUnsupportedCustomImages identifies the use of custom container images for components where that is not supported. Only official OpenShift GitOps images (built by konflux and server by Red Hat image registry) are supported.
+// - Images which match the user's image allowlist (see '--allowlist-images') are reported as Info, rather than as unsupported: this is intended for organizations which mirror the official images to an internal registry.
+func checkArgoCDCRForUnsupportedCustomImages(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
 
-		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.ha.redisProxyImage",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
-		})
+	// key: CR field, value: custom image. A slice is used so that issues are reported in a stable order.
+	customImages := [][2]string{}
 
+	if argoCD.Spec.ApplicationSet != nil {
+		customImages = append(customImages, [2]string{".spec.applicationSet.image", argoCD.Spec.ApplicationSet.Image})
 	}
 
-	if argoCD.Spec.ArgoCDAgent != nil {
+	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil {
+		customImages = append(customImages, [2]string{".spec.sso.dex.image", argoCD.Spec.SSO.Dex.Image})
+	}
 
-		if argoCD.Spec.ArgoCDAgent.Agent != nil && len(argoCD.Spec.ArgoCDAgent.Agent.Image) > 0 {
-			*issues = append(*issues, issue{
-				level:       LogLevel_Error,
-				field:       ".spec.argoCDAgent.agent.image",
-				message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-				unsupported: true,
-			})
+	customImages = append(customImages, [2]string{".spec.ha.redisProxyImage", argoCD.Spec.HA.RedisProxyImage})
+
+	if argoCD.Spec.ArgoCDAgent != nil {
+		if argoCD.Spec.ArgoCDAgent.Agent != nil {
+			customImages = append(customImages, [2]string{".spec.argoCDAgent.agent.image", argoCD.Spec.ArgoCDAgent.Agent.Image})
 		}
+		if argoCD.Spec.ArgoCDAgent.Principal != nil {
+			customImages = append(customImages, [2]string{".spec.argoCDAgent.principal.image", argoCD.Spec.ArgoCDAgent.Principal.Image})
+		}
+	}
 
-		if argoCD.Spec.ArgoCDAgent.Principal != nil && len(argoCD.Spec.ArgoCDAgent.Principal.Image) > 0 {
+	customImages = append(customImages,
+		[2]string{".spec.notifications.image", argoCD.Spec.Notifications.Image},
+		[2]string{".spec.redis.image", argoCD.Spec.Redis.Image},
+		[2]string{".spec.repo.image", argoCD.Spec.Repo.Image},
+		[2]string{".spec.image", argoCD.Spec.Image},
+	)
 
-			*issues = append(*issues, issue{
-				level:       LogLevel_Error,
-				field:       ".spec.argoCDAgent.principal.image",
-				message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-				unsupported: true,
-			})
+	for _, customImage := range customImages {
 
+		field, image := customImage[0], customImage[1]
+		if image == "" {
+			continue
 		}
 
-	}
+		if allowlistEntry := matchingImageAllowlistEntry(image, clusterInfo.ImageAllowlist); allowlistEntry != "" {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Info,
+				field:   field,
+				message: fmt.Sprintf("A custom container image ('%s') is used for an Argo CD component. The image matches the image allowlist entry '%s' (see '--allowlist-images'), so it is treated as an approved mirror of the official image. Ensure the mirrored image is kept in sync with the official image of the installed operator version.", image, allowlistEntry),
+			})
+			continue
+		}
 
-	if len(argoCD.Spec.Notifications.Image) > 0 {
 		*issues = append(*issues, issue{
 			level:       LogLevel_Error,
-			field:       ".spec.notifications.image",
+			field:       field,
 			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
 			unsupported: true,
 		})
 	}
+}
 
-	if len(argoCD.Spec.Redis.Image) > 0 {
-		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.redis.image",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
-		})
+// matchingImageAllowlistEntry returns the first entry of the image allowlist (see '--allowlist-images') which matches the image, or empty if none match.
+// - An entry ending in '/' is a registry/repository prefix (e.g. 'mirror.example.com/openshift-gitops/'), which matches any image under it.
+// - Any other entry matches the exact image, ignoring the tag/digest of the image if the entry does not include one.
+func matchingImageAllowlistEntry(image string, allowlist []string) string {
+
+	for _, allowlistEntry := range allowlist {
+
+		if strings.HasSuffix(allowlistEntry, "/") {
+			if strings.HasPrefix(image, allowlistEntry) {
+				return allowlistEntry
+			}
+			continue
+		}
+
+		if image == allowlistEntry || imageWithoutTagOrDigest(image) == allowlistEntry {
+			return allowlistEntry
+		}
 	}
 
-	if len(argoCD.Spec.Repo.Image) > 0 {
-		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.repo.image",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
-		})
+	return ""
+}
+
+// imageWithoutTagOrDigest returns the image reference without its tag and/or digest (e.g. 'registry:5000/a/b:1.0@sha256:...' returns 'registry:5000/a/b')
+func imageWithoutTagOrDigest(image string) string {
+
+	image, _, _ = strings.Cut(image, "@")
+
+	// A ':' after the last '/' is a tag separator (a ':' before it is a registry port)
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
 	}
 
-	if len(argoCD.Spec.Image) > 0 {
-		*issues = append(*issues, issue{
-			level:       LogLevel_Error,
-			field:       ".spec.image",
-			message:     "The image field is used to provide custom container images for Argo CD components. However, specifying custom images for essential Argo CD components is not supported.",
-			unsupported: true,
-		})
+	return image
+}
+
+// parseImageAllowlistFlag converts the user-specified '--allowlist-images' value (a comma-separated list of registry prefixes or images) into a list of allowlist entries, or returns an error if it is not valid.
+func parseImageAllowlistFlag(value string) ([]string, error) {
+
+	if value == "" {
+		return nil, nil
+	}
+
+	res := []string{}
+	for allowlistEntry := range strings.SplitSeq(value, ",") {
+		allowlistEntry = strings.TrimSpace(allowlistEntry)
+		if allowlistEntry == "" {
+			return nil, fmt.Errorf("'%s' contains an empty entry", value)
+		}
+		res = append(res, allowlistEntry)
 	}
 
+	return res, nil
 }
 
+// keycloakRemovalOperatorVersion is the first OpenShift GitOps operator version which no longer creates and manages a Keycloak instance for '.spec.sso.keycloak' (or '.spec.sso.provider: keycloak'). On earlier versions, Keycloak is deprecated, but still managed.
+var keycloakRemovalOperatorVersion = semver.Version{Major: 1, Minor: 16, Patch: 0}
+
 // checkArgoCDCRForDeprecatedFields identifies fields that are deprecated and no longer supported by ArgoCD operator.
-func checkArgoCDCRForDeprecatedFields(argoCD v1beta1.ArgoCD, issues *[]issue) {
+// - Keycloak is only reported as unsupported on operator versions where it has been removed (see keycloakRemovalOperatorVersion). On earlier versions, it is reported as an upcoming removal.
+func checkArgoCDCRForDeprecatedFields(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
 
 	if len(argoCD.Spec.ConfigManagementPlugins) > 0 {
 		*issues = append(*issues, issue{
@@ -626,7 +1775,7 @@ func checkArgoCDCRForDeprecatedFields(argoCD v1beta1.ArgoCD, issues *[]issue) {
 		*issues = append(*issues, issue{
 			level:   LogLevel_Error,
 			field:   ".spec.grafana",
-			message: "grafana field is deprecated from ArgoCD CR: this field will be ignored by operator, and any remaining Grafana resources will be removed.",
+			message: "grafana field is deprecated from ArgoCD CR: this field will be ignored by operator. Grafana resources previously created by the operator are not removed by the operator, and must be deleted manually.",
 		})
 	}
 
@@ -646,14 +1795,87 @@ func checkArgoCDCRForDeprecatedFields(argoCD v1beta1.ArgoCD, issues *[]issue) {
 		})
 	}
 
-	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Keycloak != nil {
+	// Legacy inline repository configuration in 'argocd-cm', via extraConfig
+	for _, key := range []string{"repositories", "repository.credentials"} {
+
+		value, exists := argoCD.Spec.ExtraConfig[key]
+		if !exists {
+			continue
+		}
+
+		message := fmt.Sprintf("The '%s' key of 'argocd-cm' is a legacy, deprecated mechanism for defining repositories (and was removed in Argo CD 3.0, after which it is ignored). Repositories and repository credentials should instead be defined as Secrets with the 'argocd.argoproj.io/secret-type: repository' (or 'repo-creds') label: migrate each entry to a Secret, then remove '%s' from '.spec.extraConfig'.", key, key)
+
+		if err := validateLegacyRepositoryList(value); err != nil {
+			message += " Additionally, the value is malformed: " + err.Error()
+		}
+
 		*issues = append(*issues, issue{
-			level:   LogLevel_Error,
-			field:   ".spec.sso.keycloak",
-			message: "keycloak field is no longer supported. ArgoCD operator will no longer create and manage a keycloak instance on the users behalf. Users may instead manage their own keycloak instance (using e.g. keycloak operator) and configure Argo CD to use it.",
+			level:   LogLevel_Warn,
+			field:   ".spec.extraConfig[" + key + "]",
+			message: message,
 		})
 	}
 
+	if field := keycloakField(argoCD); field != "" {
+
+		removalVersion := keycloakRemovalOperatorVersion.String()
+
+		if clusterInfo.OperatorVersion != nil && clusterInfo.OperatorVersion.LT(keycloakRemovalOperatorVersion) {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field,
+				message: fmt.Sprintf("Keycloak support is deprecated. Operator version %s still creates and manages a Keycloak instance on the users behalf, but this is removed in operator version %s, after which the keycloak configuration is ignored (and SSO via Keycloak stops working). Before upgrading, manage your own Keycloak instance (using e.g. keycloak operator), configure Argo CD to use it (e.g. via '.spec.oidcConfig'), and remove the keycloak configuration.", clusterInfo.OperatorVersion.String(), removalVersion),
+			})
+
+		} else {
+			message := "keycloak field is no longer supported. ArgoCD operator will no longer create and manage a keycloak instance on the users behalf. Users may instead manage their own keycloak instance (using e.g. keycloak operator) and configure Argo CD to use it."
+			if clusterInfo.OperatorVersion == nil {
+				message += fmt.Sprintf(" (Keycloak support was removed in operator version %s: the operator version could not be determined, so it is assumed to be %s or later.)", removalVersion, removalVersion)
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   field,
+				message: message,
+			})
+		}
+	}
+
+}
+
+// keycloakField returns the field which configures Keycloak SSO ('.spec.sso.keycloak', or '.spec.sso.provider: keycloak'), or an empty string if Keycloak is not configured
+func keycloakField(argoCD v1beta1.ArgoCD) string {
+
+	if argoCD.Spec.SSO == nil {
+		return ""
+	}
+
+	if argoCD.Spec.SSO.Keycloak != nil {
+		return ".spec.sso.keycloak"
+	}
+
+	if argoCD.Spec.SSO.Provider.ToLower() == v1beta1.SSOProviderTypeKeycloak {
+		return ".spec.sso.provider"
+	}
+
+	return ""
+}
+
+// validateLegacyRepositoryList verifies that a legacy 'repositories'/'repository.credentials' value from 'argocd-cm' is a YAML list, with a 'url' for each entry
+func validateLegacyRepositoryList(value string) error {
+
+	var entries []map[string]any
+	if err := yaml.Unmarshal([]byte(value), &entries); err != nil {
+		return fmt.Errorf("expected a YAML list of repository entries: %v", err)
+	}
+
+	for i, entry := range entries {
+		if url, ok := entry["url"].(string); !ok || url == "" {
+			return fmt.Errorf("entry %d does not specify a 'url'", i)
+		}
+	}
+
+	return nil
 }
 
 func checkForTechPreviewOrExperimentalFeatures(argoCD v1beta1.ArgoCD, issues *[]issue) {
@@ -673,10 +1895,16 @@ func checkForTechPreviewOrExperimentalFeatures(argoCD v1beta1.ArgoCD, issues *[]
 			})
 		}
 
-		if containerArgsContainsParam(appSet.ExtraCommandArgs, "enable-progressive-syncs") {
+		// Progressive syncs may be toggled both via the argument and the env var.
+		// - containerArgsBoolParamValue is used (rather than containerArgsContainsParam), since '--enable-progressive-syncs' is usually specified without a value (including as the last argument), and may be explicitly disabled via '--enable-progressive-syncs=false'.
+		argValue, argSet := containerArgsBoolParamValue(appSet.ExtraCommandArgs, "enable-progressive-syncs")
+		envValue, envSet := containerEnvVarValue(appSet.Env, "ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_PROGRESSIVE_SYNCS")
+
+		if argSet && argValue {
 			*issues = append(*issues, issue{
 				level:       LogLevel_Warn,
-				field:       ".spec.applicationSet.extraCommandArgs = --enable-progressive-syncs",
+				field:       ".spec.applicationSet.extraCommandArgs: --enable-progressive-syncs",
+				source:      IssueSource_ExtraCommandArgs,
 				message:     genericTechPreviewMessage,
 				unsupported: true,
 			})
@@ -691,6 +1919,29 @@ func checkForTechPreviewOrExperimentalFeatures(argoCD v1beta1.ArgoCD, issues *[]
 			})
 		}
 
+		// The argument takes precedence (the env var is only the default value of the argument), so a contradiction between them (commonly left by a partial migration from one to the other) means that one of the two is silently ignored.
+		if envEnabled, err := strconv.ParseBool(strings.TrimSpace(envValue)); argSet && envSet && err == nil && envEnabled != argValue {
+
+			enabledOrDisabled := func(enabled bool) string {
+				if enabled {
+					return "enabled"
+				}
+				return "disabled"
+			}
+
+			argDescription := "--enable-progressive-syncs"
+			if !argValue {
+				argDescription = "--enable-progressive-syncs=false"
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   ".spec.applicationSet.extraCommandArgs: --enable-progressive-syncs",
+				source:  IssueSource_ExtraCommandArgs,
+				message: fmt.Sprintf("Progressive syncs are %s by '%s' in '.spec.applicationSet.extraCommandArgs', but %s by '.spec.applicationSet.env[ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_PROGRESSIVE_SYNCS]' ('%s'). The argument takes precedence, so progressive syncs are %s, and the env var is ignored. Remove one of the two, so that there is a single source for this setting.", enabledOrDisabled(argValue), argDescription, enabledOrDisabled(envEnabled), envValue, enabledOrDisabled(argValue)),
+			})
+		}
+
 	}
 
 	if argoCD.Spec.Controller.IsEnabled() {
@@ -744,41 +1995,13 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 	if len(argoCD.Spec.ExtraConfig) > 0 {
 		extraConfig := argoCD.Spec.ExtraConfig
 
-		type directTranslation struct {
-			extraConfigField     string
-			correspondingCRField string
-		}
-
-		directTranslations := []directTranslation{
-			{extraConfigField: "admin.enabled", correspondingCRField: ".spec.disableAdmin"},
-			{extraConfigField: "application.instanceLabelKey", correspondingCRField: ".spec.applicationInstanceLabelKey"},
-			{extraConfigField: "application.resourceTrackingMethod", correspondingCRField: ".spec.resourceTrackingMethod"},
-			{extraConfigField: "dex.config", correspondingCRField: ".spec.sso.dex"},
-			{extraConfigField: "ga.anonymizeusers", correspondingCRField: ".spec.gaAnonymizeUsers"},
-			{extraConfigField: "ga.trackingid", correspondingCRField: ".spec.gaTrackingID"},
-			{extraConfigField: "help.chatText", correspondingCRField: ".spec.helpChatText"},
-			{extraConfigField: "help.chatUrl", correspondingCRField: ".spec.helpChatURL"},
-			{extraConfigField: "installationID", correspondingCRField: ".spec.installationID"},
-			{extraConfigField: "kustomize.buildOptions", correspondingCRField: ".spec.kustomizeBuildOptions"},
-			{extraConfigField: "oidc.config", correspondingCRField: ".spec.oidcConfig"},
-			{extraConfigField: "resource.respectRBAC", correspondingCRField: ".spec.controller.respectRBAC"},
-			{extraConfigField: "resource.exclusions", correspondingCRField: ".spec.resourceExclusions"},
-			{extraConfigField: "resource.inclusions", correspondingCRField: ".spec.resourceInclusions"},
-			{extraConfigField: "statusbadge.enabled", correspondingCRField: ".spec.statusBadgeEnabled"},
-			{extraConfigField: "timeout.reconciliation", correspondingCRField: ".spec.controller.appSync"},
-			{extraConfigField: "ui.bannercontent", correspondingCRField: ".spec.banner.content"},
-			{extraConfigField: "ui.bannerpermanent", correspondingCRField: "spec.banner.permanent"},
-			{extraConfigField: "ui.bannerposition", correspondingCRField: ".spec.banner.position"},
-			{extraConfigField: "ui.bannerurl", correspondingCRField: ".spec.banner.url"},
-			{extraConfigField: "users.anonymous.enabled", correspondingCRField: ".spec.usersAnonymousEnabled"},
-		}
-
-		for _, directTranslation := range directTranslations {
-			if extraConfig[directTranslation.extraConfigField] != "" {
+		for _, mapping := range argoCDCMFieldMappings {
+			if extraConfig[mapping.key] != "" {
 				*issues = append(*issues, issue{
 					level:   LogLevel_Warn,
-					field:   ".spec.extraConfig[" + directTranslation.extraConfigField + "]",
-					message: "The '" + directTranslation.extraConfigField + "' value in extraConfig is supported, but it is preferable to use '" + directTranslation.correspondingCRField + "' ArgoCD CR field for this.",
+					field:   ".spec.extraConfig[" + mapping.key + "]",
+					source:  IssueSource_ExtraConfig,
+					message: "The '" + mapping.key + "' value in extraConfig is supported, but it is preferable to use '" + mapping.crField + "' ArgoCD CR field for this.",
 				})
 			}
 		}
@@ -788,6 +2011,7 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 				*issues = append(*issues, issue{
 					level:   LogLevel_Warn,
 					field:   ".spec.extraConfig[resource.customizations.health.*]",
+					source:  IssueSource_ExtraConfig,
 					message: "The 'resource.customizations.health.*' values in extraConfig are supported, but it is preferable to use '.spec.resourceHealthChecks' ArgoCD CR field for this.",
 				})
 				break // Only add the issue once
@@ -798,6 +2022,7 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 				*issues = append(*issues, issue{
 					level:   LogLevel_Warn,
 					field:   ".spec.extraConfig[resource.customizations.actions.*]",
+					source:  IssueSource_ExtraConfig,
 					message: "The 'resource.customizations.actions.*' values in extraConfig are supported, but it is preferable to use '.spec.resourceActions' ArgoCD CR field for this.",
 				})
 				break // Only add the issue once
@@ -809,6 +2034,7 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 				*issues = append(*issues, issue{
 					level:   LogLevel_Warn,
 					field:   ".spec.extraConfig[resource.customizations.ignoreDifferences.*]",
+					source:  IssueSource_ExtraConfig,
 					message: "The 'resource.customizations.ignoreDifferences*' values in extraConfig are supported, but it is preferable to use '.spec.resourceIgnoreDifferences' ArgoCD CR field for this.",
 				})
 				break // Only add the issue once
@@ -820,18 +2046,11 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 
 		appSet := *argoCD.Spec.ApplicationSet
 
-		if containerEnvVarContainsName(appSet.Env, "ARGOCD_APPLICATIONSET_CONTROLLER_NAMESPACES") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".spec.applicationSet.env[ARGOCD_APPLICATIONSET_CONTROLLER_NAMESPACES]",
-				message: "The 'ARGOCD_APPLICATIONSET_CONTROLLER_NAMESPACES' environment variable should not be set directly. Use '.spec.applicationSet.sourceNamespaces' field instead to enable ApplicationSets in any namespace.",
-			})
-		}
-
 		if containerArgsContainsParam(appSet.ExtraCommandArgs, "applicationset-namespaces") {
 			*issues = append(*issues, issue{
 				level:   LogLevel_Error,
 				field:   ".spec.applicationSet.extraCommandArgs: --applicationset-namespaces",
+				source:  IssueSource_ExtraCommandArgs,
 				message: "The '--applicationset-namespaces' argument should not be set directly. Use '.spec.applicationSet.sourceNamespaces' field instead to enable ApplicationSets in any namespace.",
 			})
 		}
@@ -846,82 +2065,234 @@ func checkForEnvVarsOrParamsWhichOverlapWithCRFields(argoCD v1beta1.ArgoCD, issu
 			*issues = append(*issues, issue{
 				level:   LogLevel_Warn,
 				field:   ".spec.controller.extraCommandArgs: --status-processors",
+				source:  IssueSource_ExtraCommandArgs,
 				message: "While specifying --status-processors via extraCommandArgs is supported, it is preferable to use '.spec.controller.processors.status' ArgoCD CR field for this.",
 			})
 		}
 
-		if containerEnvVarContainsName(appController.Env, "ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".spec.controller.env[ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS]",
-				message: "Specifying ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS is not guaranteed to be supported. Use '.spec.controller.processors.status' ArgoCD CR field for this.",
-			})
-		}
-
 		if containerArgsContainsParam(appController.ExtraCommandArgs, "operation-processors") {
 			*issues = append(*issues, issue{
 				level:   LogLevel_Warn,
 				field:   ".spec.controller.extraCommandArgs: --operation-processors",
+				source:  IssueSource_ExtraCommandArgs,
 				message: "While specifying --operation-processors via extraCommandArgs is supported, it is preferable to use '.spec.controller.processors.operation' ArgoCD CR field for this.",
 			})
 		}
 
-		if containerEnvVarContainsName(appController.Env, "ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".spec.controller.env[ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS]",
-				message: "Specifying ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS is not guaranteed to be supported. Use '.spec.controller.processors.operation' ArgoCD CR field for this.",
-			})
-		}
-
-		if containerEnvVarContainsName(appController.Env, "ARGOCD_CONTROLLER_REPLICAS") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".spec.controller.env[ARGOCD_CONTROLLER_REPLICAS]",
-				message: "Specifying ARGOCD_CONTROLLER_REPLICAS is not supported. Use '.spec.controller.sharding.replicas' ArgoCD CR field for this.",
-			})
-		}
-
 		if containerArgsContainsParam(appController.ExtraCommandArgs, "app-resync") {
 			*issues = append(*issues, issue{
 				level:   LogLevel_Warn,
 				field:   ".spec.controller.extraCommandArgs = --app-resync",
+				source:  IssueSource_ExtraCommandArgs,
 				message: "Specifying '--app-resync' param is supported, but it is preferable to use '.spec.controller.appSync' ArgoCD CR field for this.",
 			})
 		}
 
-		if containerEnvVarContainsName(appController.Env, "ARGOCD_RECONCILIATION_TIMEOUT") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".spec.controller.env[ARGOCD_RECONCILIATION_TIMEOUT]",
-				message: "Specifying ARGOCD_RECONCILIATION_TIMEOUT is not supported. Use '.spec.controller.appSync' ArgoCD CR field for this.",
-			})
-		}
+	}
 
+	if argoCD.Spec.Server.IsEnabled() {
+		checkServerPathSettingOverlap(argoCD, issues)
 	}
 
-	if argoCD.Spec.Repo.Enabled != nil && *argoCD.Spec.Repo.Enabled {
-		repo := argoCD.Spec.Repo
+	checkEnvVarsAgainstKnowledgeBase(argoCD, issues)
 
-		if containerEnvVarContainsName(repo.Env, "ARGOCD_EXEC_TIMEOUT") {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Warn,
-				field:   ".spec.repo.env[ARGOCD_EXEC_TIMEOUT]",
-				message: "Specifying ARGOCD_EXEC_TIMEOUT is supported, but it is preferable to use '.spec.repo.execTimeout' ArgoCD CR field for this.",
-			})
+	checkForFeatureFlagsWhichOverlapWithCRBooleans(argoCD, issues)
+}
+
+// serverPathSetting is a value of one of the server subpath settings ('--rootpath'/'--basehref'), from the source it was set in
+type serverPathSetting struct {
+	field  string
+	source IssueSource
+	value  string
+}
+
+// serverPathSettingSources returns the values of a server subpath setting ('--rootpath'/'--basehref') from each source it is set in, ordered from highest precedence to lowest: the argument in '.spec.server.extraCommandArgs', then the env var (which is ignored when the argument is set).
+func serverPathSettingSources(server v1beta1.ArgoCDServerSpec, param string, envVar string) []serverPathSetting {
+
+	res := []serverPathSetting{}
+
+	if value, set := containerArgsParamValue(server.ExtraCommandArgs, param); set {
+		res = append(res, serverPathSetting{field: ".spec.server.extraCommandArgs: --" + param, source: IssueSource_ExtraCommandArgs, value: value})
+	}
+	if value, set := containerEnvVarValue(server.Env, envVar); set {
+		res = append(res, serverPathSetting{field: ".spec.server.env[" + envVar + "]", source: IssueSource_EnvVar, value: value})
+	}
+
+	return res
+}
+
+// checkServerPathSettingOverlap identifies server subpath settings which are set in more than one place, where the values conflict (Error) or are redundant (Warn). Conflicting values break routing, since the UI/API is served (or links are generated) under a different path than the user expects.
+// - Each of '--rootpath' and '--basehref' may be set via '.spec.server.extraCommandArgs', or via the 'ARGOCD_SERVER_ROOTPATH'/'ARGOCD_SERVER_BASEHREF' env vars: the argument takes precedence, and the env var is ignored.
+// - When a root path is set, Argo CD uses it as the base href, and ignores '--basehref' (logging only a warning on conflict).
+// - The ArgoCD CR (of the embedded operator API) has no root path field, so the argument/env var are the only sources.
+func checkServerPathSettingOverlap(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	server := argoCD.Spec.Server
+
+	// normalizedPath returns the path without leading/trailing slashes, so that e.g. '/argocd' and 'argocd/' are considered the same path
+	normalizedPath := func(value string) string {
+		return strings.Trim(value, "/")
+	}
+
+	// resolve returns the effective value of the setting (the argument, otherwise the env var), and reports the env var if it is ignored because the argument is also set
+	resolve := func(param string, envVar string) *serverPathSetting {
+
+		sources := serverPathSettingSources(server, param, envVar)
+		if len(sources) == 0 {
+			return nil
+		}
+
+		// Both the argument and the env var are set
+		if len(sources) > 1 {
+			argSetting, envSetting := sources[0], sources[1]
+			if normalizedPath(argSetting.value) != normalizedPath(envSetting.value) {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   envSetting.field,
+					source:  envSetting.source,
+					message: fmt.Sprintf("The server '--%s' is set to conflicting values: '%s' (via '%s') and '%s' (via '%s'). The argument takes precedence, so the env var is ignored, and the server is not served under the path the env var specifies. Set the path in only one place.", param, argSetting.value, argSetting.field, envSetting.value, envSetting.field),
+				})
+			} else {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   envSetting.field,
+					source:  envSetting.source,
+					message: fmt.Sprintf("The server '--%s' is set to '%s' both via '%s' and via '%s'. The values are the same, but the env var is redundant (the argument takes precedence), and the two may diverge when only one is updated. Set the path in only one place.", param, argSetting.value, argSetting.field, envSetting.field),
+				})
+			}
 		}
+
+		return &sources[0]
 	}
 
-	if argoCD.Spec.Server.IsEnabled() {
-		server := argoCD.Spec.Server
+	rootPath := resolve("rootpath", "ARGOCD_SERVER_ROOTPATH")
+	baseHRef := resolve("basehref", "ARGOCD_SERVER_BASEHREF")
+
+	if rootPath == nil || rootPath.value == "" || baseHRef == nil {
+		return
+	}
+
+	if normalizedPath(rootPath.value) != normalizedPath(baseHRef.value) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   baseHRef.field,
+			source:  baseHRef.source,
+			message: fmt.Sprintf("The server base href is set to '%s' (via '%s'), but the root path is set to '%s' (via '%s'). When a root path is set, Argo CD uses it as the base href, so the base href value is ignored: the UI will load its assets from '%s', not '%s'. Set only the root path (or set both to the same value).", baseHRef.value, baseHRef.field, rootPath.value, rootPath.field, rootPath.value, baseHRef.value),
+		})
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   baseHRef.field,
+		source:  baseHRef.source,
+		message: fmt.Sprintf("The server base href is set to '%s' (via '%s'), which is redundant: the root path is set to the same value (via '%s'), and Argo CD uses the root path as the base href. Remove the base href, so that the path is only set in one place.", baseHRef.value, baseHRef.field, rootPath.field),
+	})
+}
+
+// featureFlagMapping maps a boolean Argo CD feature flag of a single component (set via env var and/or command line param) to the ArgoCD CR boolean field which controls the same feature.
+type featureFlagMapping struct {
+	crField string                           // e.g. '.spec.server.insecure'
+	crValue func(argoCD v1beta1.ArgoCD) bool // the value of the CR field, or its default if unset
+
+	componentField string // e.g. '.spec.server'
+	envVar         string // empty if the feature flag cannot be set via env var
+	param          string // empty if the feature flag cannot be set via command line param
+
+	// component returns whether the component is enabled, and the env vars/command line args specified for it in the ArgoCD CR
+	component func(argoCD v1beta1.ArgoCD) (enabled bool, env []corev1.EnvVar, args []string)
+}
+
+func serverFeatureFlagComponent(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar, []string) {
+	return argoCD.Spec.Server.IsEnabled(), argoCD.Spec.Server.Env, argoCD.Spec.Server.ExtraCommandArgs
+}
+
+func controllerFeatureFlagComponent(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar, []string) {
+	return argoCD.Spec.Controller.IsEnabled(), argoCD.Spec.Controller.Env, argoCD.Spec.Controller.ExtraCommandArgs
+}
+
+func repoServerFeatureFlagComponent(argoCD v1beta1.ArgoCD) (bool, []corev1.EnvVar, []string) {
+	return argoCD.Spec.Repo.IsEnabled(), argoCD.Spec.Repo.Env, argoCD.Spec.Repo.ExtraRepoCommandArgs
+}
+
+func serverInsecureCRValue(argoCD v1beta1.ArgoCD) bool {
+	return argoCD.Spec.Server.Insecure
+}
+
+func repoVerifyTLSCRValue(argoCD v1beta1.ArgoCD) bool {
+	return argoCD.Spec.Repo.VerifyTLS
+}
+
+func redisDisableTLSVerificationCRValue(argoCD v1beta1.ArgoCD) bool {
+	return argoCD.Spec.Redis.DisableTLSVerification
+}
+
+// featureFlagMappings is the list of feature flags which have a dedicated ArgoCD CR boolean. When the CR field is true, the operator itself sets the corresponding command line param on the component.
+// - To add a new mapping, add an entry here: no other changes are required.
+var featureFlagMappings = []featureFlagMapping{
+	// The '--insecure' param is not included: it is reported (including when it is redundant with, or contradicts, '.spec.server.insecure') by checkForFailingBestPractices, as the insecure state of the server
+	{crField: ".spec.server.insecure", crValue: serverInsecureCRValue, componentField: ".spec.server", envVar: "ARGOCD_SERVER_INSECURE", component: serverFeatureFlagComponent},
+
+	{crField: ".spec.repo.verifytls", crValue: repoVerifyTLSCRValue, componentField: ".spec.server", envVar: "ARGOCD_SERVER_REPO_SERVER_STRICT_TLS", param: "repo-server-strict-tls", component: serverFeatureFlagComponent},
+	{crField: ".spec.repo.verifytls", crValue: repoVerifyTLSCRValue, componentField: ".spec.controller", envVar: "ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_STRICT_TLS", param: "repo-server-strict-tls", component: controllerFeatureFlagComponent},
+
+	{crField: ".spec.controller.sharding.dynamicScalingEnabled", crValue: func(argoCD v1beta1.ArgoCD) bool {
+		return argoCD.Spec.Controller.Sharding.DynamicScalingEnabled != nil && *argoCD.Spec.Controller.Sharding.DynamicScalingEnabled
+	}, componentField: ".spec.controller", envVar: "ARGOCD_ENABLE_DYNAMIC_CLUSTER_DISTRIBUTION", component: controllerFeatureFlagComponent},
+
+	{crField: ".spec.redis.disableTLSVerification", crValue: redisDisableTLSVerificationCRValue, componentField: ".spec.server", param: "redis-insecure-skip-tls-verify", component: serverFeatureFlagComponent},
+	{crField: ".spec.redis.disableTLSVerification", crValue: redisDisableTLSVerificationCRValue, componentField: ".spec.controller", param: "redis-insecure-skip-tls-verify", component: controllerFeatureFlagComponent},
+	{crField: ".spec.redis.disableTLSVerification", crValue: redisDisableTLSVerificationCRValue, componentField: ".spec.repo", param: "redis-insecure-skip-tls-verify", component: repoServerFeatureFlagComponent},
+}
+
+// checkForFeatureFlagsWhichOverlapWithCRBooleans reports feature flags (see featureFlagMappings) which are set via env var or command line param, rather than via their dedicated ArgoCD CR boolean.
+// - If the env var/param value matches the CR field, the setting is redundant (Warn).
+// - If the env var/param value contradicts the CR field, it is not clear which will take effect (Error).
+// - Values which are not valid booleans are ignored here: malformed env var values are reported by checkForMalformedEnvVarValues.
+func checkForFeatureFlagsWhichOverlapWithCRBooleans(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, mapping := range featureFlagMappings {
+
+		enabled, env, args := mapping.component(argoCD)
+		if !enabled {
+			continue
+		}
+
+		crValue := mapping.crValue(argoCD)
+
+		reportFlag := func(field string, source IssueSource, description string, flagValue bool) {
+
+			if flagValue == crValue {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   field,
+					source:  source,
+					message: fmt.Sprintf("%s is redundant: it has the same value ('%t') as the '%s' ArgoCD CR field. Remove it, and use only the '%s' ArgoCD CR field.", description, flagValue, mapping.crField, mapping.crField),
+				})
+				return
+			}
 
-		if containerEnvVarContainsName(server.Env, "ARGOCD_API_SERVER_REPLICAS") {
 			*issues = append(*issues, issue{
 				level:   LogLevel_Error,
-				field:   ".spec.server.env[ARGOCD_API_SERVER_REPLICAS]",
-				message: "Specifying ARGOCD_API_SERVER_REPLICAS env is not supported. Instead use ArgoCD CR '.spec.server.replicas'.",
+				field:   field,
+				source:  source,
+				message: fmt.Sprintf("%s is set to '%t', which contradicts the '%s' ArgoCD CR field (which is '%t'). Remove it, and use only the '%s' ArgoCD CR field.", description, flagValue, mapping.crField, crValue, mapping.crField),
 			})
 		}
+
+		if mapping.envVar != "" {
+			if value, set := containerEnvVarValue(env, mapping.envVar); set {
+				if flagValue, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+					reportFlag(mapping.componentField+".env["+mapping.envVar+"]", IssueSource_EnvVar, "The '"+mapping.envVar+"' environment variable", flagValue)
+				}
+			}
+		}
+
+		if mapping.param != "" {
+			if flagValue, set := containerArgsBoolParamValue(args, mapping.param); set {
+				reportFlag(mapping.componentField+".extraCommandArgs: --"+mapping.param, IssueSource_ExtraCommandArgs, "The '--"+mapping.param+"' argument", flagValue)
+			}
+		}
 	}
 }
 
@@ -931,50 +2302,9 @@ func checkForIncorrectConfigurations(argoCD v1beta1.ArgoCD, issues *[]issue) {
 	if len(argoCD.Spec.ExtraConfig) > 0 {
 		extraConfig := argoCD.Spec.ExtraConfig
 
-		// A mistake users can make is specifying 'argocd-cmd-params-cm' values in '.spec.extraConfig'. extraConfig is only for adding values to 'argocd-cm', which is different, and has a different set of supported values.
-		// This is a list of keys which I have verified are from 'argocd-cmd-params-cm' but are NOT supported when specified in extraconfig.
-		unsupportedExtraConfigKeysFromArgoCDCMDParamsCM := []string{
-			"controller.operation.processors",
-			"controller.status.processors",
-			"controller.log.format",
-			"controller.log.level",
-			"controller.sharding.algorithm",
-			"controller.kubectl.parallelism.limit",
-			"controller.diff.server.side",
-
-			"server.insecure",
-			"server.log.format",
-			"server.log.level",
-			"server.repo.server.timeout.seconds",
-			"server.repo.server.strict.tls",
-
-			"reposerver.log.format",
-			"reposerver.log.level",
-			"reposerver.parallelism.limit",
-			"reposerver.disable.tls",
-			"reposerver.repo.cache.expiration",
-			"reposerver.default.cache.expiration",
-			"reposerver.git.request.timeout",
-
-			"dexserver.log.format",
-			"dexserver.log.level",
-			"dexserver.disable.tls",
-
-			"applicationsetcontroller.log.format",
-			"applicationsetcontroller.log.level",
-			"applicationsetcontroller.dryrun",
-			"applicationsetcontroller.namespaces",
-			"applicationsetcontroller.allowed.scm.providers",
-			"applicationsetcontroller.enable.scm.providers",
-			"applicationsetcontroller.requeue.after",
-			"applicationsetcontroller.status.max.resources.count",
-
-			"notificationscontroller.log.level",
-			"notificationscontroller.log.format",
-		}
-
-		unsupportedKeysMap := make(map[string]any, len(unsupportedExtraConfigKeysFromArgoCDCMDParamsCM))
-		for _, key := range unsupportedExtraConfigKeysFromArgoCDCMDParamsCM {
+		// A mistake users can make is specifying 'argocd-cmd-params-cm' values in '.spec.extraConfig'. See 'argoCDCmdParamsCMKeys' for details.
+		unsupportedKeysMap := make(map[string]any, len(argoCDCmdParamsCMKeys))
+		for _, key := range argoCDCmdParamsCMKeys {
 			unsupportedKeysMap[key] = struct{}{}
 		}
 
@@ -1005,6 +2335,21 @@ func checkForIncorrectConfigurations(argoCD v1beta1.ArgoCD, issues *[]issue) {
 						message: "'clusterPerShard' is specified, but this value is not used because dynamic scaling is disabled. The 'clusterPerShard' field is only used when dynamic scaling is ENABLED. Enable dynamic scaling, or remove the 'clustersPerShard' field.",
 					})
 				}
+
+				// Without dynamic scaling, the operator uses '.spec.controller.sharding.replicas' as the number of application controller replicas (shards), unless it is 0, in which case the default of 1 replica is used.
+				if replicas := appController.Sharding.Replicas; replicas < 0 {
+					*issues = append(*issues, issue{
+						level:   LogLevel_Error,
+						field:   ".spec.controller.sharding.replicas",
+						message: fmt.Sprintf("Sharding is enabled, but the number of shards ('.spec.controller.sharding.replicas') is %d. The operator uses this value as the number of replicas of the application controller StatefulSet, which must not be negative, so the StatefulSet cannot be created or updated, and Applications may not be reconciled. Set 'replicas' to the number of shards, which must be at least 1 (and should be at least 2 for sharding to have an effect).", replicas),
+					})
+				} else if replicas == 0 {
+					*issues = append(*issues, issue{
+						level:   LogLevel_Error,
+						field:   ".spec.controller.sharding.replicas",
+						message: "Sharding is enabled, but the number of shards ('.spec.controller.sharding.replicas') is 0 (or not specified). The operator then ignores the value, and runs a single application controller replica, so all clusters are managed by one shard, and enabling sharding has no effect. Set 'replicas' to the number of shards, which must be at least 1 (and should be at least 2 for sharding to have an effect).",
+					})
+				}
 			}
 		}
 
@@ -1035,18 +2380,47 @@ func checkForIncorrectConfigurations(argoCD v1beta1.ArgoCD, issues *[]issue) {
 
 	}
 
+	if argoCD.Spec.Controller.IsEnabled() {
+		checkReconciliationJitter(argoCD, issues)
+		checkDynamicShardScaling(argoCD, issues)
+	}
+
+	if argoCD.Spec.Server.IsEnabled() {
+		checkServerRootPath(argoCD, issues)
+	}
+
+	// HA-only fields are ignored by the operator when HA is disabled, which can confuse users who believe they have (for example) changed the redis proxy image.
+	if !argoCD.Spec.HA.Enabled {
+		haSpec := argoCD.Spec.HA
+
+		haOnlyFields := []struct {
+			field string
+			isSet bool
+		}{
+			{field: ".spec.ha.redisProxyImage", isSet: haSpec.RedisProxyImage != ""},
+			{field: ".spec.ha.redisProxyVersion", isSet: haSpec.RedisProxyVersion != ""},
+			{field: ".spec.ha.resources", isSet: haSpec.Resources != nil},
+		}
+
+		for _, haOnlyField := range haOnlyFields {
+			if haOnlyField.isSet {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   haOnlyField.field,
+					message: "'" + haOnlyField.field + "' is specified, but HA is disabled ('.spec.ha.enabled' is false). This field is only used by the operator when HA is enabled, so it currently has no effect. Enable HA, or remove the field.",
+				})
+			}
+		}
+	}
+
 	// While the '.spec.cmdParams' fields exists for adding values to 'argocd-cmd-params-cm', only a small number of values are supported.
 	if len(argoCD.Spec.CmdParams) > 0 {
 		cmdParams := argoCD.Spec.CmdParams
 
-		// Only a subset of values are supported in CmdParams:
-		supportedCmdParams := []string{
-			"controller.resource.health.persist", "server.profile.enabled", "controller.profile.enabled",
-		}
-
+		// Only a subset of values are supported in CmdParams
 		supportCmdParamsMap := map[string]any{} // convert string list to map for efficient existence check
-		for _, supsupportedCmdParam := range supportedCmdParams {
-			supportCmdParamsMap[supsupportedCmdParam] = true
+		for _, supportedCmdParam := range supportedCmdParamsKeys {
+			supportCmdParamsMap[supportedCmdParam] = true
 		}
 
 		for key := range cmdParams {
@@ -1054,14 +2428,21 @@ func checkForIncorrectConfigurations(argoCD v1beta1.ArgoCD, issues *[]issue) {
 				*issues = append(*issues, issue{
 					level:   LogLevel_Error,
 					field:   ".spec.cmdParams[" + key + "]",
+					source:  IssueSource_CmdParams,
 					message: "The cmdParams key '" + key + "' is not a supported parameter of '.spec.cmdParams'. It will not affect Argo CD configuration. You likely instead want to either A) use the corresponding value in ArgoCD CR if it exists, or B) use environment variable/container argument to enable the configuration.",
 				})
 			}
 		}
 	}
 
+	checkForSettingsInBothConfigMaps(argoCD, issues)
+
 }
 
+// negativePolarityConditionTypes are the status condition types which indicate a problem when their status is 'True' (rather than when it is not 'True', as for e.g. 'Reconciled')
+var negativePolarityConditionTypes = []string{"Degraded", "Failed", "Error"}
+
+// checkArgoCDStatusField reports the problems which the operator records in the '.status' of the ArgoCD CR: a '.status.phase' other than 'Available', status conditions which indicate a problem, and components whose status (e.g. '.status.server') is not 'Running'.
 func checkArgoCDStatusField(argoCD v1beta1.ArgoCD, issues *[]issue) {
 	if argoCD.Status.Phase != "Available" {
 		*issues = append(*issues, issue{
@@ -1072,34 +2453,173 @@ func checkArgoCDStatusField(argoCD v1beta1.ArgoCD, issues *[]issue) {
 	}
 
 	for _, condition := range argoCD.Status.Conditions {
-		if condition.Type == "Reconciled" && condition.Status != "True" {
-			*issues = append(*issues, issue{
-				level:   LogLevel_Error,
-				field:   ".status.conditions[].type = Reconciled",
-				message: "The 'Reconciled' .status.conditions condition is currently not 'true'. This implies the ArgoCD CR has been reconciled by the operator, but not successfully. E.g. an error occured during reconciliation",
-			})
+
+		conditionDetail := ""
+		if condition.Reason != "" {
+			conditionDetail += fmt.Sprintf(" Reason: '%s'.", condition.Reason)
+		}
+		if condition.Message != "" {
+			conditionDetail += fmt.Sprintf(" Message: '%s'.", condition.Message)
 		}
-	}
-}
 
-func checkForFailingBestPractices(argoCD v1beta1.ArgoCD, issues *[]issue) {
+		if condition.Type == "Reconciled" {
+			if condition.Status != "True" {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   ".status.conditions[].type = Reconciled",
+					message: "The 'Reconciled' .status.conditions condition is currently not 'true'. This implies the ArgoCD CR has been reconciled by the operator, but not successfully. E.g. an error occured during reconciliation." + conditionDetail,
+				})
+			}
+			continue
+		}
 
-	if argoCD.Spec.Server.IsEnabled() {
-		server := argoCD.Spec.Server
+		if slices.Contains(negativePolarityConditionTypes, condition.Type) {
+			if condition.Status == "True" {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   ".status.conditions[].type = " + condition.Type,
+					message: fmt.Sprintf("The '%s' .status.conditions condition is currently 'True', which indicates a problem with the Argo CD instance.%s", condition.Type, conditionDetail),
+				})
+			}
+			continue
+		}
 
-		if server.Insecure {
+		// The meaning of a condition of any other type is not known (it may e.g. be set by a newer operator version, or by another controller), so a status other than 'True' may not indicate a problem
+		if condition.Status != "True" {
 			*issues = append(*issues, issue{
 				level:   LogLevel_Warn,
-				field:   ".spec.server.insecure",
-				message: "Argo CD server component is currently in an insecure state.",
+				field:   ".status.conditions[].type = " + condition.Type,
+				message: fmt.Sprintf("The '%s' .status.conditions condition is currently '%s' (rather than 'True'). This is not a condition type that the tool recognizes, so this may indicate a problem with the Argo CD instance.%s", condition.Type, condition.Status, conditionDetail),
 			})
 		}
 	}
 
-	if argoCD.Spec.ArgoCDAgent != nil {
-		argocdAgent := argoCD.Spec.ArgoCDAgent
-		if argocdAgent.Principal != nil {
-			principal := argocdAgent.Principal
+	checkArgoCDComponentStatuses(argoCD, issues)
+}
+
+// checkArgoCDComponentStatuses reports each enabled component whose status in the ArgoCD CR (e.g. '.status.server') is not 'Running'. The operator sets each component status to one of:
+// - 'Running': all of the pods of the component are ready.
+// - 'Pending': one or more of the resources of the component have not been created, or its pods are not yet ready. This is expected briefly after a change, but indicates a problem if it persists (e.g. pods which cannot be scheduled or pulled).
+// - 'Failed': at least one of the pods of the component had a failure.
+// - 'Unknown': the status could not be obtained. This is also the status of a component which is disabled (or, for redis and the repo server, remote), which is not reported.
+// Any other status is not one the operator is expected to report, so it is a Warn, rather than an Error. An empty status (e.g. a manifest, or a CR which has not yet been reconciled) is not reported.
+func checkArgoCDComponentStatuses(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	spec := argoCD.Spec
+	status := argoCD.Status
+
+	components := []struct {
+		name    string
+		field   string
+		status  string
+		enabled bool
+	}{
+		{name: "application controller", field: ".status.applicationController", status: status.ApplicationController, enabled: spec.Controller.IsEnabled()},
+		{name: "applicationset controller", field: ".status.applicationSetController", status: status.ApplicationSetController, enabled: spec.ApplicationSet != nil && spec.ApplicationSet.IsEnabled()},
+		{name: "notifications controller", field: ".status.notificationsController", status: status.NotificationsController, enabled: spec.Notifications.Enabled},
+		{name: "redis", field: ".status.redis", status: status.Redis, enabled: spec.Redis.IsEnabled() && !spec.Redis.IsRemote()},
+		{name: "repo server", field: ".status.repo", status: status.Repo, enabled: spec.Repo.IsEnabled() && !spec.Repo.IsRemote()},
+		{name: "server", field: ".status.server", status: status.Server, enabled: spec.Server.IsEnabled()},
+		{name: "SSO", field: ".status.sso", status: status.SSO, enabled: spec.SSO.IsEnabled()},
+	}
+
+	for _, component := range components {
+
+		if component.status == "" || component.status == "Running" || (component.status == "Unknown" && !component.enabled) {
+			continue
+		}
+
+		level := LogLevel_Error
+		var impact string
+		switch component.status {
+		case "Pending":
+			impact = "one or more of its resources have not been created, or its pods are not yet ready. If this persists, check whether the pods can be scheduled, and whether their images can be pulled"
+		case "Failed":
+			impact = "at least one of its pods had a failure. Check the status and logs of the pods"
+		case "Unknown":
+			impact = "the operator could not obtain its status. Check the operator logs, and the status of the pods"
+		default:
+			// The meaning of an unrecognized status is not known, so it may not indicate a problem
+			level = LogLevel_Warn
+			impact = "this is not a status value that the operator is expected to report. Check the status of the pods"
+		}
+
+		*issues = append(*issues, issue{
+			level:   level,
+			field:   component.field,
+			message: fmt.Sprintf("The status of the %s component is '%s' (rather than 'Running'): %s.", component.name, component.status, impact),
+		})
+	}
+}
+
+func checkForFailingBestPractices(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if argoCD.Spec.Server.IsEnabled() {
+		server := argoCD.Spec.Server
+
+		if server.Insecure {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.server.insecure",
+				message: "Argo CD server component is currently in an insecure state.",
+			})
+		}
+
+		// Insecure mode may also be enabled via the '--insecure' argument. This is reported separately from '.spec.server.insecure' (with a distinct field), so that both issues are reported when both are set.
+		// - containerArgsBoolParamValue is used (rather than containerArgsContainsParam), since '--insecure' is usually specified without a value, and may be explicitly disabled via '--insecure=false'.
+		// - This is the only check which reports the '--insecure' argument (it is not in featureFlagMappings), so it also reports an argument which contradicts '.spec.server.insecure'.
+		if insecure, set := containerArgsBoolParamValue(server.ExtraCommandArgs, "insecure"); set && insecure {
+			message := "Argo CD server component is currently in an insecure state, as '--insecure' is specified in '.spec.server.extraCommandArgs'. If insecure mode is intended, use the '.spec.server.insecure' ArgoCD CR field rather than the argument."
+			if server.Insecure {
+				message = "Argo CD server component is currently in an insecure state, as '--insecure' is specified in '.spec.server.extraCommandArgs'. The argument is redundant with the '.spec.server.insecure' ArgoCD CR field: remove it, and use only the CR field."
+			}
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.server.extraCommandArgs: --insecure",
+				source:  IssueSource_ExtraCommandArgs,
+				message: message,
+			})
+		} else if set && server.Insecure {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   ".spec.server.extraCommandArgs: --insecure",
+				source:  IssueSource_ExtraCommandArgs,
+				message: "The '--insecure' argument is set to 'false', which contradicts the '.spec.server.insecure' ArgoCD CR field (which is 'true'). Remove the argument, and use only the '.spec.server.insecure' ArgoCD CR field.",
+			})
+		}
+
+		if isExternallyExposedServiceType(server.Service.Type) {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.server.service.type",
+				message: fmt.Sprintf("The Argo CD server component is exposed via a Service of type '%s'. This exposes the server directly on the nodes/a cloud load balancer, which is often unintended on OpenShift, and may incur cost and additional security exposure. On OpenShift, it is recommended to use the default 'ClusterIP' Service type, and expose the server via a Route ('.spec.server.route') or Ingress ('.spec.server.ingress').", server.Service.Type),
+			})
+		}
+
+		// Controller sharding is generally only enabled for large installations (many clusters/Applications). In such installations, the server is also heavily used (UI/API/CLI requests, and webhook events for all Applications), so a single server replica is likely to be a bottleneck (and a single point of failure) in front of an otherwise horizontally scaled controller.
+		// - This is a heuristic: a sharded controller does not strictly require a scaled server, so this is only a Warn.
+		if argoCD.Spec.Controller.IsEnabled() && !server.Autoscale.Enabled && (server.Replicas == nil || *server.Replicas <= 1) {
+
+			if controllerShards := expectedControllerShards(argoCD); controllerShards >= minimumControllerShardsForScaledServer {
+
+				serverReplicas := "unset (defaults to 1)"
+				if server.Replicas != nil {
+					serverReplicas = fmt.Sprintf("%d", *server.Replicas)
+				}
+
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   ".spec.server.replicas",
+					message: fmt.Sprintf("The application controller is sharded across up to %d replicas, but the server has a single replica ('.spec.server.replicas' is %s), and server autoscaling is disabled. A sharded controller indicates a large installation, where a single server replica is likely to be a bottleneck for UI/API/CLI and webhook requests. Consider increasing '.spec.server.replicas', or enabling '.spec.server.autoscale'.", controllerShards, serverReplicas),
+				})
+			}
+		}
+	}
+
+	if argoCD.Spec.ArgoCDAgent != nil {
+		argocdAgent := argoCD.Spec.ArgoCDAgent
+		if argocdAgent.Principal != nil {
+			principal := argocdAgent.Principal
 
 			if principal.TLS != nil && principal.TLS.InsecureGenerate != nil && *principal.TLS.InsecureGenerate {
 				*issues = append(*issues, issue{
@@ -1108,6 +2628,14 @@ func checkForFailingBestPractices(argoCD v1beta1.ArgoCD, issues *[]issue) {
 					message: "Argo CD Agent principal is generating insecure TLS certificates",
 				})
 			}
+
+			if principal.Server != nil && isExternallyExposedServiceType(principal.Server.Service.Type) {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   ".spec.argoCDAgent.principal.server.service.type",
+					message: fmt.Sprintf("The Argo CD Agent principal component is exposed via a Service of type '%s'. This exposes the principal directly on the nodes/a cloud load balancer, which is often unintended on OpenShift, and may incur cost and additional security exposure. On OpenShift, it is recommended to use the default 'ClusterIP' Service type, and expose the principal via a Route ('.spec.argoCDAgent.principal.server.route').", principal.Server.Service.Type),
+				})
+			}
 		}
 		if argocdAgent.Agent != nil {
 			agent := argocdAgent.Agent
@@ -1121,4 +2649,2206 @@ func checkForFailingBestPractices(argoCD v1beta1.ArgoCD, issues *[]issue) {
 		}
 	}
 
+	if argoCD.Spec.HA.Enabled {
+		checkHAResourceUplift(argoCD, issues)
+	}
+}
+
+var (
+	// minimumHAMemoryLimit is the memory limit below which the redis HA pods (redis and HAProxy, which share '.spec.ha.resources') are likely to be under-resourced
+	minimumHAMemoryLimit = resource.MustParse("256Mi")
+
+	// minimumHAControllerMemoryLimit is the memory limit below which the application controller of an HA instance is likely to be under-resourced. HA is generally enabled for large installations, where the controller caches many resources.
+	minimumHAControllerMemoryLimit = resource.MustParse("1Gi")
+)
+
+// checkHAResourceUplift identifies instances where HA is enabled, but the resources of the components affected by HA were not increased to match. HA replaces the single redis pod with 3 redis and 3 HAProxy pods, which frequently fail to schedule (or are OOMKilled/throttled) when resources are left at their defaults.
+// - In HA mode, both redis and HAProxy use '.spec.ha.resources': '.spec.redis.resources' is not used. A user who has increased '.spec.redis.resources' has likely uplifted the wrong field.
+// - Resources which are not specified at all are reported, since the pods then receive only the namespace defaults (if any).
+func checkHAResourceUplift(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	underResourced := []string{}
+
+	if argoCD.Spec.Redis.IsEnabled() && !argoCD.Spec.Redis.IsRemote() {
+
+		haResources := argoCD.Spec.HA.Resources
+		if haResources == nil {
+			underResourced = append(underResourced, "redis/HAProxy ('.spec.ha.resources' is not specified)")
+		} else if limit, exists := haResources.Limits[corev1.ResourceMemory]; exists && limit.Cmp(minimumHAMemoryLimit) < 0 {
+			underResourced = append(underResourced, fmt.Sprintf("redis/HAProxy ('.spec.ha.resources' memory limit %s is below %s)", limit.String(), minimumHAMemoryLimit.String()))
+		}
+
+		if argoCD.Spec.Redis.Resources != nil {
+			underResourced = append(underResourced, "redis ('.spec.redis.resources' is specified, but is NOT used in HA mode: use '.spec.ha.resources' instead)")
+		}
+	}
+
+	if argoCD.Spec.Controller.IsEnabled() {
+
+		controllerResources := argoCD.Spec.Controller.Resources
+		if controllerResources == nil {
+			underResourced = append(underResourced, "application controller ('.spec.controller.resources' is not specified)")
+		} else if limit, exists := controllerResources.Limits[corev1.ResourceMemory]; exists && limit.Cmp(minimumHAControllerMemoryLimit) < 0 {
+			underResourced = append(underResourced, fmt.Sprintf("application controller ('.spec.controller.resources' memory limit %s is below %s)", limit.String(), minimumHAControllerMemoryLimit.String()))
+		}
+	}
+
+	if len(underResourced) == 0 {
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   ".spec.ha.enabled",
+		message: fmt.Sprintf("HA is enabled, which multiplies resource consumption (3 redis and 3 HAProxy pods replace the single redis pod), but the resources of the components affected by HA have not been increased to match. HA pods may fail to schedule, or be OOMKilled, without resource increases. Likely under-resourced: %s.", strings.Join(underResourced, "; ")),
+	})
+}
+
+// isExternallyExposedServiceType returns true if a Service of the given type is exposed outside of the cluster network (on a node port, or via a cloud load balancer)
+func isExternallyExposedServiceType(serviceType corev1.ServiceType) bool {
+	return serviceType == corev1.ServiceTypeLoadBalancer || serviceType == corev1.ServiceTypeNodePort
+}
+
+// minimumControllerShardsForScaledServer is the number of controller shards at (or above) which the server is also expected to be scaled beyond a single replica
+const minimumControllerShardsForScaledServer = 3
+
+// expectedControllerShards returns the (maximum) number of application controller shards configured in the ArgoCD CR, or 1 if sharding is not enabled.
+func expectedControllerShards(argoCD v1beta1.ArgoCD) int32 {
+
+	sharding := argoCD.Spec.Controller.Sharding
+
+	if sharding.DynamicScalingEnabled != nil && *sharding.DynamicScalingEnabled {
+		return max(sharding.MaxShards, 1)
+	}
+
+	if sharding.Enabled {
+		return max(sharding.Replicas, 1)
+	}
+
+	return 1
+}
+
+// isClusterScopedInstance returns true if the ArgoCD instance is in one of the cluster-scoped Argo CD instance namespaces (from the Subscription 'ARGOCD_CLUSTER_CONFIG_NAMESPACES' env var). Cluster-scoped instances have cluster-wide permissions.
+func isClusterScopedInstance(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation) bool {
+	return slices.Contains(clusterInfo.ClusterScopedNamespaces, argoCD.Namespace)
+}
+
+// checkLocalAdminAccount identifies ArgoCD instances where the local 'admin' account is enabled. Best practice is to disable the local admin account once SSO is configured.
+// - The admin account of a cluster-scoped instance effectively has cluster-admin privileges (via the instance), and so it is reported as an Error. The admin account of a namespace-scoped instance is a legitimate and common configuration (e.g. on development instances without SSO), and so it is only reported as Info.
+func checkLocalAdminAccount(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	// Find the effective 'admin.enabled' value, since it may be set via either '.spec.disableAdmin' or extraConfig
+	adminEnabledEntry := effectiveArgoCDCMEntry(argoCD, "admin.enabled")
+	if adminEnabledEntry == nil {
+		return
+	}
+
+	if adminEnabled, err := strconv.ParseBool(adminEnabledEntry.value); err != nil || !adminEnabled {
+		return
+	}
+
+	field := adminEnabledEntry.source
+	if field == ".spec.extraConfig" {
+		field = ".spec.extraConfig[admin.enabled]"
+	}
+
+	if isClusterScopedInstance(argoCD, clusterInfo) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: "The local 'admin' account is enabled on this cluster-scoped Argo CD instance. Since a cluster-scoped instance can manage resources across the entire cluster, the admin account effectively has cluster-wide privileges. Configure SSO and disable the local admin account by setting '.spec.disableAdmin' to true.",
+		})
+	} else {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Info,
+			field:   field,
+			message: "The local 'admin' account is enabled on this (namespace-scoped) Argo CD instance. Once SSO is configured, consider disabling the local admin account by setting '.spec.disableAdmin' to true.",
+		})
+	}
+}
+
+// checkComponentResources identifies missing (see checkForMissingControllerResources) and partially specified (see checkForPartialComponentResources) compute resources of the Argo CD components
+func checkComponentResources(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+	checkForMissingControllerResources(argoCD, clusterInfo, issues)
+	checkForPartialComponentResources(argoCD, issues)
+}
+
+// checkForPartialComponentResources identifies enabled components whose resources specify only one side (the request or the limit) of the CPU or memory requirements. Components with no requests or limits at all are not reported here (see checkForMissingControllerResources).
+// - A limit without a request: K8s defaults the request to the limit, so the scheduler reserves the full limit on the node. This is often much more than the component uses, which wastes node capacity, and may prevent the pod from being scheduled.
+// - A memory request without a memory limit: the memory usage of the component is unbounded, so it may exhaust node memory, and be evicted or OOM killed under node memory pressure. A LimitRange in the namespace may supply a default limit (see ACC016).
+// - A CPU request without a CPU limit is NOT reported: this is a common (and often recommended) configuration, since CPU is compressible, and a CPU limit throttles the component even when the node has idle CPU.
+func checkForPartialComponentResources(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, component := range argoCDComponentResources(argoCD) {
+
+		resources := component.resources
+		if resources == nil || (len(resources.Requests) == 0 && len(resources.Limits) == 0) {
+			continue
+		}
+
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+
+			request, requestSet := resources.Requests[resourceName]
+			limit, limitSet := resources.Limits[resourceName]
+
+			if limitSet && !requestSet {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   component.field,
+					message: fmt.Sprintf("The %s specifies a %s limit (%s), but no %s request. K8s defaults the request to the limit, so the full limit is reserved for each pod on its node, even if the %s uses much less: this wastes node capacity, and may prevent the pod from being scheduled. Set a %s request in '%s.requests', based on the observed usage of the %s.", component.name, resourceName, limit.String(), resourceName, component.name, resourceName, component.field, component.name),
+				})
+			} else if requestSet && !limitSet && resourceName == corev1.ResourceMemory {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   component.field,
+					message: fmt.Sprintf("The %s specifies a memory request (%s), but no memory limit, so its memory usage is unbounded (unless a LimitRange in the namespace supplies a default limit): it may exhaust the memory of its node, and be evicted or OOM killed under node memory pressure. Set a memory limit in '%s.limits'.", component.name, request.String(), component.field),
+				})
+			}
+		}
+	}
+}
+
+// checkForMissingControllerResources identifies ArgoCD instances which do not specify compute resources (requests or limits) for the application controller.
+// - The controller caches the state of every resource it manages, so its memory usage grows with the number of managed namespaces/resources. A cluster-scoped instance manages (potentially) the entire cluster, and so without explicit resources it is very likely to be OOM killed, or starved by other workloads on the node: this is an Error for cluster-scoped instances, and a Warn otherwise.
+func checkForMissingControllerResources(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	if !argoCD.Spec.Controller.IsEnabled() {
+		return
+	}
+
+	resources := argoCD.Spec.Controller.Resources
+	if resources != nil && (len(resources.Requests) > 0 || len(resources.Limits) > 0) {
+		return
+	}
+
+	if isClusterScopedInstance(argoCD, clusterInfo) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.controller.resources",
+			message: "No resources (requests/limits) are specified for the application controller of this cluster-scoped Argo CD instance. The controller caches the state of all the resources it manages, so its memory usage grows with the number of managed namespaces/resources: a cluster-scoped instance (which may manage resources across the entire cluster) will quickly exceed what is available to a pod without explicit resources, and be OOM killed or starved by other workloads. Set '.spec.controller.resources' (in particular a memory request and limit) based on the observed usage of the controller.",
+		})
+		return
+	}
+
+	// Most namespace-scoped instances manage few resources, for which the defaults are sufficient, so this is only reported as a recommendation
+	*issues = append(*issues, issue{
+		level:   LogLevel_Info,
+		field:   ".spec.controller.resources",
+		message: "No resources (requests/limits) are specified for the application controller. The memory usage of the controller grows with the number of resources it manages, so if the instance grows it may be OOM killed or starved by other workloads. Consider setting '.spec.controller.resources' (in particular a memory request and limit).",
+	})
+}
+
+// componentEnv is the list of env vars that are specified in the ArgoCD CR for a single Argo CD component
+type componentEnv struct {
+	field string // e.g. '.spec.controller.env'
+	env   []corev1.EnvVar
+}
+
+// argoCDComponentEnvs returns the env vars of each of the Argo CD components that support env vars in the ArgoCD CR.
+func argoCDComponentEnvs(argoCD v1beta1.ArgoCD) []componentEnv {
+
+	res := []componentEnv{
+		{field: ".spec.controller.env", env: argoCD.Spec.Controller.Env},
+		{field: ".spec.repo.env", env: argoCD.Spec.Repo.Env},
+		{field: ".spec.server.env", env: argoCD.Spec.Server.Env},
+		{field: ".spec.notifications.env", env: argoCD.Spec.Notifications.Env},
+		{field: ".spec.imageUpdater.env", env: argoCD.Spec.ImageUpdater.Env},
+	}
+
+	if argoCD.Spec.ApplicationSet != nil {
+		res = append(res, componentEnv{field: ".spec.applicationSet.env", env: argoCD.Spec.ApplicationSet.Env})
+	}
+
+	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil {
+		res = append(res, componentEnv{field: ".spec.sso.dex.env", env: argoCD.Spec.SSO.Dex.Env})
+	}
+
+	if argoCD.Spec.ArgoCDAgent != nil {
+		if argoCD.Spec.ArgoCDAgent.Principal != nil {
+			res = append(res, componentEnv{field: ".spec.argoCDAgent.principal.env", env: argoCD.Spec.ArgoCDAgent.Principal.Env})
+		}
+		if argoCD.Spec.ArgoCDAgent.Agent != nil {
+			res = append(res, componentEnv{field: ".spec.argoCDAgent.agent.env", env: argoCD.Spec.ArgoCDAgent.Agent.Env})
+		}
+	}
+
+	return res
+}
+
+// componentResources is the compute resources that are specified in the ArgoCD CR for a single (enabled) Argo CD component
+type componentResources struct {
+	name      string // e.g. 'application controller'
+	field     string // e.g. '.spec.controller.resources'
+	resources *corev1.ResourceRequirements
+
+	// replicas is the expected number of pods of the component
+	replicas int64
+}
+
+// argoCDComponentResources returns the resources of each Argo CD component that is enabled in the ArgoCD CR (including components with no resources specified).
+func argoCDComponentResources(argoCD v1beta1.ArgoCD) []componentResources {
+
+	replicasOrDefault := func(replicas *int32) int64 {
+		if replicas == nil || *replicas < 1 {
+			return 1
+		}
+		return int64(*replicas)
+	}
+
+	res := []componentResources{}
+
+	spec := argoCD.Spec
+
+	if spec.Controller.IsEnabled() {
+		controllerReplicas := int64(1)
+		if spec.Controller.Sharding.Enabled && spec.Controller.Sharding.Replicas > 1 {
+			controllerReplicas = int64(spec.Controller.Sharding.Replicas)
+		}
+		res = append(res, componentResources{name: "application controller", field: ".spec.controller.resources", resources: spec.Controller.Resources, replicas: controllerReplicas})
+	}
+
+	if spec.Server.IsEnabled() {
+		res = append(res, componentResources{name: "server", field: ".spec.server.resources", resources: spec.Server.Resources, replicas: replicasOrDefault(spec.Server.Replicas)})
+	}
+
+	if spec.Repo.IsEnabled() && !spec.Repo.IsRemote() {
+		res = append(res, componentResources{name: "repo server", field: ".spec.repo.resources", resources: spec.Repo.Resources, replicas: replicasOrDefault(spec.Repo.Replicas)})
+	}
+
+	if spec.Redis.IsEnabled() && !spec.Redis.IsRemote() {
+		if spec.HA.Enabled {
+			// In HA mode, redis runs as a 3 replica StatefulSet, fronted by 3 replicas of HAProxy
+			res = append(res, componentResources{name: "redis (HA)", field: ".spec.redis.resources", resources: spec.Redis.Resources, replicas: 3})
+			res = append(res, componentResources{name: "redis HAProxy", field: ".spec.ha.resources", resources: spec.HA.Resources, replicas: 3})
+		} else {
+			res = append(res, componentResources{name: "redis", field: ".spec.redis.resources", resources: spec.Redis.Resources, replicas: 1})
+		}
+	}
+
+	if spec.ApplicationSet != nil && spec.ApplicationSet.IsEnabled() {
+		res = append(res, componentResources{name: "applicationset controller", field: ".spec.applicationSet.resources", resources: spec.ApplicationSet.Resources, replicas: 1})
+	}
+
+	if spec.Notifications.Enabled {
+		res = append(res, componentResources{name: "notifications controller", field: ".spec.notifications.resources", resources: spec.Notifications.Resources, replicas: replicasOrDefault(spec.Notifications.Replicas)})
+	}
+
+	if spec.SSO.IsEnabled() && spec.SSO.Provider.ToLower() == v1beta1.SSOProviderTypeDex && spec.SSO.Dex != nil {
+		res = append(res, componentResources{name: "dex", field: ".spec.sso.dex.resources", resources: spec.SSO.Dex.Resources, replicas: 1})
+	}
+
+	if spec.ImageUpdater.Enabled {
+		res = append(res, componentResources{name: "image updater", field: ".spec.imageUpdater.resources", resources: spec.ImageUpdater.Resources, replicas: 1})
+	}
+
+	return res
+}
+
+type envVarValueType string
+
+const (
+	envVarValueType_Duration envVarValueType = "duration"
+	envVarValueType_Int      envVarValueType = "integer"
+	envVarValueType_Bool     envVarValueType = "boolean"
+)
+
+// knownEnvVarValueTypes is the expected value type of Argo CD env vars which require a value in a specific format. A malformed value for these env vars will either prevent the component from starting, or cause the value to be silently ignored.
+var knownEnvVarValueTypes = map[string]envVarValueType{
+	"ARGOCD_RECONCILIATION_TIMEOUT":                             envVarValueType_Duration,
+	"ARGOCD_HARD_RECONCILIATION_TIMEOUT":                        envVarValueType_Duration,
+	"ARGOCD_RECONCILIATION_JITTER":                              envVarValueType_Duration,
+	"ARGOCD_EXEC_TIMEOUT":                                       envVarValueType_Duration,
+	"ARGOCD_APPLICATIONSET_CONTROLLER_REQUEUE_AFTER":            envVarValueType_Duration,
+	"ARGOCD_APPLICATION_CONTROLLER_STATUS_PROCESSORS":           envVarValueType_Int,
+	"ARGOCD_APPLICATION_CONTROLLER_OPERATION_PROCESSORS":        envVarValueType_Int,
+	"ARGOCD_APPLICATION_CONTROLLER_KUBECTL_PARALLELISM_LIMIT":   envVarValueType_Int,
+	"ARGOCD_APPLICATION_CONTROLLER_SELF_HEAL_TIMEOUT_SECONDS":   envVarValueType_Int,
+	"ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS": envVarValueType_Int,
+	"ARGOCD_CONTROLLER_REPLICAS":                                envVarValueType_Int,
+	"ARGOCD_API_SERVER_REPLICAS":                                envVarValueType_Int,
+	"ARGOCD_REPO_SERVER_PARALLELISM_LIMIT":                      envVarValueType_Int,
+	"ARGOCD_GIT_ATTEMPTS_COUNT":                                 envVarValueType_Int,
+	"ARGOCD_APPLICATION_CONTROLLER_SERVER_SIDE_DIFF":            envVarValueType_Bool,
+	"ARGOCD_APPLICATIONSET_CONTROLLER_ENABLE_PROGRESSIVE_SYNCS": envVarValueType_Bool,
+	"ARGOCD_APPLICATIONSET_CONTROLLER_DRY_RUN":                  envVarValueType_Bool,
+	"ARGOCD_SERVER_INSECURE":                                    envVarValueType_Bool,
+	"ARGOCD_GIT_MODULES_ENABLED":                                envVarValueType_Bool,
+}
+
+// checkForMalformedEnvVarValues identifies env vars (from knownEnvVarValueTypes) whose values cannot be parsed as the type that Argo CD expects. For example, 'ARGOCD_RECONCILIATION_TIMEOUT=180' is missing a duration unit, and is thus invalid.
+func checkForMalformedEnvVarValues(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, component := range argoCDComponentEnvs(argoCD) {
+
+		for _, envVar := range component.env {
+
+			expectedType, exists := knownEnvVarValueTypes[envVar.Name]
+			if !exists || envVar.ValueFrom != nil { // We can't verify values that are read from a ConfigMap/Secret
+				continue
+			}
+
+			var valid bool
+			var example string
+
+			switch expectedType {
+			case envVarValueType_Duration:
+				valid = valueParsesAsDuration(envVar.Value)
+				example = "'180s', '3m', '1h'"
+			case envVarValueType_Int:
+				valid = valueParsesAsInt(envVar.Value)
+				example = "'10'"
+			case envVarValueType_Bool:
+				valid = valueParsesAsBool(envVar.Value)
+				example = "'true', 'false'"
+			}
+
+			if !valid {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   component.field + "[" + envVar.Name + "]",
+					message: fmt.Sprintf("The value '%s' of env var '%s' could not be parsed as a %s (for example: %s). A malformed value may prevent the component from starting, or cause the value to be ignored.", envVar.Value, envVar.Name, expectedType, example),
+				})
+			}
+		}
+	}
+}
+
+// isExplicitlyDisabled normalizes the different ways that ArgoCD CR components represent their enabled state, returning true only if the user has explicitly disabled the component.
+// - Most core components use an 'Enabled *bool' field, where nil means enabled (see e.g. IsEnabled() on the controller/server/repo/redis specs).
+// - Other components (e.g. notifications, agent) instead default to disabled, so 'disabled' is not notable for them, and they should not be passed to this function.
+func isExplicitlyDisabled(enabled *bool) bool {
+	return enabled != nil && !*enabled
+}
+
+// isAgentModeInstance returns true if the ArgoCD CR is configured to run either the Argo CD Agent principal or agent component.
+func isAgentModeInstance(argoCD v1beta1.ArgoCD) bool {
+	if argoCD.Spec.ArgoCDAgent == nil {
+		return false
+	}
+
+	agentSpec := argoCD.Spec.ArgoCDAgent
+
+	return (agentSpec.Principal != nil && agentSpec.Principal.IsEnabled()) || (agentSpec.Agent != nil && agentSpec.Agent.IsEnabled())
+}
+
+// checkForDisabledCoreComponents identifies core Argo CD components that have been explicitly disabled in the ArgoCD CR. Disabling a core component on a standard instance breaks core functionality (e.g. no repo server means no manifests can be generated).
+func checkForDisabledCoreComponents(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	type coreComponent struct {
+		name     string
+		field    string
+		disabled bool
+		impact   string
+	}
+
+	coreComponents := []coreComponent{
+		{
+			name:     "application controller",
+			field:    ".spec.controller.enabled",
+			disabled: isExplicitlyDisabled(argoCD.Spec.Controller.Enabled),
+			impact:   "Applications will not be reconciled/synchronized.",
+		},
+		{
+			name:     "server",
+			field:    ".spec.server.enabled",
+			disabled: isExplicitlyDisabled(argoCD.Spec.Server.Enabled),
+			impact:   "The Argo CD UI/API/CLI will not be available.",
+		},
+		{
+			name:     "repo server",
+			field:    ".spec.repo.enabled",
+			disabled: isExplicitlyDisabled(argoCD.Spec.Repo.Enabled) && !argoCD.Spec.Repo.IsRemote(), // A remote repo server legitimately replaces the local one
+			impact:   "Manifests cannot be generated from Git/Helm/OCI sources, so Applications cannot be synchronized.",
+		},
+		{
+			name:     "redis",
+			field:    ".spec.redis.enabled",
+			disabled: isExplicitlyDisabled(argoCD.Spec.Redis.Enabled) && !argoCD.Spec.Redis.IsRemote(), // A remote redis legitimately replaces the local one
+			impact:   "Argo CD components require redis for caching, and will be degraded or fail without it.",
+		},
+	}
+
+	agentMode := isAgentModeInstance(argoCD)
+
+	for _, component := range coreComponents {
+		if !component.disabled {
+			continue
+		}
+
+		if agentMode {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   component.field,
+				message: "The " + component.name + " component is explicitly disabled. This instance is running the Argo CD Agent, where some components legitimately do not run, so this may be expected. Verify this component is not required by this instance: " + component.impact,
+			})
+		} else {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   component.field,
+				message: "The " + component.name + " component is a core Argo CD component, but is explicitly disabled: " + component.impact,
+			})
+		}
+	}
+}
+
+const (
+	// minimumSafeReconciliationTimeout is the reconciliation timeout below which constant re-reconciliation of Applications places significant load on the Kubernetes API server, Git servers, and the repo server. The Argo CD default is 180s.
+	minimumSafeReconciliationTimeout = 60 * time.Second
+
+	// maximumSafeReconciliationTimeout is the reconciliation timeout above which drift between Git and the cluster will go undetected for an excessive period of time
+	maximumSafeReconciliationTimeout = 24 * time.Hour
+)
+
+// durationSource is a duration setting, and the field (and kind of source) it was read from
+type durationSource struct {
+	field  string
+	source IssueSource
+	value  time.Duration
+}
+
+// reconciliationTimeoutSources returns the fields which set the reconciliation timeout (with a valid duration), ordered from highest precedence to lowest:
+// - The operator sets the 'ARGOCD_RECONCILIATION_TIMEOUT' env var on the controller from '.spec.controller.appSync', but a user-specified env var replaces it.
+// - Otherwise, the env var is read from 'argocd-cm' 'timeout.reconciliation', which may be set via extraConfig.
+func reconciliationTimeoutSources(argoCD v1beta1.ArgoCD) []durationSource {
+
+	sources := []durationSource{}
+
+	for _, envVar := range argoCD.Spec.Controller.Env {
+		if envVar.Name != "ARGOCD_RECONCILIATION_TIMEOUT" || envVar.ValueFrom != nil {
+			continue
+		}
+		if duration, err := time.ParseDuration(envVar.Value); err == nil { // Malformed values are reported by checkForMalformedEnvVarValues
+			sources = append(sources, durationSource{field: ".spec.controller.env[ARGOCD_RECONCILIATION_TIMEOUT]", source: IssueSource_EnvVar, value: duration})
+		}
+	}
+
+	if argoCD.Spec.Controller.AppSync != nil {
+		sources = append(sources, durationSource{field: ".spec.controller.appSync", source: IssueSource_CRField, value: argoCD.Spec.Controller.AppSync.Duration})
+	}
+
+	if value, exists := argoCD.Spec.ExtraConfig["timeout.reconciliation"]; exists {
+		if duration, err := time.ParseDuration(value); err == nil { // Malformed values are reported by checkReconciliationTimeout
+			sources = append(sources, durationSource{field: ".spec.extraConfig[timeout.reconciliation]", source: IssueSource_ExtraConfig, value: duration})
+		}
+	}
+
+	return sources
+}
+
+// defaultReconciliationTimeout is the reconciliation timeout used by Argo CD when none is set
+const defaultReconciliationTimeout = 180 * time.Second
+
+// checkDynamicShardScaling identifies application controller dynamic scaling ('.spec.controller.sharding.dynamicScalingEnabled') which is combined with a static number of shards, or whose shard bounds prevent it from scaling.
+// - With dynamic scaling, the operator computes the number of replicas from the number of clusters ('clustersPerShard'), bounded by 'minShards' (default 1) and 'maxShards' (default, and never less than, 'minShards'), and ignores '.spec.controller.sharding.replicas'.
+func checkDynamicShardScaling(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	sharding := argoCD.Spec.Controller.Sharding
+
+	if sharding.DynamicScalingEnabled == nil || !*sharding.DynamicScalingEnabled {
+		return
+	}
+
+	if sharding.Replicas != 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.controller.sharding.replicas",
+			message: fmt.Sprintf("A static number of shards ('.spec.controller.sharding.replicas: %d') is specified, but dynamic scaling is enabled ('.spec.controller.sharding.dynamicScalingEnabled: true'). With dynamic scaling, the operator manages the number of application controller replicas itself (from the number of clusters, '.spec.controller.sharding.clustersPerShard', and the 'minShards'/'maxShards' bounds), so the static value is ignored. Remove 'replicas', or disable dynamic scaling to use a fixed number of shards.", sharding.Replicas),
+		})
+	}
+
+	minShards := sharding.MinShards
+	if minShards < 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.controller.sharding.minShards",
+			message: fmt.Sprintf("The minimum number of shards ('.spec.controller.sharding.minShards: %d') is less than 1, so the operator uses 1 instead. Set 'minShards' to at least 1.", sharding.MinShards),
+		})
+	}
+	minShards = max(minShards, 1)
+
+	switch {
+	case sharding.MaxShards == 0:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.controller.sharding.maxShards",
+			message: fmt.Sprintf("Dynamic scaling is enabled ('.spec.controller.sharding.dynamicScalingEnabled: true'), but '.spec.controller.sharding.maxShards' is not specified, so the operator uses the minimum number of shards (%d) as the maximum: the number of shards is fixed, and dynamic scaling has no effect. Set 'maxShards' to the largest number of application controller replicas that should be run.", minShards),
+		})
+
+	case sharding.MaxShards < minShards:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.controller.sharding.maxShards",
+			message: fmt.Sprintf("The maximum number of shards ('.spec.controller.sharding.maxShards: %d') is less than the minimum number of shards ('.spec.controller.sharding.minShards: %d'), so the operator uses the minimum as the maximum: the number of shards is fixed, and dynamic scaling has no effect. Set 'maxShards' to a value greater than 'minShards'.", sharding.MaxShards, minShards),
+		})
+
+	case sharding.MaxShards == minShards:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.controller.sharding.maxShards",
+			message: fmt.Sprintf("The minimum and maximum number of shards ('.spec.controller.sharding.minShards'/'maxShards') are both %d, so the number of shards is fixed, and dynamic scaling has no effect. Increase 'maxShards', or disable dynamic scaling and use '.spec.controller.sharding.replicas'.", minShards),
+		})
+	}
+}
+
+// checkReconciliationJitter identifies a reconciliation jitter (the maximum random delay added to each Application's reconciliation) which is set without a base reconciliation timeout, or which is not smaller than the base reconciliation timeout. Either produces erratic (and, in the latter case, potentially much longer than expected) reconciliation intervals.
+// - The jitter may be set via '.spec.controller.env[ARGOCD_RECONCILIATION_JITTER]' or the '--app-resync-jitter' argument (in seconds).
+// - Unlike upstream Argo CD, the operator does not pass 'timeout.reconciliation.jitter' from 'argocd-cm' to the controller, so setting it via '.spec.extraConfig' has no effect.
+func checkReconciliationJitter(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if _, exists := argoCD.Spec.ExtraConfig["timeout.reconciliation.jitter"]; exists && !containerEnvVarContainsName(argoCD.Spec.Controller.Env, "ARGOCD_RECONCILIATION_JITTER") {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.extraConfig[timeout.reconciliation.jitter]",
+			source:  IssueSource_ExtraConfig,
+			message: "'timeout.reconciliation.jitter' is set in extraConfig, but the operator does not pass this 'argocd-cm' value to the application controller, so it has no effect. Set the 'ARGOCD_RECONCILIATION_JITTER' env var via '.spec.controller.env' instead.",
+		})
+	}
+
+	// Sources of the jitter that are set, ordered from highest precedence to lowest
+	jitterSources := []durationSource{}
+
+	if value, set := containerArgsParamValue(argoCD.Spec.Controller.ExtraCommandArgs, "app-resync-jitter"); set {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			jitterSources = append(jitterSources, durationSource{field: ".spec.controller.extraCommandArgs: --app-resync-jitter", source: IssueSource_ExtraCommandArgs, value: time.Duration(seconds) * time.Second})
+		}
+	}
+
+	if value, set := containerEnvVarValue(argoCD.Spec.Controller.Env, "ARGOCD_RECONCILIATION_JITTER"); set {
+		if duration, err := time.ParseDuration(value); err == nil { // Malformed values are reported by checkForMalformedEnvVarValues
+			jitterSources = append(jitterSources, durationSource{field: ".spec.controller.env[ARGOCD_RECONCILIATION_JITTER]", source: IssueSource_EnvVar, value: duration})
+		}
+	}
+
+	if len(jitterSources) == 0 || jitterSources[0].value == 0 {
+		return
+	}
+
+	jitter := jitterSources[0]
+
+	timeoutSources := reconciliationTimeoutSources(argoCD)
+
+	if len(timeoutSources) == 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   jitter.field,
+			source:  jitter.source,
+			message: fmt.Sprintf("A reconciliation jitter of %s is set (via '%s'), but no base reconciliation timeout is set (so the Argo CD default of %s is used). The jitter is only meaningful relative to the base timeout: set the base timeout explicitly via '.spec.controller.appSync', and a jitter which is smaller than it.", jitter.value, jitter.field, defaultReconciliationTimeout),
+		})
+		if jitter.value < defaultReconciliationTimeout {
+			return
+		}
+		timeoutSources = []durationSource{{field: "(default)", value: defaultReconciliationTimeout}}
+	}
+
+	timeout := timeoutSources[0]
+
+	if timeout.value > 0 && jitter.value >= timeout.value {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   jitter.field,
+			source:  jitter.source,
+			message: fmt.Sprintf("The reconciliation jitter (%s, via '%s') is not smaller than the base reconciliation timeout (%s, via '%s'). Each Application is reconciled after the base timeout plus a random delay of up to the jitter, so the reconciliation interval will vary erratically between %s and %s. Set a jitter which is a fraction of the base timeout.", jitter.value, jitter.field, timeout.value, timeout.field, timeout.value, timeout.value+jitter.value),
+		})
+	}
+}
+
+// checkServerRootPath identifies a server root path (when Argo CD is served under a subpath, e.g. 'https://example.com/argocd') which is not included in the external URL of Argo CD ('url' in 'argocd-cm'). The URL is used to generate links (for example, SSO callback URLs and notification links), so these links will be broken.
+// - The root path may be set via the '--rootpath' argument or 'ARGOCD_SERVER_ROOTPATH' env var. If neither is set, the '--basehref' argument or 'ARGOCD_SERVER_BASEHREF' env var is used instead, since the UI is then served under that path.
+// - The operator generates the URL from the host of the server Route/Ingress, without a path, so a root path always requires the URL to be set explicitly via '.spec.extraConfig[url]'.
+func checkServerRootPath(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	server := argoCD.Spec.Server
+
+	// Sources of the path that are set, ordered from highest precedence to lowest
+	pathSources := append(serverPathSettingSources(server, "rootpath", "ARGOCD_SERVER_ROOTPATH"), serverPathSettingSources(server, "basehref", "ARGOCD_SERVER_BASEHREF")...)
+
+	if len(pathSources) == 0 {
+		return
+	}
+
+	rootPath := "/" + strings.Trim(pathSources[0].value, "/")
+	rootPathField := pathSources[0].field
+	rootPathSource := pathSources[0].source
+
+	if rootPath == "/" {
+		return
+	}
+
+	configuredURL, urlSet := argoCD.Spec.ExtraConfig["url"]
+	if !urlSet {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   rootPathField,
+			source:  rootPathSource,
+			message: fmt.Sprintf("Argo CD is served under the path '%s' (via '%s'), but the external URL ('url' in 'argocd-cm') is not set, so the operator generates it from the server Route/Ingress host without this path. Links generated from the URL (for example, SSO callback URLs and notification links) will not include the path, and so will be broken. Set '.spec.extraConfig[url]' to the full external URL, including the path (e.g. 'https://(host)%s').", rootPath, rootPathField, rootPath),
+		})
+		return
+	}
+
+	parsedURL, err := url.Parse(configuredURL)
+	if err != nil {
+		// A malformed URL is not the concern of this check
+		return
+	}
+
+	urlPath := "/" + strings.Trim(parsedURL.Path, "/")
+	if urlPath == rootPath || strings.HasPrefix(urlPath, rootPath+"/") {
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   ".spec.extraConfig[url]",
+		source:  IssueSource_ExtraConfig,
+		message: fmt.Sprintf("Argo CD is served under the path '%s' (via '%s'), but the external URL '%s' ('url' in 'argocd-cm') has the path '%s'. Links generated from the URL (for example, SSO callback URLs and notification links) will not match the path that Argo CD is served under, and so will be broken. Update the URL to include the path (e.g. '%s://%s%s'), or update the root path to match the URL.", rootPath, rootPathField, configuredURL, urlPath, parsedURL.Scheme, parsedURL.Host, rootPath),
+	})
+}
+
+// checkReconciliationTimeout identifies a reconciliation timeout (the interval at which Application controller compares Git against the cluster) that is outside a safe range. The timeout may be set via any of '.spec.controller.env[ARGOCD_RECONCILIATION_TIMEOUT]', '.spec.controller.appSync', or '.spec.extraConfig[timeout.reconciliation]', so the value is resolved from whichever of these is set.
+func checkReconciliationTimeout(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if !argoCD.Spec.Controller.IsEnabled() {
+		return
+	}
+
+	if value, exists := argoCD.Spec.ExtraConfig["timeout.reconciliation"]; exists {
+		if _, err := time.ParseDuration(value); err != nil {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   ".spec.extraConfig[timeout.reconciliation]",
+				source:  IssueSource_ExtraConfig,
+				message: fmt.Sprintf("The value '%s' of 'timeout.reconciliation' could not be parsed as a duration (for example: '180s', '3m', '1h'). A malformed value may cause the value to be ignored.", value),
+			})
+		}
+	}
+
+	sources := reconciliationTimeoutSources(argoCD)
+
+	if len(sources) == 0 {
+		return
+	}
+
+	resolved := sources[0]
+
+	disagreeingSources := []string{}
+	for _, source := range sources[1:] {
+		if source.value != resolved.value {
+			disagreeingSources = append(disagreeingSources, fmt.Sprintf("'%s' is %s", source.field, source.value))
+		}
+	}
+
+	if len(disagreeingSources) > 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: fmt.Sprintf("The reconciliation timeout is set in multiple places with different values: '%s' is %s, but %s. The value of '%s' takes precedence. Set the reconciliation timeout in only one place (preferably '.spec.controller.appSync') to avoid confusion.", resolved.field, resolved.value, strings.Join(disagreeingSources, ", and "), resolved.field),
+		})
+	}
+
+	switch {
+	case resolved.value == 0:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: "The resolved reconciliation timeout is 0, which disables periodic reconciliation of Applications. Drift between Git and the cluster will only be detected when an Application is refreshed (for example, via Git webhook or manual refresh).",
+		})
+
+	case resolved.value < minimumSafeReconciliationTimeout:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: fmt.Sprintf("The resolved reconciliation timeout is %s, which is below the recommended minimum of %s (the default is 180s). Each reconciliation compares every Application against Git, so a low value causes near-constant re-reconciliation, and significant load on the Kubernetes API server, Git server(s), and repo server.", resolved.value, minimumSafeReconciliationTimeout),
+		})
+
+	case resolved.value > maximumSafeReconciliationTimeout:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: fmt.Sprintf("The resolved reconciliation timeout is %s, which is above the recommended maximum of %s (the default is 180s). Drift between Git and the cluster may go undetected for up to this duration, unless Applications are refreshed by other means (for example, via Git webhook).", resolved.value, maximumSafeReconciliationTimeout),
+		})
+	}
+}
+
+// checkForMalformedRepositoryConnectionConfig verifies the structure of the SSH known hosts and TLS certificates that are used by Argo CD to connect to Git repositories. Malformed entries cause repository connection failures which are difficult to trace back to the ArgoCD CR.
+func checkForMalformedRepositoryConnectionConfig(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for lineNumber, line := range strings.Split(argoCD.Spec.InitialSSHKnownHosts.Keys, "\n") {
+		if err := parseKnownHostsLine(line); err != nil {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   ".spec.initialSSHKnownHosts.keys",
+				message: fmt.Sprintf("Line %d of the SSH known hosts is malformed (%v). Each entry should be of the form 'host keytype key'. Line: '%s'", lineNumber+1, err, strings.TrimSpace(line)),
+			})
+		}
+	}
+
+	hosts := []string{}
+	for host := range argoCD.Spec.TLS.InitialCerts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		if err := parsePEMCertificates(argoCD.Spec.TLS.InitialCerts[host]); err != nil {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   ".spec.tls.initialCerts[" + host + "]",
+				message: fmt.Sprintf("The TLS certificate data for repository server '%s' is not a valid PEM-encoded certificate: %v. Connections to this repository server will fail TLS verification.", host, err),
+			})
+		}
+	}
+}
+
+// checkRedisTLSConfiguration identifies Redis TLS settings which are internally inconsistent. Partially configured Redis TLS results in either broken connectivity between the Argo CD components and Redis, or in traffic which is not verified (and thus open to interception).
+// - When '.spec.redis.autotls' is 'openshift', the operator requests a TLS certificate from the OpenShift service CA, and configures the Argo CD components (including HA Redis/HAProxy) to connect to Redis using TLS.
+func checkRedisTLSConfiguration(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	redis := argoCD.Spec.Redis
+
+	if redis.AutoTLS != "" && !redis.WantsAutoTLS() {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.redis.autotls",
+			message: "The '.spec.redis.autotls' value '" + redis.AutoTLS + "' is not a supported auto TLS provider (the only supported value is 'openshift'). The value is ignored, so Redis TLS will not be configured by the operator.",
+		})
+	}
+
+	if !redis.IsEnabled() || redis.IsRemote() {
+		if redis.AutoTLS != "" || redis.DisableTLSVerification {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.redis",
+				message: "'.spec.redis.autotls'/'.spec.redis.disableTLSVerification' is set, but the operator-managed Redis is disabled or remote. Auto TLS only applies to the operator-managed Redis, so it will have no effect.",
+			})
+		}
+		return
+	}
+
+	if redis.DisableTLSVerification {
+		if redis.WantsAutoTLS() {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.redis.disableTLSVerification",
+				message: "Redis TLS verification is disabled, while Redis auto TLS is enabled. Traffic to Redis is encrypted, but Argo CD components will not verify the identity of the Redis server, leaving the connection open to interception. Since the certificate is issued by the OpenShift service CA (which the components already trust), disabling verification is not required: set '.spec.redis.disableTLSVerification' to false.",
+			})
+		} else {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.redis.disableTLSVerification",
+				message: "Redis TLS verification is disabled, but Redis auto TLS ('.spec.redis.autotls') is not enabled. Unless a Redis TLS certificate has been provided manually (via the 'argocd-operator-redis-tls' Secret), Redis does not use TLS, and this field has no effect. If a certificate has been provided, the Argo CD components will not verify the identity of the Redis server, leaving the connection open to interception.",
+			})
+		}
+	}
+
+	// Redis TLS client arguments that are added manually, rather than by the operator
+	if !redis.WantsAutoTLS() {
+
+		componentArgs := []struct {
+			field string
+			args  []string
+		}{
+			{field: ".spec.controller.extraCommandArgs", args: argoCD.Spec.Controller.ExtraCommandArgs},
+			{field: ".spec.server.extraCommandArgs", args: argoCD.Spec.Server.ExtraCommandArgs},
+			{field: ".spec.repo.extraRepoCommandArgs", args: argoCD.Spec.Repo.ExtraRepoCommandArgs},
+		}
+
+		for _, component := range componentArgs {
+			if useTLS, set := containerArgsBoolParamValue(component.args, "redis-use-tls"); set && useTLS {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   component.field + ": --redis-use-tls",
+					message: "The component is configured to connect to Redis using TLS, but Redis auto TLS ('.spec.redis.autotls') is not enabled. Unless a Redis TLS certificate has been provided manually, Redis does not use TLS, and the component will fail to connect to Redis. Instead, enable TLS for all components by setting '.spec.redis.autotls' to 'openshift'.",
+				})
+			}
+		}
+	}
+}
+
+// resourceCompareOptions is the structure of the 'resource.compareoptions' 'argocd-cm' value
+type resourceCompareOptions struct {
+	IgnoreAggregatedRoles              *bool  `json:"ignoreAggregatedRoles,omitempty"`
+	IgnoreResourceStatusField          string `json:"ignoreResourceStatusField,omitempty"`
+	IgnoreDifferencesOnResourceUpdates *bool  `json:"ignoreDifferencesOnResourceUpdates,omitempty"`
+}
+
+// checkResourceCompareOptions validates the structure of '.spec.extraConfig[resource.compareoptions]', and identifies compare options which broadly suppress diffing, and thus may mask real drift between Git and the cluster.
+// - 'ignoreResourceStatusField: all' is NOT reported: this is the default as of Argo CD 3.0, as the status field of a resource is rarely stored in Git.
+func checkResourceCompareOptions(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	value, exists := argoCD.Spec.ExtraConfig["resource.compareoptions"]
+	if !exists {
+		return
+	}
+
+	const field = ".spec.extraConfig[resource.compareoptions]"
+
+	var compareOptions resourceCompareOptions
+	if err := parseYAMLConfigValue(value, &compareOptions); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: "The 'resource.compareoptions' value is malformed or contains unrecognized keys: " + err.Error() + ". A malformed value causes Argo CD to fail to load the compare options, and unrecognized keys are ignored. Valid keys are 'ignoreAggregatedRoles', 'ignoreResourceStatusField', and 'ignoreDifferencesOnResourceUpdates'.",
+		})
+		return
+	}
+
+	if compareOptions.IgnoreAggregatedRoles != nil && *compareOptions.IgnoreAggregatedRoles {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: "'ignoreAggregatedRoles: true' is set in 'resource.compareoptions'. The rules of ALL aggregated ClusterRoles are ignored when diffing, so drift in the permissions granted by aggregated ClusterRoles will not be detected. This is usually only needed to avoid constant 'OutOfSync' status of aggregated ClusterRoles: if so, consider using an 'ignoreDifferences' on only the affected ClusterRoles instead.",
+		})
+	}
+
+	validStatusFieldValues := []string{"", "crd", "all", "none", "off"}
+	if !slices.Contains(validStatusFieldValues, compareOptions.IgnoreResourceStatusField) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: "'ignoreResourceStatusField: " + compareOptions.IgnoreResourceStatusField + "' in 'resource.compareoptions' is not a valid value. Valid values are 'crd', 'all', 'none', and 'off'.",
+		})
+	}
+}
+
+// cachePathEnvVars are env vars which point a component at a directory that it (or a tool it runs, such as kubectl, Helm, or GnuPG) writes cache, config, or temporary files to. In addition to these, any env var with 'CACHE' in its name is treated as a cache path.
+var cachePathEnvVars = []string{"KUBECACHEDIR", "HOME", "TMPDIR", "TMP", "TEMP", "XDG_CACHE_HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "HELM_CACHE_HOME", "HELM_CONFIG_HOME", "HELM_DATA_HOME", "GNUPGHOME"}
+
+// componentFilesystem is the env vars and volume mounts that are specified in the ArgoCD CR for a single (enabled) Argo CD component whose container runs with a read-only root filesystem
+type componentFilesystem struct {
+	name  string // e.g. 'application controller'
+	field string // e.g. '.spec.controller'
+
+	env          []corev1.EnvVar
+	volumes      []corev1.Volume
+	volumeMounts []corev1.VolumeMount
+
+	// defaultWritableMountPaths are the paths of the writable (emptyDir) volumes that the operator mounts into the container
+	defaultWritableMountPaths []string
+
+	// workload is the Deployment/StatefulSet that the operator creates for the component, and containerName is the name of the component's container within it
+	workload      client.Object
+	workloadKind  string // 'Deployment' or 'StatefulSet'
+	containerName string
+}
+
+// argoCDComponentFilesystems returns the filesystem configuration of the application controller, server, and repo server, for each that is enabled in the ArgoCD CR.
+// - The operator runs each of these containers with 'readOnlyRootFilesystem: true', so only paths on a mounted volume are writable.
+func argoCDComponentFilesystems(argoCD v1beta1.ArgoCD) []componentFilesystem {
+
+	res := []componentFilesystem{}
+
+	spec := argoCD.Spec
+
+	if spec.Controller.IsEnabled() {
+		res = append(res, componentFilesystem{
+			name: "application controller", field: ".spec.controller",
+			env: spec.Controller.Env, volumes: spec.Controller.Volumes, volumeMounts: spec.Controller.VolumeMounts,
+			defaultWritableMountPaths: []string{"/home/argocd", "/tmp"},
+			workload:                  &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: argoCD.Name + "-application-controller", Namespace: argoCD.Namespace}},
+			workloadKind:              "StatefulSet",
+			containerName:             "argocd-application-controller",
+		})
+	}
+
+	if spec.Server.IsEnabled() {
+		res = append(res, componentFilesystem{
+			name: "server", field: ".spec.server",
+			env: spec.Server.Env, volumes: spec.Server.Volumes, volumeMounts: spec.Server.VolumeMounts,
+			defaultWritableMountPaths: []string{"/home/argocd", "/tmp"},
+			workload:                  &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: argoCD.Name + "-server", Namespace: argoCD.Namespace}},
+			workloadKind:              "Deployment",
+			containerName:             "argocd-server",
+		})
+	}
+
+	if spec.Repo.IsEnabled() && !spec.Repo.IsRemote() {
+		res = append(res, componentFilesystem{
+			name: "repo server", field: ".spec.repo",
+			env: spec.Repo.Env, volumes: spec.Repo.Volumes, volumeMounts: spec.Repo.VolumeMounts,
+			defaultWritableMountPaths: []string{"/tmp", "/app/config/gpg/keys", "/home/argocd/cmp-server/plugins"},
+			workload:                  &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: argoCD.Name + "-repo-server", Namespace: argoCD.Namespace}},
+			workloadKind:              "Deployment",
+			containerName:             "argocd-repo-server",
+		})
+	}
+
+	return res
+}
+
+// cachePathsOfEnv returns the env vars of 'env' which point a cache/temporary directory at an absolute path, in the order they are specified
+func cachePathsOfEnv(env []corev1.EnvVar) []corev1.EnvVar {
+
+	res := []corev1.EnvVar{}
+
+	for _, envVar := range env {
+		if !strings.HasPrefix(envVar.Value, "/") {
+			// Ignore values from a ConfigMap/Secret (which we cannot see), and relative paths
+			continue
+		}
+		if slices.Contains(cachePathEnvVars, envVar.Name) || strings.Contains(strings.ToUpper(envVar.Name), "CACHE") {
+			res = append(res, envVar)
+		}
+	}
+
+	return res
+}
+
+// isPathUnderMountPath returns true if 'filePath' is the volume mount path, or a descendant of it
+func isPathUnderMountPath(filePath string, mountPath string) bool {
+
+	filePath = path.Clean(filePath)
+	mountPath = path.Clean(mountPath)
+
+	return filePath == mountPath || strings.HasPrefix(filePath, strings.TrimSuffix(mountPath, "/")+"/")
+}
+
+// isWritableVolume returns true if the volume source is one which a container may write to. ConfigMap, Secret, projected, and downward API volumes are always read-only.
+func isWritableVolume(volume corev1.Volume) bool {
+	return volume.ConfigMap == nil && volume.Secret == nil && volume.Projected == nil && volume.DownwardAPI == nil
+}
+
+// writableMountPathsOfContainer returns the mount paths of each of the container's volume mounts which are writable
+// - A volume mount referencing a volume that is not in 'volumes' (for example, a volume added by the operator) is assumed to be writable, to avoid false positives.
+func writableMountPathsOfContainer(volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) []string {
+
+	res := []string{}
+
+	for _, volumeMount := range volumeMounts {
+
+		if volumeMount.ReadOnly {
+			continue
+		}
+
+		volumeIndex := slices.IndexFunc(volumes, func(volume corev1.Volume) bool { return volume.Name == volumeMount.Name })
+		if volumeIndex != -1 && !isWritableVolume(volumes[volumeIndex]) {
+			continue
+		}
+
+		res = append(res, volumeMount.MountPath)
+	}
+
+	return res
+}
+
+// checkForCachePathsOnReadOnlyRootFilesystem identifies cache/temporary path env vars (for example 'KUBECACHEDIR') of the controller, server, and repo server, which point to a path that is likely not writable.
+// - The operator runs these components with a read-only root filesystem, so a path is only writable if it is on a writable volume: either one the operator mounts by default (e.g. '/tmp'), or one added via the component's '.volumeMounts'.
+// - This is a heuristic based only on the ArgoCD CR, and so is available with must-gather. When connected to a live cluster, the deployed containers are checked instead (see checkForCachePathsAgainstDeployedContainers), and the issues of this check are dropped.
+func checkForCachePathsOnReadOnlyRootFilesystem(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, component := range argoCDComponentFilesystems(argoCD) {
+
+		writableMountPaths := append(slices.Clone(component.defaultWritableMountPaths), writableMountPathsOfContainer(component.volumes, component.volumeMounts)...)
+
+		for _, envVar := range cachePathsOfEnv(component.env) {
+
+			if slices.ContainsFunc(writableMountPaths, func(mountPath string) bool { return isPathUnderMountPath(envVar.Value, mountPath) }) {
+				continue
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   component.field + ".env[" + envVar.Name + "]",
+				message: fmt.Sprintf("Env var '%s' points the %s at path '%s', which does not appear to be on a writable volume. The operator runs the %s with a read-only root filesystem, so writes to this path will likely fail, which may cause the component to crash loop. Either use a path under a writable volume (writable by default: %s), or add a writable volume (e.g. emptyDir) via '%s.volumes' and '%s.volumeMounts'.", envVar.Name, component.name, envVar.Value, component.name, strings.Join(component.defaultWritableMountPaths, ", "), component.field, component.field),
+			})
+		}
+	}
+}
+
+// namespaceLabelMap is a namespace label which relates a namespace to an Argo CD instance, and the namespaces which have that label
+type namespaceLabelMap struct {
+	label string
+
+	// key: namespace that is managed, value: namespace of argocd instance that is managing
+	namespaces map[string]string
+}
+
+// namespaceLabelMaps returns the namespace labels that relate namespaces to Argo CD instances, as collected from the cluster
+func namespaceLabelMaps(clusterInfo clusterInformation) []namespaceLabelMap {
+	return []namespaceLabelMap{
+		{label: common.ArgoCDManagedByLabel, namespaces: clusterInfo.NamespaceWithManagedByLabel},
+		{label: common.ArgoCDManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithManagedByClusterArgoCDLabel},
+		{label: common.ArgoCDApplicationSetManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel},
+		{label: common.ArgoCDNotificationsManagedByClusterArgoCDLabel, namespaces: clusterInfo.NamespaceWithArgoCDNotificationsManagedByClusterArgoCDLabel},
+	}
+}
+
+// checkForConflictingManagedByLabels identifies namespaces which carry more than one of the managed-by label variants (e.g. both 'argocd.argoproj.io/managed-by' and 'argocd.argoproj.io/managed-by-cluster-argocd'), where the labels point at different Argo CD instances. In this case, which instance manages the namespace (and thus the effective RBAC within it) is ambiguous.
+// - The conflict is reported for each Argo CD instance that is the target of one of the conflicting labels.
+// - Labels which point at the same Argo CD instance are not a conflict.
+func checkForConflictingManagedByLabels(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	labelMaps := namespaceLabelMaps(clusterInfo)
+
+	// key: namespace that is managed, value: the label variants on the namespace, in 'label=target' form
+	labelsOfNamespace := map[string][]string{}
+
+	// key: namespace that is managed, value: the distinct namespaces of the Argo CD instances targeted by the namespace's labels
+	targetsOfNamespace := map[string][]string{}
+
+	for _, labelMap := range labelMaps {
+		for namespace, managingNamespace := range labelMap.namespaces {
+			labelsOfNamespace[namespace] = append(labelsOfNamespace[namespace], labelMap.label+"="+managingNamespace)
+			if !slices.Contains(targetsOfNamespace[namespace], managingNamespace) {
+				targetsOfNamespace[namespace] = append(targetsOfNamespace[namespace], managingNamespace)
+			}
+		}
+	}
+
+	conflictingNamespaces := []string{}
+	for namespace, targets := range targetsOfNamespace {
+		if len(targets) > 1 && slices.Contains(targets, argoCD.Namespace) {
+			conflictingNamespaces = append(conflictingNamespaces, namespace)
+		}
+	}
+	sort.Strings(conflictingNamespaces)
+
+	for _, namespace := range conflictingNamespaces {
+
+		labels := labelsOfNamespace[namespace]
+		sort.Strings(labels)
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   "(labels of Namespace '" + namespace + "')",
+			message: fmt.Sprintf("Namespace '%s' carries managed-by labels which point at different Argo CD instances: %s. The namespace should be managed by a single Argo CD instance: with conflicting labels, which instance manages the namespace (and thus the effective RBAC within the namespace) is ambiguous. Remove the labels which do not point at the intended Argo CD instance.", namespace, strings.Join(labels, ", ")),
+		})
+	}
+}
+
+// luaReturnStatementRegex matches a Lua 'return' statement which returns a value (e.g. 'return hs')
+var luaReturnStatementRegex = regexp.MustCompile(`(^|[^A-Za-z0-9_])return\s+[A-Za-z_{]`)
+
+// checkResourceHealthChecks identifies '.spec.resourceHealthChecks' entries whose Lua health check script is ineffective: an empty script, or (best-effort) a script which never returns a health status. In both cases Argo CD cannot assess the health of resources of that kind.
+func checkResourceHealthChecks(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	for _, healthCheck := range argoCD.Spec.ResourceHealthChecks {
+
+		groupKind := healthCheck.Kind
+		if healthCheck.Group != "" {
+			groupKind = healthCheck.Group + "/" + healthCheck.Kind
+		}
+
+		field := ".spec.resourceHealthChecks[" + groupKind + "]"
+
+		if healthCheck.Kind == "" {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   field,
+				message: fmt.Sprintf("A resource health check (group '%s') does not specify a 'kind', so the health check does not apply to any resource kind.", healthCheck.Group),
+			})
+			continue
+		}
+
+		if strings.TrimSpace(healthCheck.Check) == "" {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field,
+				message: fmt.Sprintf("The resource health check for '%s' has an empty 'check' script. An empty health check script prevents Argo CD from assessing the health of '%s' resources. Either provide a Lua script which returns a health status, or remove the entry.", groupKind, groupKind),
+			})
+			continue
+		}
+
+		if !luaReturnStatementRegex.MatchString(healthCheck.Check) {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field,
+				message: fmt.Sprintf("The resource health check script for '%s' does not appear to return a health status (no 'return hs' statement was found). A health check script must return a table containing a 'status' (and optionally a 'message'), otherwise Argo CD cannot assess the health of '%s' resources.", groupKind, groupKind),
+			})
+		}
+	}
+}
+
+// validRespectRBACValues are the valid values of 'resource.respectRBAC' ('.spec.controller.respectRBAC'). When unset, the controller does not consider its own RBAC permissions when listing/watching resources.
+var validRespectRBACValues = []string{"normal", "strict"}
+
+// checkRespectRBAC validates the effective 'resource.respectRBAC' value (set via '.spec.controller.respectRBAC', or '.spec.extraConfig'), and identifies cluster-scoped instances where it is not enabled.
+// - With respectRBAC enabled, the controller only lists/watches the resources it has permission to access, rather than attempting (and failing) to access all resource types. This is most relevant to cluster-scoped instances, whose RBAC is commonly restricted to a subset of resource types.
+func checkRespectRBAC(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	respectRBAC := ""
+	field := ".spec.controller.respectRBAC"
+
+	if entry := effectiveArgoCDCMEntry(argoCD, "resource.respectRBAC"); entry != nil {
+		respectRBAC = entry.value
+		if entry.source == ".spec.extraConfig" {
+			field = ".spec.extraConfig[resource.respectRBAC]"
+		}
+	}
+
+	if respectRBAC != "" && !slices.Contains(validRespectRBACValues, respectRBAC) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: fmt.Sprintf("The respectRBAC value '%s' is not valid: valid values are '%s' (or unset). An invalid value is treated as if respectRBAC was not enabled.", respectRBAC, strings.Join(validRespectRBACValues, "', '")),
+		})
+		return
+	}
+
+	if respectRBAC == "" && isClusterScopedInstance(argoCD, clusterInfo) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: "respectRBAC is not enabled on this cluster-scoped Argo CD instance (resolved value: unset). Without respectRBAC, the application controller attempts to list/watch all resource types in the cluster, regardless of whether its RBAC allows it, which results in errors (and failed syncs/refreshes) when its permissions are restricted. It is recommended to set '.spec.controller.respectRBAC' to 'normal' (or 'strict', which is more accurate but generates more API server requests).",
+		})
+	}
+}
+
+// dexConfig is the subset of the Dex configuration ('.spec.sso.dex.config') which is validated by checkDexConfig
+type dexConfig struct {
+	Connectors []dexConnector `json:"connectors"`
+}
+
+type dexConnector struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Config is the connector-specific configuration, whose fields depend on the connector type
+	Config map[string]any `json:"config"`
+}
+
+// checkDexConfig parses the Dex configuration of '.spec.sso.dex.config', and reports problems which prevent Dex from starting: YAML which does not parse, and connectors which share the same 'id'.
+// - These problems are otherwise only discovered by reading the logs of the Dex pod.
+func checkDexConfig(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if argoCD.Spec.SSO == nil || argoCD.Spec.SSO.Dex == nil || strings.TrimSpace(argoCD.Spec.SSO.Dex.Config) == "" {
+		return
+	}
+
+	field := ".spec.sso.dex.config"
+
+	var config dexConfig
+	if err := yaml.Unmarshal([]byte(argoCD.Spec.SSO.Dex.Config), &config); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   field,
+			message: fmt.Sprintf("The Dex configuration is not valid YAML: %v. Dex will fail to start.", err),
+		})
+		return
+	}
+
+	connectorCountByID := map[string]int{}
+	connectorIDs := []string{} // in order of first appearance, so that issues are reported in a deterministic order
+	for _, connector := range config.Connectors {
+		if connectorCountByID[connector.ID] == 0 {
+			connectorIDs = append(connectorIDs, connector.ID)
+		}
+		connectorCountByID[connector.ID]++
+	}
+
+	for _, id := range connectorIDs {
+		if count := connectorCountByID[id]; count > 1 {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   field + ".connectors[id=" + id + "]",
+				message: fmt.Sprintf("%d Dex connectors share the connector id '%s'. Connector ids must be unique, otherwise Dex fails to start. Give each connector a unique 'id'.", count, id),
+			})
+		}
+	}
+}
+
+// insecureDexConnectorSettings are the Dex connector settings (in the 'config' of a connector) which disable TLS verification (Warn), or TLS altogether (Error), when set to true
+var insecureDexConnectorSettings = []struct {
+	key       string
+	level     LogLevel
+	rationale string
+}{
+	{key: "insecureSkipVerify", level: LogLevel_Warn, rationale: "TLS certificate verification of the identity provider is disabled, so a man-in-the-middle can impersonate the identity provider, and issue identities that Argo CD will trust. Instead, configure the CA certificate of the identity provider (e.g. 'rootCA' or 'rootCAs')"},
+	{key: "insecureCA", level: LogLevel_Warn, rationale: "TLS certificate verification of the OpenShift API server is disabled, so a man-in-the-middle can impersonate it, and issue identities that Argo CD will trust. Instead, configure the CA certificate of the API server ('rootCA')"},
+	{key: "insecureNoSSL", level: LogLevel_Error, rationale: "the connection to the LDAP server is not encrypted, so user credentials (including the bind password, and the password of each user who logs in) are sent in plaintext. Use LDAPS or StartTLS instead"},
+}
+
+// plaintextDexConnectorURLSettings are the Dex connector settings (in the 'config' of a connector) which contain the URL of the identity provider, and so must not use 'http://'
+var plaintextDexConnectorURLSettings = []string{"issuer", "baseURL", "ssoURL"}
+
+// checkForInsecureDexConnectors identifies Dex connectors which disable TLS verification (e.g. 'insecureSkipVerify: true', a Warn), or which communicate with the identity provider without TLS (e.g. an 'http://' issuer, or 'insecureNoSSL: true', an Error). These are commonly development shortcuts which remain in production configuration, and undermine the security of SSO: the identities of all users who log in via the connector can be forged or intercepted.
+// - Both '.spec.sso.dex.config' and the legacy '.spec.extraConfig[dex.config]' are checked (see checkForConflictingDexConfigSources). Configuration which does not parse is reported by checkDexConfig.
+func checkForInsecureDexConnectors(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	type dexConfigSource struct {
+		field  string
+		config string
+	}
+
+	configSources := []dexConfigSource{}
+	if argoCD.Spec.SSO != nil && argoCD.Spec.SSO.Dex != nil {
+		configSources = append(configSources, dexConfigSource{field: ".spec.sso.dex.config", config: argoCD.Spec.SSO.Dex.Config})
+	}
+	configSources = append(configSources, dexConfigSource{field: ".spec.extraConfig[dex.config]", config: argoCD.Spec.ExtraConfig["dex.config"]})
+
+	for _, configSource := range configSources {
+
+		if strings.TrimSpace(configSource.config) == "" {
+			continue
+		}
+
+		var config dexConfig
+		if err := yaml.Unmarshal([]byte(configSource.config), &config); err != nil {
+			continue
+		}
+
+		for _, connector := range config.Connectors {
+
+			connectorField := configSource.field + ".connectors[id=" + connector.ID + "].config"
+
+			for _, setting := range insecureDexConnectorSettings {
+				if value, exists := connector.Config[setting.key]; exists && strings.EqualFold(fmt.Sprintf("%v", value), "true") {
+					*issues = append(*issues, issue{
+						level:   setting.level,
+						field:   connectorField + "." + setting.key,
+						message: fmt.Sprintf("The Dex connector '%s' (type '%s') sets '%s: true': %s.", connector.ID, connector.Type, setting.key, setting.rationale),
+					})
+				}
+			}
+
+			for _, key := range plaintextDexConnectorURLSettings {
+				value, isString := connector.Config[key].(string)
+				if !isString || !strings.HasPrefix(strings.ToLower(strings.TrimSpace(value)), "http://") {
+					continue
+				}
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   connectorField + "." + key,
+					message: fmt.Sprintf("The Dex connector '%s' (type '%s') uses a plaintext (http://) URL for '%s' ('%s'), so tokens and user information from the identity provider are sent unencrypted, and can be intercepted or forged. Use an 'https://' URL.", connector.ID, connector.Type, key, value),
+				})
+			}
+		}
+	}
+}
+
+// checkForConflictingDexConfigSources identifies Dex configuration which is set both via '.spec.sso.dex' and via the legacy '.spec.extraConfig[dex.config]' key. This commonly remains on CRs which were migrated from older operator versions (where Dex was configured via the top-level '.spec.dex', or directly via 'argocd-cm'), and only one of the two is used.
+// - If '.spec.sso.dex.openShiftOAuth' is true, the operator generates the OpenShift Dex configuration, which replaces the configuration from both other fields (once 'argocd-cm' exists: when 'argocd-cm' is first created, '.spec.extraConfig' is applied last, and so briefly takes precedence).
+// - Otherwise, '.spec.extraConfig[dex.config]' takes precedence over '.spec.sso.dex.config'.
+// - The legacy top-level '.spec.dex' itself cannot be detected: it does not exist in the v1beta1 API, and is converted to '.spec.sso.dex' (or dropped) when the CR is upgraded.
+func checkForConflictingDexConfigSources(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	legacyConfig := strings.TrimSpace(argoCD.Spec.ExtraConfig["dex.config"])
+
+	if legacyConfig == "" || argoCD.Spec.SSO == nil || argoCD.Spec.SSO.Dex == nil {
+		return
+	}
+
+	dex := argoCD.Spec.SSO.Dex
+
+	ssoFields := []string{}
+	if dex.OpenShiftOAuth {
+		ssoFields = append(ssoFields, ".spec.sso.dex.openShiftOAuth")
+	}
+	if strings.TrimSpace(dex.Config) != "" {
+		ssoFields = append(ssoFields, ".spec.sso.dex.config")
+	}
+
+	if len(ssoFields) == 0 {
+		return
+	}
+
+	winningField := ".spec.extraConfig[dex.config]"
+	if dex.OpenShiftOAuth {
+		winningField = ".spec.sso.dex.openShiftOAuth"
+	}
+
+	ignoredFields := []string{}
+	for _, field := range append([]string{".spec.extraConfig[dex.config]"}, ssoFields...) {
+		if field != winningField {
+			ignoredFields = append(ignoredFields, "'"+field+"'")
+		}
+	}
+
+	operatorDescription := "the operator"
+	if clusterInfo.OperatorVersion != nil {
+		operatorDescription = "operator version " + clusterInfo.OperatorVersion.String()
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Error,
+		field:   ".spec.extraConfig[dex.config]",
+		source:  IssueSource_ExtraConfig,
+		message: fmt.Sprintf("Dex is configured both via the legacy '.spec.extraConfig[dex.config]' key and via '%s'. This is common on CRs which were migrated from older operator versions, and only one of these is used: %s uses '%s', so %s is ignored. Remove the configuration which is not intended, and configure Dex only via '.spec.sso.dex'.", strings.Join(ssoFields, "' and '"), operatorDescription, winningField, strings.Join(ignoredFields, " and ")),
+	})
+}
+
+// gitOpsOperatorArgoCDVersions maps each OpenShift GitOps operator version (major.minor) to the Argo CD version (major.minor) that it ships and is tested with
+var gitOpsOperatorArgoCDVersions = map[string]string{
+	"1.10": "2.8",
+	"1.11": "2.9",
+	"1.12": "2.10",
+	"1.13": "2.11",
+	"1.14": "2.12",
+	"1.15": "2.13",
+	"1.16": "2.14",
+	"1.17": "3.0",
+	"1.18": "3.1",
+}
+
+// imageReferenceIncludesTagOrDigest returns true if the container image reference includes a tag (e.g. 'quay.io/argoproj/argocd:v3.1.0') or a digest (e.g. '...@sha256:...'), rather than only the image name
+func imageReferenceIncludesTagOrDigest(image string) bool {
+
+	if strings.Contains(image, "@") {
+		return true
+	}
+
+	// A ':' before the last '/' is the port of the registry host (e.g. 'registry.example.com:5000/argocd'), rather than a tag
+	lastPathSegment := image[strings.LastIndex(image, "/")+1:]
+	return strings.Contains(lastPathSegment, ":")
+}
+
+// checkImageAndVersion identifies contradictory or risky combinations of '.spec.image' and '.spec.version' (the image and tag/digest of the core Argo CD components).
+// - The operator always appends '.spec.version' to '.spec.image'. So, if '.spec.image' already includes a tag or digest, the version is not applied as the user intended: the resulting image reference is invalid.
+// - A '.spec.version' of a different Argo CD version than the one shipped with the operator runs the Argo CD components at a version the operator was not tested against. This is only reported for operator versions in gitOpsOperatorArgoCDVersions.
+func checkImageAndVersion(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	if argoCD.Spec.Version == "" {
+		return
+	}
+
+	if argoCD.Spec.Image != "" && imageReferenceIncludesTagOrDigest(argoCD.Spec.Image) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.version",
+			message: fmt.Sprintf("Both '.spec.image' ('%s') and '.spec.version' ('%s') are set, but the image already includes a tag or digest. The operator appends the version to the image, so the version does not have the intended effect, and the resulting image reference is invalid. Either remove the tag/digest from '.spec.image', or remove '.spec.version'.", argoCD.Spec.Image, argoCD.Spec.Version),
+		})
+		return
+	}
+
+	if clusterInfo.OperatorVersion == nil {
+		return
+	}
+
+	operatorVersion := fmt.Sprintf("%d.%d", clusterInfo.OperatorVersion.Major, clusterInfo.OperatorVersion.Minor)
+	expectedArgoCDVersion, exists := gitOpsOperatorArgoCDVersions[operatorVersion]
+	if !exists {
+		return
+	}
+
+	// Digests (e.g. 'sha256:...') and other non-semver versions cannot be compared
+	version, err := semver.ParseTolerant(argoCD.Spec.Version)
+	if err != nil {
+		return
+	}
+
+	if fmt.Sprintf("%d.%d", version.Major, version.Minor) == expectedArgoCDVersion {
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Warn,
+		field:   ".spec.version",
+		message: fmt.Sprintf("'.spec.version' is '%s', but operator version %s ships Argo CD %s. Running Argo CD components at a version that differs from the one shipped with the operator is risky: the operator generates configuration for (and is tested against) Argo CD %s, and this may not be compatible with Argo CD %d.%d. It is recommended to remove '.spec.version', so that the Argo CD version shipped with the operator is used.", argoCD.Spec.Version, clusterInfo.OperatorVersion.String(), expectedArgoCDVersion, expectedArgoCDVersion, version.Major, version.Minor),
+	})
+}
+
+// controllerEssentialVolumes are the volumes which the operator mounts into the application controller container (volume name -> mount path). These hold the controller's home directory, TLS certificates, and 'argocd-cmd-params-cm' parameters.
+var controllerEssentialVolumes = map[string]string{
+	"argocd-repo-server-tls":              "/app/config/controller/tls",
+	common.ArgoCDRedisServerTLSSecretName: "/app/config/controller/tls/redis",
+	"argocd-home":                         "/home/argocd",
+	"argocd-cmd-params-cm":                "/home/argocd/params",
+	"argocd-application-controller-tmp":   "/tmp",
+}
+
+// checkControllerCustomContainers identifies custom init containers ('.spec.controller.initContainers') and sidecar containers ('.spec.controller.sidecarContainers') of the application controller.
+// - These are allowed by the CR, but the operator makes assumptions about the controller StatefulSet (for example, sharding is based on the pod's ordinal, and the operator owns the controller's volume layout), which custom containers may break. Custom containers are also not generally covered by support.
+// - A custom container which mounts one of the controller's essential volumes (see controllerEssentialVolumes) writable, or a '.spec.controller.volumes' entry which redefines one of those volumes, overrides state that the operator relies on, and is reported as unsupported.
+func checkControllerCustomContainers(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	controller := argoCD.Spec.Controller
+
+	if !controller.IsEnabled() {
+		return
+	}
+
+	customContainers := []struct {
+		field      string
+		kind       string
+		containers []corev1.Container
+	}{
+		{field: ".spec.controller.initContainers", kind: "init container", containers: controller.InitContainers},
+		{field: ".spec.controller.sidecarContainers", kind: "sidecar container", containers: controller.SidecarContainers},
+	}
+
+	for _, custom := range customContainers {
+
+		if len(custom.containers) == 0 {
+			continue
+		}
+
+		names := []string{}
+		for _, container := range custom.containers {
+			names = append(names, "'"+container.Name+"'")
+		}
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   custom.field,
+			message: fmt.Sprintf("The application controller defines custom %s(s): %s. Custom containers on the controller StatefulSet may interfere with assumptions the operator makes about it (for example, sharding is based on the ordinal of the controller pod, and the operator owns the controller's volume layout), and are not generally covered by support. Verify that they are required, and that they do not modify the controller's state or configuration.", custom.kind, strings.Join(names, ", ")),
+		})
+
+		for _, container := range custom.containers {
+			for _, volumeMount := range container.VolumeMounts {
+
+				if _, essential := controllerEssentialVolumes[volumeMount.Name]; !essential || volumeMount.ReadOnly {
+					continue
+				}
+
+				*issues = append(*issues, issue{
+					level:       LogLevel_Warn,
+					field:       custom.field + "[" + container.Name + "].volumeMounts[" + volumeMount.Name + "]",
+					message:     fmt.Sprintf("The custom %s '%s' mounts the controller's '%s' volume as writable (at '%s'). This volume is managed by the operator for the application controller container, and modifying its contents overrides the controller's configuration/state. Mount it read-only, or use a separate volume.", custom.kind, container.Name, volumeMount.Name, volumeMount.MountPath),
+					unsupported: true,
+				})
+			}
+		}
+	}
+
+	for _, volume := range controller.Volumes {
+
+		mountPath, essential := controllerEssentialVolumes[volume.Name]
+		if !essential {
+			continue
+		}
+
+		*issues = append(*issues, issue{
+			level:       LogLevel_Warn,
+			field:       ".spec.controller.volumes[" + volume.Name + "]",
+			message:     fmt.Sprintf("'.spec.controller.volumes' defines a volume named '%s', which is the name of a volume the operator mounts into the application controller (at '%s'). This overrides (or conflicts with) a volume which the controller relies on. Rename the volume.", volume.Name, mountPath),
+			unsupported: true,
+		})
+	}
+}
+
+// criticalGroupKinds are resource kinds (by API group, "" for the core group) which almost every Argo CD instance needs to track: the standard workload/configuration kinds, and Argo CD's own resources. Excluding these (via 'resource.exclusions') stops Argo CD from syncing, tracking, or pruning them in every Application.
+var criticalGroupKinds = []schema.GroupKind{
+	{Group: "", Kind: "ConfigMap"},
+	{Group: "", Kind: "Secret"},
+	{Group: "", Kind: "Service"},
+	{Group: "", Kind: "ServiceAccount"},
+	{Group: "", Kind: "Namespace"},
+	{Group: "", Kind: "PersistentVolumeClaim"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "batch", Kind: "CronJob"},
+	{Group: "networking.k8s.io", Kind: "Ingress"},
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+	{Group: "argoproj.io", Kind: "Application"},
+	{Group: "argoproj.io", Kind: "ApplicationSet"},
+	{Group: "argoproj.io", Kind: "AppProject"},
+	{Group: "argoproj.io", Kind: "ArgoCD"},
+}
+
+// checkResourceExclusions identifies 'resource.exclusions' entries (set via '.spec.resourceExclusions', or '.spec.extraConfig[resource.exclusions]') which exclude kinds that Argo CD almost always needs (see criticalGroupKinds). Excluded resources are not tracked, diffed, synced, or pruned by any Application, so this silently breaks Applications which contain them.
+// - The exclusions are matched in the same way as Argo CD: API groups (and clusters) are glob patterns, kinds are either '*' or an exact kind, and an empty list matches everything.
+// - Exclusions which are limited to specific clusters are still reported, since they break Applications that deploy to those clusters.
+func checkResourceExclusions(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	exclusionSources := []struct {
+		field  string
+		source IssueSource
+		value  string
+	}{
+		{field: ".spec.resourceExclusions", source: IssueSource_CRField, value: argoCD.Spec.ResourceExclusions},
+		{field: ".spec.extraConfig[resource.exclusions]", source: IssueSource_ExtraConfig, value: argoCD.Spec.ExtraConfig["resource.exclusions"]},
+	}
+
+	for _, exclusionSource := range exclusionSources {
+
+		if strings.TrimSpace(exclusionSource.value) == "" {
+			continue
+		}
+
+		var exclusions []settings.FilteredResource
+		if err := yaml.Unmarshal([]byte(exclusionSource.value), &exclusions); err != nil {
+			*issues = append(*issues, issue{
+				level:   LogLevel_Error,
+				field:   exclusionSource.field,
+				source:  exclusionSource.source,
+				message: fmt.Sprintf("The resource exclusions could not be parsed: %v. Resource exclusions must be a YAML list of entries, each with optional 'apiGroups', 'kinds', and 'clusters' lists. Argo CD will fail to load its settings while this value is invalid.", err),
+			})
+			continue
+		}
+
+		for index, exclusion := range exclusions {
+
+			// The destination clusters are not known here, so exclusions which apply only to some clusters are matched as if they applied to all
+			allClusters := exclusion
+			allClusters.Clusters = nil
+
+			excluded := []string{}
+			for _, groupKind := range criticalGroupKinds {
+				if allClusters.Match(groupKind.Group, groupKind.Kind, "") {
+					excluded = append(excluded, "'"+groupKindString(groupKind)+"'")
+				}
+			}
+
+			if len(excluded) == 0 {
+				continue
+			}
+
+			clusters := "all clusters"
+			if len(exclusion.Clusters) > 0 {
+				clusters = "clusters matching '" + strings.Join(exclusion.Clusters, "', '") + "'"
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   fmt.Sprintf("%s[%d]", exclusionSource.field, index),
+				source:  exclusionSource.source,
+				message: fmt.Sprintf("The resource exclusion with apiGroups %s and kinds %s excludes %s on %s. Excluded resources are not tracked, synced, or pruned by any Application, so Applications which contain these kinds will silently stop managing them. Narrow the exclusion to the specific groups/kinds that should be ignored.", resourceExclusionPatterns(exclusion.APIGroups), resourceExclusionPatterns(exclusion.Kinds), strings.Join(excluded, ", "), clusters),
+			})
+		}
+	}
+}
+
+// groupKindString returns the group/kind in the 'group/Kind' form used in Argo CD settings, or just 'Kind' for the core group
+func groupKindString(groupKind schema.GroupKind) string {
+	if groupKind.Group == "" {
+		return groupKind.Kind
+	}
+	return groupKind.Group + "/" + groupKind.Kind
+}
+
+// resourceExclusionPatterns describes the apiGroups/kinds patterns of a resource exclusion. An empty list matches everything.
+func resourceExclusionPatterns(patterns []string) string {
+	if len(patterns) == 0 {
+		return "(any)"
+	}
+	return "['" + strings.Join(patterns, "', '") + "']"
+}
+
+// checkServerAutoscale identifies server autoscaling ('.spec.server.autoscale') which is enabled, but which is configured such that it is ineffective: the HPA may never scale the server, or scaling is pointless.
+// - If '.spec.server.autoscale.hpa' is not set, the operator creates a HPA with sensible defaults (1-3 replicas, 50% target CPU utilization), so only the server CPU request is checked.
+// - Target utilization is relative to the CPU request of the server container, so without a CPU request the HPA cannot compute utilization, and never scales.
+func checkServerAutoscale(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	server := argoCD.Spec.Server
+
+	if !server.IsEnabled() || !server.Autoscale.Enabled {
+		return
+	}
+
+	if server.Resources == nil || server.Resources.Requests.Cpu().IsZero() {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.server.resources.requests.cpu",
+			message: "Server autoscaling is enabled, but no CPU request is set for the server. The HorizontalPodAutoscaler computes CPU utilization relative to the CPU request, so without one it cannot determine utilization, and will never scale the server. Set '.spec.server.resources.requests.cpu'.",
+		})
+	}
+
+	hpa := server.Autoscale.HPA
+	if hpa == nil {
+		return
+	}
+
+	minReplicas := int32(1)
+	if hpa.MinReplicas != nil {
+		minReplicas = *hpa.MinReplicas
+	}
+
+	switch {
+	case hpa.MaxReplicas < 1:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.server.autoscale.hpa.maxReplicas",
+			message: "Server autoscaling is enabled with a custom '.spec.server.autoscale.hpa', but 'maxReplicas' is not set (or is less than 1). 'maxReplicas' is required, so the HorizontalPodAutoscaler is invalid, and the server will not be autoscaled. Set 'maxReplicas' to the maximum number of server replicas.",
+		})
+
+	case minReplicas > hpa.MaxReplicas:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.server.autoscale.hpa.minReplicas",
+			message: fmt.Sprintf("Server autoscaling 'minReplicas' (%d) is greater than 'maxReplicas' (%d), so the HorizontalPodAutoscaler is invalid, and the server will not be autoscaled. Set 'minReplicas' to less than 'maxReplicas'.", minReplicas, hpa.MaxReplicas),
+		})
+
+	case minReplicas == hpa.MaxReplicas:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.server.autoscale.hpa.minReplicas",
+			message: fmt.Sprintf("Server autoscaling 'minReplicas' and 'maxReplicas' are both %d, so the HorizontalPodAutoscaler can never change the number of replicas, and autoscaling is pointless. Either increase 'maxReplicas', or disable autoscaling and set '.spec.server.replicas' instead.", minReplicas),
+		})
+	}
+
+	if hpa.TargetCPUUtilizationPercentage == nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.server.autoscale.hpa.targetCPUUtilizationPercentage",
+			message: "Server autoscaling is enabled with a custom '.spec.server.autoscale.hpa', but no 'targetCPUUtilizationPercentage' is set, so the Kubernetes default (80%) is used. Set a target utilization which is appropriate for the server (the operator default is 50%).",
+		})
+	} else if target := *hpa.TargetCPUUtilizationPercentage; target < 10 || target > 95 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.server.autoscale.hpa.targetCPUUtilizationPercentage",
+			message: fmt.Sprintf("Server autoscaling 'targetCPUUtilizationPercentage' is %d%%. A very low target causes the server to scale up on minimal load (and to thrash between replica counts), while a very high target means the server is unlikely to scale before it is saturated. A target between 50%% and 80%% is typical.", target),
+		})
+	}
+
+	expectedTarget := argoCD.Name + "-server"
+	if target := hpa.ScaleTargetRef; target.Kind != "Deployment" || target.Name != expectedTarget {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Error,
+			field:   ".spec.server.autoscale.hpa.scaleTargetRef",
+			message: fmt.Sprintf("Server autoscaling 'scaleTargetRef' is '%s/%s', but the server is the Deployment '%s'. The HorizontalPodAutoscaler will not scale the server. Set 'scaleTargetRef' to 'apiVersion: apps/v1', 'kind: Deployment', 'name: %s'.", target.Kind, target.Name, expectedTarget, expectedTarget),
+		})
+	}
+}
+
+// checkWebTerminal identifies instances which enable the web-based terminal ('exec.enabled' in '.spec.extraConfig'), which allows users to open a shell in the pods of their Applications via the Argo CD UI.
+// - The terminal is only as safe as the RBAC policy which grants the 'exec' permission, so the finding is escalated when the RBAC policy grants it to every user (via the default role).
+func checkWebTerminal(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	// Argo CD only enables the terminal for the exact value 'true'
+	if argoCD.Spec.ExtraConfig["exec.enabled"] != "true" {
+		return
+	}
+
+	field := ".spec.extraConfig[exec.enabled]"
+
+	message := "The web-based terminal is enabled ('exec.enabled: true'). This allows users to open a shell in any pod of the Applications they have access to, via the Argo CD UI, which is a significant security surface: " +
+		"the shell runs with the permissions of the container, and commands run within it are not recorded by Argo CD (only the opening of the session is logged by the Argo CD server). " +
+		"Only grant the 'exec' 'create' RBAC permission to trusted roles (for example 'p, role:<trusted-role>, exec, create, <project>/*, allow'), and disable the terminal if it is not required."
+
+	defaultPolicy := common.ArgoCDDefaultRBACDefaultPolicy
+	if argoCD.Spec.RBAC.DefaultPolicy != nil {
+		defaultPolicy = *argoCD.Spec.RBAC.DefaultPolicy
+	}
+
+	permissiveReason := ""
+	if defaultPolicy == "role:admin" {
+		permissiveReason = "the default RBAC role ('.spec.rbac.defaultPolicy') is 'role:admin', which includes the 'exec' permission"
+
+	} else if defaultPolicy != "" && argoCD.Spec.RBAC.Policy != nil && rbacPolicyGrantsExec(*argoCD.Spec.RBAC.Policy, defaultPolicy) {
+		permissiveReason = fmt.Sprintf("the RBAC policy ('.spec.rbac.policy') grants the 'exec' permission to the default RBAC role '%s' (directly, or via a role it inherits)", defaultPolicy)
+	}
+
+	if permissiveReason == "" {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: message,
+			source:  IssueSource_ExtraConfig,
+		})
+		return
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Error,
+		field:   field,
+		message: "The web-based terminal is enabled, and " + permissiveReason + ", so EVERY user who can log in to Argo CD can open a shell in the pods of the Applications they can see. " + message,
+		source:  IssueSource_ExtraConfig,
+	})
+}
+
+// rbacPolicyGrantsExec returns true if the Argo CD RBAC policy CSV contains a policy line which allows the 'exec' 'create' permission (or a wildcard of either) to the given role, or to a role that it inherits (see rbacInheritedRoles). The built-in 'role:admin' includes the permission.
+func rbacPolicyGrantsExec(policy string, role string) bool {
+
+	roles := rbacInheritedRoles(policy, role)
+	if roles["role:admin"] {
+		return true
+	}
+
+	for _, fields := range rbacPolicyLines(policy) {
+
+		// 'p, <subject>, <resource>, <action>, <object>[, <effect>]'
+		if len(fields) < 5 || fields[0] != "p" || !roles[fields[1]] {
+			continue
+		}
+
+		if (fields[2] == "exec" || fields[2] == "*") && (fields[3] == "create" || fields[3] == "*") && (len(fields) < 6 || fields[5] != "deny") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rbacInheritedRoles returns the given role, and every role that it inherits (transitively) via the 'g, <subject>, <role>' lines of the Argo CD RBAC policy CSV, since a role has the permissions of all of the roles assigned to it
+func rbacInheritedRoles(policy string, role string) map[string]bool {
+
+	res := map[string]bool{role: true}
+
+	// Repeated until no further roles are found, since the 'g' lines may be in any order
+	for found := true; found; {
+		found = false
+		for _, fields := range rbacPolicyLines(policy) {
+			if len(fields) >= 3 && fields[0] == "g" && res[fields[1]] && !res[fields[2]] {
+				res[fields[2]] = true
+				found = true
+			}
+		}
+	}
+
+	return res
+}
+
+// rbacPolicyLines returns the (whitespace trimmed) comma-separated fields of each line of an Argo CD RBAC policy CSV
+func rbacPolicyLines(policy string) [][]string {
+
+	res := [][]string{}
+
+	for line := range strings.Lines(policy) {
+
+		fields := strings.Split(line, ",")
+		for idx := range fields {
+			fields[idx] = strings.TrimSpace(fields[idx])
+		}
+
+		res = append(res, fields)
+	}
+
+	return res
+}
+
+// validLogFormats are the log formats accepted by the Argo CD components
+var validLogFormats = []string{"text", "json"}
+
+// componentLogFormat describes where the log format of an Argo CD component may be configured: a CR field, an 'argocd-cmd-params-cm' key (via '.spec.cmdParams'), or an env var.
+type componentLogFormat struct {
+	component    string // e.g. 'application controller'
+	crField      string // e.g. '.spec.controller.logFormat'
+	cmdParamsKey string // e.g. 'controller.log.format'
+	envField     string // e.g. '.spec.controller.env'
+	envVar       string // e.g. 'ARGOCD_APPLICATION_CONTROLLER_LOGFORMAT'
+
+	enabled bool
+	crValue string
+	env     []corev1.EnvVar
+}
+
+// argoCDComponentLogFormats returns the log format configuration of each Argo CD component
+func argoCDComponentLogFormats(argoCD v1beta1.ArgoCD) []componentLogFormat {
+
+	spec := argoCD.Spec
+
+	res := []componentLogFormat{
+		{component: "application controller", crField: ".spec.controller.logFormat", cmdParamsKey: "controller.log.format", envField: ".spec.controller.env", envVar: "ARGOCD_APPLICATION_CONTROLLER_LOGFORMAT",
+			enabled: spec.Controller.IsEnabled(), crValue: spec.Controller.LogFormat, env: spec.Controller.Env},
+		{component: "server", crField: ".spec.server.logFormat", cmdParamsKey: "server.log.format", envField: ".spec.server.env", envVar: "ARGOCD_SERVER_LOGFORMAT",
+			enabled: spec.Server.IsEnabled(), crValue: spec.Server.LogFormat, env: spec.Server.Env},
+		{component: "repo server", crField: ".spec.repo.logFormat", cmdParamsKey: "reposerver.log.format", envField: ".spec.repo.env", envVar: "ARGOCD_REPO_SERVER_LOGFORMAT",
+			enabled: spec.Repo.IsEnabled() && !spec.Repo.IsRemote(), crValue: spec.Repo.LogFormat, env: spec.Repo.Env},
+		{component: "notifications controller", crField: ".spec.notifications.logformat", cmdParamsKey: "notificationscontroller.log.format", envField: ".spec.notifications.env", envVar: "ARGOCD_NOTIFICATIONS_CONTROLLER_LOGFORMAT",
+			enabled: spec.Notifications.Enabled, crValue: spec.Notifications.LogFormat, env: spec.Notifications.Env},
+	}
+
+	if spec.ApplicationSet != nil {
+		res = append(res, componentLogFormat{component: "applicationset controller", crField: ".spec.applicationSet.logformat", cmdParamsKey: "applicationsetcontroller.log.format", envField: ".spec.applicationSet.env", envVar: "ARGOCD_APPLICATIONSET_CONTROLLER_LOGFORMAT",
+			enabled: spec.ApplicationSet.IsEnabled(), crValue: spec.ApplicationSet.LogFormat, env: spec.ApplicationSet.Env})
+	}
+
+	return res
+}
+
+// checkLogFormats validates the log format of each enabled Argo CD component, from each source where it may be configured (CR field, '.spec.cmdParams', env var), and identifies components whose log formats are inconsistent.
+// - The operator always passes the log format to the component as a container argument (the CR field, or 'text' if not set), which takes precedence over the other sources. Thus the resolved format is always the CR field value (or 'text'), and a different value in another source is ignored.
+// - Components which log in different formats complicate log aggregation, since the log pipeline must parse both formats.
+func checkLogFormats(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	// resolvedFormats are the resolved (valid) log formats of each enabled component, by component
+	resolvedFormats := map[string]string{}
+	componentsByFormat := map[string][]string{}
+
+	for _, logFormat := range argoCDComponentLogFormats(argoCD) {
+
+		if !logFormat.enabled {
+			continue
+		}
+
+		resolved := common.ArgoCDDefaultLogFormat
+		if logFormat.crValue != "" {
+			resolved = logFormat.crValue
+		}
+
+		type logFormatSource struct {
+			field  string
+			source IssueSource
+			value  string
+		}
+
+		sources := []logFormatSource{}
+		if logFormat.crValue != "" {
+			sources = append(sources, logFormatSource{field: logFormat.crField, source: IssueSource_CRField, value: logFormat.crValue})
+		}
+		if value, exists := argoCD.Spec.CmdParams[logFormat.cmdParamsKey]; exists {
+			sources = append(sources, logFormatSource{field: ".spec.cmdParams[" + logFormat.cmdParamsKey + "]", source: IssueSource_CmdParams, value: value})
+		}
+		for _, envVar := range []string{logFormat.envVar, "ARGOCD_LOG_FORMAT"} {
+			if value, exists := containerEnvVarValue(logFormat.env, envVar); exists {
+				sources = append(sources, logFormatSource{field: logFormat.envField + "[" + envVar + "]", source: IssueSource_EnvVar, value: value})
+			}
+		}
+
+		for _, source := range sources {
+
+			if !slices.Contains(validLogFormats, source.value) {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Error,
+					field:   source.field,
+					message: fmt.Sprintf("The %s log format '%s' is not valid: valid log formats are '%s'. Argo CD components fail to start (or ignore the value) when the log format is not valid.", logFormat.component, source.value, strings.Join(validLogFormats, "', '")),
+					source:  source.source,
+				})
+				continue
+			}
+
+			if source.source != IssueSource_CRField && source.value != resolved {
+				*issues = append(*issues, issue{
+					level:   LogLevel_Warn,
+					field:   source.field,
+					message: fmt.Sprintf("The %s log format is set to '%s' here, but the resolved log format is '%s' (from %s): the operator passes the log format to the component as a container argument, which takes precedence, so this value is ignored. Set '%s' instead, and remove this value.", logFormat.component, source.value, resolved, resolvedLogFormatDescription(logFormat), logFormat.crField),
+					source:  source.source,
+				})
+			}
+		}
+
+		if !slices.Contains(validLogFormats, resolved) {
+			continue
+		}
+
+		resolvedFormats[logFormat.component] = resolved
+		componentsByFormat[resolved] = append(componentsByFormat[resolved], logFormat.component)
+	}
+
+	if len(componentsByFormat) < 2 {
+		return
+	}
+
+	// The most common format is assumed to be the intended one, and the components which differ from it are reported
+	majorityFormat := common.ArgoCDDefaultLogFormat
+	for format, components := range componentsByFormat {
+		if len(components) > len(componentsByFormat[majorityFormat]) {
+			majorityFormat = format
+		}
+	}
+
+	for _, logFormat := range argoCDComponentLogFormats(argoCD) {
+
+		resolved, exists := resolvedFormats[logFormat.component]
+		if !exists || resolved == majorityFormat {
+			continue
+		}
+
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   logFormat.crField,
+			message: fmt.Sprintf("The %s logs in the '%s' format (from %s), but the other Argo CD components (%s) log in the '%s' format. Inconsistent log formats complicate log aggregation, since the log pipeline must parse both formats. Set '.logFormat' of all components to the same value.", logFormat.component, resolved, resolvedLogFormatDescription(logFormat), strings.Join(componentsByFormat[majorityFormat], ", "), majorityFormat),
+		})
+	}
+}
+
+// resolvedLogFormatDescription describes where the resolved log format of a component comes from: its CR field, or the operator default
+func resolvedLogFormatDescription(logFormat componentLogFormat) string {
+	if logFormat.crValue != "" {
+		return "'" + logFormat.crField + "'"
+	}
+	return "the operator default"
+}
+
+// serviceAccountTokenMountPath is the path at which Kubernetes mounts the service account token into a container, when the token is automounted
+const serviceAccountTokenMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// checkRepoServerServiceAccountToken identifies configurations which expose a service account token to the repo server. The repo server does not use the K8s API (manifests are generated from Git/Helm/OCI sources only), so by default the operator disables automounting of the token, and a token that is made available is only useful to an attacker who compromises the repo server (for example, via a malicious Helm chart or config management plugin).
+// - The token is exposed by '.spec.repo.mountsatoken', by a projected volume with a 'serviceAccountToken' source in '.spec.repo.volumes', or by a volume mount at the standard token path in '.spec.repo.volumeMounts'.
+// - Only the repo server is checked: the other components (e.g. the application controller, server, and ApplicationSet controller) use the K8s API, so a token is required, and is mounted by the operator.
+func checkRepoServerServiceAccountToken(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	repo := argoCD.Spec.Repo
+
+	if !repo.IsEnabled() || repo.IsRemote() {
+		return
+	}
+
+	serviceAccount := "the default repo server service account"
+	if repo.ServiceAccount != "" {
+		serviceAccount = "the '" + repo.ServiceAccount + "' service account ('.spec.repo.serviceaccount')"
+	}
+
+	recommendation := "Unless a config management plugin of the repo server requires K8s API access, remove this, so that a compromise of the repo server (which runs tools such as Helm and Kustomize against untrusted repository content) cannot be used to access the K8s API. If API access is required, ensure the service account is only granted the minimum RBAC permissions required."
+
+	if repo.MountSAToken {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.repo.mountsatoken",
+			message: "The repo server mounts the token of " + serviceAccount + " ('.spec.repo.mountsatoken: true'), but the repo server does not itself require K8s API access. " + recommendation,
+		})
+	}
+
+	for _, volume := range repo.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.ServiceAccountToken == nil {
+				continue
+			}
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   ".spec.repo.volumes[" + volume.Name + "]",
+				message: "The repo server volume '" + volume.Name + "' projects a token of " + serviceAccount + ", but the repo server does not itself require K8s API access. " + recommendation,
+			})
+			break
+		}
+	}
+
+	for _, volumeMount := range repo.VolumeMounts {
+		if path.Clean(volumeMount.MountPath) != serviceAccountTokenMountPath {
+			continue
+		}
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   ".spec.repo.volumeMounts[" + volumeMount.Name + "]",
+			message: "The repo server volume mount '" + volumeMount.Name + "' mounts a volume at the service account token path '" + serviceAccountTokenMountPath + "', which makes a token available to tools that use the default in-cluster K8s client configuration, but the repo server does not itself require K8s API access. " + recommendation,
+		})
+	}
+}
+
+// checkStatusBadge identifies instances which enable the Application status badge ('statusbadge.enabled' in 'argocd-cm'). The badge endpoint ('/api/badge') returns the sync/health status (and optionally the revision) of an Application without authentication, to anyone who can reach the Argo CD server and knows (or guesses) the Application name.
+// - This is often intentional (e.g. badges in a README), so it is a Warn, escalated to an Error when anonymous access ('users.anonymous.enabled') is also enabled, since the instance then exposes Application information without authentication by two separate means.
+func checkStatusBadge(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	statusBadgeEntry := effectiveArgoCDCMEntry(argoCD, "statusbadge.enabled")
+	if statusBadgeEntry == nil || statusBadgeEntry.value != "true" {
+		return
+	}
+
+	field := ".spec.statusBadgeEnabled"
+	source := IssueSource_CRField
+	if statusBadgeEntry.source == ".spec.extraConfig" {
+		field = ".spec.extraConfig[statusbadge.enabled]"
+		source = IssueSource_ExtraConfig
+	}
+
+	message := fmt.Sprintf("The Application status badge is enabled (via '%s'). The badge endpoint ('/api/badge') returns the sync and health status of any Application (and, if requested, its revision) without authentication, to anyone who can reach the Argo CD server and knows the Application name. If the badges are not used, disable the status badge.", field)
+
+	anonymousEntry := effectiveArgoCDCMEntry(argoCD, "users.anonymous.enabled")
+	if anonymousEntry == nil || anonymousEntry.value != "true" {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			source:  source,
+			message: message,
+		})
+		return
+	}
+
+	anonymousField := ".spec.usersAnonymousEnabled"
+	if anonymousEntry.source == ".spec.extraConfig" {
+		anonymousField = ".spec.extraConfig[users.anonymous.enabled]"
+	}
+
+	*issues = append(*issues, issue{
+		level:   LogLevel_Error,
+		field:   field,
+		source:  source,
+		message: fmt.Sprintf("The Application status badge is enabled, and anonymous access is also enabled (via '%s'), so Application information is available without authentication both via the badge endpoint, and via the UI/API (with the permissions of the default RBAC role). Unless this Argo CD instance is intended to be public, disable anonymous access, and the status badge if it is not used. %s", anonymousField, message),
+	})
+}
+
+const (
+	// defaultControllerRepoServerTimeout is the timeout of the application controller's calls to the repo server (e.g. to generate manifests) used by Argo CD when none is set
+	defaultControllerRepoServerTimeout = 60 * time.Second
+
+	// minimumSafeControllerRepoServerTimeout is the controller repo server timeout below which manifest generation of large repositories (e.g. monorepos) or slow Helm/Kustomize/plugin builds is likely to time out, causing the Application to fail to sync, with a 'context deadline exceeded' ComparisonError
+	minimumSafeControllerRepoServerTimeout = defaultControllerRepoServerTimeout
+)
+
+// controllerRepoServerTimeoutSources returns the fields which set the application controller repo server timeout (with a valid integer number of seconds), ordered from highest precedence to lowest:
+// - The '--repo-server-timeout-seconds' argument (via '.spec.controller.extraCommandArgs'), whose default is read from the 'ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS' env var. The operator sets neither.
+// - The 'argocd-cmd-params-cm' key ('controller.repo.server.timeout.seconds') is not read from '.spec.extraConfig', which only populates 'argocd-cm', so it is not a source (a value set there is reported by checkForIncorrectConfigurations, see argoCDCmdParamsCMKeys).
+func controllerRepoServerTimeoutSources(argoCD v1beta1.ArgoCD) []durationSource {
+
+	res := []durationSource{}
+
+	parseSeconds := func(value string) (time.Duration, bool) {
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if value, exists := containerArgsParamValue(argoCD.Spec.Controller.ExtraCommandArgs, "repo-server-timeout-seconds"); exists {
+		if timeout, valid := parseSeconds(value); valid {
+			res = append(res, durationSource{field: ".spec.controller.extraCommandArgs: --repo-server-timeout-seconds", source: IssueSource_ExtraCommandArgs, value: timeout})
+		}
+	}
+
+	if value, exists := containerEnvVarValue(argoCD.Spec.Controller.Env, "ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS"); exists {
+		if timeout, valid := parseSeconds(value); valid { // Malformed values are reported by checkForMalformedEnvVarValues
+			res = append(res, durationSource{field: ".spec.controller.env[ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS]", source: IssueSource_EnvVar, value: timeout})
+		}
+	}
+
+	return res
+}
+
+// checkControllerRepoServerTimeout identifies an application controller repo server timeout (the maximum duration of each call from the controller to the repo server, e.g. to generate the manifests of an Application) which is too low for manifest generation to complete, or which is set in multiple places with different values.
+// - Manifest generation of a large repository (e.g. a monorepo), or of a slow Helm/Kustomize/plugin build, may take longer than the timeout, in which case the Application fails to sync with a 'context deadline exceeded' error.
+// - The resolved value (or the default) is always included in the message, since the 'argocd-cmd-params-cm' keys which users commonly set via extraConfig instead have no effect (these are reported by checkForIncorrectConfigurations, rather than here).
+func checkControllerRepoServerTimeout(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	if !argoCD.Spec.Controller.IsEnabled() {
+		return
+	}
+
+	effective := controllerRepoServerTimeoutSources(argoCD)
+
+	if len(effective) == 0 {
+		effective = []durationSource{{field: "(default)", value: defaultControllerRepoServerTimeout}}
+	}
+
+	resolved := effective[0]
+	resolvedDescription := fmt.Sprintf("%s (from '%s')", resolved.value, resolved.field)
+
+	disagreeingSources := []string{}
+	for _, source := range effective[1:] {
+		if source.value != resolved.value {
+			disagreeingSources = append(disagreeingSources, fmt.Sprintf("'%s' is %s", source.field, source.value))
+		}
+	}
+
+	if len(disagreeingSources) > 0 {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: fmt.Sprintf("The application controller repo server timeout is set in multiple places with different values: '%s' is %s, but %s. The container argument takes precedence over the env var, so the resolved timeout is %s. Set the timeout in only one place to avoid confusion.", resolved.field, resolved.value, strings.Join(disagreeingSources, ", and "), resolvedDescription),
+		})
+	}
+
+	switch {
+	case resolved.value == 0:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: "The resolved application controller repo server timeout is 0, which disables the timeout: a call to the repo server which never completes (for example, a hung Git fetch or plugin) blocks an application controller processor indefinitely, rather than failing. Set a timeout which is sufficient for the slowest manifest generation, instead.",
+		})
+
+	case resolved.value < minimumSafeControllerRepoServerTimeout:
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   resolved.field,
+			source:  resolved.source,
+			message: fmt.Sprintf("The resolved application controller repo server timeout is %s, which is below the recommended minimum of %s (the Argo CD default). Manifest generation of large repositories (e.g. monorepos) or slow Helm/Kustomize/plugin builds may not complete in time, causing Applications to fail to sync with a 'context deadline exceeded' error. Increase the timeout to at least %s (large monorepos commonly require 180s or more).", resolvedDescription, minimumSafeControllerRepoServerTimeout, minimumSafeControllerRepoServerTimeout),
+		})
+	}
+}
+
+// checkResourceTrackingLabelKey identifies combinations of the resource tracking method and the application instance label key which do not behave as users commonly expect. These two settings are frequently confused: the instance label key is only used to track resources when the tracking method includes 'label'.
+// - With 'annotation' tracking (the default), a custom instance label key has no effect on which resources Argo CD considers to be part of an Application.
+// - With 'label' or 'annotation+label' tracking, the default label key ('app.kubernetes.io/instance') is also set by many Helm charts and other tools, which causes resources to be incorrectly tracked (and pruned) by Argo CD.
+// - Both settings are resolved from the effective 'argocd-cm' (see effectiveArgoCDCMEntry), so values set via '.spec.extraConfig' are taken into account. An invalid tracking method is ignored here.
+func checkResourceTrackingLabelKey(argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	// cmEntryField returns the CR field (and issue source) that an effective 'argocd-cm' entry was set from
+	cmEntryField := func(key string, entry configMapEntry) (string, IssueSource) {
+		if entry.source == ".spec.extraConfig" {
+			return ".spec.extraConfig[" + key + "]", IssueSource_ExtraConfig
+		}
+		return entry.source, IssueSource_CRField
+	}
+
+	trackingMethod := v1beta1.ResourceTrackingMethodAnnotation
+	trackingMethodField, trackingMethodSource := ".spec.resourceTrackingMethod", IssueSource_CRField
+	if entry := effectiveArgoCDCMEntry(argoCD, "application.resourceTrackingMethod"); entry != nil {
+		trackingMethod = v1beta1.ParseResourceTrackingMethod(strings.TrimSpace(entry.value))
+		trackingMethodField, trackingMethodSource = cmEntryField(entry.key, *entry)
+	}
+
+	labelKey := common.ArgoCDDefaultApplicationInstanceLabelKey
+	labelKeyField, labelKeySource := ".spec.applicationInstanceLabelKey", IssueSource_CRField
+	if entry := effectiveArgoCDCMEntry(argoCD, "application.instanceLabelKey"); entry != nil {
+		if value := strings.TrimSpace(entry.value); value != "" {
+			labelKey = value
+		}
+		labelKeyField, labelKeySource = cmEntryField(entry.key, *entry)
+	}
+	usesDefaultLabelKey := labelKey == common.ArgoCDDefaultApplicationInstanceLabelKey
+
+	switch trackingMethod {
+	case v1beta1.ResourceTrackingMethodAnnotation:
+		if usesDefaultLabelKey {
+			return
+		}
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   labelKeyField,
+			source:  labelKeySource,
+			message: fmt.Sprintf("A custom application instance label key ('%s') is set in '%s', but the resource tracking method ('%s') is 'annotation'. With annotation tracking, Argo CD tracks resources via the 'argocd.argoproj.io/tracking-id' annotation, and the label key does not affect which resources belong to an Application. If the intent was to change how resources are tracked, set the tracking method to 'label' or 'annotation+label'; otherwise, remove the custom label key.", labelKey, labelKeyField, trackingMethodField),
+		})
+
+	case v1beta1.ResourceTrackingMethodLabel, v1beta1.ResourceTrackingMethodAnnotationAndLabel:
+		if !usesDefaultLabelKey {
+			return
+		}
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   trackingMethodField,
+			source:  trackingMethodSource,
+			message: fmt.Sprintf("The resource tracking method in '%s' is '%s', which tracks resources via the application instance label, but the default label key ('%s') is used ('%s' is not set). This label is also set by many Helm charts and other tools, which causes Argo CD to incorrectly consider resources to be part of an Application (and to prune them). Set a custom label key (e.g. 'argocd.argoproj.io/instance'), or use 'annotation' tracking (the default).", trackingMethodField, trackingMethod.String(), labelKey, labelKeyField),
+		})
+	}
+}
+
+// checkForMissingSourceNamespaces identifies namespaces which are referenced by the '.sourceNamespaces' fields of the ArgoCD CR (Applications, ApplicationSets, and notifications in any namespace), but which do not exist on the cluster. The feature then silently covers nothing in that namespace: the operator does not create the RBAC which allows the instance to manage resources there, and a typo in the namespace name is otherwise easy to miss.
+// - Entries may be glob patterns (e.g. 'team-*') or regular expressions (e.g. '/^team-.*$/'), as supported by the operator: a pattern which matches no namespace is also reported.
+// - Only run when the namespaces of the cluster are known (see clusterInformation.Namespaces), which is not the case for incomplete cluster data (e.g. must-gather).
+func checkForMissingSourceNamespaces(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	if clusterInfo.Namespaces == nil {
+		return
+	}
+
+	type sourceNamespacesField struct {
+		field      string
+		feature    string
+		namespaces []string
+	}
+
+	fields := []sourceNamespacesField{
+		{field: ".spec.sourceNamespaces", feature: "Applications", namespaces: argoCD.Spec.SourceNamespaces},
+	}
+	if argoCD.Spec.ApplicationSet != nil && argoCD.Spec.ApplicationSet.IsEnabled() {
+		fields = append(fields, sourceNamespacesField{field: ".spec.applicationSet.sourceNamespaces", feature: "ApplicationSets", namespaces: argoCD.Spec.ApplicationSet.SourceNamespaces})
+	}
+	if argoCD.Spec.Notifications.Enabled {
+		fields = append(fields, sourceNamespacesField{field: ".spec.notifications.sourceNamespaces", feature: "notifications configuration", namespaces: argoCD.Spec.Notifications.SourceNamespaces})
+	}
+
+	for _, field := range fields {
+		for _, sourceNamespace := range field.namespaces {
+
+			if strings.TrimSpace(sourceNamespace) == "" {
+				continue
+			}
+
+			matched := slices.ContainsFunc(clusterInfo.Namespaces, func(namespace string) bool {
+				return glob.MatchStringInList([]string{sourceNamespace}, namespace, glob.REGEXP)
+			})
+			if matched {
+				continue
+			}
+
+			message := fmt.Sprintf("The namespace '%s' does not exist on the cluster, so %s in this namespace are not managed by this Argo CD instance. Verify the namespace name, or remove it from '%s'.", sourceNamespace, field.feature, field.field)
+			if isNamespacePattern(sourceNamespace) {
+				message = fmt.Sprintf("The namespace pattern '%s' does not match any namespace on the cluster, so it does not allow %s in any namespace to be managed by this Argo CD instance. Verify the pattern, or remove it from '%s'.", sourceNamespace, field.feature, field.field)
+			}
+
+			*issues = append(*issues, issue{
+				level:   LogLevel_Warn,
+				field:   field.field + "[" + sourceNamespace + "]",
+				message: message,
+			})
+		}
+	}
+}
+
+// isNamespacePattern returns true if a '.sourceNamespaces' entry is a glob pattern or regular expression (see checkForMissingSourceNamespaces), rather than a namespace name
+func isNamespacePattern(sourceNamespace string) bool {
+	return strings.ContainsAny(sourceNamespace, "*?[") || (len(sourceNamespace) > 1 && strings.HasPrefix(sourceNamespace, "/") && strings.HasSuffix(sourceNamespace, "/"))
 }
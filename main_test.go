@@ -0,0 +1,764 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	semver "github.com/blang/semver/v4"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// loadSelfTestFixtureArgoCDs returns the ArgoCD CR of each of the self-test fixtures, in the order of selfTestFixtures
+func loadSelfTestFixtureArgoCDs(t *testing.T) []v1beta1.ArgoCD {
+	t.Helper()
+
+	res := []v1beta1.ArgoCD{}
+
+	for _, fixture := range selfTestFixtures {
+		data, err := selfTestFixturesFS.ReadFile(path.Join("fixtures", fixture.file))
+		if err != nil {
+			t.Fatalf("unable to read fixture '%s': %v", fixture.file, err)
+		}
+
+		var argoCD v1beta1.ArgoCD
+		if err := yaml.UnmarshalStrict(data, &argoCD); err != nil {
+			t.Fatalf("unable to parse fixture '%s': %v", fixture.file, err)
+		}
+
+		res = append(res, argoCD)
+	}
+
+	return res
+}
+
+// TestCheckArgoCDCRsConcurrently verifies that checking the ArgoCD CRs in parallel produces the same results, in the same order, as checking them sequentially. Run with 'go test -race' to detect data races between the checks.
+func TestCheckArgoCDCRsConcurrently(t *testing.T) {
+
+	argoCDs := loadSelfTestFixtureArgoCDs(t)
+	if len(argoCDs) < 2 {
+		t.Fatalf("expected multiple self-test fixtures, found %d", len(argoCDs))
+	}
+
+	clusterInfo := clusterInformation{}
+
+	sequential := checkArgoCDCRsConcurrently(argoCDs, clusterInfo, 1)
+	if len(sequential) != len(argoCDs) {
+		t.Fatalf("expected results for %d ArgoCD CRs, got %d", len(argoCDs), len(sequential))
+	}
+
+	for _, maxParallel := range []int{2, 4, len(argoCDs), len(argoCDs) + 5} {
+
+		parallel := checkArgoCDCRsConcurrently(argoCDs, clusterInfo, maxParallel)
+
+		if len(parallel) != len(sequential) {
+			t.Fatalf("maxParallel %d: expected results for %d ArgoCD CRs, got %d", maxParallel, len(sequential), len(parallel))
+		}
+
+		for idx := range sequential {
+			if !reflect.DeepEqual(parallel[idx], sequential[idx]) {
+				t.Errorf("maxParallel %d: results of '%s' differ from the sequential run:\nparallel:   %+v\nsequential: %+v", maxParallel, selfTestFixtures[idx].file, parallel[idx], sequential[idx])
+			}
+		}
+	}
+}
+
+func TestDedupeIssues(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		issues   []issue
+		expected []issue
+	}{
+		{
+			name: "the same rule and field are collapsed",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+			},
+			expected: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+			},
+		},
+		{
+			name: "different rules with an identical field and message are not collapsed",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC002"},
+			},
+			expected: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC002"},
+			},
+		},
+		{
+			name: "the distinct messages of the same rule and field are combined",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "First.", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "Second.", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "First.", ruleID: "ACC001"},
+			},
+			expected: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "First. Second.", ruleID: "ACC001"},
+			},
+		},
+		{
+			name: "the highest severity of the duplicates is kept",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Error, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Info, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+			},
+			expected: []issue{
+				{level: LogLevel_Error, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+			},
+		},
+		{
+			name: "unsupported is set if any of the duplicates is unsupported",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001", unsupported: true},
+			},
+			expected: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001", unsupported: true},
+			},
+		},
+		{
+			name: "issues of the same rule on different fields are not collapsed",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.ingress", message: "msg", ruleID: "ACC001"},
+			},
+			expected: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.server.ingress", message: "msg", ruleID: "ACC001"},
+			},
+		},
+		{
+			name: "issues without a rule ID are only collapsed if their field and message are identical",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "msg"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "other msg"},
+				{level: LogLevel_Error, field: ".spec.server.route", message: "msg"},
+			},
+			expected: []issue{
+				{level: LogLevel_Error, field: ".spec.server.route", message: "msg"},
+				{level: LogLevel_Warn, field: ".spec.server.route", message: "other msg"},
+			},
+		},
+		{
+			name: "the order of the first occurrence of each issue is preserved",
+			issues: []issue{
+				{level: LogLevel_Warn, field: ".spec.b", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.a", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Error, field: ".spec.b", message: "msg", ruleID: "ACC001"},
+			},
+			expected: []issue{
+				{level: LogLevel_Error, field: ".spec.b", message: "msg", ruleID: "ACC001"},
+				{level: LogLevel_Warn, field: ".spec.a", message: "msg", ruleID: "ACC001"},
+			},
+		},
+		{
+			name:     "no issues",
+			issues:   []issue{},
+			expected: []issue{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := dedupeIssues(test.issues); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckArgoCDCRForDeprecatedFieldsKeycloak(t *testing.T) {
+
+	version := func(value string) *semver.Version {
+		res := semver.MustParse(value)
+		return &res
+	}
+
+	keycloakArgoCD := v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{SSO: &v1beta1.ArgoCDSSOSpec{Provider: v1beta1.SSOProviderTypeKeycloak}}}
+
+	tests := []struct {
+		name            string
+		argoCD          v1beta1.ArgoCD
+		operatorVersion *semver.Version
+
+		// expectedLevel is empty if no Keycloak issue is expected
+		expectedLevel   LogLevel
+		expectedField   string
+		expectedMessage string
+	}{
+		{
+			name:            "before the removal version",
+			argoCD:          keycloakArgoCD,
+			operatorVersion: version("1.15.4"),
+			expectedLevel:   LogLevel_Warn,
+			expectedField:   ".spec.sso.provider",
+			expectedMessage: "removed in operator version " + keycloakRemovalOperatorVersion.String(),
+		},
+		{
+			name:            "the removal version",
+			argoCD:          keycloakArgoCD,
+			operatorVersion: &keycloakRemovalOperatorVersion,
+			expectedLevel:   LogLevel_Error,
+			expectedField:   ".spec.sso.provider",
+			expectedMessage: "no longer supported",
+		},
+		{
+			name:            "after the removal version",
+			argoCD:          keycloakArgoCD,
+			operatorVersion: version("1.18.1"),
+			expectedLevel:   LogLevel_Error,
+			expectedField:   ".spec.sso.provider",
+			expectedMessage: "no longer supported",
+		},
+		{
+			name:            "unknown operator version",
+			argoCD:          keycloakArgoCD,
+			operatorVersion: nil,
+			expectedLevel:   LogLevel_Error,
+			expectedField:   ".spec.sso.provider",
+			expectedMessage: "could not be determined",
+		},
+		{
+			name:            "'.spec.sso.keycloak' before the removal version",
+			argoCD:          v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{SSO: &v1beta1.ArgoCDSSOSpec{Keycloak: &v1beta1.ArgoCDKeycloakSpec{}}}},
+			operatorVersion: version("1.15.0"),
+			expectedLevel:   LogLevel_Warn,
+			expectedField:   ".spec.sso.keycloak",
+		},
+		{
+			name:            "Keycloak is not configured",
+			argoCD:          v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{SSO: &v1beta1.ArgoCDSSOSpec{Provider: v1beta1.SSOProviderTypeDex}}},
+			operatorVersion: version("1.18.1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			issues := []issue{}
+			checkArgoCDCRForDeprecatedFields(test.argoCD, clusterInformation{OperatorVersion: test.operatorVersion}, &issues)
+
+			keycloakIssues := []issue{}
+			for _, currIssue := range issues {
+				if strings.HasPrefix(currIssue.field, ".spec.sso.") {
+					keycloakIssues = append(keycloakIssues, currIssue)
+				}
+			}
+
+			if test.expectedLevel == "" {
+				if len(keycloakIssues) != 0 {
+					t.Errorf("expected no Keycloak issues, got %+v", keycloakIssues)
+				}
+				return
+			}
+
+			if len(keycloakIssues) != 1 {
+				t.Fatalf("expected a single Keycloak issue, got %+v", keycloakIssues)
+			}
+
+			actual := keycloakIssues[0]
+			if actual.level != test.expectedLevel || actual.field != test.expectedField {
+				t.Errorf("expected [%s] %s, got [%s] %s", test.expectedLevel, test.expectedField, actual.level, actual.field)
+			}
+			if !strings.Contains(actual.message, test.expectedMessage) {
+				t.Errorf("expected the message to contain '%s', got: %s", test.expectedMessage, actual.message)
+			}
+		})
+	}
+}
+
+func TestCheckForConflictingManagedByLabels(t *testing.T) {
+
+	clusterInfo := clusterInformation{
+		NamespaceWithManagedByLabel: map[string]string{
+			"team-a": "team-a-argocd",
+			"team-b": "openshift-gitops",
+			"team-c": "team-c-argocd",
+		},
+		NamespaceWithManagedByClusterArgoCDLabel: map[string]string{
+			// 'team-a' is managed by both the namespace-scoped instance in 'team-a-argocd', and the cluster-scoped instance in 'openshift-gitops'
+			"team-a": "openshift-gitops",
+			// 'team-b' has two labels which point at the same instance, which is not a conflict
+			"team-b": "openshift-gitops",
+		},
+		NamespaceWithArgoCDApplicationSetManagedByClusterArgoCDLabel: map[string]string{
+			"team-c": "openshift-gitops",
+		},
+	}
+
+	tests := []struct {
+		argoCDNamespace string
+		expectedFields  []string
+	}{
+		{
+			argoCDNamespace: "openshift-gitops",
+			expectedFields:  []string{"(labels of Namespace 'team-a')", "(labels of Namespace 'team-c')"},
+		},
+		{
+			argoCDNamespace: "team-a-argocd",
+			expectedFields:  []string{"(labels of Namespace 'team-a')"},
+		},
+		{
+			argoCDNamespace: "team-c-argocd",
+			expectedFields:  []string{"(labels of Namespace 'team-c')"},
+		},
+		{
+			// An instance which is not the target of any of the conflicting labels is not affected
+			argoCDNamespace: "unrelated-argocd",
+			expectedFields:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.argoCDNamespace, func(t *testing.T) {
+
+			argoCD := v1beta1.ArgoCD{}
+			argoCD.Namespace = test.argoCDNamespace
+			argoCD.Name = "argocd"
+
+			issues := []issue{}
+			checkForConflictingManagedByLabels(argoCD, clusterInfo, &issues)
+
+			fields := []string{}
+			for _, currIssue := range issues {
+				if currIssue.level != LogLevel_Error {
+					t.Errorf("expected an Error, got %s: %s", currIssue.level, currIssue.message)
+				}
+				fields = append(fields, currIssue.field)
+			}
+
+			if !reflect.DeepEqual(fields, test.expectedFields) {
+				t.Errorf("expected issues on %v, got %v", test.expectedFields, fields)
+			}
+		})
+	}
+
+	// The message lists each of the conflicting labels
+	argoCD := v1beta1.ArgoCD{}
+	argoCD.Namespace = "openshift-gitops"
+	issues := []issue{}
+	checkForConflictingManagedByLabels(argoCD, clusterInfo, &issues)
+	if len(issues) == 0 || !strings.Contains(issues[0].message, "argocd.argoproj.io/managed-by=team-a-argocd") || !strings.Contains(issues[0].message, "argocd.argoproj.io/managed-by-cluster-argocd=openshift-gitops") {
+		t.Errorf("expected the issue of 'team-a' to list both labels, got %+v", issues)
+	}
+}
+
+// fakeNamespaceK8sClient is an AbstractK8sClient which returns the given Namespaces (or an error), and supports no other resource types
+type fakeNamespaceK8sClient struct {
+	namespaces []corev1.Namespace
+
+	// listNamespacesErr is returned when Namespaces are listed, if non-nil
+	listNamespacesErr error
+
+	incompleteControlPlaneData bool
+}
+
+func (f *fakeNamespaceK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+
+	namespaceList, ok := list.(*corev1.NamespaceList)
+	if !ok {
+		return fmt.Errorf("unexpected list type: %T", list)
+	}
+
+	if f.listNamespacesErr != nil {
+		return f.listNamespacesErr
+	}
+
+	namespaceList.Items = append([]corev1.Namespace{}, f.namespaces...)
+	return nil
+}
+
+func (f *fakeNamespaceK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return fmt.Errorf("unexpected list type: %T", list)
+}
+
+func (f *fakeNamespaceK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return fmt.Errorf("unexpected get: %T %v", obj, key)
+}
+
+func (f *fakeNamespaceK8sClient) IncompleteControlPlaneData() bool {
+	return f.incompleteControlPlaneData
+}
+
+func TestAcquireNamespaceInformation(t *testing.T) {
+
+	namespace := func(name string, labels map[string]string) corev1.Namespace {
+		res := corev1.Namespace{}
+		res.Name = name
+		res.Labels = labels
+		return res
+	}
+
+	namespaces := []corev1.Namespace{
+		namespace("team-b", map[string]string{"argocd.argoproj.io/managed-by": "openshift-gitops", "environment": "prod"}),
+		namespace("team-a", map[string]string{"kubernetes.io/metadata.name": "team-a"}),
+		namespace("unlabeled", nil),
+	}
+
+	expectedLabels := map[string]map[string]string{
+		"team-b":    {"argocd.argoproj.io/managed-by": "openshift-gitops", "environment": "prod"},
+		"team-a":    {"kubernetes.io/metadata.name": "team-a"},
+		"unlabeled": nil,
+	}
+
+	for _, incomplete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("incomplete control plane data: %v", incomplete), func(t *testing.T) {
+
+			clusterInfo := clusterInformation{}
+			entries := acquireNamespaceInformation(context.Background(), &fakeNamespaceK8sClient{namespaces: namespaces, incompleteControlPlaneData: incomplete}, &clusterInfo)
+
+			if len(entries) != 0 {
+				t.Errorf("expected no entries, got %+v", entries)
+			}
+
+			// The labels are collected whether or not the data is complete
+			if !reflect.DeepEqual(clusterInfo.NamespaceLabels, expectedLabels) {
+				t.Errorf("expected namespace labels %v, got %v", expectedLabels, clusterInfo.NamespaceLabels)
+			}
+
+			if !reflect.DeepEqual(clusterInfo.NamespaceWithManagedByLabel, map[string]string{"team-b": "openshift-gitops"}) {
+				t.Errorf("unexpected managed-by namespaces: %v", clusterInfo.NamespaceWithManagedByLabel)
+			}
+
+			// The list of namespaces is only populated when the data is complete, since a namespace which is not in incomplete data may still exist
+			var expectedNamespaces []string
+			if !incomplete {
+				expectedNamespaces = []string{"team-a", "team-b", "unlabeled"}
+			}
+			if !reflect.DeepEqual(clusterInfo.Namespaces, expectedNamespaces) {
+				t.Errorf("expected namespaces %v, got %v", expectedNamespaces, clusterInfo.Namespaces)
+			}
+		})
+	}
+
+	t.Run("labels are copied", func(t *testing.T) {
+
+		labels := map[string]string{"environment": "prod"}
+
+		clusterInfo := clusterInformation{}
+		acquireNamespaceInformation(context.Background(), &fakeNamespaceK8sClient{namespaces: []corev1.Namespace{namespace("team-a", labels)}}, &clusterInfo)
+
+		labels["environment"] = "dev"
+		if clusterInfo.NamespaceLabels["team-a"]["environment"] != "prod" {
+			t.Errorf("expected the labels of the namespace to be copied, got %v", clusterInfo.NamespaceLabels["team-a"])
+		}
+	})
+
+	t.Run("listing namespaces is forbidden", func(t *testing.T) {
+
+		forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New(`User "developer" cannot list resource "namespaces" at the cluster scope`))
+
+		clusterInfo := clusterInformation{}
+		entries := acquireNamespaceInformation(context.Background(), &fakeNamespaceK8sClient{listNamespacesErr: forbidden}, &clusterInfo)
+
+		if len(entries) != 1 || entries[0].level != LogLevel_Fatal {
+			t.Errorf("expected a single Fatal entry, got %+v", entries)
+		}
+
+		if clusterInfo.NamespaceLabels == nil || len(clusterInfo.NamespaceLabels) != 0 {
+			t.Errorf("expected empty (non-nil) namespace labels, got %#v", clusterInfo.NamespaceLabels)
+		}
+
+		if clusterInfo.Namespaces != nil {
+			t.Errorf("expected no namespaces, got %v", clusterInfo.Namespaces)
+		}
+	})
+}
+
+func TestCheckRedisTLSConfigurationUseTLSArg(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedIssue bool
+	}{
+		{name: "flag", args: []string{"--redis-use-tls"}, expectedIssue: true},
+		{name: "flag with a true value", args: []string{"--redis-use-tls=true"}, expectedIssue: true},
+		{name: "flag with a false value", args: []string{"--redis-use-tls=false"}},
+		{name: "flag with an invalid value", args: []string{"--redis-use-tls=yes please"}},
+		{name: "not set", args: []string{"--loglevel", "debug"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			argoCD := v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{Server: v1beta1.ArgoCDServerSpec{ExtraCommandArgs: test.args}}}
+
+			issues := []issue{}
+			checkRedisTLSConfiguration(argoCD, &issues)
+
+			found := slices.ContainsFunc(issues, func(currIssue issue) bool {
+				return currIssue.field == ".spec.server.extraCommandArgs: --redis-use-tls"
+			})
+			if found != test.expectedIssue {
+				t.Errorf("expected an issue: %v, got: %+v", test.expectedIssue, issues)
+			}
+		})
+	}
+}
+
+func TestCheckIndividualArgoCDCRAgainstClusterSupersededChecks(t *testing.T) {
+
+	argoCD := v1beta1.ArgoCD{}
+	argoCD.Name = "argocd"
+	argoCD.Namespace = "argocd"
+
+	crIssues := []issue{
+		{level: LogLevel_Warn, field: ".spec.controller.env[KUBECACHEDIR]", message: "msg", ruleID: "ACC019"},
+		{level: LogLevel_Warn, field: ".spec.server.replicas", message: "msg", ruleID: "ACC004"},
+	}
+
+	for _, incomplete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("incomplete control plane data: %v", incomplete), func(t *testing.T) {
+
+			// The cluster checks fail to read any resources from the fake client, which they report as issues of their own
+			issues := checkIndividualArgoCDCRAgainstCluster(context.Background(), &fakeNamespaceK8sClient{incompleteControlPlaneData: incomplete}, argoCD, crIssues, clusterInformation{}, nil)
+
+			ruleIDs := []string{}
+			for _, currIssue := range issues {
+				ruleIDs = append(ruleIDs, currIssue.ruleID)
+			}
+
+			// ACC019 is superseded by ACC020, which is only run against a live cluster
+			if slices.Contains(ruleIDs, "ACC019") != incomplete {
+				t.Errorf("expected ACC019 to be reported: %v, got rule IDs %v", incomplete, ruleIDs)
+			}
+			if !slices.Contains(ruleIDs, "ACC004") {
+				t.Errorf("expected ACC004 to be reported, got rule IDs %v", ruleIDs)
+			}
+
+			coverage := computeCheckCoverage(argoCD, clusterInformation{}, issues, incomplete, nil)
+			acc019 := coverage[slices.IndexFunc(coverage, func(currCoverage checkCoverage) bool { return currCoverage.ruleID == "ACC019" })]
+			if (acc019.status == checkCoverage_Skipped) == incomplete {
+				t.Errorf("unexpected coverage of ACC019: %+v", acc019)
+			}
+		})
+	}
+}
+
+func TestInsecureServerArgumentIsReportedOnce(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		crInsecure     bool
+		args           []string
+		expectedLevels []LogLevel
+	}{
+		{name: "argument only", args: []string{"--insecure"}, expectedLevels: []LogLevel{LogLevel_Warn}},
+		{name: "argument redundant with the CR field", crInsecure: true, args: []string{"--insecure=true"}, expectedLevels: []LogLevel{LogLevel_Warn}},
+		{name: "argument contradicts the CR field", crInsecure: true, args: []string{"--insecure=false"}, expectedLevels: []LogLevel{LogLevel_Error}},
+		{name: "argument disables insecure mode", args: []string{"--insecure=false"}},
+		{name: "CR field only", crInsecure: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			argoCD := v1beta1.ArgoCD{Spec: v1beta1.ArgoCDSpec{Server: v1beta1.ArgoCDServerSpec{Insecure: test.crInsecure, ExtraCommandArgs: test.args}}}
+
+			issues := []issue{}
+			checkForFailingBestPractices(argoCD, &issues)
+			checkForFeatureFlagsWhichOverlapWithCRBooleans(argoCD, &issues)
+
+			levels := []LogLevel{}
+			for _, currIssue := range issues {
+				if currIssue.field == ".spec.server.extraCommandArgs: --insecure" {
+					levels = append(levels, currIssue.level)
+				}
+			}
+
+			if !slices.Equal(levels, test.expectedLevels) {
+				t.Errorf("expected '--insecure' issues of levels %v, got %v", test.expectedLevels, levels)
+			}
+		})
+	}
+}
+
+func TestRBACPolicyGrantsExec(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		policy   string
+		role     string
+		expected bool
+	}{
+		{name: "granted directly", policy: "p, role:dev, exec, create, */*, allow", role: "role:dev", expected: true},
+		{name: "denied", policy: "p, role:dev, exec, create, */*, deny", role: "role:dev"},
+		{name: "granted to another role", policy: "p, role:ops, exec, create, */*, allow", role: "role:dev"},
+		{name: "granted to an inherited role", policy: "p, role:ops, exec, create, */*, allow\ng, role:dev, role:ops", role: "role:dev", expected: true},
+		{name: "granted to a transitively inherited role, in any order", policy: "g, role:ops, role:exec\ng, role:dev, role:ops\np, role:exec, *, *, */*", role: "role:dev", expected: true},
+		{name: "inherits role:admin", policy: "g, role:dev, role:admin", role: "role:dev", expected: true},
+		{name: "inherited by another role", policy: "p, role:dev, exec, create, */*, allow\ng, role:ops, role:dev", role: "role:ops", expected: true},
+		{name: "assigned to the role, rather than inherited", policy: "p, role:ops, exec, create, */*, allow\ng, role:ops, role:dev", role: "role:dev"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := rbacPolicyGrantsExec(test.policy, test.role); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFindNonDefaultFieldsAppSync(t *testing.T) {
+
+	tests := []struct {
+		appSync          string
+		expectedReported bool
+	}{
+		{appSync: "180s"},
+		{appSync: "3m"},
+		{appSync: "2m", expectedReported: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.appSync, func(t *testing.T) {
+
+			var argoCD v1beta1.ArgoCD
+			if err := yaml.Unmarshal([]byte("spec:\n  controller:\n    appSync: "+test.appSync), &argoCD); err != nil {
+				t.Fatal(err)
+			}
+
+			reported := slices.ContainsFunc(findNonDefaultFields(argoCD), func(field nonDefaultField) bool {
+				return field.crField == ".spec.controller.appSync"
+			})
+			if reported != test.expectedReported {
+				t.Errorf("expected '.spec.controller.appSync' to be reported: %v, got: %v", test.expectedReported, reported)
+			}
+		})
+	}
+}
+
+// fakeConsoleK8sClient is an AbstractK8sClient which returns the console operator configuration (with the given enabled plugins) and, if it exists, the GitOps ConsolePlugin
+type fakeConsoleK8sClient struct {
+	plugins             []string
+	consolePluginExists bool
+
+	// getErr is returned by Get of any resource, if non-nil
+	getErr error
+}
+
+func (f *fakeConsoleK8sClient) ListFromAllNamespaces(ctx context.Context, list client.ObjectList) error {
+	return fmt.Errorf("unexpected list type: %T", list)
+}
+
+func (f *fakeConsoleK8sClient) ListFromSingleNamespace(ctx context.Context, list client.ObjectList, namespace string) error {
+	return fmt.Errorf("unexpected list type: %T", list)
+}
+
+func (f *fakeConsoleK8sClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+
+	if f.getErr != nil {
+		return f.getErr
+	}
+
+	switch obj := obj.(type) {
+	case *operatorv1.Console:
+		obj.Spec.Plugins = f.plugins
+		return nil
+	case *consolev1.ConsolePlugin:
+		if !f.consolePluginExists {
+			return apierrors.NewNotFound(schema.GroupResource{Group: "console.openshift.io", Resource: "consoleplugins"}, key.Name)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unexpected get: %T %v", obj, key)
+}
+
+func (f *fakeConsoleK8sClient) IncompleteControlPlaneData() bool {
+	return false
+}
+
+func TestCheckGitOpsConsolePlugin(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		k8sClient     *fakeConsoleK8sClient
+		expectedLevel LogLevel
+	}{
+		{name: "enabled", k8sClient: &fakeConsoleK8sClient{plugins: []string{gitOpsConsolePluginName}, consolePluginExists: true}, expectedLevel: LogLevel_Info},
+		{name: "enabled, but missing", k8sClient: &fakeConsoleK8sClient{plugins: []string{gitOpsConsolePluginName}}, expectedLevel: LogLevel_Warn},
+		{name: "not enabled", k8sClient: &fakeConsoleK8sClient{plugins: []string{"other-plugin"}, consolePluginExists: true}, expectedLevel: LogLevel_Info},
+		{name: "not enabled, and missing", k8sClient: &fakeConsoleK8sClient{}, expectedLevel: LogLevel_Info},
+		{name: "forbidden", k8sClient: &fakeConsoleK8sClient{getErr: apierrors.NewForbidden(schema.GroupResource{Group: "operator.openshift.io", Resource: "consoles"}, "cluster", errors.New("forbidden"))}, expectedLevel: LogLevel_Info},
+		{name: "other error", k8sClient: &fakeConsoleK8sClient{getErr: errors.New("connection refused")}, expectedLevel: LogLevel_Warn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			entries := checkGitOpsConsolePlugin(context.Background(), test.k8sClient)
+
+			if len(entries) != 1 || entries[0].level != test.expectedLevel {
+				t.Errorf("expected a single %s entry, got: %+v", test.expectedLevel, entries)
+			}
+		})
+	}
+
+	// Nothing is reported when there is no console
+	notFound := &fakeConsoleK8sClient{getErr: apierrors.NewNotFound(schema.GroupResource{Group: "operator.openshift.io", Resource: "consoles"}, "cluster")}
+	if entries := checkGitOpsConsolePlugin(context.Background(), notFound); len(entries) != 0 {
+		t.Errorf("expected no entries when there is no console, got: %+v", entries)
+	}
+}
+
+func TestCheckArgoCDStatusFieldConditionLevels(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		conditionType   string
+		conditionStatus string
+		expectedLevel   LogLevel
+	}{
+		{name: "Reconciled, not true", conditionType: "Reconciled", conditionStatus: "False", expectedLevel: LogLevel_Error},
+		{name: "Degraded, true", conditionType: "Degraded", conditionStatus: "True", expectedLevel: LogLevel_Error},
+		{name: "unknown type, not true", conditionType: "Progressing", conditionStatus: "False", expectedLevel: LogLevel_Warn},
+		{name: "unknown type, unknown", conditionType: "Progressing", conditionStatus: "Unknown", expectedLevel: LogLevel_Warn},
+		{name: "unknown type, true", conditionType: "Progressing", conditionStatus: "True"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			var argoCD v1beta1.ArgoCD
+			if err := yaml.Unmarshal([]byte("status:\n  phase: Available\n  conditions:\n  - type: "+test.conditionType+"\n    status: \""+test.conditionStatus+"\"\n"), &argoCD); err != nil {
+				t.Fatal(err)
+			}
+
+			issues := []issue{}
+			checkArgoCDStatusField(argoCD, &issues)
+
+			if test.expectedLevel == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got: %+v", issues)
+				}
+				return
+			}
+
+			if len(issues) != 1 || issues[0].level != test.expectedLevel {
+				t.Errorf("expected a single %s issue, got: %+v", test.expectedLevel, issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jgwest/argocd-config-check/clients"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// exitCode_ClusterCheckFailed is the exit status code used when checking multiple clusters, and at least one of the clusters could not be checked (for example, because it could not be connected to). If '--fail-on-unsupported', '--fail-on', or '--min-score' also applies, their status code is used instead.
+const exitCode_ClusterCheckFailed = 5
+
+// clusterTarget is a cluster to check, when checking multiple clusters in one run (see '--kubeconfig' and '--contexts')
+type clusterTarget struct {
+	// name identifies the cluster in output
+	name string
+
+	// kubeConfigPath is the kubeconfig file of the cluster, or empty to use the default kubeconfig loading rules
+	kubeConfigPath string
+
+	// kubeConfigData is the content of the kubeconfig of the cluster (see '--kubeconfig-data'), or nil if the kubeconfig is read from kubeConfigPath
+	kubeConfigData []byte
+
+	// contextName is the kubeconfig context of the cluster, or empty to use the current context of the kubeconfig
+	contextName string
+}
+
+// clusterTargets returns the clusters to check: each of the contexts, within each of the kubeconfig files (or within the kubeconfig content, if 'kubeConfigData' is non-nil, in which case no kubeconfig files may be specified).
+// - If no kubeconfig files (or content) are specified, the default kubeconfig is used. If no contexts are specified, the current context of each kubeconfig is used.
+// - Clusters are named by their context (qualified by the kubeconfig file, if there is more than one), otherwise by their kubeconfig file.
+func clusterTargets(kubeConfigPaths []string, kubeConfigData []byte, contextNames []string) []clusterTarget {
+
+	if len(kubeConfigPaths) == 0 {
+		kubeConfigPaths = []string{""}
+	}
+
+	if len(contextNames) == 0 {
+		contextNames = []string{""}
+	}
+
+	res := []clusterTarget{}
+
+	for _, kubeConfigPath := range kubeConfigPaths {
+		for _, contextName := range contextNames {
+
+			var name string
+			switch {
+			case contextName != "" && len(kubeConfigPaths) == 1:
+				name = contextName
+			case contextName != "":
+				name = kubeConfigPath + ":" + contextName
+			case kubeConfigPath != "":
+				name = kubeConfigPath
+			case kubeConfigData != nil:
+				name = "(current context of '--kubeconfig-data')"
+			default:
+				name = "(current context)"
+			}
+
+			res = append(res, clusterTarget{name: name, kubeConfigPath: kubeConfigPath, kubeConfigData: kubeConfigData, contextName: contextName})
+		}
+	}
+
+	return res
+}
+
+// k8sClient returns a client for the cluster, from its kubeconfig file or kubeconfig content
+func (target clusterTarget) k8sClient() (clients.AbstractK8sClient, error) {
+	if target.kubeConfigData != nil {
+		return clients.KubeConfigDataK8sClient(target.kubeConfigData, target.contextName)
+	}
+	return clients.KubeConfigK8sClient(target.kubeConfigPath, target.contextName)
+}
+
+// parseKubeConfigDataFlag converts the user-specified '--kubeconfig-data' value (the base64-encoded content of a kubeconfig file) into the kubeconfig content, or returns an error if it is not valid. The kubeconfig is used directly from memory, so that CI systems which provide the kubeconfig as a secret env var do not need to write it to a temporary file.
+// - Line breaks within the value are ignored (e.g. the output of 'base64' without '-w0').
+func parseKubeConfigDataFlag(value string) ([]byte, error) {
+
+	res, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("the value is not valid base64 (it must be the base64-encoded content of a kubeconfig file, e.g. the output of 'base64 -w0 ~/.kube/config'): %w", err)
+	}
+
+	if len(bytes.TrimSpace(res)) == 0 {
+		return nil, fmt.Errorf("the decoded kubeconfig is empty")
+	}
+
+	if _, err := clientcmd.Load(res); err != nil {
+		return nil, fmt.Errorf("the decoded value is not a valid kubeconfig: %w", err)
+	}
+
+	return res, nil
+}
+
+// parseContextsFlag converts the user-specified '--contexts' value (a comma-separated list of kubeconfig contexts) into a list of context names, or returns an error if it is not valid.
+func parseContextsFlag(value string) ([]string, error) {
+
+	if value == "" {
+		return nil, nil
+	}
+
+	res := []string{}
+	for contextName := range strings.SplitSeq(value, ",") {
+		contextName = strings.TrimSpace(contextName)
+		if contextName == "" {
+			return nil, fmt.Errorf("'%s' contains an empty context name", value)
+		}
+		res = append(res, contextName)
+	}
+
+	return res, nil
+}
+
+// clusterResult contains the results of checking a single cluster, when checking multiple clusters
+type clusterResult struct {
+	target clusterTarget
+
+	// err is non-nil if the cluster could not be checked (for example, because it could not be connected to), in which case results is empty
+	err error
+
+	results checkResults
+}
+
+// clusterConnectionOptions contains the user-specified options (from command line flags) which affect how the client of each cluster is created
+type clusterConnectionOptions struct {
+	// namespace is the only namespace that resources are read from, or empty for all namespaces (see '--namespace')
+	namespace string
+
+	// showProgress enables progress output while resources are being read (see clients.ProgressK8sClient)
+	showProgress bool
+}
+
+// connectToClusterTarget returns a client for the given cluster, and verifies that ArgoCD CRs can be listed from it. Unlike a single cluster run, an error here is returned rather than exiting, so that one unreachable cluster does not prevent the other clusters from being checked.
+func connectToClusterTarget(ctx context.Context, target clusterTarget, connectionOpts clusterConnectionOptions) (clients.AbstractK8sClient, error) {
+
+	k8sClient, err := target.k8sClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve K8s client configuration: %w", err)
+	}
+
+	if connectionOpts.namespace != "" {
+		k8sClient = clients.NamespaceScopedK8sClient(k8sClient, connectionOpts.namespace)
+	}
+
+	if connectionOpts.showProgress {
+		k8sClient = clients.ProgressK8sClient(k8sClient, os.Stderr)
+	}
+
+	// runChecks exits if no ArgoCD CRs can be listed, so this is verified beforehand
+	if _, err := tryListArgoCDs(ctx, k8sClient); err != nil {
+		return nil, err
+	}
+
+	return k8sClient, nil
+}
+
+// runMultiClusterChecks runs all checks against each of the clusters in turn, and outputs the results of each cluster, grouped by cluster. A cluster which cannot be checked is reported, and skipped.
+func runMultiClusterChecks(ctx context.Context, targets []clusterTarget, opts runOptions, connectionOpts clusterConnectionOptions) []clusterResult {
+
+	res := []clusterResult{}
+
+	coloredCluster := color.New(color.FgHiMagenta).Sprint("Cluster")
+
+	for _, target := range targets {
+
+		outputStatusMessage("==============================================================================")
+		outputStatusMessage(coloredCluster + " '" + target.name + "':")
+		outputStatusMessage("==============================================================================")
+		outputStatusMessage("")
+
+		k8sClient, err := connectToClusterTarget(ctx, target, connectionOpts)
+		if err != nil {
+			outputStatusMessage(entry{level: LogLevel_Error, message: "Unable to check cluster '" + target.name + "', so it will be skipped: " + err.Error()}.string())
+			outputStatusMessage("")
+			res = append(res, clusterResult{target: target, err: err})
+			continue
+		}
+
+		results := runChecks(ctx, k8sClient, opts)
+		results.cluster = target.name
+
+		switch opts.outputFormat {
+		case outputFormat_GitHub:
+			outputResultsAsGitHubAnnotations(results)
+		case outputFormat_TeamCity:
+			outputResultsAsTeamCityServiceMessages(results)
+		}
+
+		outputScoreSummary(results)
+
+		res = append(res, clusterResult{target: target, results: results})
+	}
+
+	outputClusterSummary(res, opts.outputFormat)
+
+	return res
+}
+
+// outputClusterSummary outputs which clusters were checked, and which could not be checked, once all clusters have been checked
+func outputClusterSummary(clusterResults []clusterResult, format outputFormat) {
+
+	outputStatusMessage("==============================================================================")
+	outputStatusMessage("Cluster summary:")
+	for _, clusterRes := range clusterResults {
+
+		if clusterRes.err != nil {
+			outputStatusMessage(colorizeLogLevel(LogLevel_Error, fmt.Sprintf("- %s: not checked (%v)", clusterRes.target.name, clusterRes.err)))
+			continue
+		}
+
+		instanceCount := len(clusterRes.results.instances)
+		issueCount := len(clusterRes.results.allIssues())
+		outputStatusMessage(fmt.Sprintf("- %s: %d ArgoCD instance(s) checked, %d issue(s) reported", clusterRes.target.name, instanceCount, issueCount))
+	}
+	outputStatusMessage("")
+
+	// GitHub and TeamCity consume the report output, so the clusters which could not be checked are also reported there
+	for _, clusterRes := range clusterResults {
+		if clusterRes.err == nil {
+			continue
+		}
+		message := "Unable to check cluster '" + clusterRes.target.name + "': " + clusterRes.err.Error()
+
+		switch format {
+		case outputFormat_GitHub:
+			fmt.Fprintf(reportOutput, "::error title=%s::%s\n", escapeGitHubCommandProperty("Cluster '"+clusterRes.target.name+"'"), escapeGitHubCommandData(message))
+		case outputFormat_TeamCity:
+			fmt.Fprintf(reportOutput, "##teamcity[buildProblem description='%s' identity='%s']\n", escapeTeamCityValue(message), teamCityBuildProblemIdentity("cluster", clusterRes.target.name))
+		}
+	}
+}
+
+// jsonMultiClusterResults is the top-level JSON output document when checking multiple clusters. Each cluster contains the same fields as the single cluster document (see jsonResults), plus the name of the cluster, and the reason it could not be checked (if any):
+//
+//	{"schemaVersion": 2, "kind": "multiClusterResults", "clusters": [{"name": ..., "error": ..., "operator": {...}, "installationFindings": [...], "instances": [...]}]}
+type jsonMultiClusterResults struct {
+	// SchemaVersion is always the first field of the document. See jsonMultiClusterSchemaVersion.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Kind is always jsonDocumentKind_MultiClusterResults
+	Kind jsonDocumentKind `json:"kind"`
+
+	Clusters []jsonCluster `json:"clusters"`
+}
+
+type jsonCluster struct {
+	Name string `json:"name"`
+
+	// Error is the reason the cluster could not be checked, or empty if it was checked
+	Error string `json:"error,omitempty"`
+
+	Operator             jsonOperator              `json:"operator"`
+	InstallationFindings []jsonInstallationFinding `json:"installationFindings"`
+	Instances            []jsonInstance            `json:"instances"`
+}
+
+// outputMultiClusterResultsAsJSON writes the check results of all clusters as a single JSON document
+func outputMultiClusterResultsAsJSON(clusterResults []clusterResult) {
+
+	res := jsonMultiClusterResults{SchemaVersion: jsonMultiClusterSchemaVersion, Kind: jsonDocumentKind_MultiClusterResults, Clusters: []jsonCluster{}}
+
+	for _, clusterRes := range clusterResults {
+
+		jsonRes := toJSONResults(clusterRes.results)
+
+		cluster := jsonCluster{
+			Name:                 clusterRes.target.name,
+			Operator:             jsonRes.Operator,
+			InstallationFindings: jsonRes.InstallationFindings,
+			Instances:            jsonRes.Instances,
+		}
+		if clusterRes.err != nil {
+			cluster.Error = clusterRes.err.Error()
+		}
+
+		res.Clusters = append(res.Clusters, cluster)
+	}
+
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		failWithError("unable to convert results to JSON", err)
+	}
+
+	fmt.Fprintln(reportOutput, string(data))
+}
+
+// clusterTargetNames returns the name of each of the clusters
+func clusterTargetNames(targets []clusterTarget) []string {
+	res := []string{}
+	for _, target := range targets {
+		res = append(res, target.name)
+	}
+	return res
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/fatih/color"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// This file contains the operator/Argo CD default values of the most commonly tuned ArgoCD CR fields, which are used by '--show-nondefault' to report how an ArgoCD CR deviates from the defaults. This is informational only: a non-default value is not an issue.
+
+// defaultedField is an ArgoCD CR field, and the value that is used by the operator (or Argo CD) when the field is not set.
+type defaultedField struct {
+	component    string // e.g. 'application controller'
+	crField      string // e.g. '.spec.controller.processors.status'
+	defaultValue string
+
+	// crValue returns the value of the field in the CR, or false if the field is not set (in which case the default applies)
+	crValue func(argoCD v1beta1.ArgoCD) (string, bool)
+}
+
+// noResourcesDefault is the default value of the resources fields: the operator does not set resource requests/limits on components unless they are specified in the CR.
+const noResourcesDefault = "(none)"
+
+// defaultedFields is the list of ArgoCD CR fields that are compared against their defaults, grouped by component (in the order in which components are output).
+var defaultedFields = []defaultedField{
+	{component: "application controller", crField: ".spec.controller.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedResourceRequirements(a.Spec.Controller.Resources)
+	}},
+	{component: "application controller", crField: ".spec.controller.processors.operation", defaultValue: strconv.Itoa(int(common.ArgoCDDefaultServerOperationProcessors)), crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.Processors.Operation)
+	}},
+	{component: "application controller", crField: ".spec.controller.processors.status", defaultValue: strconv.Itoa(int(common.ArgoCDDefaultServerStatusProcessors)), crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.Processors.Status)
+	}},
+	{component: "application controller", crField: ".spec.controller.parallelismLimit", defaultValue: strconv.Itoa(int(common.ArgoCDDefaultControllerParallelismLimit)), crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.ParallelismLimit)
+	}},
+	{component: "application controller", crField: ".spec.controller.sharding.enabled", defaultValue: "false", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.Controller.Sharding.Enabled), true
+	}},
+	{component: "application controller", crField: ".spec.controller.sharding.replicas", defaultValue: "1", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonZeroInt32(a.Spec.Controller.Sharding.Replicas)
+	}},
+	{component: "application controller", crField: ".spec.controller.sharding.dynamicScalingEnabled", defaultValue: "false", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Controller.Sharding.DynamicScalingEnabled == nil {
+			return "", false
+		}
+		return strconv.FormatBool(*a.Spec.Controller.Sharding.DynamicScalingEnabled), true
+	}},
+	// When '.spec.controller.appSync' is not set, the operator does not set 'timeout.reconciliation', so the Argo CD default is used
+	{component: "application controller", crField: ".spec.controller.appSync", defaultValue: defaultReconciliationTimeout.String(), crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Controller.AppSync == nil {
+			return "", false
+		}
+		return a.Spec.Controller.AppSync.Duration.String(), true
+	}},
+	{component: "application controller", crField: ".spec.controller.logLevel", defaultValue: common.ArgoCDDefaultLogLevel, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Controller.LogLevel)
+	}},
+	{component: "server", crField: ".spec.server.replicas", defaultValue: "1", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedReplicas(a.Spec.Server.Replicas)
+	}},
+	{component: "server", crField: ".spec.server.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedResourceRequirements(a.Spec.Server.Resources)
+	}},
+	{component: "server", crField: ".spec.server.autoscale.enabled", defaultValue: "false", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.Server.Autoscale.Enabled), true
+	}},
+	{component: "server", crField: ".spec.server.logLevel", defaultValue: common.ArgoCDDefaultLogLevel, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Server.LogLevel)
+	}},
+	{component: "repo server", crField: ".spec.repo.replicas", defaultValue: "1", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedReplicas(a.Spec.Repo.Replicas)
+	}},
+	{component: "repo server", crField: ".spec.repo.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedResourceRequirements(a.Spec.Repo.Resources)
+	}},
+	{component: "repo server", crField: ".spec.repo.execTimeout", defaultValue: "90s", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.Repo.ExecTimeout == nil {
+			return "", false
+		}
+		return strconv.Itoa(*a.Spec.Repo.ExecTimeout) + "s", true
+	}},
+	{component: "repo server", crField: ".spec.repo.logLevel", defaultValue: common.ArgoCDDefaultLogLevel, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return nonEmptyString(a.Spec.Repo.LogLevel)
+	}},
+	{component: "redis", crField: ".spec.redis.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedResourceRequirements(a.Spec.Redis.Resources)
+	}},
+	{component: "redis", crField: ".spec.ha.enabled", defaultValue: "false", crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return strconv.FormatBool(a.Spec.HA.Enabled), true
+	}},
+	{component: "redis", crField: ".spec.ha.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		return formattedResourceRequirements(a.Spec.HA.Resources)
+	}},
+	{component: "applicationset controller", crField: ".spec.applicationSet.resources", defaultValue: noResourcesDefault, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.ApplicationSet == nil {
+			return "", false
+		}
+		return formattedResourceRequirements(a.Spec.ApplicationSet.Resources)
+	}},
+	{component: "applicationset controller", crField: ".spec.applicationSet.logLevel", defaultValue: common.ArgoCDDefaultLogLevel, crValue: func(a v1beta1.ArgoCD) (string, bool) {
+		if a.Spec.ApplicationSet == nil {
+			return "", false
+		}
+		return nonEmptyString(a.Spec.ApplicationSet.LogLevel)
+	}},
+}
+
+// nonDefaultField is an ArgoCD CR field which is set to a value other than its default (see defaultedFields)
+type nonDefaultField struct {
+	component    string
+	crField      string
+	value        string
+	defaultValue string
+}
+
+// findNonDefaultFields returns the fields of the ArgoCD CR which are set to a value other than their default, in the order of defaultedFields. A field which is explicitly set to its default value is not included.
+func findNonDefaultFields(argoCD v1beta1.ArgoCD) []nonDefaultField {
+
+	res := []nonDefaultField{}
+
+	for _, field := range defaultedFields {
+		value, set := field.crValue(argoCD)
+		if !set || value == field.defaultValue {
+			continue
+		}
+		res = append(res, nonDefaultField{component: field.component, crField: field.crField, value: value, defaultValue: field.defaultValue})
+	}
+
+	return res
+}
+
+// outputNonDefaultFields outputs the non-default fields of a single Argo CD instance, grouped by component
+func outputNonDefaultFields(fields []nonDefaultField) {
+
+	outputStatusMessage("Fields set to non-default values:")
+
+	if len(fields) == 0 {
+		outputStatusMessage("- (none: all compared fields use the default values)")
+		return
+	}
+
+	component := ""
+	for _, field := range fields {
+		if field.component != component {
+			component = field.component
+			outputStatusMessage("- " + color.New(color.FgHiWhite, color.Bold).Sprint(component) + ":")
+		}
+		defaultText := color.New(color.FgHiBlack).Sprint("(default: " + field.defaultValue + ")")
+		outputStatusMessage(fmt.Sprintf("  - %s: %s %s", field.crField, field.value, defaultText))
+	}
+}
+
+// formattedResourceRequirements returns the resource requests/limits in a compact, single line form (e.g. 'requests: cpu=250m, memory=256Mi; limits: memory=1Gi'), or false if no requests/limits are set.
+func formattedResourceRequirements(resources *corev1.ResourceRequirements) (string, bool) {
+
+	if resources == nil {
+		return "", false
+	}
+
+	formatList := func(list corev1.ResourceList) string {
+		names := []string{}
+		for name := range list {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		values := []string{}
+		for _, name := range names {
+			quantity := list[corev1.ResourceName(name)]
+			values = append(values, name+"="+quantity.String())
+		}
+		return strings.Join(values, ", ")
+	}
+
+	parts := []string{}
+	if len(resources.Requests) > 0 {
+		parts = append(parts, "requests: "+formatList(resources.Requests))
+	}
+	if len(resources.Limits) > 0 {
+		parts = append(parts, "limits: "+formatList(resources.Limits))
+	}
+
+	if len(parts) == 0 {
+		return "", false
+	}
+
+	return strings.Join(parts, "; "), true
+}
+
+// formattedReplicas returns the number of replicas, or false if not set
+func formattedReplicas(replicas *int32) (string, bool) {
+	if replicas == nil {
+		return "", false
+	}
+	return strconv.Itoa(int(*replicas)), true
+}
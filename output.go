@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// outputFormat is the format used to report the issues found for each ArgoCD instance
+type outputFormat string
+
+const (
+	// outputFormat_Text reports each issue as a multi-line block (severity, field, and full message). This is the default.
+	outputFormat_Text outputFormat = "text"
+
+	// outputFormat_TextCompact reports each issue as a single line ('<severity> <namespace>/<name> <field>: <message>'), which is easier to grep and scroll through than the default multi-line block, when there are many issues
+	outputFormat_TextCompact outputFormat = "text-compact"
+
+	// outputFormat_Table reports issues as a compact aligned table, sorted by severity, with long messages truncated
+	outputFormat_Table outputFormat = "table"
+
+	// outputFormat_JSON reports all results as a single JSON document, once all checks have completed. See jsonResults.
+	outputFormat_JSON outputFormat = "json"
+
+	// outputFormat_GitHub reports each issue as a GitHub Actions workflow command (e.g. '::warning ...::'), once all checks have completed, so that issues are shown as annotations. See outputResultsAsGitHubAnnotations.
+	outputFormat_GitHub outputFormat = "github"
+
+	// outputFormat_TeamCity reports each issue as a TeamCity service message (e.g. "##teamcity[inspection ...]"), once all checks have completed, so that issues are shown on the build results. See outputResultsAsTeamCityServiceMessages.
+	outputFormat_TeamCity outputFormat = "teamcity"
+
+	// outputFormat_CSV reports all findings as a single CSV document (one row per finding), once all checks have completed, for triage in a spreadsheet. See outputResultsAsCSV.
+	outputFormat_CSV outputFormat = "csv"
+)
+
+// outputFormats is the list of valid output formats, in the order they are presented to the user
+var outputFormats = []outputFormat{outputFormat_Text, outputFormat_TextCompact, outputFormat_Table, outputFormat_JSON, outputFormat_GitHub, outputFormat_TeamCity, outputFormat_CSV}
+
+// isMachineReadable returns true if the format is intended to be parsed by other tools, rather than read by a user
+func (f outputFormat) isMachineReadable() bool {
+	return f == outputFormat_JSON || f == outputFormat_GitHub || f == outputFormat_TeamCity || f == outputFormat_CSV
+}
+
+// parseOutputFormat converts the user-specified '--output' value into an outputFormat, or returns an error if it is not a valid format.
+func parseOutputFormat(value string) (outputFormat, error) {
+
+	for _, format := range outputFormats {
+		if string(format) == value {
+			return format, nil
+		}
+	}
+
+	validFormats := []string{}
+	for _, format := range outputFormats {
+		validFormats = append(validFormats, string(format))
+	}
+
+	return "", fmt.Errorf("unrecognized output format '%s': valid formats are: %s", value, strings.Join(validFormats, ", "))
+}
+
+// outputIssues reports the issues of a single ArgoCD instance (identified by 'instanceName', e.g. 'namespace/name'), in the given format
+func outputIssues(issues []issue, instanceName string, format outputFormat) {
+
+	issues = summarizeUnsupportedIssuesByComponent(issues)
+
+	switch format {
+	case outputFormat_TextCompact:
+		outputIssuesAsCompactText(issues, instanceName)
+
+	case outputFormat_Table:
+		outputIssuesAsTable(issues)
+
+	case outputFormat_JSON:
+		// Issues are reported by outputResultsAsJSON, once all instances have been checked
+
+	case outputFormat_GitHub:
+		// Issues are reported by outputResultsAsGitHubAnnotations, once all instances have been checked
+
+	case outputFormat_TeamCity:
+		// Issues are reported by outputResultsAsTeamCityServiceMessages, once all instances have been checked
+
+	case outputFormat_CSV:
+		// Issues are reported by outputResultsAsCSV, once all instances have been checked
+
+	default:
+		for _, issue := range issues {
+			reportIssue(issue)
+			fmt.Fprintln(reportOutput)
+		}
+	}
+}
+
+// minUnsupportedIssuesForComponentSummary is the number of unsupported issues of a single component, at which the issues are summarized (see summarizeUnsupportedIssuesByComponent)
+const minUnsupportedIssuesForComponentSummary = 2
+
+// summarizeUnsupportedIssuesByComponent correlates the unsupported issues of each component, so that a component which is unsupported for multiple reasons is reported as such, rather than as scattered individual issues. For each component with multiple unsupported issues, a summary issue is inserted immediately before the first issue of the component. The individual issues are kept, since they contain the details.
+// - The summaries are only added when reporting the issues to the user (see outputIssues): they are not findings, so they are not part of the check results (e.g. JSON output, or '--group-by rule').
+// - The component of an issue is the top-level CR field of the issue's field (see componentOfIssueField), for example '.spec.notifications' for '.spec.notifications.image'.
+// - The summary issue has the highest severity of the issues it summarizes. It has no rule ID, since it is not reported by a single check.
+// - 'issues' should be sorted by field (see sortIssuesByField), so that the issues of each component are adjacent.
+func summarizeUnsupportedIssuesByComponent(issues []issue) []issue {
+
+	unsupportedByComponent := map[string][]issue{}
+	for _, currIssue := range issues {
+		if component := componentOfIssueField(currIssue.field); currIssue.unsupported && component != "" {
+			unsupportedByComponent[component] = append(unsupportedByComponent[component], currIssue)
+		}
+	}
+
+	res := []issue{}
+	summarized := map[string]bool{}
+
+	for _, currIssue := range issues {
+
+		component := componentOfIssueField(currIssue.field)
+		componentIssues := unsupportedByComponent[component]
+
+		if len(componentIssues) >= minUnsupportedIssuesForComponentSummary && !summarized[component] {
+			summarized[component] = true
+
+			level := LogLevel_Warn
+			fields := []string{}
+			for _, componentIssue := range componentIssues {
+				if logLevelSeverity(componentIssue.level) > logLevelSeverity(level) {
+					level = componentIssue.level
+				}
+				fields = append(fields, "'"+componentIssue.field+"'")
+			}
+
+			res = append(res, issue{
+				level:       level,
+				field:       component,
+				message:     fmt.Sprintf("The '%s' component is in an unsupported configuration for %d reasons: %s. See the individual issues of these fields for details. All of these must be resolved for the component to be in a supported configuration.", component, len(componentIssues), strings.Join(fields, ", ")),
+				unsupported: true,
+			})
+		}
+
+		res = append(res, currIssue)
+	}
+
+	return res
+}
+
+// componentOfIssueField returns the top-level ArgoCD CR field (e.g. '.spec.notifications') of an issue field (e.g. '.spec.notifications.image' or '.spec.notifications.env[X]'), or an empty string if the issue field is not an ArgoCD CR '.spec' field.
+func componentOfIssueField(field string) string {
+
+	rest, found := strings.CutPrefix(field, ".spec.")
+	if !found {
+		return ""
+	}
+
+	if end := strings.IndexAny(rest, ".[: "); end != -1 {
+		rest = rest[:end]
+	}
+
+	if rest == "" {
+		return ""
+	}
+
+	return ".spec." + rest
+}
+
+// outputIssuesAsCompactText reports each issue as a single line: '<severity> <instance> <field>: <message>'. Only the severity is colored, so that lines remain easy to grep. Unlike the table format, messages are not truncated.
+func outputIssuesAsCompactText(issues []issue, instanceName string) {
+
+	for _, issue := range issues {
+
+		message := strings.Join(strings.Fields(issue.message), " ")
+		if issue.unsupported {
+			message = "[Unsupported] " + message
+		}
+
+		fmt.Fprintf(reportOutput, "%s %s %s: %s\n", colorizeLogLevel(issue.level, strings.ToUpper(string(issue.level))), instanceName, issue.field, message)
+	}
+
+	fmt.Fprintln(reportOutput)
+}
+
+// maxTableMessageWidth is the maximum number of characters of an issue message to output in a table row. Longer messages are truncated.
+const maxTableMessageWidth = 100
+
+// outputIssuesAsTable reports issues as an aligned 'Severity | Field | Message' table, sorted from most to least severe.
+func outputIssuesAsTable(issues []issue) {
+
+	sortedIssues := slices.Clone(issues)
+	sort.SliceStable(sortedIssues, func(i, j int) bool {
+		return logLevelSeverity(sortedIssues[i].level) > logLevelSeverity(sortedIssues[j].level)
+	})
+
+	const severityHeader = "Severity"
+	const ruleHeader = "Rule"
+	const fieldHeader = "Field"
+	const messageHeader = "Message"
+
+	// Column widths are calculated from the uncolored text, since color escape codes are not visible. Widths are in runes (rather than bytes), since that is how fmt pads strings, and how messages are truncated.
+	severityWidth := utf8.RuneCountInString(severityHeader)
+	ruleWidth := utf8.RuneCountInString(ruleHeader)
+	fieldWidth := utf8.RuneCountInString(fieldHeader)
+	for _, issue := range sortedIssues {
+		severityWidth = max(severityWidth, utf8.RuneCountInString(string(issue.level)))
+		ruleWidth = max(ruleWidth, utf8.RuneCountInString(issue.ruleID))
+		fieldWidth = max(fieldWidth, utf8.RuneCountInString(issue.field))
+	}
+
+	fmt.Fprintf(reportOutput, "%-*s | %-*s | %-*s | %s\n", severityWidth, severityHeader, ruleWidth, ruleHeader, fieldWidth, fieldHeader, messageHeader)
+	fmt.Fprintln(reportOutput, strings.Repeat("-", severityWidth)+"-+-"+strings.Repeat("-", ruleWidth)+"-+-"+strings.Repeat("-", fieldWidth)+"-+-"+strings.Repeat("-", utf8.RuneCountInString(messageHeader)))
+
+	for _, issue := range sortedIssues {
+
+		message := issue.message
+		if issue.unsupported {
+			message = "[Unsupported] " + message
+		}
+
+		paddedLevel := fmt.Sprintf("%-*s", severityWidth, issue.level)
+
+		fmt.Fprintf(reportOutput, "%s | %-*s | %-*s | %s\n", colorizeLogLevel(issue.level, paddedLevel), ruleWidth, issue.ruleID, fieldWidth, issue.field, truncateString(message, maxTableMessageWidth))
+	}
+
+	fmt.Fprintln(reportOutput)
+}
+
+// colorizeLogLevel returns 'text' in the color that is used for the given log level
+func colorizeLogLevel(level LogLevel, text string) string {
+	switch level {
+	case LogLevel_Fatal:
+		return color.New(color.FgRed, color.Bold).Sprint(text)
+	case LogLevel_Error:
+		return color.RedString("%s", text)
+	case LogLevel_Warn:
+		return color.YellowString("%s", text)
+	default:
+		return text
+	}
+}
+
+// truncateString returns 'str' truncated to at most 'maxLength' characters, with a '...' indicator at the end if the string was truncated.
+func truncateString(str string, maxLength int) string {
+
+	runes := []rune(str)
+	if len(runes) <= maxLength {
+		return str
+	}
+
+	return string(runes[:maxLength-3]) + "..."
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// captureReportOutput redirects the report output (and disables color) for the duration of the test, and returns the buffer that the output is written to
+func captureReportOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	previousOutput, previousNoColor := reportOutput, color.NoColor
+	t.Cleanup(func() {
+		reportOutput, color.NoColor = previousOutput, previousNoColor
+	})
+
+	res := &bytes.Buffer{}
+	reportOutput = res
+	color.NoColor = true
+
+	return res
+}
+
+func TestOutputIssuesAsTableAlignsNonASCIIText(t *testing.T) {
+
+	output := captureReportOutput(t)
+
+	longestField := ".spec.extraConfig[über.schlüssel.für.größe]"
+
+	outputIssuesAsTable([]issue{
+		{level: LogLevel_Warn, ruleID: "ACC001", field: ".spec.extraConfig[ui.bannercontent]", message: "Bannière: « déploiement » ✓"},
+		{level: LogLevel_Error, ruleID: "ACC010", field: longestField, message: strings.Repeat("é", 150)},
+		{level: LogLevel_Info, ruleID: "", field: ".spec.server", message: "plain"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header, a separator, and 3 rows, got:\n%s", output.String())
+	}
+
+	// columnPositions returns the (rune) positions of the column separators of a line
+	columnPositions := func(line string, separator rune) []int {
+		res := []int{}
+		for idx, r := range []rune(line) {
+			if r == separator {
+				res = append(res, idx)
+			}
+		}
+		return res
+	}
+
+	expected := columnPositions(lines[0], '|')
+	if len(expected) != 3 {
+		t.Fatalf("expected 3 column separators in the header: %s", lines[0])
+	}
+
+	// The field column is as wide as the longest field (in runes), so that multi-byte characters do not widen the column
+	if fieldWidth := expected[2] - expected[1] - 3; fieldWidth != utf8.RuneCountInString(longestField) {
+		t.Errorf("expected the field column to be %d wide, got %d\n%s", utf8.RuneCountInString(longestField), fieldWidth, output.String())
+	}
+
+	if actual := columnPositions(lines[1], '+'); !slices.Equal(actual, expected) {
+		t.Errorf("separator line is not aligned with the header: %v != %v\n%s", actual, expected, output.String())
+	}
+
+	for _, line := range lines[2:] {
+		// Only the first 3 separators delimit columns: the message may also contain '|'
+		if actual := columnPositions(line, '|'); len(actual) < 3 || !slices.Equal(actual[:3], expected) {
+			t.Errorf("row is not aligned with the header: %v != %v\n%s", actual, expected, output.String())
+		}
+	}
+
+	// Rows are sorted by severity, and messages are truncated to maxTableMessageWidth runes
+	if !strings.HasSuffix(lines[2], strings.Repeat("é", maxTableMessageWidth-3)+"...") {
+		t.Errorf("expected the long message to be truncated, got: %s", lines[2])
+	}
+}
+
+func TestOutputIssuesSummarizesUnsupportedIssuesByComponent(t *testing.T) {
+
+	output := captureReportOutput(t)
+
+	issues := []issue{
+		{level: LogLevel_Warn, ruleID: "ACC002", field: ".spec.notifications.image", message: "custom image", unsupported: true},
+		{level: LogLevel_Error, ruleID: "ACC003", field: ".spec.notifications.replicas", message: "tech preview", unsupported: true},
+		{level: LogLevel_Warn, ruleID: "ACC004", field: ".spec.server.insecure", message: "insecure"},
+	}
+	original := slices.Clone(issues)
+
+	outputIssues(issues, "team-a/argocd", outputFormat_TextCompact)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a summary and 3 issues, got:\n%s", output.String())
+	}
+
+	if expected := "ERROR team-a/argocd .spec.notifications: [Unsupported] The '.spec.notifications' component is in an unsupported configuration for 2 reasons"; !strings.HasPrefix(lines[0], expected) {
+		t.Errorf("expected the summary %q first, got:\n%s", expected, output.String())
+	}
+
+	// The summary is only added to the output, not to the issues of the instance
+	if !slices.EqualFunc(issues, original, func(a, b issue) bool { return a.field == b.field && a.message == b.message }) {
+		t.Errorf("expected the issues to be unchanged, got %+v", issues)
+	}
+}
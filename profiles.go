@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sizingProfile is a preset for an expected scale of Argo CD instance (selected via '--profile'). When a profile is selected, settings of the ArgoCD CR which fall below the expectations of the profile are reported as tuning recommendations.
+type sizingProfile struct {
+	name string
+
+	// description is a short description of the scale of installation the profile is intended for
+	description string
+
+	// minControllerShards is the minimum number of application controller shards. 1 indicates sharding is not expected.
+	minControllerShards int32
+
+	// minStatusProcessors/minOperationProcessors are the minimum application controller processor counts
+	minStatusProcessors    int32
+	minOperationProcessors int32
+
+	// minParallelismLimit is the minimum number of concurrent kubectl fork/execs of the application controller
+	minParallelismLimit int32
+
+	// minServerReplicas/minRepoReplicas are the minimum replica counts of the server and repo server. Server autoscaling is accepted in place of server replicas.
+	minServerReplicas int32
+	minRepoReplicas   int32
+
+	// minControllerMemoryLimit/minRepoMemoryLimit are the minimum memory limits of the application controller and repo server. Components without a memory limit are not reported.
+	minControllerMemoryLimit resource.Quantity
+	minRepoMemoryLimit       resource.Quantity
+}
+
+// Defaults used by Argo CD/the operator when the corresponding ArgoCD CR field is not set
+const (
+	defaultStatusProcessors    = 20
+	defaultOperationProcessors = 10
+	defaultParallelismLimit    = 10
+)
+
+// sizingProfiles are the available '--profile' values, from smallest to largest
+var sizingProfiles = []sizingProfile{
+	{
+		name:                     "small",
+		description:              "up to ~100 Applications, managing a small number of clusters",
+		minControllerShards:      1,
+		minStatusProcessors:      defaultStatusProcessors,
+		minOperationProcessors:   defaultOperationProcessors,
+		minParallelismLimit:      defaultParallelismLimit,
+		minServerReplicas:        1,
+		minRepoReplicas:          1,
+		minControllerMemoryLimit: resource.MustParse("1Gi"),
+		minRepoMemoryLimit:       resource.MustParse("512Mi"),
+	},
+	{
+		name:                     "medium",
+		description:              "hundreds of Applications, managing tens of clusters",
+		minControllerShards:      1,
+		minStatusProcessors:      50,
+		minOperationProcessors:   25,
+		minParallelismLimit:      20,
+		minServerReplicas:        2,
+		minRepoReplicas:          2,
+		minControllerMemoryLimit: resource.MustParse("2Gi"),
+		minRepoMemoryLimit:       resource.MustParse("1Gi"),
+	},
+	{
+		name:                     "large",
+		description:              "thousands of Applications, managing many clusters",
+		minControllerShards:      3,
+		minStatusProcessors:      100,
+		minOperationProcessors:   50,
+		minParallelismLimit:      40,
+		minServerReplicas:        3,
+		minRepoReplicas:          3,
+		minControllerMemoryLimit: resource.MustParse("4Gi"),
+		minRepoMemoryLimit:       resource.MustParse("2Gi"),
+	},
+}
+
+// findSizingProfile returns the sizing profile with the given name, or an error listing the valid profiles if there is no such profile
+func findSizingProfile(name string) (*sizingProfile, error) {
+
+	validNames := []string{}
+	for i := range sizingProfiles {
+		if sizingProfiles[i].name == name {
+			return &sizingProfiles[i], nil
+		}
+		validNames = append(validNames, sizingProfiles[i].name)
+	}
+
+	return nil, fmt.Errorf("unrecognized profile '%s': valid profiles are: %s", name, strings.Join(validNames, ", "))
+}
+
+// describeSizingProfiles returns a description of the expectations of each sizing profile (used by '--explain')
+func describeSizingProfiles() string {
+
+	descriptions := []string{}
+
+	for _, profile := range sizingProfiles {
+		descriptions = append(descriptions, fmt.Sprintf("'%s' (%s): controller shards >= %d, status processors >= %d, operation processors >= %d, parallelism limit >= %d, server replicas >= %d (or autoscaling), repo server replicas >= %d, controller memory limit >= %s, repo server memory limit >= %s.",
+			profile.name, profile.description, profile.minControllerShards, profile.minStatusProcessors, profile.minOperationProcessors, profile.minParallelismLimit, profile.minServerReplicas, profile.minRepoReplicas, profile.minControllerMemoryLimit.String(), profile.minRepoMemoryLimit.String()))
+	}
+
+	return strings.Join(descriptions, " ")
+}
+
+// checkSizingProfile reports settings of the ArgoCD CR which fall below the expectations of the sizing profile selected by the user (via '--profile'). No issues are reported if no profile was selected.
+func checkSizingProfile(argoCD v1beta1.ArgoCD, clusterInfo clusterInformation, issues *[]issue) {
+
+	if clusterInfo.SizingProfile == "" {
+		return
+	}
+
+	profile, err := findSizingProfile(clusterInfo.SizingProfile)
+	if err != nil {
+		return
+	}
+
+	spec := argoCD.Spec
+
+	// recommend reports a single setting which is below the expectation of the profile
+	recommend := func(field string, setting string, actual string, expected string) {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   field,
+			message: fmt.Sprintf("The %s is %s, but the '%s' profile (%s) expects at least %s. Consider increasing '%s'.", setting, actual, profile.name, profile.description, expected, field),
+		})
+	}
+
+	valueOrDefault := func(value int32, defaultValue int32) int32 {
+		if value <= 0 {
+			return defaultValue
+		}
+		return value
+	}
+
+	if spec.Controller.IsEnabled() {
+
+		controller := spec.Controller
+
+		if shards := expectedControllerShards(argoCD); shards < profile.minControllerShards {
+			recommend(".spec.controller.sharding", "number of application controller shards", fmt.Sprintf("%d", shards), fmt.Sprintf("%d", profile.minControllerShards))
+		}
+
+		if processors := valueOrDefault(controller.Processors.Status, defaultStatusProcessors); processors < profile.minStatusProcessors {
+			recommend(".spec.controller.processors.status", "number of status processors", fmt.Sprintf("%d", processors), fmt.Sprintf("%d", profile.minStatusProcessors))
+		}
+
+		if processors := valueOrDefault(controller.Processors.Operation, defaultOperationProcessors); processors < profile.minOperationProcessors {
+			recommend(".spec.controller.processors.operation", "number of operation processors", fmt.Sprintf("%d", processors), fmt.Sprintf("%d", profile.minOperationProcessors))
+		}
+
+		if parallelismLimit := valueOrDefault(controller.ParallelismLimit, defaultParallelismLimit); parallelismLimit < profile.minParallelismLimit {
+			recommend(".spec.controller.parallelismLimit", "kubectl parallelism limit", fmt.Sprintf("%d", parallelismLimit), fmt.Sprintf("%d", profile.minParallelismLimit))
+		}
+
+		if memoryLimit, exists := memoryLimitOf(controller.Resources); exists && memoryLimit.Cmp(profile.minControllerMemoryLimit) < 0 {
+			recommend(".spec.controller.resources.limits.memory", "application controller memory limit", memoryLimit.String(), profile.minControllerMemoryLimit.String())
+		}
+	}
+
+	if spec.Server.IsEnabled() && !spec.Server.Autoscale.Enabled {
+		if replicas := valueOrDefault(ptrValueOrZero(spec.Server.Replicas), 1); replicas < profile.minServerReplicas {
+			recommend(".spec.server.replicas", "number of server replicas", fmt.Sprintf("%d", replicas), fmt.Sprintf("%d (or server autoscaling)", profile.minServerReplicas))
+		}
+	}
+
+	if spec.Repo.IsEnabled() && !spec.Repo.IsRemote() {
+
+		if replicas := valueOrDefault(ptrValueOrZero(spec.Repo.Replicas), 1); replicas < profile.minRepoReplicas {
+			recommend(".spec.repo.replicas", "number of repo server replicas", fmt.Sprintf("%d", replicas), fmt.Sprintf("%d", profile.minRepoReplicas))
+		}
+
+		if memoryLimit, exists := memoryLimitOf(spec.Repo.Resources); exists && memoryLimit.Cmp(profile.minRepoMemoryLimit) < 0 {
+			recommend(".spec.repo.resources.limits.memory", "repo server memory limit", memoryLimit.String(), profile.minRepoMemoryLimit.String())
+		}
+	}
+}
+
+// memoryLimitOf returns the memory limit of the resource requirements, and whether a memory limit was set
+func memoryLimitOf(resources *corev1.ResourceRequirements) (resource.Quantity, bool) {
+
+	if resources == nil {
+		return resource.Quantity{}, false
+	}
+
+	memoryLimit, exists := resources.Limits[corev1.ResourceMemory]
+	return memoryLimit, exists
+}
+
+// ptrValueOrZero returns the value pointed to, or 0 if the pointer is nil
+func ptrValueOrZero(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
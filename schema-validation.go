@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/jgwest/argocd-config-check/clients"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// argoCDCRDData is the ArgoCD CustomResourceDefinition of the operator version whose API is embedded in the tool (github.com/argoproj-labs/argocd-operator, 'config/crd/bases/argoproj.io_argocds.yaml'). Update it alongside the operator dependency.
+//
+//go:embed schemas/argoproj.io_argocds.yaml
+var argoCDCRDData []byte
+
+// argoCDSchema is the OpenAPI schema of the v1beta1 ArgoCD CR, parsed from argoCDCRDData, in the forms used to find structural violations: unknown fields (structural), and all other violations such as wrong types and invalid enum values (validator)
+type argoCDSchema struct {
+	structural *structuralschema.Structural
+	validator  validation.SchemaValidator
+}
+
+// loadArgoCDSchema parses the embedded ArgoCD CRD, on first use
+var loadArgoCDSchema = sync.OnceValues(func() (*argoCDSchema, error) {
+
+	var crd crdv1.CustomResourceDefinition
+	if err := yaml.Unmarshal(argoCDCRDData, &crd); err != nil {
+		return nil, fmt.Errorf("unable to parse the embedded ArgoCD CRD: %w", err)
+	}
+
+	var versionSchema *crdv1.JSONSchemaProps
+	for _, version := range crd.Spec.Versions {
+		if version.Name == v1beta1.GroupVersion.Version && version.Schema != nil {
+			versionSchema = version.Schema.OpenAPIV3Schema
+		}
+	}
+	if versionSchema == nil {
+		return nil, fmt.Errorf("the embedded ArgoCD CRD does not contain a schema for version '%s'", v1beta1.GroupVersion.Version)
+	}
+
+	var internalSchema apiextensions.JSONSchemaProps
+	if err := crdv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(versionSchema, &internalSchema, nil); err != nil {
+		return nil, fmt.Errorf("unable to convert the embedded ArgoCD CRD schema: %w", err)
+	}
+
+	structural, err := structuralschema.NewStructural(&internalSchema)
+	if err != nil {
+		return nil, fmt.Errorf("the embedded ArgoCD CRD schema is not structural: %w", err)
+	}
+
+	validator, _, err := validation.NewSchemaValidator(&internalSchema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a validator from the embedded ArgoCD CRD schema: %w", err)
+	}
+
+	return &argoCDSchema{structural: structural, validator: validator}, nil
+})
+
+// checkArgoCDCRAgainstSchema validates the ArgoCD CR, as it was read from the cluster/must-gather/manifest, against the OpenAPI schema of the embedded ArgoCD CRD, and reports each structural violation (for example an unknown field, a value of the wrong type, or a value which is not one of the allowed values) as an Error, at the JSON path of the violation.
+// - The other checks only see the fields of the CR which are known to the tool, since the CR is decoded into the ArgoCD type: unknown fields (e.g. a misspelled field name) are silently dropped, just as they are by the API server (or rejected, with strict field validation). This is most useful for manifests, which have not yet been applied to a cluster.
+// - The raw CR is read again via the client, since the unknown fields are not present in 'argoCD'.
+func checkArgoCDCRAgainstSchema(ctx context.Context, k8sClient clients.AbstractK8sClient, argoCD v1beta1.ArgoCD, issues *[]issue) {
+
+	schema, err := loadArgoCDSchema()
+	if err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(ArgoCD CRD schema)",
+			message: "Unable to validate the ArgoCD CR against the ArgoCD CRD schema: " + err.Error(),
+		})
+		return
+	}
+
+	rawArgoCD := &unstructured.Unstructured{}
+	rawArgoCD.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("ArgoCD"))
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&argoCD), rawArgoCD); err != nil {
+		*issues = append(*issues, issue{
+			level:   LogLevel_Warn,
+			field:   "(ArgoCD CRD schema)",
+			message: "Unable to read the ArgoCD CR, so it could not be validated against the ArgoCD CRD schema: " + err.Error(),
+		})
+		return
+	}
+
+	*issues = append(*issues, validateAgainstArgoCDSchema(schema, rawArgoCD.Object)...)
+}
+
+// validateAgainstArgoCDSchema returns an Error issue for each violation of the ArgoCD CRD schema by the (unstructured) ArgoCD CR, in the order: unknown fields, then all other violations
+func validateAgainstArgoCDSchema(schema *argoCDSchema, object map[string]any) []issue {
+
+	res := []issue{}
+
+	// Pruning removes (and returns the paths of) unknown fields: the object is copied, so that the caller's object is unchanged
+	objectCopy := (&unstructured.Unstructured{Object: object}).DeepCopy().Object
+
+	unknownFieldPaths := pruning.PruneWithOptions(objectCopy, schema.structural, true, structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true})
+	for _, unknownFieldPath := range unknownFieldPaths {
+		res = append(res, issue{
+			level:   LogLevel_Error,
+			field:   "." + unknownFieldPath,
+			message: fmt.Sprintf("The field '.%s' is not defined by the ArgoCD CRD schema, so it is dropped by the API server (or the CR is rejected, with strict field validation), and has no effect. This is often a misspelled field name, or a field in the wrong place: see the ArgoCD CR documentation for the correct field.", unknownFieldPath),
+		})
+	}
+
+	// Validation of metadata is the responsibility of the API server, not of the CRD schema
+	for _, fieldError := range validation.ValidateCustomResource(nil, objectCopy, schema.validator) {
+		if strings.HasPrefix(fieldError.Field, "metadata") {
+			continue
+		}
+		res = append(res, issue{
+			level:   LogLevel_Error,
+			field:   "." + fieldError.Field,
+			message: "The ArgoCD CR is not valid according to the ArgoCD CRD schema, so the API server rejects it: " + schemaViolationDescription(fieldError),
+		})
+	}
+
+	return res
+}
+
+// preflightArgoCDSchemaValidation validates the ArgoCD CRs which cannot be decoded by the tool (for example, a manifest with a string where a boolean is expected) against the ArgoCD CRD schema, and exits with the schema violations of each.
+// - Without this, the run fails with only the decoding error, which does not include the path of the field. CRs which can be decoded are validated by checkArgoCDCRAgainstSchema, alongside the other checks.
+// - The API server rejects CRs with such violations, so in practice this only applies to manifests (and to must-gathers which were edited by hand).
+func preflightArgoCDSchemaValidation(ctx context.Context, k8sClient clients.AbstractK8sClient) {
+
+	rawArgoCDList := &unstructured.UnstructuredList{}
+	rawArgoCDList.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("ArgoCDList"))
+	if err := k8sClient.ListFromAllNamespaces(ctx, rawArgoCDList); err != nil {
+		// Failures to list ArgoCD CRs are reported by listArgoCDs
+		return
+	}
+
+	schema, err := loadArgoCDSchema()
+	if err != nil {
+		failWithError("unable to validate ArgoCD CRs against the ArgoCD CRD schema", err)
+	}
+
+	undecodable := 0
+
+	for _, rawArgoCD := range rawArgoCDList.Items {
+
+		var argoCD v1beta1.ArgoCD
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawArgoCD.Object, &argoCD); err == nil {
+			continue
+		}
+		undecodable++
+
+		outputStatusMessage(fmt.Sprintf("ArgoCD '%s' in namespace '%s' does not match the ArgoCD CRD schema, and cannot be checked:", rawArgoCD.GetName(), rawArgoCD.GetNamespace()))
+		for _, violation := range validateAgainstArgoCDSchema(schema, rawArgoCD.Object) {
+			outputStatusMessage(fmt.Sprintf("- %s: %s", violation.field, violation.message))
+		}
+		outputStatusMessage("")
+	}
+
+	if undecodable > 0 {
+		failWithError(fmt.Sprintf("%d ArgoCD CR(s) do not match the ArgoCD CRD schema: correct the violations listed above, and re-run.", undecodable), nil)
+	}
+}
+
+// schemaViolationDescription returns a description of a schema violation, for example 'Unsupported value: "bogus": supported values: "edge", "reencrypt", "passthrough"'
+func schemaViolationDescription(fieldError *field.Error) string {
+	if fieldError.Detail != "" && fieldError.Type == field.ErrorTypeTypeInvalid {
+		// The detail already includes the path and value, e.g. 'spec.ha.enabled in body must be of type boolean: "string"'
+		return fieldError.Detail
+	}
+	return fieldError.ErrorBody()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scoring of an ArgoCD instance, for an at-a-glance comparison of the health of instances (e.g. for dashboards).
+//
+// The score is computed from the issues of the instance, as follows:
+// - The score starts at 100 (no issues).
+// - Each issue deducts a penalty based on its severity: see scorePenaltyFatal/Error/Warn.
+// - Each issue which is an unsupported configuration deducts an additional scorePenaltyUnsupported.
+// - The score is never less than 0.
+// - Duplicate issues (see dedupeIssues) are counted once, and the score is computed before '--only-unsupported' filtering, so that the score of an instance does not depend on which issues are reported.
+//
+// The grade is derived from the score: A (90-100), B (80-89), C (70-79), D (60-69), F (0-59).
+const (
+	scoreMaximum = 100
+
+	scorePenaltyFatal = 40
+	scorePenaltyError = 15
+	scorePenaltyWarn  = 5
+
+	scorePenaltyUnsupported = 10
+)
+
+// scoreGrades are the minimum score of each grade, from best to worst
+var scoreGrades = []struct {
+	grade    string
+	minScore int
+}{
+	{grade: "A", minScore: 90},
+	{grade: "B", minScore: 80},
+	{grade: "C", minScore: 70},
+	{grade: "D", minScore: 60},
+	{grade: "F", minScore: 0},
+}
+
+// instanceScore is the score (0-100) and grade of a single ArgoCD instance. See the scoring constants above for how this is computed.
+type instanceScore struct {
+	score int
+	grade string
+}
+
+// scoreIssues computes the score and grade of an ArgoCD instance from its issues
+func scoreIssues(issues []issue) instanceScore {
+
+	score := scoreMaximum
+
+	for _, issue := range issues {
+
+		switch issue.level {
+		case LogLevel_Fatal:
+			score -= scorePenaltyFatal
+		case LogLevel_Error:
+			score -= scorePenaltyError
+		case LogLevel_Warn:
+			score -= scorePenaltyWarn
+		}
+
+		if issue.unsupported {
+			score -= scorePenaltyUnsupported
+		}
+	}
+
+	score = max(score, 0)
+
+	res := instanceScore{score: score}
+	for _, grade := range scoreGrades {
+		if score >= grade.minScore {
+			res.grade = grade.grade
+			break
+		}
+	}
+
+	return res
+}
+
+// string returns the score in the form '85/100 (grade B)'
+func (s instanceScore) string() string {
+	return fmt.Sprintf("%d/%d (grade %s)", s.score, scoreMaximum, s.grade)
+}
+
+// outputScoreSummary outputs the score of each ArgoCD instance, once all instances have been checked
+func outputScoreSummary(results checkResults) {
+
+	if len(results.instances) == 0 {
+		return
+	}
+
+	outputStatusMessage("--------------------")
+	outputStatusMessage("Instance scores (" + scoreFormulaDescription + "):")
+	for _, instance := range results.instances {
+		outputStatusMessage(fmt.Sprintf("- %s: %s", results.instanceName(instance), instance.score.string()))
+	}
+	outputStatusMessage("")
+}
+
+// instancesBelowMinimumScore returns the ('namespace/name' of the) ArgoCD instances whose score is less than 'minScore'
+func instancesBelowMinimumScore(results checkResults, minScore int) []string {
+	res := []string{}
+	for _, instance := range results.instances {
+		if instance.score.score < minScore {
+			res = append(res, results.instanceName(instance))
+		}
+	}
+	return res
+}
+
+// scoreFormulaDescription is a one line description of how scores are calculated, for the usage output
+var scoreFormulaDescription = strings.Join([]string{
+	fmt.Sprintf("score starts at %d", scoreMaximum),
+	fmt.Sprintf("-%d per fatal, -%d per error, -%d per warning", scorePenaltyFatal, scorePenaltyError, scorePenaltyWarn),
+	fmt.Sprintf("-%d more per unsupported configuration", scorePenaltyUnsupported),
+	"minimum 0",
+	"grades: A >= 90, B >= 80, C >= 70, D >= 60, otherwise F",
+}, "; ")
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"path"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	semver "github.com/blang/semver/v4"
+	"github.com/fatih/color"
+	"sigs.k8s.io/yaml"
+)
+
+// selfTestFixturesFS contains ArgoCD CRs which are used to verify the checks, without requiring access to a cluster or must-gather.
+//
+//go:embed fixtures/*.yaml
+var selfTestFixturesFS embed.FS
+
+// expectedIssue is an issue that a self-test fixture is expected to produce
+type expectedIssue struct {
+	level LogLevel
+	field string
+}
+
+// selfTestFixture is an embedded ArgoCD CR, and the issues that the checks are expected to report for it.
+// - A fixture with no expected issues must produce no issues at all.
+// - Otherwise, the expected issues must be present, but other issues are tolerated.
+type selfTestFixture struct {
+	file           string
+	expectedIssues []expectedIssue
+
+	// clusterInfo is the cluster information that the checks are run with (for example, namespace labels). This is empty for most fixtures.
+	clusterInfo clusterInformation
+}
+
+// selfTestFixtures is one clean ArgoCD CR, plus one CR for each class of problem that is detected by the checks. This also serves as an example of what each check catches.
+var selfTestFixtures = []selfTestFixture{
+	{
+		file: "clean.yaml",
+	},
+	{
+		file: "deprecated-fields.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.initialRepositories"},
+			{level: LogLevel_Error, field: ".spec.grafana"},
+			{level: LogLevel_Warn, field: ".spec.extraConfig[repositories]"},
+		},
+	},
+	{
+		file: "custom-images.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.repo.image"},
+			{level: LogLevel_Warn, field: ".spec.version"},
+		},
+	},
+	{
+		file: "tech-preview.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.sharding.dynamicScalingEnabled"},
+		},
+	},
+	{
+		file: "overlapping-fields.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.extraConfig[admin.enabled]"},
+			{level: LogLevel_Error, field: ".spec.server.env[ARGOCD_API_SERVER_REPLICAS]"},
+			{level: LogLevel_Error, field: ".spec.server.env[ARGOCD_SERVER_INSECURE]"},
+			{level: LogLevel_Error, field: ".spec.applicationSet.env[ARGOCD_APPLICATIONSET_CONTROLLER_LOGLEVEL]"},
+			{level: LogLevel_Info, field: ".spec.applicationSet.env[ARGOCD_APPLICATIONSET_CONTROLLER_GIT_TIMEOUT]"},
+		},
+	},
+	{
+		file: "incorrect-configuration.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.extraConfig[controller.log.level]"},
+			{level: LogLevel_Error, field: ".spec.cmdParams[not.a.real.key]"},
+			{level: LogLevel_Warn, field: ".spec.controller.env[ARGOCD_RECONCILIATION_JITTER]"},
+			{level: LogLevel_Warn, field: ".spec.extraConfig[url]"},
+		},
+	},
+	{
+		file: "status.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".status.phase"},
+			{level: LogLevel_Error, field: ".status.conditions[].type = Reconciled"},
+		},
+	},
+	{
+		file: "best-practices.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.server.insecure"},
+			{level: LogLevel_Warn, field: ".spec.server.extraCommandArgs: --insecure"},
+			{level: LogLevel_Warn, field: ".spec.server.replicas"},
+			{level: LogLevel_Warn, field: ".spec.server.service.type"},
+			{level: LogLevel_Info, field: ".spec.disableAdmin"},
+			{level: LogLevel_Warn, field: ".spec.ha.enabled"},
+		},
+	},
+	{
+		file: "malformed-env-values.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.repo.env[ARGOCD_GIT_ATTEMPTS_COUNT]"},
+		},
+	},
+	{
+		file: "disabled-components.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.repo.enabled"},
+		},
+	},
+	{
+		file: "reconciliation-timeout.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.appSync"},
+		},
+	},
+	{
+		file: "repository-connection.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.initialSSHKnownHosts.keys"},
+			{level: LogLevel_Error, field: ".spec.tls.initialCerts[git.example.com]"},
+		},
+	},
+	{
+		file: "cache-paths.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.env[KUBECACHEDIR]"},
+		},
+	},
+	{
+		file: "conflicting-managed-by-labels.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: "(labels of Namespace 'team-a')"},
+		},
+		clusterInfo: clusterInformation{
+			// 'team-a' is labeled as managed by both the namespace-scoped instance in 'team-a-argocd', and the cluster-scoped instance in 'self-test'
+			NamespaceWithManagedByLabel:              map[string]string{"team-a": "team-a-argocd"},
+			NamespaceWithManagedByClusterArgoCDLabel: map[string]string{"team-a": "self-test"},
+		},
+	},
+	{
+		file: "sizing-profile.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.sharding"},
+			{level: LogLevel_Warn, field: ".spec.controller.processors.status"},
+			{level: LogLevel_Warn, field: ".spec.repo.resources.limits.memory"},
+		},
+		clusterInfo: clusterInformation{
+			SizingProfile: "large",
+		},
+	},
+	{
+		file: "resource-health-checks.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.resourceHealthChecks[certmanager.k8s.io/Certificate]"},
+			{level: LogLevel_Warn, field: ".spec.resourceHealthChecks[example.com/Widget]"},
+		},
+	},
+	{
+		file: "dex-config.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.sso.dex.config.connectors[id=github]"},
+		},
+	},
+	{
+		file: "dex-conflicting-config.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.extraConfig[dex.config]"},
+		},
+	},
+	{
+		file: "controller-custom-containers.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.initContainers"},
+			{level: LogLevel_Warn, field: ".spec.controller.sidecarContainers"},
+			{level: LogLevel_Warn, field: ".spec.controller.initContainers[seed-home].volumeMounts[argocd-home]"},
+		},
+	},
+	{
+		file: "resource-exclusions.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.resourceExclusions[1]"},
+		},
+	},
+	{
+		file: "server-autoscale.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.server.resources.requests.cpu"},
+			{level: LogLevel_Warn, field: ".spec.server.autoscale.hpa.minReplicas"},
+			{level: LogLevel_Warn, field: ".spec.server.autoscale.hpa.targetCPUUtilizationPercentage"},
+			{level: LogLevel_Error, field: ".spec.server.autoscale.hpa.scaleTargetRef"},
+		},
+	},
+	{
+		file: "web-terminal.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.extraConfig[exec.enabled]"},
+		},
+	},
+	{
+		file: "keycloak-supported-version.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.sso.keycloak"},
+		},
+		clusterInfo: clusterInformation{
+			// The last operator version before Keycloak support was removed (see keycloakRemovalOperatorVersion)
+			OperatorVersion: &semver.Version{Major: 1, Minor: 15, Patch: 3},
+		},
+	},
+	{
+		file: "keycloak-removed-version.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.sso.keycloak"},
+		},
+		clusterInfo: clusterInformation{
+			OperatorVersion: &semver.Version{Major: 1, Minor: 16, Patch: 0},
+		},
+	},
+	{
+		file: "log-formats.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.server.logFormat"},
+			{level: LogLevel_Warn, field: ".spec.cmdParams[reposerver.log.format]"},
+			{level: LogLevel_Warn, field: ".spec.applicationSet.logformat"},
+		},
+	},
+	{
+		file: "repo-service-account-token.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.repo.mountsatoken"},
+			{level: LogLevel_Warn, field: ".spec.repo.volumes[api-token]"},
+		},
+	},
+	{
+		file: "allowlisted-images.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Info, field: ".spec.repo.image"},
+			{level: LogLevel_Error, field: ".spec.redis.image"},
+		},
+		clusterInfo: clusterInformation{
+			// The repo server image is a mirror of the official image, but the redis image is not
+			ImageAllowlist: []string{"mirror.example.com/openshift-gitops-1/"},
+		},
+	},
+	{
+		file: "dynamic-sharding.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.controller.sharding.replicas"},
+			{level: LogLevel_Error, field: ".spec.controller.sharding.maxShards"},
+		},
+	},
+	{
+		file: "suppressed-findings.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Info, field: suppressAnnotationField},
+			{level: LogLevel_Warn, field: suppressAnnotationField},
+		},
+	},
+	{
+		file: "server-subpath.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.server.env[ARGOCD_SERVER_ROOTPATH]"},
+			{level: LogLevel_Error, field: ".spec.server.extraCommandArgs: --basehref"},
+		},
+	},
+	{
+		file: "status-badge.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.statusBadgeEnabled"},
+		},
+	},
+	{
+		file: "repo-server-timeout.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.controller.env[ARGOCD_APPLICATION_CONTROLLER_REPO_SERVER_TIMEOUT_SECONDS]"},
+			{level: LogLevel_Error, field: ".spec.extraConfig[controller.repo.server.timeout.seconds]"},
+		},
+	},
+	{
+		file: "partial-resources.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.repo.resources"},
+			{level: LogLevel_Warn, field: ".spec.repo.resources"},
+			{level: LogLevel_Warn, field: ".spec.server.resources"},
+		},
+	},
+	{
+		file: "extraconfig-cmdparams-overlap.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.extraConfig[controller.resource.health.persist]"},
+		},
+	},
+	{
+		file: "component-status.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".status.phase"},
+			{level: LogLevel_Error, field: ".status.conditions[].type = Reconciled"},
+			{level: LogLevel_Error, field: ".status.repo"},
+			{level: LogLevel_Error, field: ".status.server"},
+		},
+	},
+	{
+		file: "insecure-dex-connectors.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.sso.dex.config.connectors[id=keycloak].config.issuer"},
+			{level: LogLevel_Warn, field: ".spec.sso.dex.config.connectors[id=keycloak].config.insecureSkipVerify"},
+			{level: LogLevel_Error, field: ".spec.sso.dex.config.connectors[id=ldap].config.insecureNoSSL"},
+		},
+	},
+	{
+		file: "resource-tracking-label-key.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.applicationInstanceLabelKey"},
+		},
+	},
+	{
+		file: "progressive-syncs-conflict.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.applicationSet.extraCommandArgs: --enable-progressive-syncs"},
+		},
+	},
+	{
+		file: "missing-source-namespaces.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Warn, field: ".spec.sourceNamespaces[team-typo]"},
+			{level: LogLevel_Warn, field: ".spec.applicationSet.sourceNamespaces[prod-*]"},
+		},
+		clusterInfo: clusterInformation{
+			Namespaces: []string{"self-test", "team-a", "team-b"},
+		},
+	},
+	{
+		file: "sharding-replicas.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Error, field: ".spec.controller.sharding.replicas"},
+		},
+	},
+	{
+		file: "being-deleted.yaml",
+		expectedIssues: []expectedIssue{
+			{level: LogLevel_Fatal, field: ".metadata.deletionTimestamp"},
+			{level: LogLevel_Info, field: ".metadata.deletionTimestamp"},
+		},
+	},
+}
+
+// runSelfTest runs the checks against each of the embedded fixture ArgoCD CRs, and reports whether the expected issues were produced. Returns true if all fixtures passed.
+func runSelfTest() bool {
+
+	coloredPass := color.GreenString("PASS")
+	coloredFail := color.RedString("FAIL")
+
+	failures := 0
+
+	for _, fixture := range selfTestFixtures {
+
+		problems := runSelfTestFixture(fixture)
+
+		if len(problems) == 0 {
+			outputStatusMessage("[" + coloredPass + "] " + fixture.file)
+			continue
+		}
+
+		failures++
+		outputStatusMessage("[" + coloredFail + "] " + fixture.file)
+		for _, problem := range problems {
+			outputStatusMessage("- " + problem)
+		}
+	}
+
+	outputStatusMessage("")
+
+	if failures > 0 {
+		outputStatusMessage(fmt.Sprintf("Self-test failed: %d of %d fixtures failed.", failures, len(selfTestFixtures)))
+		return false
+	}
+
+	outputStatusMessage(fmt.Sprintf("Self-test passed: %d of %d fixtures passed.", len(selfTestFixtures), len(selfTestFixtures)))
+	return true
+}
+
+// runSelfTestFixture runs the checks against a single fixture, returning a description of each way in which the result differed from what was expected (or an empty slice on success).
+func runSelfTestFixture(fixture selfTestFixture) []string {
+
+	data, err := selfTestFixturesFS.ReadFile(path.Join("fixtures", fixture.file))
+	if err != nil {
+		return []string{"unable to read fixture: " + err.Error()}
+	}
+
+	var argoCD v1beta1.ArgoCD
+	if err := yaml.UnmarshalStrict(data, &argoCD); err != nil {
+		return []string{"unable to parse fixture: " + err.Error()}
+	}
+
+	issues := suppressIssuesByAnnotation(argoCD, checkIndividualArgoCDCR(argoCD, fixture.clusterInfo))
+
+	problems := []string{}
+
+	if len(fixture.expectedIssues) == 0 {
+		for _, issue := range issues {
+			problems = append(problems, fmt.Sprintf("unexpected issue: [%s] %s: %s", issue.level, issue.field, issue.message))
+		}
+		return problems
+	}
+
+	for _, expected := range fixture.expectedIssues {
+
+		found := false
+		for _, issue := range issues {
+			if issue.level == expected.level && issue.field == expected.field {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("expected issue was not reported: [%s] %s", expected.level, expected.field))
+		}
+	}
+
+	return problems
+}
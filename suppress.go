@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// suppressAnnotation is the ArgoCD CR annotation which lists the rule IDs (comma-separated, e.g. 'ACC012,ACC019') whose findings have been acknowledged for the instance, and so are not reported as issues. This allows a known finding to be acknowledged per instance, in Git, alongside the CR.
+const suppressAnnotation = "config-check.jgwest.io/suppress"
+
+// suppressAnnotationField is the field that issues about the suppression annotation itself are reported against
+const suppressAnnotationField = ".metadata.annotations[" + suppressAnnotation + "]"
+
+// suppressIssuesByAnnotation removes the issues of the rule IDs listed in the suppression annotation of the ArgoCD CR (see suppressAnnotation), and returns the remaining issues.
+// - Suppressed findings are not silently dropped: for each suppressed rule, an Info issue lists the fields of the findings which were suppressed. Info issues do not affect the score, or '--fail-on'.
+// - Fatal issues are never suppressed, since they mean the instance could not be (fully) checked.
+// - A rule ID which does not exist is reported as a Warn, since it is likely a typo (which would leave the intended finding unsuppressed).
+func suppressIssuesByAnnotation(argoCD v1beta1.ArgoCD, issues []issue) []issue {
+
+	value, exists := argoCD.Annotations[suppressAnnotation]
+	if !exists {
+		return issues
+	}
+
+	// The rule IDs to suppress, in the order they are listed in the annotation
+	suppressedRuleIDs := []string{}
+	unknownRuleIDs := []string{}
+
+	for ruleID := range strings.SplitSeq(value, ",") {
+		ruleID = strings.ToUpper(strings.TrimSpace(ruleID))
+		if ruleID == "" {
+			continue
+		}
+		if !isKnownRuleID(ruleID) {
+			unknownRuleIDs = append(unknownRuleIDs, ruleID)
+			continue
+		}
+		suppressedRuleIDs = append(suppressedRuleIDs, ruleID)
+	}
+
+	res := []issue{}
+
+	// key: rule ID, value: the fields of the suppressed findings of the rule
+	suppressedFields := map[string][]string{}
+
+	for _, currIssue := range issues {
+		ruleID := strings.ToUpper(currIssue.ruleID)
+		if currIssue.level != LogLevel_Fatal && ruleID != "" && slices.Contains(suppressedRuleIDs, ruleID) {
+			suppressedFields[ruleID] = append(suppressedFields[ruleID], currIssue.field)
+			continue
+		}
+		res = append(res, currIssue)
+	}
+
+	if len(unknownRuleIDs) > 0 {
+		res = append(res, issue{
+			level:   LogLevel_Warn,
+			field:   suppressAnnotationField,
+			message: fmt.Sprintf("The '%s' annotation lists rule ID(s) which do not exist: %s. Findings can only be suppressed by the rule ID reported with them (e.g. 'ACC012'): correct or remove the unknown rule ID(s).", suppressAnnotation, strings.Join(unknownRuleIDs, ", ")),
+		})
+	}
+
+	for _, ruleID := range suppressedRuleIDs {
+
+		fields, suppressed := suppressedFields[ruleID]
+		if !suppressed {
+			res = append(res, issue{
+				level:   LogLevel_Info,
+				field:   suppressAnnotationField,
+				message: fmt.Sprintf("Rule %s is suppressed by the '%s' annotation, but no findings of the rule were reported. If the finding has been resolved, remove %s from the annotation, so that it is reported if it recurs.", ruleID, suppressAnnotation, ruleID),
+			})
+			continue
+		}
+
+		rule := ruleID
+		if registration := findCheckRegistration(ruleID); registration != nil {
+			rule = ruleID + " (" + registration.title + ")"
+		}
+
+		res = append(res, issue{
+			level:   LogLevel_Info,
+			field:   suppressAnnotationField,
+			message: fmt.Sprintf("Suppressed by annotation: %d finding(s) of rule %s were not reported, since the rule is listed in the '%s' annotation. Field(s): %s", len(fields), rule, suppressAnnotation, strings.Join(fields, ", ")),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// teamCityDefaultInspectionTypeID is the TeamCity inspection type of issues which are not reported by a registered check (and thus have no rule ID)
+const teamCityDefaultInspectionTypeID = "argocd-config-check"
+
+// teamCityInspectionSeverity returns the TeamCity inspection severity that is used for issues of the given log level
+func teamCityInspectionSeverity(level LogLevel) string {
+	switch level {
+	case LogLevel_Fatal, LogLevel_Error:
+		return "ERROR"
+	case LogLevel_Warn:
+		return "WARNING"
+	default:
+		return "INFO"
+	}
+}
+
+// teamCityEscapes are the characters of a TeamCity service message attribute value which are escaped with a '|'
+var teamCityEscapes = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+)
+
+// escapeTeamCityValue escapes an attribute value of a TeamCity service message, so that it is output as a single message.
+// - Non-ASCII characters are escaped as '|0xNNNN' (the UTF-16 code units of the character), since TeamCity otherwise decodes the output using the build agent's default charset, which may not be UTF-8.
+func escapeTeamCityValue(value string) string {
+
+	var res strings.Builder
+
+	for _, r := range teamCityEscapes.Replace(value) {
+		if r < utf8.RuneSelf {
+			res.WriteRune(r)
+			continue
+		}
+		for _, unit := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&res, "|0x%04X", unit)
+		}
+	}
+
+	return res.String()
+}
+
+// teamCityBuildProblemIdentity returns a stable identity for a TeamCity build problem, so that TeamCity can track the same problem across builds. TeamCity limits identities to 60 characters, so the identity is a hash of the problem's location.
+func teamCityBuildProblemIdentity(parts ...string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("argocd-config-check-%x", hash.Sum64())
+}
+
+// outputResultsAsTeamCityServiceMessages writes each issue of the check results as a TeamCity service message (for example "##teamcity[inspection typeId='ACC004' ...]"), so that the issues are shown natively on the build results.
+// - Fatal issues are output as build problems (which fail the build), all other issues as inspections, with Error, Warn, and other issues mapped to the ERROR, WARNING, and INFO inspection severities.
+// - The inspection 'file' is the ArgoCD instance ('namespace/name'), and the field is included in the message.
+// - Inspections do not themselves fail the build: use '--fail-on' to exit with a non-zero status code, or a TeamCity failure condition on the inspection count.
+func outputResultsAsTeamCityServiceMessages(results checkResults) {
+
+	// Each inspection type must be declared (once) before it is referenced by an inspection
+	declaredInspectionTypes := map[string]bool{}
+	declareInspectionType := func(typeID string) {
+		if declaredInspectionTypes[typeID] {
+			return
+		}
+		declaredInspectionTypes[typeID] = true
+
+		name := "ArgoCD configuration check"
+		description := "Issue reported by argocd-config-check"
+		if registration := findCheckRegistration(typeID); registration != nil {
+			name = registration.title
+			description = registration.explanation
+		}
+
+		fmt.Fprintf(reportOutput, "##teamcity[inspectionType id='%s' name='%s' category='ArgoCD configuration' description='%s']\n", escapeTeamCityValue(typeID), escapeTeamCityValue(name), escapeTeamCityValue(description))
+	}
+
+	for _, entry := range results.installEntries {
+
+		message := results.operatorInstallationName() + ": " + entry.message
+
+		if entry.level == LogLevel_Fatal {
+			identityParts := []string{"installation", entry.message}
+			if results.cluster != "" {
+				identityParts = append([]string{results.cluster}, identityParts...)
+			}
+			fmt.Fprintf(reportOutput, "##teamcity[buildProblem description='%s' identity='%s']\n", escapeTeamCityValue(message), teamCityBuildProblemIdentity(identityParts...))
+			continue
+		}
+
+		declareInspectionType(teamCityDefaultInspectionTypeID)
+		fmt.Fprintf(reportOutput, "##teamcity[inspection typeId='%s' message='%s' file='%s' SEVERITY='%s']\n", teamCityDefaultInspectionTypeID, escapeTeamCityValue(message), escapeTeamCityValue(results.operatorInstallationName()), teamCityInspectionSeverity(entry.level))
+	}
+
+	for _, instance := range results.instances {
+
+		instanceName := results.instanceName(instance)
+
+		for _, issue := range instance.issues {
+
+			message := issue.message
+			if issue.unsupported {
+				message = "[Unsupported] " + message
+			}
+			message = issue.field + ": " + message
+
+			if issue.level == LogLevel_Fatal {
+				description := "ArgoCD '" + instanceName + "' " + message
+				if issue.ruleID != "" {
+					description = issue.ruleID + " " + description
+				}
+				fmt.Fprintf(reportOutput, "##teamcity[buildProblem description='%s' identity='%s']\n", escapeTeamCityValue(description), teamCityBuildProblemIdentity(instanceName, issue.ruleID, issue.field))
+				continue
+			}
+
+			typeID := issue.ruleID
+			if typeID == "" {
+				typeID = teamCityDefaultInspectionTypeID
+			}
+			declareInspectionType(typeID)
+
+			fmt.Fprintf(reportOutput, "##teamcity[inspection typeId='%s' message='%s' file='%s' SEVERITY='%s']\n", escapeTeamCityValue(typeID), escapeTeamCityValue(message), escapeTeamCityValue(instanceName), teamCityInspectionSeverity(issue.level))
+		}
+	}
+}
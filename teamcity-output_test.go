@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestEscapeTeamCityValue(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "plain", value: "team-a/argocd .spec.server", expected: "team-a/argocd .spec.server"},
+		{name: "special characters", value: "it's [x]|y\r\n", expected: "it|'s |[x|]||y|r|n"},
+		{name: "non-ASCII", value: "Bannière é", expected: "Banni|0x00E8re |0x00E9"},
+		{name: "line separator", value: "a\u2028b", expected: "a|0x2028b"},
+		{name: "outside the basic multilingual plane", value: "✓ 🚀", expected: "|0x2713 |0xD83D|0xDE80"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := escapeTeamCityValue(test.value); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/jgwest/argocd-config-check/clients"
+)
+
+// topologyFormat_JSON is the only supported '--topology' format
+const topologyFormat_JSON = "json"
+
+// jsonTopologySchemaVersion is the version of the structure of the '--topology json' document (see jsonTopology). This is independent of the version of the check results document (see jsonSchemaVersion), and follows the same compatibility contract.
+const jsonTopologySchemaVersion = 1
+
+// jsonTopology is the JSON document output by '--topology json': which namespaces are managed by which Argo CD instances, based on the managed-by namespace labels.
+type jsonTopology struct {
+	// SchemaVersion is always the first field of the document. See jsonTopologySchemaVersion.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Kind is always jsonDocumentKind_Topology, which distinguishes this document from the check results document (see jsonDocumentKind)
+	Kind jsonDocumentKind `json:"kind"`
+
+	// ClusterScopedNamespaces are the namespaces of cluster-scoped Argo CD instances (from the Subscription 'ARGOCD_CLUSTER_CONFIG_NAMESPACES' env)
+	ClusterScopedNamespaces []string `json:"clusterScopedNamespaces"`
+
+	// Instances is keyed by the namespace of the managing Argo CD instance. This includes both the namespaces containing ArgoCD CRs, and namespaces that are referenced by a managed-by label (even if no ArgoCD CR exists in that namespace).
+	Instances map[string]*jsonTopologyInstance `json:"instances"`
+}
+
+type jsonTopologyInstance struct {
+	// ArgoCDNames are the names of the ArgoCD CRs in the namespace (empty if there are none)
+	ArgoCDNames []string `json:"argoCDNames"`
+
+	ClusterScoped bool `json:"clusterScoped"`
+
+	// ManagedNamespaces is keyed by label (e.g. 'argocd.argoproj.io/managed-by'), with the value being the namespaces that have that label pointing at the instance
+	ManagedNamespaces map[string][]string `json:"managedNamespaces"`
+}
+
+// outputTopology outputs the relationships between namespaces and Argo CD instances (used by '--topology').
+func outputTopology(ctx context.Context, k8sClient clients.AbstractK8sClient) {
+
+	clusterInfo, entries := acquireInstallConfigurationData(ctx, k8sClient)
+
+	outputEntryList(entries)
+
+	if entryListContainsFatal(entries) {
+		failWithError("unable to determine the cluster topology, due to the above error", nil)
+	}
+
+	data, err := json.MarshalIndent(toJSONTopology(clusterInfo, listArgoCDs(ctx, k8sClient).Items), "", "  ")
+	if err != nil {
+		failWithError("unable to convert topology to JSON", err)
+	}
+
+	fmt.Fprintln(reportOutput, string(data))
+}
+
+// toJSONTopology returns the topology document of the ArgoCD CRs on the cluster, and the managed-by namespace labels of the cluster
+func toJSONTopology(clusterInfo clusterInformation, argoCDs []v1beta1.ArgoCD) jsonTopology {
+
+	topology := jsonTopology{
+		SchemaVersion:           jsonTopologySchemaVersion,
+		Kind:                    jsonDocumentKind_Topology,
+		ClusterScopedNamespaces: nonNilStrings(clusterInfo.ClusterScopedNamespaces),
+		Instances:               map[string]*jsonTopologyInstance{},
+	}
+
+	instanceOf := func(namespace string) *jsonTopologyInstance {
+		instance, exists := topology.Instances[namespace]
+		if !exists {
+			instance = &jsonTopologyInstance{
+				ArgoCDNames:       []string{},
+				ClusterScoped:     slices.Contains(clusterInfo.ClusterScopedNamespaces, namespace),
+				ManagedNamespaces: map[string][]string{},
+			}
+			topology.Instances[namespace] = instance
+		}
+		return instance
+	}
+
+	for _, argoCD := range argoCDs {
+		instance := instanceOf(argoCD.Namespace)
+		instance.ArgoCDNames = append(instance.ArgoCDNames, argoCD.Name)
+	}
+
+	for _, labelMap := range namespaceLabelMaps(clusterInfo) {
+		for namespace, managingNamespace := range labelMap.namespaces {
+			instance := instanceOf(managingNamespace)
+			instance.ManagedNamespaces[labelMap.label] = append(instance.ManagedNamespaces[labelMap.label], namespace)
+		}
+	}
+
+	for _, instance := range topology.Instances {
+		sort.Strings(instance.ArgoCDNames)
+		for _, namespaces := range instance.ManagedNamespaces {
+			sort.Strings(namespaces)
+		}
+	}
+
+	return topology
+}
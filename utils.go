@@ -1,11 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
 )
 
 func failWithError(str string, err error) {
@@ -18,6 +27,16 @@ func failWithError(str string, err error) {
 	os.Exit(1)
 }
 
+// isTerminal returns true if the file is an interactive terminal (rather than e.g. a pipe or a regular file)
+func isTerminal(f *os.File) bool {
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
 // containerArgsContainsParam returns true if args contains --paramKey=value or --paramKey value, false otherwise.
 func containerArgsContainsParamKV(args []string, paramKey string, paramValue string) bool {
 
@@ -85,6 +104,68 @@ func containerEnvVarContainsName(envs []corev1.EnvVar, name string) bool {
 	return false
 }
 
+// containerArgsParamValue returns the value of a param in args (from either '--paramKey=value' or '--paramKey value'), and true if it is set.
+func containerArgsParamValue(args []string, paramKey string) (string, bool) {
+
+	// If calling function specified '--paramKey' (rather than only 'paramKey') then just strip it.
+	paramKey = strings.TrimPrefix(paramKey, "--")
+
+	for i, arg := range args {
+		// Strip quotes from the argument. It's technically valid to include these in an arg string, but we don't care about them here.
+		arg = strings.ReplaceAll(arg, "'", "")
+		arg = strings.ReplaceAll(arg, "\"", "")
+
+		if value, found := strings.CutPrefix(arg, "--"+paramKey+"="); found {
+			return value, true
+		}
+
+		if arg == "--"+paramKey && i+1 < len(args) {
+			nextArg := args[i+1]
+			nextArg = strings.ReplaceAll(nextArg, "'", "")
+			nextArg = strings.ReplaceAll(nextArg, "\"", "")
+			return nextArg, true
+		}
+	}
+
+	return "", false
+}
+
+// containerEnvVarValue returns the (literal) value of the env var with the given name, and true if it is set. Env vars whose value is read from another resource (via 'valueFrom') are treated as not set, since their value is not known.
+func containerEnvVarValue(envs []corev1.EnvVar, name string) (string, bool) {
+	for _, envVar := range envs {
+		if envVar.Name == name && envVar.ValueFrom == nil {
+			return envVar.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// containerArgsBoolParamValue returns the value of a boolean param in args, and true if it is set with a valid value: '--paramKey' is true, and '--paramKey=value' is the value parsed by strconv.ParseBool.
+func containerArgsBoolParamValue(args []string, paramKey string) (bool, bool) {
+
+	// If calling function specified '--paramKey' (rather than only 'paramKey') then just strip it.
+	paramKey = strings.TrimPrefix(paramKey, "--")
+
+	for _, arg := range args {
+		// Strip quotes from the argument. It's technically valid to include these in an arg string, but we don't care about them here.
+		arg = strings.ReplaceAll(arg, "'", "")
+		arg = strings.ReplaceAll(arg, "\"", "")
+
+		if arg == "--"+paramKey {
+			return true, true
+		}
+
+		if value, found := strings.CutPrefix(arg, "--"+paramKey+"="); found {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return parsed, true
+			}
+		}
+	}
+
+	return false, false
+}
+
 func containerEnvVarContainsKeyValue(envs []corev1.EnvVar, key string, value string) bool {
 	for _, envVar := range envs {
 		if envVar.Name == key && envVar.Value == value {
@@ -94,3 +175,160 @@ func containerEnvVarContainsKeyValue(envs []corev1.EnvVar, key string, value str
 
 	return false
 }
+
+// valueParsesAsDuration returns true if the value is a valid Go duration string, e.g. '180s' or '3m'. Argo CD parses its duration env vars using time.ParseDuration, so a unitless value (e.g. '180') is NOT valid.
+func valueParsesAsDuration(value string) bool {
+	_, err := time.ParseDuration(strings.TrimSpace(value))
+	return err == nil
+}
+
+// valueParsesAsInt returns true if the value is a valid base-10 integer
+func valueParsesAsInt(value string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(value))
+	return err == nil
+}
+
+// valueParsesAsBool returns true if the value is a valid boolean, as accepted by strconv.ParseBool (e.g. 'true', 'false', '1', '0')
+func valueParsesAsBool(value string) bool {
+	_, err := strconv.ParseBool(strings.TrimSpace(value))
+	return err == nil
+}
+
+// knownHostsKeyTypes are the SSH key types that may appear in a known_hosts entry
+var knownHostsKeyTypes = map[string]bool{
+	"ssh-rsa":                            true,
+	"ssh-dss":                            true,
+	"ssh-ed25519":                        true,
+	"ecdsa-sha2-nistp256":                true,
+	"ecdsa-sha2-nistp384":                true,
+	"ecdsa-sha2-nistp521":                true,
+	"sk-ssh-ed25519@openssh.com":         true,
+	"sk-ecdsa-sha2-nistp256@openssh.com": true,
+}
+
+// parseKnownHostsLine verifies that a single line of an SSH known_hosts file has the structure '[@marker] host[,host...] keytype base64-key [comment]'. Blank lines and comments are valid. Returns an error describing the problem if the line is malformed.
+func parseKnownHostsLine(line string) error {
+
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	fields := strings.Fields(line)
+
+	// Optional marker, e.g. '@cert-authority *.example.com ssh-rsa AAAA...'
+	if strings.HasPrefix(fields[0], "@") {
+		if fields[0] != "@cert-authority" && fields[0] != "@revoked" {
+			return fmt.Errorf("unknown marker '%s'", fields[0])
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) < 3 {
+		return fmt.Errorf("expected 'host keytype key', but found %d field(s)", len(fields))
+	}
+
+	keyType := fields[1]
+	if !knownHostsKeyTypes[keyType] {
+		return fmt.Errorf("unrecognized key type '%s'", keyType)
+	}
+
+	keyData, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil {
+		return fmt.Errorf("key is not valid base64: %v", err)
+	}
+
+	// The key data begins with the key type, as a length-prefixed string, which should match the key type field
+	if len(keyData) < 4 {
+		return fmt.Errorf("key data is too short")
+	}
+	embeddedKeyTypeLength := binary.BigEndian.Uint32(keyData[0:4])
+	if uint64(len(keyData)) < 4+uint64(embeddedKeyTypeLength) {
+		return fmt.Errorf("key data is truncated")
+	}
+	if embeddedKeyType := string(keyData[4 : 4+embeddedKeyTypeLength]); embeddedKeyType != keyType {
+		return fmt.Errorf("key type field is '%s', but the key data is of type '%s'", keyType, embeddedKeyType)
+	}
+
+	return nil
+}
+
+// parsePEMCertificates verifies that the data contains one or more PEM-encoded X.509 certificates, and nothing else. Returns an error describing the problem if not.
+func parsePEMCertificates(data string) error {
+
+	rest := []byte(strings.TrimSpace(data))
+	if len(rest) == 0 {
+		return fmt.Errorf("certificate data is empty")
+	}
+
+	certificates := 0
+
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			if certificates == 0 {
+				return fmt.Errorf("data could not be decoded as PEM")
+			}
+			return fmt.Errorf("data after certificate %d could not be decoded as PEM", certificates)
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("expected PEM block of type 'CERTIFICATE', but found '%s'", block.Type)
+		}
+
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("certificate %d could not be parsed: %v", certificates+1, err)
+		}
+
+		certificates++
+		rest = bytes.TrimSpace(rest)
+	}
+
+	return nil
+}
+
+// parseYAMLConfigValue parses a YAML (or JSON) value from an Argo CD ConfigMap key (e.g. an '.spec.extraConfig' value) into 'into'. Keys which are not fields of 'into' are reported as an error, since Argo CD will either reject or ignore them.
+func parseYAMLConfigValue(value string, into any) error {
+	return yaml.UnmarshalStrict([]byte(value), into)
+}
+
+// writeFileAtomically writes data to a temporary file in the same directory as 'path', then renames it to 'path'. Readers of 'path' will thus see either the previous file contents, or the complete new contents, but never a partially written file.
+func writeFileAtomically(path string, data []byte) error {
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempFilePath := tempFile.Name()
+
+	// Write and flush the file before renaming, so that the rename never exposes incomplete data
+	writeErr := func() error {
+		if _, err := tempFile.Write(data); err != nil {
+			return err
+		}
+		if err := tempFile.Sync(); err != nil {
+			return err
+		}
+		if err := tempFile.Chmod(0o644); err != nil {
+			return err
+		}
+		return nil
+	}()
+
+	if closeErr := tempFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+
+	if writeErr != nil {
+		os.Remove(tempFilePath)
+		return writeErr
+	}
+
+	if err := os.Rename(tempFilePath, path); err != nil {
+		os.Remove(tempFilePath)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sshKeyData returns the base64-encoded key data of a known_hosts entry, whose embedded key type is 'keyType'
+func sshKeyData(keyType string) string {
+
+	res := binary.BigEndian.AppendUint32(nil, uint32(len(keyType)))
+	res = append(res, keyType...)
+	res = binary.BigEndian.AppendUint32(res, 32)
+	res = append(res, make([]byte, 32)...)
+
+	return base64.StdEncoding.EncodeToString(res)
+}
+
+func TestParseKnownHostsLine(t *testing.T) {
+
+	ed25519Key := sshKeyData("ssh-ed25519")
+
+	tests := []struct {
+		name string
+		line string
+
+		// expectedErr is a substring of the expected error, or empty if the line is valid
+		expectedErr string
+	}{
+		{name: "host entry", line: "github.com ssh-ed25519 " + ed25519Key},
+		{name: "multiple hosts, with a comment", line: "github.com,140.82.112.3 ssh-ed25519 " + ed25519Key + " github key"},
+		{name: "hashed host", line: "|1|F1E1KeoE/eEWhi10WpGv4OdiO6Y=|3988QV0VE8wmZL7suNrYQLITLCg= ssh-ed25519 " + ed25519Key},
+		{name: "cert-authority marker", line: "@cert-authority *.example.com ssh-ed25519 " + ed25519Key},
+		{name: "revoked marker", line: "@revoked bitbucket.org ssh-ed25519 " + ed25519Key},
+		{name: "comment", line: "# GitHub keys, from https://api.github.com/meta"},
+		{name: "indented comment", line: "   # comment"},
+		{name: "blank", line: ""},
+		{name: "whitespace only", line: " \t "},
+		{name: "unknown marker", line: "@trusted *.example.com ssh-ed25519 " + ed25519Key, expectedErr: "unknown marker"},
+		{name: "marker without key", line: "@cert-authority *.example.com ssh-ed25519", expectedErr: "found 2 field(s)"},
+		{name: "missing key", line: "github.com ssh-ed25519", expectedErr: "found 2 field(s)"},
+		{name: "unrecognized key type", line: "github.com ssh-foo " + ed25519Key, expectedErr: "unrecognized key type"},
+		{name: "key is not base64", line: "github.com ssh-ed25519 not-base64!", expectedErr: "not valid base64"},
+		{name: "key data too short", line: "github.com ssh-ed25519 AAA=", expectedErr: "too short"},
+		{name: "key data is of another type", line: "github.com ssh-rsa " + ed25519Key, expectedErr: "key data is of type 'ssh-ed25519'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			err := parseKnownHostsLine(test.line)
+
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("expected an error containing '%s', got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+// pemCertificate returns a self-signed PEM-encoded certificate which is valid from 'notBefore' until 'notAfter'
+func pemCertificate(t *testing.T, notBefore time.Time, notAfter time.Time) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParsePEMCertificates(t *testing.T) {
+
+	now := time.Now()
+	certificate := pemCertificate(t, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiredCertificate := pemCertificate(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	tests := []struct {
+		name string
+		data string
+
+		// expectedErr is a substring of the expected error, or empty if the data is valid
+		expectedErr string
+	}{
+		{name: "single certificate", data: certificate},
+		{name: "bundle", data: certificate + "\n" + certificate},
+		{name: "surrounding whitespace", data: "\n  " + certificate + "\n\n"},
+		// The check is structural: an expired certificate is still a well-formed certificate
+		{name: "expired certificate", data: expiredCertificate},
+		{name: "empty", data: "  \n", expectedErr: "empty"},
+		{name: "not PEM", data: "not a certificate", expectedErr: "could not be decoded as PEM"},
+		{name: "bundle with a trailing garbage block", data: certificate + "\n" + certificate + "\ngarbage", expectedErr: "data after certificate 2"},
+		{
+			name:        "private key",
+			data:        string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})),
+			expectedErr: "expected PEM block of type 'CERTIFICATE'",
+		},
+		{
+			name:        "certificate block which is not a certificate",
+			data:        certificate + string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not DER")})),
+			expectedErr: "certificate 2 could not be parsed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			err := parsePEMCertificates(test.data)
+
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("expected an error containing '%s', got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, injected at build time via '-ldflags', for example:
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.gitCommit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// When not injected, the git commit and build date are read from the VCS information that 'go build' embeds in the binary (if available).
+var (
+	version   = "dev"
+	gitCommit = ""
+	buildDate = ""
+)
+
+// versionReportedModules are the modules whose versions are output by '--version', since the behaviour of many checks depends on the API types (and defaults) of these modules
+var versionReportedModules = []struct {
+	description string
+	path        string
+}{
+	{description: "Argo CD operator API", path: "github.com/argoproj-labs/argocd-operator"},
+	{description: "Argo CD API", path: "github.com/argoproj/argo-cd/v3"},
+}
+
+// outputVersion outputs the version and build information of the tool, and the versions of the key embedded APIs
+func outputVersion() {
+
+	commit, date := gitCommit, buildDate
+
+	buildInfo, buildInfoAvailable := debug.ReadBuildInfo()
+
+	if buildInfoAvailable {
+		for _, setting := range buildInfo.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && commit == "":
+				commit = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+
+	outputStatusMessage("argocd-config-check " + version)
+	outputStatusMessage("- Git commit: " + valueOrUnknown(commit))
+	outputStatusMessage("- Build date: " + valueOrUnknown(date))
+	outputStatusMessage("- Go version: " + runtime.Version())
+
+	for _, module := range versionReportedModules {
+		moduleVersion := ""
+		if buildInfoAvailable {
+			for _, dependency := range buildInfo.Deps {
+				if dependency.Path == module.path {
+					moduleVersion = dependency.Version
+					if dependency.Replace != nil {
+						moduleVersion = dependency.Replace.Path + " " + dependency.Replace.Version
+					}
+				}
+			}
+		}
+		outputStatusMessage("- " + module.description + " (" + module.path + "): " + valueOrUnknown(moduleVersion))
+	}
+}
+
+// valueOrUnknown returns 'value', or 'unknown' if it is empty
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
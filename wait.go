@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jgwest/argocd-config-check/clients"
+)
+
+// exitCode_WaitTimedOut is the exit status code used (with '--wait-for-available') when the ArgoCD instances did not become available before the timeout
+const exitCode_WaitTimedOut = 6
+
+// waitForAvailablePollInterval is how often the status of the ArgoCD CRs is read, while waiting for them to become available
+const waitForAvailablePollInterval = 5 * time.Second
+
+// waitForAvailableArgoCDs polls the ArgoCD CRs on the (live) cluster until all of them are available, or until the timeout expires, and returns the exit status code: 0 if all instances became available, otherwise exitCode_WaitTimedOut. This allows the tool to be used as a gate in install scripts, so that subsequent steps do not race the operator.
+// - An instance is available when its '.status' reports no problems (see checkArgoCDStatusField): '.status.phase' is 'Available', the 'Reconciled' condition is true, and each enabled component is 'Running'.
+// - Until at least one ArgoCD CR exists (for example, while the operator and its CRD are still being installed), the wait continues.
+// - What is blocking availability is output whenever it changes, and again on timeout.
+func waitForAvailableArgoCDs(ctx context.Context, k8sClient clients.AbstractK8sClient, timeout time.Duration) int {
+
+	outputStatusMessage(fmt.Sprintf("Waiting up to %s for the ArgoCD instance(s) to become available...", timeout))
+
+	deadline := time.Now().Add(timeout)
+
+	var previousBlockers []string
+
+	for {
+		blockers := argoCDAvailabilityBlockers(ctx, k8sClient)
+
+		if len(blockers) == 0 {
+			outputStatusMessage("All ArgoCD instance(s) are available.")
+			return 0
+		}
+
+		if !slices.Equal(blockers, previousBlockers) {
+			outputStatusMessage(fmt.Sprintf("[%s] Not yet available:", time.Now().Format(time.TimeOnly)))
+			for _, blocker := range blockers {
+				outputStatusMessage("- " + blocker)
+			}
+			previousBlockers = blockers
+		}
+
+		if !time.Now().Before(deadline) {
+			outputStatusMessage("")
+			outputStatusMessage(fmt.Sprintf("Timed out after %s waiting for the ArgoCD instance(s) to become available. Blocked by:", timeout))
+			for _, blocker := range blockers {
+				outputStatusMessage("- " + blocker)
+			}
+			return exitCode_WaitTimedOut
+		}
+
+		select {
+		case <-ctx.Done():
+			return exitCode_WaitTimedOut
+		case <-time.After(min(waitForAvailablePollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// argoCDAvailabilityBlockers returns a description of each reason that the ArgoCD CRs are not (all) available, in the form '(namespace)/(name): (field): (message)', or an empty list if all of them are available.
+func argoCDAvailabilityBlockers(ctx context.Context, k8sClient clients.AbstractK8sClient) []string {
+
+	argoCDList, err := tryListArgoCDs(ctx, k8sClient)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	res := []string{}
+
+	for _, argoCD := range argoCDList.Items {
+
+		issues := []issue{}
+		checkArgoCDStatusField(argoCD, &issues)
+		sortIssuesByField(issues)
+
+		for _, issue := range issues {
+			res = append(res, fmt.Sprintf("%s/%s: %s: %s", argoCD.Namespace, argoCD.Name, issue.field, strings.TrimSpace(issue.message)))
+		}
+	}
+
+	return res
+}